@@ -26,6 +26,7 @@ const (
 	InstanceMetaKey    = GroupPrefix + "/instance"
 	ResourceSeperator  = "-v-"
 	ReferenceSeparator = ":"
+	RevisionSeparator  = "@"
 	DisplayName        = "displayName"
 	RootResource       = "rootResource"
 	ParentName         = "parentName"
@@ -33,6 +34,8 @@ const (
 	Solution           = "solution"
 	Target             = "target"
 	Campaign           = "campaign"
+	ParentActivation   = GroupPrefix + "/parent-activation"
+	ActivationSchedule = GroupPrefix + "/activation-schedule"
 )
 
 // Environment variables keys
@@ -94,6 +94,15 @@ func (s *ActivationsCleanupManager) Poll() []error {
 		}
 		duration := time.Since(updateTime)
 		if duration > s.RetentionDuration {
+			if s.ActivationsManager.ArchivingEnabled() {
+				// keep the history of the activation for audits, and delete it only once it's archived
+				err = s.ActivationsManager.ArchiveActivation(ctx, activation)
+				if err != nil {
+					log.ErrorfCtx(ctx, "M (Activation Cleanup): Cannot archive activation %s: %+v", activation.ObjectMeta.Name, err)
+					ret = append(ret, err)
+					continue
+				}
+			}
 			log.InfofCtx(ctx, "M (Activation Cleanup): Deleting activation %s since it has completed for %s", activation.ObjectMeta.Name, duration.String())
 			err = s.ActivationsManager.DeleteState(ctx, activation.ObjectMeta.Name, activation.ObjectMeta.Namespace)
 			if err != nil {
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package activations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
+	observability "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability"
+	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
+)
+
+const (
+	// ProvidersArchiveState names the state provider that keeps the archives of completed activations
+	ProvidersArchiveState = "providers.archivestate"
+	archiveResource       = "activationarchives"
+	archiveKind           = "ActivationArchive"
+)
+
+// getArchiveStateProvider returns the archive state provider of the manager config, or nil if archiving isn't configured
+func getArchiveStateProvider(config managers.ManagerConfig, providers map[string]providers.IProvider) (states.IStateProvider, error) {
+	name, ok := config.Properties[ProvidersArchiveState]
+	if !ok {
+		return nil, nil
+	}
+	provider, ok := providers[name]
+	if !ok {
+		return nil, v1alpha2.NewCOAError(nil, "archive state provider is not supplied", v1alpha2.MissingConfig)
+	}
+	stateProvider, ok := provider.(states.IStateProvider)
+	if !ok {
+		return nil, v1alpha2.NewCOAError(nil, "supplied archive provider is not a state provider", v1alpha2.BadConfig)
+	}
+	return stateProvider, nil
+}
+
+func archiveMetadata(namespace string) map[string]interface{} {
+	return map[string]interface{}{
+		"version":   "v1",
+		"group":     model.WorkflowGroup,
+		"resource":  archiveResource,
+		"namespace": namespace,
+		"kind":      archiveKind,
+	}
+}
+
+// ArchivingEnabled tells if completed activations are archived before they are deleted
+func (m *ActivationsManager) ArchivingEnabled() bool {
+	return m.ArchiveStateProvider != nil
+}
+
+// ArchiveActivation compacts a completed activation into an archive record. Stage inputs that read a $secret() in the
+// campaign are redacted along with the inputs and outputs whose keys name a secret.
+func (m *ActivationsManager) ArchiveActivation(ctx context.Context, activation model.ActivationState) error {
+	ctx, span := observability.StartSpan("Activations Manager", ctx, &map[string]string{
+		"method": "ArchiveActivation",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	if m.ArchiveStateProvider == nil {
+		err = v1alpha2.NewCOAError(nil, "activation archiving is not configured", v1alpha2.BadConfig)
+		return err
+	}
+	log.InfofCtx(ctx, "Archive activation %s in namespace %s", activation.ObjectMeta.Name, activation.ObjectMeta.Namespace)
+
+	archive := model.NewActivationArchive(activation, m.secretInputs(ctx, activation))
+	_, err = m.ArchiveStateProvider.Upsert(ctx, states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   archive.ID,
+			Body: archive,
+		},
+		Metadata: archiveMetadata(activation.ObjectMeta.Namespace),
+	})
+	return err
+}
+
+// secretInputs returns the stage inputs that read a $secret() in the campaign of the activation. The campaign may be
+// gone by the time its activations are archived, in which case only the inputs whose keys name a secret are redacted.
+func (m *ActivationsManager) secretInputs(ctx context.Context, activation model.ActivationState) map[string][]string {
+	ret := map[string][]string{}
+	if activation.Spec == nil || activation.Spec.Campaign == "" {
+		return ret
+	}
+	body, err := m.CampaignLookup(ctx, utils.ConvertReferenceToObjectName(activation.Spec.Campaign), activation.ObjectMeta.Namespace)
+	if err != nil {
+		log.InfofCtx(ctx, "Campaign %s of activation %s is not found, only inputs named as secrets are redacted: %v", activation.Spec.Campaign, activation.ObjectMeta.Name, err)
+		return ret
+	}
+	var campaign model.CampaignState
+	data, _ := json.Marshal(body)
+	if err = json.Unmarshal(data, &campaign); err != nil || campaign.Spec == nil {
+		return ret
+	}
+	for name, stage := range campaign.Spec.Stages {
+		for key, value := range stage.Inputs {
+			if s, ok := value.(string); ok && strings.Contains(s, "$secret(") {
+				ret[name] = append(ret[name], key)
+			}
+		}
+	}
+	return ret
+}
+
+// GetArchive returns an archive by its ID, or the latest archive of the activations with the name
+func (m *ActivationsManager) GetArchive(ctx context.Context, name string, namespace string) (model.ActivationArchive, error) {
+	ctx, span := observability.StartSpan("Activations Manager", ctx, &map[string]string{
+		"method": "GetArchive",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	if m.ArchiveStateProvider == nil {
+		err = v1alpha2.NewCOAError(nil, "activation archiving is not configured", v1alpha2.NotFound)
+		return model.ActivationArchive{}, err
+	}
+	var entry states.StateEntry
+	entry, err = m.ArchiveStateProvider.Get(ctx, states.GetRequest{
+		ID:       name,
+		Metadata: archiveMetadata(namespace),
+	})
+	if err == nil {
+		var ret model.ActivationArchive
+		ret, err = getActivationArchive(entry.Body)
+		return ret, err
+	}
+	if !v1alpha2.IsNotFound(err) {
+		return model.ActivationArchive{}, err
+	}
+	// an activation name can be reused, so the activation may have more than one archive
+	var archives []model.ActivationArchive
+	archives, err = m.ListArchives(ctx, namespace, model.ActivationArchiveQuery{Name: name})
+	if err != nil {
+		return model.ActivationArchive{}, err
+	}
+	if len(archives) == 0 {
+		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("archive of activation '%s' is not found", name), v1alpha2.NotFound)
+		return model.ActivationArchive{}, err
+	}
+	return archives[0], nil
+}
+
+// ListArchives lists the archives in a namespace that match the query, the most recently completed first
+func (m *ActivationsManager) ListArchives(ctx context.Context, namespace string, query model.ActivationArchiveQuery) ([]model.ActivationArchive, error) {
+	ctx, span := observability.StartSpan("Activations Manager", ctx, &map[string]string{
+		"method": "ListArchives",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	ret := make([]model.ActivationArchive, 0)
+	if m.ArchiveStateProvider == nil {
+		return ret, nil
+	}
+	var entries []states.StateEntry
+	entries, _, err = m.ArchiveStateProvider.List(ctx, states.ListRequest{
+		Metadata: archiveMetadata(namespace),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		var archive model.ActivationArchive
+		archive, err = getActivationArchive(entry.Body)
+		if err != nil {
+			return nil, err
+		}
+		if query.Matches(archive) {
+			ret = append(ret, archive)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].EndTime > ret[j].EndTime
+	})
+	return ret, nil
+}
+
+func getActivationArchive(body interface{}) (model.ActivationArchive, error) {
+	var archive model.ActivationArchive
+	data, _ := json.Marshal(body)
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return model.ActivationArchive{}, v1alpha2.NewCOAError(err, "invalid activation archive", v1alpha2.InternalError)
+	}
+	return archive, nil
+}
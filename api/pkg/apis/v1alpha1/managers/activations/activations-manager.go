@@ -35,8 +35,10 @@ var log = logger.NewLogger("coa.runtime")
 type ActivationsManager struct {
 	managers.Manager
 	StateProvider states.IStateProvider
-	needValidate  bool
-	Validator     validation.ActivationValidator
+	// ArchiveStateProvider keeps the archives of completed activations, archiving is off if it's not configured
+	ArchiveStateProvider states.IStateProvider
+	needValidate         bool
+	Validator            validation.ActivationValidator
 }
 
 const (
@@ -58,6 +60,10 @@ func (s *ActivationsManager) Init(context *contexts.VendorContext, config manage
 	} else {
 		return err
 	}
+	s.ArchiveStateProvider, err = getArchiveStateProvider(config, providers)
+	if err != nil {
+		return err
+	}
 	s.needValidate = managers.NeedObjectValidate(config, providers)
 	if s.needValidate {
 		// Turn off validation of differnt types: https://github.com/eclipse-symphony/symphony/issues/445
@@ -115,6 +121,9 @@ func getActivationState(body interface{}, etag string) (model.ActivationState, e
 	if activationState.Status == nil {
 		activationState.Status = &model.ActivationStatus{}
 	}
+	if parent := activationState.ObjectMeta.Annotations[constants.ParentActivation]; parent != "" {
+		activationState.Status.ParentActivation = parent
+	}
 	return activationState, nil
 }
 
@@ -146,6 +155,17 @@ func (m *ActivationsManager) UpsertState(ctx context.Context, name string, state
 		}
 	}
 
+	// the child is linked to its parent before it's created, so that a child is never left out of its parent's
+	// status. If the create fails, the parent keeps the name of a child that doesn't exist, which is skipped when
+	// the children are cancelled.
+	if parent := state.ObjectMeta.Annotations[constants.ParentActivation]; parent != "" {
+		err = m.addChildActivation(ctx, parent, state.ObjectMeta.Namespace, name)
+		if err != nil {
+			log.ErrorfCtx(ctx, "Failed to link activation %s to its parent activation %s: %v", name, parent, err)
+			return err
+		}
+	}
+
 	upsertRequest := states.UpsertRequest{
 		Value: states.StateEntry{
 			ID: name,
@@ -166,10 +186,22 @@ func (m *ActivationsManager) UpsertState(ctx context.Context, name string, state
 		},
 	}
 	_, err = m.StateProvider.Upsert(ctx, upsertRequest)
-	if err != nil {
-		return err
-	}
-	return nil
+	return err
+}
+
+// addChildActivation records an activation started by a campaign stage in the status of the parent activation. The
+// child links back to its parent through the parent activation annotation.
+func (m *ActivationsManager) addChildActivation(ctx context.Context, parent string, namespace string, child string) error {
+	_, err := m.updateStatus(ctx, parent, namespace, func(state *model.ActivationState) error {
+		for _, c := range state.Status.ChildActivations {
+			if c == child {
+				return nil
+			}
+		}
+		state.Status.ChildActivations = append(state.Status.ChildActivations, child)
+		return nil
+	})
+	return err
 }
 
 func (m *ActivationsManager) DeleteState(ctx context.Context, name string, namespace string) error {
@@ -195,8 +227,14 @@ func (m *ActivationsManager) DeleteState(ctx context.Context, name string, names
 }
 
 func (t *ActivationsManager) ListState(ctx context.Context, namespace string) ([]model.ActivationState, error) {
+	ret, _, err := t.ListStatePage(ctx, namespace, states.PageOptions{})
+	return ret, err
+}
+
+// ListStatePage lists a page of activations sorted by the page options, and returns the continuation token of the next page
+func (t *ActivationsManager) ListStatePage(ctx context.Context, namespace string, page states.PageOptions) ([]model.ActivationState, string, error) {
 	ctx, span := observability.StartSpan("Activations Manager", ctx, &map[string]string{
-		"method": "ListSpec",
+		"method": "ListStatePage",
 	})
 	var err error = nil
 	defer observ_utils.CloseSpanWithError(span, &err)
@@ -212,23 +250,25 @@ func (t *ActivationsManager) ListState(ctx context.Context, namespace string) ([
 			"namespace": namespace,
 			"kind":      "Activation",
 		},
+		PageOptions: page,
 	}
 	var activations []states.StateEntry
-	activations, _, err = t.StateProvider.List(ctx, listRequest)
+	var token string
+	activations, token, err = t.StateProvider.List(ctx, listRequest)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	ret := make([]model.ActivationState, 0)
 	for _, t := range activations {
 		var rt model.ActivationState
 		rt, err = getActivationState(t.Body, t.ETag)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		ret = append(ret, rt)
 	}
 	log.InfofCtx(ctx, "List activation state for namespace %s get total count %d", namespace, len(ret))
-	return ret, nil
+	return ret, token, nil
 }
 func (t *ActivationsManager) ReportStatus(ctx context.Context, name string, namespace string, current model.ActivationStatus) error {
 	ctx, span := observability.StartSpan("Activations Manager", ctx, &map[string]string{
@@ -331,6 +371,143 @@ func (t *ActivationsManager) ReportStageStatus(ctx context.Context, name string,
 	return nil
 }
 
+// CancelActivation marks an activation as cancelled. A paused stage is cancelled right away, while a running stage is
+// cancelled by the stage manager, which reports the cancelled stage when its providers return.
+func (t *ActivationsManager) CancelActivation(ctx context.Context, name string, namespace string) (model.ActivationState, error) {
+	ctx, span := observability.StartSpan("Activations Manager", ctx, &map[string]string{
+		"method": "CancelActivation",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	log.InfofCtx(ctx, "Cancel activation %s in namespace %s", name, namespace)
+	var activationState model.ActivationState
+	activationState, err = t.updateStatus(ctx, name, namespace, func(state *model.ActivationState) error {
+		if !state.Status.IsActive() {
+			return v1alpha2.NewCOAError(nil, fmt.Sprintf("activation %s is already %s", name, state.Status.Status.String()), v1alpha2.Conflict)
+		}
+		state.Status.Status = v1alpha2.Cancelled
+		state.Status.StatusMessage = v1alpha2.Cancelled.String()
+		state.Status.PauseRequested = false
+		if len(state.Status.StageHistory) > 0 {
+			latestStage := &state.Status.StageHistory[len(state.Status.StageHistory)-1]
+			if latestStage.Status == v1alpha2.Paused {
+				latestStage.Status = v1alpha2.Cancelled
+				latestStage.StatusMessage = v1alpha2.Cancelled.String()
+				latestStage.IsActive = false
+			}
+		}
+		return nil
+	})
+	return activationState, err
+}
+
+// PauseActivation requests an activation to pause before its next stage
+func (t *ActivationsManager) PauseActivation(ctx context.Context, name string, namespace string) (model.ActivationState, error) {
+	ctx, span := observability.StartSpan("Activations Manager", ctx, &map[string]string{
+		"method": "PauseActivation",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	log.InfofCtx(ctx, "Pause activation %s in namespace %s", name, namespace)
+	var activationState model.ActivationState
+	activationState, err = t.updateStatus(ctx, name, namespace, func(state *model.ActivationState) error {
+		if !state.Status.IsActive() {
+			return v1alpha2.NewCOAError(nil, fmt.Sprintf("activation %s is already %s", name, state.Status.Status.String()), v1alpha2.Conflict)
+		}
+		state.Status.PauseRequested = true
+		return nil
+	})
+	return activationState, err
+}
+
+// ResumeActivation clears the pause request of an activation. The caller triggers the stage the activation stopped
+// before, if any.
+func (t *ActivationsManager) ResumeActivation(ctx context.Context, name string, namespace string) (model.ActivationState, error) {
+	ctx, span := observability.StartSpan("Activations Manager", ctx, &map[string]string{
+		"method": "ResumeActivation",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	log.InfofCtx(ctx, "Resume activation %s in namespace %s", name, namespace)
+	var activationState model.ActivationState
+	activationState, err = t.updateStatus(ctx, name, namespace, func(state *model.ActivationState) error {
+		if !state.Status.PauseRequested {
+			return v1alpha2.NewCOAError(nil, fmt.Sprintf("activation %s is not paused", name), v1alpha2.Conflict)
+		}
+		state.Status.PauseRequested = false
+		return nil
+	})
+	return activationState, err
+}
+
+// ReportPaused marks an activation as paused once it has stopped before its next stage. It returns false if the
+// activation was resumed in the meantime, in which case the caller should go on with the stage.
+func (t *ActivationsManager) ReportPaused(ctx context.Context, name string, namespace string) (bool, error) {
+	ctx, span := observability.StartSpan("Activations Manager", ctx, &map[string]string{
+		"method": "ReportPaused",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	paused := false
+	_, err = t.updateStatus(ctx, name, namespace, func(state *model.ActivationState) error {
+		if state.Status.PauseRequested {
+			paused = true
+			state.Status.Status = v1alpha2.Paused
+			state.Status.StatusMessage = v1alpha2.Paused.String()
+		}
+		return nil
+	})
+	return paused, err
+}
+
+// updateStatus applies a change to the status of an activation under the status lock
+func (t *ActivationsManager) updateStatus(ctx context.Context, name string, namespace string, update func(*model.ActivationState) error) (model.ActivationState, error) {
+	lock.Lock()
+	defer lock.Unlock()
+
+	activationState, err := t.GetState(ctx, name, namespace)
+	if err != nil {
+		return model.ActivationState{}, err
+	}
+	if err = update(&activationState); err != nil {
+		return model.ActivationState{}, err
+	}
+	activationState.Status.UpdateTime = time.Now().Format(time.RFC3339)
+	if activationState.ObjectMeta.Labels == nil {
+		activationState.ObjectMeta.Labels = make(map[string]string)
+	}
+	// label doesn't allow space, so remove space
+	activationState.ObjectMeta.Labels[constants.StatusMessage] = utils.ConvertStringToValidLabel(activationState.Status.Status.String())
+
+	upsertRequest := states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   activationState.ObjectMeta.Name,
+			Body: activationState,
+		},
+		Metadata: map[string]interface{}{
+			"version":   "v1",
+			"group":     model.WorkflowGroup,
+			"resource":  "activations",
+			"namespace": activationState.ObjectMeta.Namespace,
+			"kind":      "Activation",
+		},
+	}
+	_, err = t.StateProvider.Upsert(ctx, upsertRequest)
+	if err != nil {
+		log.ErrorfCtx(ctx, "Failed to update status in state store for activation %s in namespace %s: %v", name, namespace, err)
+		return model.ActivationState{}, err
+	}
+	return activationState, nil
+}
+
 func mergeStageStatus(ctx context.Context, activationState *model.ActivationState, current model.StageStatus) error {
 	if current.Outputs["__site"] == nil {
 		// The StageStatus is triggered locally
@@ -362,7 +539,9 @@ func mergeStageStatus(ctx context.Context, activationState *model.ActivationStat
 		if len(activationState.Status.StageHistory) == 0 {
 			activationState.Status.StageHistory = append(activationState.Status.StageHistory, current)
 		} else if activationState.Status.StageHistory[len(activationState.Status.StageHistory)-1].Stage != current.Stage {
-			if len(activationState.Status.StageHistory)+1 > activationHistorySize {
+			// a dry run keeps its whole path through the stages
+			dryRun := activationState.Spec != nil && activationState.Spec.DryRun
+			if len(activationState.Status.StageHistory)+1 > activationHistorySize && !dryRun {
 				oldestStage := activationState.Status.StageHistory[0].Stage
 				activationState.Status.StageHistory = activationState.Status.StageHistory[1:]
 				log.InfofCtx(ctx, "Activation state size exceeds the limit %d, remove the oldest stage %s.", activationHistorySize, oldestStage)
@@ -395,6 +574,7 @@ func mergeStageStatus(ctx context.Context, activationState *model.ActivationStat
 		}
 		outputBytes, _ := json.Marshal(output)
 		parentStageStatus.Outputs[fmt.Sprintf("%s.__output", site)] = string(outputBytes)
+		parentStageStatus.Attempts = append(parentStageStatus.Attempts, current.Attempts...)
 		parentStageStatus.Status = v1alpha2.Done
 		for k, v := range parentStageStatus.Outputs {
 			if strings.HasSuffix(k, "__status") {
@@ -408,10 +588,13 @@ func mergeStageStatus(ctx context.Context, activationState *model.ActivationStat
 	}
 
 	latestStage := &activationState.Status.StageHistory[len(activationState.Status.StageHistory)-1]
-	if latestStage.NextStage != "" {
-		activationState.Status.Status = v1alpha2.Running
-	} else {
-		activationState.Status.Status = latestStage.Status
+	// a cancelled activation stays cancelled while its stages report their last status
+	if activationState.Status.Status != v1alpha2.Cancelled {
+		if latestStage.NextStage != "" {
+			activationState.Status.Status = v1alpha2.Running
+		} else {
+			activationState.Status.Status = latestStage.Status
+		}
 	}
 	activationState.Status.StatusMessage = activationState.Status.Status.String()
 	return nil
@@ -10,8 +10,10 @@ import (
 	"context"
 	"testing"
 
+	"github.com/eclipse-symphony/symphony/api/constants"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/memorystate"
 	"github.com/stretchr/testify/assert"
 )
@@ -277,3 +279,281 @@ func TestUpdateActivationWithRunningStatus(t *testing.T) {
 	assert.Contains(t, err.Error(), "spec is immutable: stage doesn't match")
 }
 */
+
+func TestPauseResumeActivation(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager := ActivationsManager{
+		StateProvider: stateProvider,
+	}
+	err := manager.UpsertState(context.Background(), "test", model.ActivationState{Spec: &model.ActivationSpec{}})
+	assert.Nil(t, err)
+
+	_, err = manager.ResumeActivation(context.Background(), "test", "default")
+	assertConflict(t, err)
+
+	state, err := manager.PauseActivation(context.Background(), "test", "default")
+	assert.Nil(t, err)
+	assert.True(t, state.Status.PauseRequested)
+
+	paused, err := manager.ReportPaused(context.Background(), "test", "default")
+	assert.Nil(t, err)
+	assert.True(t, paused)
+	state, err = manager.GetState(context.Background(), "test", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.Paused, state.Status.Status)
+
+	state, err = manager.ResumeActivation(context.Background(), "test", "default")
+	assert.Nil(t, err)
+	assert.False(t, state.Status.PauseRequested)
+
+	paused, err = manager.ReportPaused(context.Background(), "test", "default")
+	assert.Nil(t, err)
+	assert.False(t, paused)
+}
+
+func TestCancelActivation(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager := ActivationsManager{
+		StateProvider: stateProvider,
+	}
+	err := manager.UpsertState(context.Background(), "test", model.ActivationState{Spec: &model.ActivationSpec{}})
+	assert.Nil(t, err)
+	err = manager.ReportStageStatus(context.Background(), "test", "default", model.StageStatus{
+		Stage:         "test1",
+		NextStage:     "test2",
+		Status:        v1alpha2.Running,
+		StatusMessage: v1alpha2.Running.String(),
+		IsActive:      true,
+	})
+	assert.Nil(t, err)
+
+	state, err := manager.CancelActivation(context.Background(), "test", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.Cancelled, state.Status.Status)
+
+	// the running stage reports its status after it is cancelled
+	err = manager.ReportStageStatus(context.Background(), "test", "default", model.StageStatus{
+		Stage:         "test1",
+		Status:        v1alpha2.Cancelled,
+		StatusMessage: v1alpha2.Cancelled.String(),
+	})
+	assert.Nil(t, err)
+	state, err = manager.GetState(context.Background(), "test", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.Cancelled, state.Status.Status)
+	assert.Equal(t, v1alpha2.Cancelled, state.Status.StageHistory[0].Status)
+
+	_, err = manager.CancelActivation(context.Background(), "test", "default")
+	assertConflict(t, err)
+	_, err = manager.PauseActivation(context.Background(), "test", "default")
+	assertConflict(t, err)
+}
+
+func assertConflict(t *testing.T, err error) {
+	coaErr, ok := err.(v1alpha2.COAError)
+	assert.True(t, ok)
+	assert.Equal(t, v1alpha2.Conflict, coaErr.State)
+}
+
+func TestChildActivationLinks(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager := ActivationsManager{
+		StateProvider: stateProvider,
+	}
+	err := manager.UpsertState(context.Background(), "parent", model.ActivationState{Spec: &model.ActivationSpec{}})
+	assert.Nil(t, err)
+	child := model.ActivationState{
+		ObjectMeta: model.ObjectMeta{
+			Annotations: map[string]string{
+				constants.ParentActivation: "parent",
+			},
+		},
+		Spec: &model.ActivationSpec{},
+	}
+	err = manager.UpsertState(context.Background(), "child", child)
+	assert.Nil(t, err)
+	// upserting the child again doesn't add it twice
+	err = manager.UpsertState(context.Background(), "child", child)
+	assert.Nil(t, err)
+
+	state, err := manager.GetState(context.Background(), "parent", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"child"}, state.Status.ChildActivations)
+
+	state, err = manager.GetState(context.Background(), "child", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "parent", state.Status.ParentActivation)
+	// the child isn't started by the link
+	assert.Equal(t, "", state.Status.UpdateTime)
+
+	err = manager.UpsertState(context.Background(), "orphan", model.ActivationState{
+		ObjectMeta: model.ObjectMeta{
+			Annotations: map[string]string{
+				constants.ParentActivation: "missing",
+			},
+		},
+		Spec: &model.ActivationSpec{},
+	})
+	assert.True(t, v1alpha2.IsNotFound(err))
+	// an activation that can't be linked to its parent isn't created
+	_, err = manager.GetState(context.Background(), "orphan", "default")
+	assert.True(t, v1alpha2.IsNotFound(err))
+}
+
+func TestArchiveReusedActivationName(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	archiveProvider := &memorystate.MemoryStateProvider{}
+	archiveProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager := ActivationsManager{
+		StateProvider:        stateProvider,
+		ArchiveStateProvider: archiveProvider,
+	}
+	for _, updateTime := range []string{"2024-01-01T00:10:00Z", "2024-01-02T00:10:00Z"} {
+		err := manager.ArchiveActivation(context.Background(), model.ActivationState{
+			ObjectMeta: model.ObjectMeta{
+				Name:      "nightly",
+				Namespace: "default",
+			},
+			Spec: &model.ActivationSpec{
+				Campaign: "testcampaign:v1",
+			},
+			Status: &model.ActivationStatus{
+				Status:        v1alpha2.Done,
+				StatusMessage: v1alpha2.Done.String(),
+				UpdateTime:    updateTime,
+			},
+		})
+		assert.Nil(t, err)
+	}
+
+	archives, err := manager.ListArchives(context.Background(), "default", model.ActivationArchiveQuery{Name: "nightly"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(archives))
+
+	// the name gets the latest archive, the ID gets an earlier one
+	archive, err := manager.GetArchive(context.Background(), "nightly", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-01-02T00:10:00Z", archive.EndTime)
+	archive, err = manager.GetArchive(context.Background(), archives[1].ID, "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-01-01T00:10:00Z", archive.EndTime)
+	_, err = manager.GetArchive(context.Background(), "weekly", "default")
+	assert.True(t, v1alpha2.IsNotFound(err))
+}
+
+func TestArchiveBeforeCleanup(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	archiveProvider := &memorystate.MemoryStateProvider{}
+	archiveProvider.Init(memorystate.MemoryStateProviderConfig{})
+
+	manager := ActivationsManager{
+		StateProvider:        stateProvider,
+		ArchiveStateProvider: archiveProvider,
+	}
+	cleanupmanager := ActivationsCleanupManager{
+		ActivationsManager: manager,
+		RetentionDuration:  0,
+	}
+	stateProvider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID: "testcampaign-v-v1",
+			Body: map[string]interface{}{
+				"apiVersion": model.WorkflowGroup + "/v1",
+				"kind":       "Campaign",
+				"metadata": model.ObjectMeta{
+					Name:      "testcampaign-v-v1",
+					Namespace: "default",
+				},
+				"spec": model.CampaignSpec{
+					FirstStage: "deploy",
+					Stages: map[string]model.StageSpec{
+						"deploy": {
+							Name:     "deploy",
+							Provider: "providers.stage.mock",
+							Inputs: map[string]interface{}{
+								"login": "${{$secret(creds, login)}}",
+							},
+						},
+					},
+				},
+			},
+		},
+		Metadata: map[string]interface{}{
+			"namespace": "default",
+			"group":     model.WorkflowGroup,
+			"version":   "v1",
+			"resource":  "campaigns",
+			"kind":      "Campaign",
+		},
+	})
+
+	err := manager.UpsertState(context.Background(), "test", model.ActivationState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: &model.ActivationSpec{
+			Campaign: "testcampaign:v1",
+			Inputs: map[string]interface{}{
+				"apiToken": "abc",
+			},
+		},
+	})
+	assert.Nil(t, err)
+	err = manager.ReportStatus(context.Background(), "test", "default", model.ActivationStatus{
+		Status:        v1alpha2.Done,
+		StatusMessage: v1alpha2.Done.String(),
+		StageHistory: []model.StageStatus{
+			{
+				Stage:         "deploy",
+				Status:        v1alpha2.Done,
+				StatusMessage: v1alpha2.Done.String(),
+				Inputs: map[string]interface{}{
+					"login": "admin:pa55",
+				},
+				Outputs: map[string]interface{}{
+					"echo":   "admin:pa55",
+					"status": 200,
+				},
+				Attempts: []model.StageAttempt{
+					{Attempt: 1, Status: v1alpha2.Done, StartTime: "2024-01-01T00:00:00Z", EndTime: "2024-01-01T00:01:00Z"},
+				},
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	errList := cleanupmanager.Poll()
+	assert.Empty(t, errList)
+	_, err = manager.GetState(context.Background(), "test", "default")
+	assert.True(t, v1alpha2.IsNotFound(err))
+
+	archive, err := manager.GetArchive(context.Background(), "test", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "testcampaign:v1", archive.Campaign)
+	assert.Equal(t, v1alpha2.Done, archive.Status)
+	assert.Equal(t, model.RedactedValue, archive.Inputs["apiToken"])
+	assert.Equal(t, "2024-01-01T00:00:00Z", archive.StartTime)
+	assert.Equal(t, 1, len(archive.Stages))
+	assert.Equal(t, model.RedactedValue, archive.Stages[0].Inputs["login"])
+	assert.Equal(t, model.RedactedValue, archive.Stages[0].Outputs["echo"])
+	assert.Equal(t, float64(200), archive.Stages[0].Outputs["status"])
+	assert.Equal(t, "2024-01-01T00:01:00Z", archive.Stages[0].EndTime)
+
+	archives, err := manager.ListArchives(context.Background(), "default", model.ActivationArchiveQuery{
+		Campaign: "testcampaign:v1",
+		Outcome:  "done",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(archives))
+	archives, err = manager.ListArchives(context.Background(), "default", model.ActivationArchiveQuery{
+		Outcome: v1alpha2.Cancelled.String(),
+	})
+	assert.Nil(t, err)
+	assert.Empty(t, archives)
+}
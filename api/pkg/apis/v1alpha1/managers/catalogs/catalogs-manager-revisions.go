@@ -0,0 +1,291 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package catalogs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
+	observability "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability"
+	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/httpstate"
+)
+
+const (
+	// ProvidersRevisionState names the state provider that keeps the revisions of catalogs
+	ProvidersRevisionState = "providers.revisionstate"
+	revisionResource       = "catalogrevisions"
+	revisionKind           = "CatalogRevision"
+)
+
+// getRevisionStateProvider returns the revision state provider of the manager config, or nil if revisions aren't
+// configured. Revisions are numbered with writes that are conditional on etags, so the HTTP state provider, which
+// doesn't support etags, can't keep them.
+func getRevisionStateProvider(config managers.ManagerConfig, providers map[string]providers.IProvider) (states.IStateProvider, error) {
+	name, ok := config.Properties[ProvidersRevisionState]
+	if !ok {
+		return nil, nil
+	}
+	provider, ok := providers[name]
+	if !ok {
+		return nil, v1alpha2.NewCOAError(nil, "revision state provider is not supplied", v1alpha2.MissingConfig)
+	}
+	stateProvider, ok := provider.(states.IStateProvider)
+	if !ok {
+		return nil, v1alpha2.NewCOAError(nil, "supplied revision provider is not a state provider", v1alpha2.BadConfig)
+	}
+	if _, ok := provider.(*httpstate.HttpStateProvider); ok {
+		return nil, v1alpha2.NewCOAError(nil, "supplied revision provider doesn't support etags", v1alpha2.BadConfig)
+	}
+	return stateProvider, nil
+}
+
+func revisionMetadata(namespace string) map[string]interface{} {
+	return map[string]interface{}{
+		"version":   "v1",
+		"group":     model.FederationGroup,
+		"resource":  revisionResource,
+		"namespace": namespace,
+		"kind":      revisionKind,
+	}
+}
+
+func revisionID(name string, revision int) string {
+	return fmt.Sprintf("%s-r-%d", name, revision)
+}
+
+func revisionCounterID(name string) string {
+	return fmt.Sprintf("%s-r-latest", name)
+}
+
+// revisionCounter is the latest revision number that was given to a write of a catalog
+type revisionCounter struct {
+	Revision int `json:"revision"`
+}
+
+// RevisionsEnabled tells if a revision is recorded on each catalog write
+func (m *CatalogsManager) RevisionsEnabled() bool {
+	return m.RevisionStateProvider != nil
+}
+
+// allocateRevision takes the next revision number of a catalog from its revision counter. The counter is only updated
+// if it hasn't changed since it was read, so two writes never get the same number; the write that loses the race fails
+// with a conflict. The state provider gives the counter a new etag on each update. A number is not reused if the write
+// it was taken for fails.
+func (m *CatalogsManager) allocateRevision(ctx context.Context, name string, namespace string) (int, error) {
+	counter := revisionCounter{Revision: 1}
+	// an empty etag creates the counter of the first revision only if it doesn't exist yet
+	etag := ""
+	entry, err := m.RevisionStateProvider.Get(ctx, states.GetRequest{
+		ID:       revisionCounterID(name),
+		Metadata: revisionMetadata(namespace),
+	})
+	if err == nil {
+		data, _ := json.Marshal(entry.Body)
+		if err = json.Unmarshal(data, &counter); err != nil {
+			return 0, v1alpha2.NewCOAError(err, "invalid catalog revision counter", v1alpha2.InternalError)
+		}
+		counter.Revision++
+		etag = entry.ETag
+	} else if !v1alpha2.IsNotFound(err) {
+		return 0, err
+	}
+	_, err = m.RevisionStateProvider.Upsert(ctx, states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   revisionCounterID(name),
+			Body: counter,
+		},
+		ETag:     &etag,
+		Metadata: revisionMetadata(namespace),
+	})
+	if err != nil {
+		if v1alpha2.IsConflict(err) {
+			return 0, v1alpha2.NewCOAError(err, fmt.Sprintf("catalog '%s' is being written concurrently, retry the write", name), v1alpha2.Conflict)
+		}
+		return 0, err
+	}
+	return counter.Revision, nil
+}
+
+// recordRevision records the catalog that was just written as the revision that was allocated for the write. An
+// existing revision is never overwritten.
+func (m *CatalogsManager) recordRevision(ctx context.Context, state model.CatalogState, number int, previousETag string, restoredFrom int) (model.CatalogRevision, error) {
+	revision := model.CatalogRevision{
+		Catalog:      state.ObjectMeta.Name,
+		Namespace:    state.ObjectMeta.Namespace,
+		Revision:     number,
+		Timestamp:    time.Now().UTC().Format(time.RFC3339),
+		PreviousETag: previousETag,
+		RestoredFrom: restoredFrom,
+	}
+	// a revision is immutable, so it doesn't share the spec of the written catalog
+	data, _ := json.Marshal(state.Spec)
+	if err := json.Unmarshal(data, &revision.Spec); err != nil {
+		return model.CatalogRevision{}, err
+	}
+	if identity, ok := v1alpha2.GetIdentity(ctx); ok {
+		revision.Author = identity.User
+	}
+	absent := ""
+	_, err := m.RevisionStateProvider.Upsert(ctx, states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   revisionID(revision.Catalog, revision.Revision),
+			Body: revision,
+		},
+		ETag:     &absent,
+		Metadata: revisionMetadata(revision.Namespace),
+	})
+	if err != nil {
+		return model.CatalogRevision{}, err
+	}
+	return revision, nil
+}
+
+// GetRevision returns a revision of a catalog
+func (m *CatalogsManager) GetRevision(ctx context.Context, name string, namespace string, revision int) (model.CatalogRevision, error) {
+	ctx, span := observability.StartSpan("Catalogs Manager", ctx, &map[string]string{
+		"method": "GetRevision",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	if m.RevisionStateProvider == nil {
+		err = v1alpha2.NewCOAError(nil, "catalog revisions are not configured", v1alpha2.NotFound)
+		return model.CatalogRevision{}, err
+	}
+	var entry states.StateEntry
+	entry, err = m.RevisionStateProvider.Get(ctx, states.GetRequest{
+		ID:       revisionID(name, revision),
+		Metadata: revisionMetadata(namespace),
+	})
+	if err != nil {
+		if v1alpha2.IsNotFound(err) {
+			err = v1alpha2.NewCOAError(err, fmt.Sprintf("revision %d of catalog '%s' is not found", revision, name), v1alpha2.NotFound)
+		}
+		return model.CatalogRevision{}, err
+	}
+	var ret model.CatalogRevision
+	ret, err = getCatalogRevision(entry.Body)
+	return ret, err
+}
+
+// ListRevisions lists the revisions of a catalog, the latest first. Revisions are kept after the catalog is deleted.
+func (m *CatalogsManager) ListRevisions(ctx context.Context, name string, namespace string) ([]model.CatalogRevision, error) {
+	ctx, span := observability.StartSpan("Catalogs Manager", ctx, &map[string]string{
+		"method": "ListRevisions",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	if m.RevisionStateProvider == nil {
+		return make([]model.CatalogRevision, 0), nil
+	}
+	var ret []model.CatalogRevision
+	ret, err = m.listRevisions(ctx, name, namespace)
+	return ret, err
+}
+
+func (m *CatalogsManager) listRevisions(ctx context.Context, name string, namespace string) ([]model.CatalogRevision, error) {
+	entries, _, err := m.RevisionStateProvider.List(ctx, states.ListRequest{
+		Metadata: revisionMetadata(namespace),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]model.CatalogRevision, 0)
+	for _, entry := range entries {
+		if entry.ID == revisionCounterID(name) {
+			continue
+		}
+		revision, err := getCatalogRevision(entry.Body)
+		if err != nil {
+			return nil, err
+		}
+		if revision.Catalog == name {
+			ret = append(ret, revision)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Revision > ret[j].Revision
+	})
+	return ret, nil
+}
+
+// DiffRevisions lists the changes of a catalog spec from one revision to another
+func (m *CatalogsManager) DiffRevisions(ctx context.Context, name string, namespace string, from int, to int) (model.CatalogRevisionDiff, error) {
+	ctx, span := observability.StartSpan("Catalogs Manager", ctx, &map[string]string{
+		"method": "DiffRevisions",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	var fromRevision, toRevision model.CatalogRevision
+	fromRevision, err = m.GetRevision(ctx, name, namespace, from)
+	if err != nil {
+		return model.CatalogRevisionDiff{}, err
+	}
+	toRevision, err = m.GetRevision(ctx, name, namespace, to)
+	if err != nil {
+		return model.CatalogRevisionDiff{}, err
+	}
+	return model.NewCatalogRevisionDiff(fromRevision, toRevision), nil
+}
+
+// RestoreRevision writes the spec of a revision back to its catalog, which records a new revision. A deleted catalog is
+// created again.
+func (m *CatalogsManager) RestoreRevision(ctx context.Context, name string, namespace string, revision int) (model.CatalogRevision, error) {
+	ctx, span := observability.StartSpan("Catalogs Manager", ctx, &map[string]string{
+		"method": "RestoreRevision",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	var old model.CatalogRevision
+	old, err = m.GetRevision(ctx, name, namespace, revision)
+	if err != nil {
+		return model.CatalogRevision{}, err
+	}
+	var state model.CatalogState
+	state, err = m.GetState(ctx, name, namespace)
+	if err != nil {
+		if !v1alpha2.IsNotFound(err) {
+			return model.CatalogRevision{}, err
+		}
+		state = model.CatalogState{
+			ObjectMeta: model.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+		}
+	}
+	state.Spec = old.Spec
+	log.InfofCtx(ctx, "Restore revision %d of catalog %s in namespace %s", revision, name, namespace)
+	var ret model.CatalogRevision
+	ret, err = m.upsertState(ctx, name, state, revision)
+	return ret, err
+}
+
+func getCatalogRevision(body interface{}) (model.CatalogRevision, error) {
+	var revision model.CatalogRevision
+	data, _ := json.Marshal(body)
+	if err := json.Unmarshal(data, &revision); err != nil {
+		return model.CatalogRevision{}, v1alpha2.NewCOAError(err, "invalid catalog revision", v1alpha2.InternalError)
+	}
+	return revision, nil
+}
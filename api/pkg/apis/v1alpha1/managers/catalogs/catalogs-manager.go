@@ -34,6 +34,8 @@ type CatalogsManager struct {
 	GraphProvider    graph.IGraphProvider
 	needValidate     bool
 	CatalogValidator validation.CatalogValidator
+	// RevisionStateProvider keeps the revisions of catalogs, revisions are off if it's not configured
+	RevisionStateProvider states.IStateProvider
 }
 
 func (s *CatalogsManager) Init(context *contexts.VendorContext, config managers.ManagerConfig, providers map[string]providers.IProvider) error {
@@ -47,6 +49,10 @@ func (s *CatalogsManager) Init(context *contexts.VendorContext, config managers.
 	} else {
 		return err
 	}
+	s.RevisionStateProvider, err = getRevisionStateProvider(config, providers)
+	if err != nil {
+		return err
+	}
 	for _, provider := range providers {
 		if cProvider, ok := provider.(graph.IGraphProvider); ok {
 			s.GraphProvider = cProvider
@@ -117,8 +123,15 @@ func (m *CatalogsManager) UpsertState(ctx context.Context, name string, state mo
 	defer observ_utils.CloseSpanWithError(span, &err)
 	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
 
+	_, err = m.upsertState(ctx, name, state, 0)
+	return err
+}
+
+// upsertState writes a catalog and records its revision when revisions are configured
+func (m *CatalogsManager) upsertState(ctx context.Context, name string, state model.CatalogState, restoredFrom int) (model.CatalogRevision, error) {
+	var err error
 	if state.ObjectMeta.Name != "" && state.ObjectMeta.Name != name {
-		return v1alpha2.NewCOAError(nil, fmt.Sprintf("Name in metadata (%s) does not match name in request (%s)", state.ObjectMeta.Name, name), v1alpha2.BadRequest)
+		return model.CatalogRevision{}, v1alpha2.NewCOAError(nil, fmt.Sprintf("Name in metadata (%s) does not match name in request (%s)", state.ObjectMeta.Name, name), v1alpha2.BadRequest)
 	}
 	state.ObjectMeta.FixNames(name)
 
@@ -133,7 +146,22 @@ func (m *CatalogsManager) UpsertState(ctx context.Context, name string, state mo
 			}
 		}
 		if err = m.ValidateCreateOrUpdate(ctx, state); err != nil {
-			return err
+			return model.CatalogRevision{}, err
+		}
+	}
+
+	previousETag := ""
+	revisionNumber := 0
+	if m.RevisionStateProvider != nil {
+		var old model.CatalogState
+		if old, err = m.GetState(ctx, state.ObjectMeta.Name, state.ObjectMeta.Namespace); err == nil {
+			previousETag = old.ObjectMeta.ETag
+		} else if !v1alpha2.IsNotFound(err) {
+			return model.CatalogRevision{}, err
+		}
+		// the revision number is taken before the write, so that a concurrent write fails instead of sharing it
+		if revisionNumber, err = m.allocateRevision(ctx, state.ObjectMeta.Name, state.ObjectMeta.Namespace); err != nil {
+			return model.CatalogRevision{}, err
 		}
 	}
 
@@ -158,7 +186,15 @@ func (m *CatalogsManager) UpsertState(ctx context.Context, name string, state mo
 	}
 	_, err = m.StateProvider.Upsert(ctx, upsertRequest)
 	if err != nil {
-		return err
+		return model.CatalogRevision{}, err
+	}
+	var revision model.CatalogRevision
+	if m.RevisionStateProvider != nil {
+		revision, err = m.recordRevision(ctx, state, revisionNumber, previousETag, restoredFrom)
+		if err != nil {
+			log.ErrorfCtx(ctx, "Failed to record the revision of catalog %s: %v", name, err)
+			return model.CatalogRevision{}, v1alpha2.NewCOAError(err, fmt.Sprintf("catalog '%s' is written but its revision is not recorded", name), v1alpha2.InternalError)
+		}
 	}
 	m.Context.Publish("catalog", v1alpha2.Event{
 		Metadata: map[string]string{
@@ -171,7 +207,7 @@ func (m *CatalogsManager) UpsertState(ctx context.Context, name string, state mo
 		},
 		Context: ctx,
 	})
-	return nil
+	return revision, nil
 }
 
 func (m *CatalogsManager) DeleteState(ctx context.Context, name string, namespace string) error {
@@ -203,8 +239,14 @@ func (m *CatalogsManager) DeleteState(ctx context.Context, name string, namespac
 }
 
 func (t *CatalogsManager) ListState(ctx context.Context, namespace string, filterType string, filterValue string) ([]model.CatalogState, error) {
+	ret, _, err := t.ListStatePage(ctx, namespace, filterType, filterValue, states.PageOptions{})
+	return ret, err
+}
+
+// ListStatePage lists a page of catalogs sorted by the page options, and returns the continuation token of the next page
+func (t *CatalogsManager) ListStatePage(ctx context.Context, namespace string, filterType string, filterValue string, page states.PageOptions) ([]model.CatalogState, string, error) {
 	ctx, span := observability.StartSpan("Catalogs Manager", ctx, &map[string]string{
-		"method": "ListState",
+		"method": "ListStatePage",
 	})
 	var err error = nil
 	defer observ_utils.CloseSpanWithError(span, &err)
@@ -218,24 +260,26 @@ func (t *CatalogsManager) ListState(ctx context.Context, namespace string, filte
 			"namespace": namespace,
 			"kind":      "Catalog",
 		},
+		PageOptions: page,
 	}
 	listRequest.FilterType = filterType
 	listRequest.FilterValue = filterValue
 	var catalogs []states.StateEntry
-	catalogs, _, err = t.StateProvider.List(ctx, listRequest)
+	var token string
+	catalogs, token, err = t.StateProvider.List(ctx, listRequest)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	ret := make([]model.CatalogState, 0)
 	for _, t := range catalogs {
 		var rt model.CatalogState
 		rt, err = getCatalogState(t.Body, t.ETag)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		ret = append(ret, rt)
 	}
-	return ret, nil
+	return ret, token, nil
 }
 func (g *CatalogsManager) setProviderDataIfNecessary(ctx context.Context, namespace string) error {
 	if !g.GraphProvider.IsPure() {
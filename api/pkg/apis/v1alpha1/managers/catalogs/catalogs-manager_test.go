@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
@@ -23,6 +24,7 @@ import (
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub/memory"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/httpstate"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/memorystate"
 	"github.com/stretchr/testify/assert"
 )
@@ -71,6 +73,17 @@ func initalizeManager() error {
 	return err
 }
 
+func initalizeManagerWithRevisions() error {
+	err := initalizeManager()
+	if err != nil {
+		return err
+	}
+	revisionProvider := &memorystate.MemoryStateProvider{}
+	revisionProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager.RevisionStateProvider = revisionProvider
+	return nil
+}
+
 func CreateSimpleChain(root string, length int, CTManager CatalogsManager, catalog model.CatalogState) error {
 	if length < 1 {
 		return errors.New("Length can not be less than 1.")
@@ -322,6 +335,282 @@ func TestSchemaCheck(t *testing.T) {
 	assert.True(t, strings.Contains(err.Error(), "email: property does not match pattern"))
 }
 
+func TestJSONSchemaCheck(t *testing.T) {
+	err := initalizeManager()
+	assert.Nil(t, err)
+	manager.CatalogValidator.CatalogContainerLookupFunc = nil
+	portSchema := model.CatalogState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "port-v-v1",
+			Namespace: "default",
+		},
+		Spec: &model.CatalogSpec{
+			RootResource: "port",
+			CatalogType:  "schema",
+			Properties: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"type":    "integer",
+					"minimum": 1,
+					"maximum": 65535,
+				},
+			},
+		},
+	}
+	err = manager.UpsertState(context.Background(), portSchema.ObjectMeta.Name, portSchema)
+	assert.Nil(t, err)
+	serviceSchema := model.CatalogState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "service-v-v1",
+			Namespace: "default",
+		},
+		Spec: &model.CatalogSpec{
+			RootResource: "service",
+			CatalogType:  "schema",
+			Properties: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"$schema":  "https://json-schema.org/draft/2020-12/schema",
+					"type":     "object",
+					"required": []string{"listeners"},
+					"properties": map[string]interface{}{
+						"listeners": map[string]interface{}{
+							"type": "array",
+							"items": map[string]interface{}{
+								"type":     "object",
+								"required": []string{"port"},
+								"properties": map[string]interface{}{
+									"port":     map[string]interface{}{"$ref": "port:v1"},
+									"protocol": map[string]interface{}{"enum": []string{"tcp", "udp"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	err = manager.UpsertState(context.Background(), serviceSchema.ObjectMeta.Name, serviceSchema)
+	assert.Nil(t, err)
+
+	catalog := model.CatalogState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "web-v-v1",
+			Namespace: "default",
+		},
+		Spec: &model.CatalogSpec{
+			RootResource: "web",
+			CatalogType:  "config",
+			Metadata: map[string]string{
+				"schema": "service:v1",
+			},
+			Properties: map[string]interface{}{
+				"listeners": []interface{}{
+					map[string]interface{}{"port": 80, "protocol": "tcp"},
+					map[string]interface{}{"port": 0, "protocol": "http"},
+				},
+			},
+		},
+	}
+	err = manager.UpsertState(context.Background(), catalog.ObjectMeta.Name, catalog)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "spec.properties.listeners[1].port")
+	assert.Contains(t, err.Error(), "value 0 is less than the minimum 1")
+	assert.Contains(t, err.Error(), "spec.properties.listeners[1].protocol")
+
+	catalog.Spec.Properties["listeners"] = []interface{}{
+		map[string]interface{}{"port": 443, "protocol": "tcp"},
+	}
+	err = manager.UpsertState(context.Background(), catalog.ObjectMeta.Name, catalog)
+	assert.Nil(t, err)
+}
+
+func TestMergeModeCheck(t *testing.T) {
+	err := initalizeManager()
+	assert.Nil(t, err)
+	manager.CatalogValidator.CatalogContainerLookupFunc = nil
+	catalog := model.CatalogState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "site-v-v1",
+			Namespace: "default",
+		},
+		Spec: &model.CatalogSpec{
+			RootResource: "site",
+			CatalogType:  "config",
+			Metadata: map[string]string{
+				"mergeMode": "merge",
+			},
+		},
+	}
+	err = manager.UpsertState(context.Background(), catalog.ObjectMeta.Name, catalog)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "merge mode must be one of replace, deep-merge and append-list")
+
+	catalog.Spec.Metadata["mergeMode"] = "deep-merge"
+	err = manager.UpsertState(context.Background(), catalog.ObjectMeta.Name, catalog)
+	assert.Nil(t, err)
+}
+
+func TestRevisions(t *testing.T) {
+	err := initalizeManagerWithRevisions()
+	assert.Nil(t, err)
+	manager.CatalogValidator.CatalogContainerLookupFunc = nil
+	ctx := context.WithValue(context.Background(), v1alpha2.COAIdentityContextKey, v1alpha2.Identity{User: "alice"})
+
+	catalog := model.CatalogState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "site-v-v1",
+			Namespace: "default",
+		},
+		Spec: &model.CatalogSpec{
+			RootResource: "site",
+			CatalogType:  "config",
+			Properties: map[string]interface{}{
+				"dns":  "8.8.8.8",
+				"port": 80,
+			},
+		},
+	}
+	err = manager.UpsertState(ctx, catalog.ObjectMeta.Name, catalog)
+	assert.Nil(t, err)
+	current, err := manager.GetState(ctx, "site-v-v1", "default")
+	assert.Nil(t, err)
+	firstETag := current.ObjectMeta.ETag
+
+	catalog.Spec.Properties = map[string]interface{}{
+		"dns":   "10.0.0.2",
+		"proxy": "proxy.local",
+	}
+	err = manager.UpsertState(context.Background(), catalog.ObjectMeta.Name, catalog)
+	assert.Nil(t, err)
+
+	revisions, err := manager.ListRevisions(ctx, "site-v-v1", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(revisions))
+	assert.Equal(t, 2, revisions[0].Revision)
+	assert.Equal(t, firstETag, revisions[0].PreviousETag)
+	assert.Equal(t, "", revisions[0].Author)
+	assert.Equal(t, 1, revisions[1].Revision)
+	assert.Equal(t, "", revisions[1].PreviousETag)
+	assert.Equal(t, "alice", revisions[1].Author)
+	assert.NotEmpty(t, revisions[1].Timestamp)
+
+	revision, err := manager.GetRevision(ctx, "site-v-v1", "default", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "8.8.8.8", revision.Spec.Properties["dns"])
+	_, err = manager.GetRevision(ctx, "site-v-v1", "default", 3)
+	assert.True(t, v1alpha2.IsNotFound(err))
+
+	diff, err := manager.DiffRevisions(ctx, "site-v-v1", "default", 1, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.PropertyChange{
+		{Path: "properties.dns", Type: model.PropertyChanged, Current: "8.8.8.8", Desired: "10.0.0.2"},
+		{Path: "properties.port", Type: model.PropertyRemoved, Current: float64(80)},
+		{Path: "properties.proxy", Type: model.PropertyAdded, Desired: "proxy.local"},
+	}, diff.Changes)
+
+	restored, err := manager.RestoreRevision(ctx, "site-v-v1", "default", 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, restored.Revision)
+	assert.Equal(t, 1, restored.RestoredFrom)
+	current, err = manager.GetState(ctx, "site-v-v1", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "8.8.8.8", current.Spec.Properties["dns"])
+	assert.Nil(t, current.Spec.Properties["proxy"])
+
+	// a deleted catalog keeps its revisions and can be restored
+	err = manager.DeleteState(ctx, "site-v-v1", "default")
+	assert.Nil(t, err)
+	restored, err = manager.RestoreRevision(ctx, "site-v-v1", "default", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, restored.Revision)
+	assert.Equal(t, "", restored.PreviousETag)
+	current, err = manager.GetState(ctx, "site-v-v1", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "proxy.local", current.Spec.Properties["proxy"])
+}
+
+func TestConcurrentRevisions(t *testing.T) {
+	err := initalizeManagerWithRevisions()
+	assert.Nil(t, err)
+	manager.CatalogValidator.CatalogContainerLookupFunc = nil
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = manager.UpsertState(context.Background(), "site-v-v1", model.CatalogState{
+				ObjectMeta: model.ObjectMeta{
+					Name:      "site-v-v1",
+					Namespace: "default",
+				},
+				Spec: &model.CatalogSpec{
+					RootResource: "site",
+					CatalogType:  "config",
+					Properties: map[string]interface{}{
+						"writer": i,
+					},
+				},
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	written := 0
+	for _, err := range errs {
+		if err == nil {
+			written++
+			continue
+		}
+		assert.Equal(t, v1alpha2.Conflict, err.(v1alpha2.COAError).State)
+	}
+	assert.True(t, written > 0)
+	// every write that succeeded has its own revision
+	revisions, err := manager.ListRevisions(context.Background(), "site-v-v1", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, written, len(revisions))
+	seen := make(map[int]bool)
+	for _, revision := range revisions {
+		assert.False(t, seen[revision.Revision])
+		seen[revision.Revision] = true
+	}
+}
+
+func TestRevisionsNotConfigured(t *testing.T) {
+	err := initalizeManager()
+	assert.Nil(t, err)
+	assert.False(t, manager.RevisionsEnabled())
+	revisions, err := manager.ListRevisions(context.Background(), "site-v-v1", "default")
+	assert.Nil(t, err)
+	assert.Empty(t, revisions)
+	_, err = manager.GetRevision(context.Background(), "site-v-v1", "default", 1)
+	assert.True(t, v1alpha2.IsNotFound(err))
+}
+
+func TestRevisionStateProviderWithoutETags(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	revisionProvider := &httpstate.HttpStateProvider{}
+	revisionProvider.Init(httpstate.HttpStateProviderConfig{Url: "http://localhost:3500/v1.0/state/statestore"})
+	config := managers.ManagerConfig{
+		Properties: map[string]string{
+			"providers.persistentstate": "StateProvider",
+			ProvidersRevisionState:      "RevisionProvider",
+		},
+	}
+	providers := map[string]providers.IProvider{
+		"StateProvider":    stateProvider,
+		"RevisionProvider": revisionProvider,
+	}
+	vendorContext := &contexts.VendorContext{}
+	pubSubProvider := memory.InMemoryPubSubProvider{}
+	pubSubProvider.Init(memory.InMemoryPubSubConfig{Name: "test"})
+	vendorContext.Init(&pubSubProvider)
+	manager := CatalogsManager{}
+	err := manager.Init(vendorContext, config, providers)
+	assert.True(t, v1alpha2.IsBadConfig(err))
+}
+
 func TestParentCatalog(t *testing.T) {
 	err := initalizeManager()
 	assert.Nil(t, err)
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/contexts"
@@ -24,6 +25,12 @@ import (
 
 var log = logger.NewLogger("coa.runtime")
 
+// IExplainConfigProvider is a config provider that can tell which catalogs supplied the values it reads
+type IExplainConfigProvider interface {
+	ExplainRead(ctx context.Context, object string, field string, localContext interface{}) (model.ConfigValue, error)
+	ExplainReadObject(ctx context.Context, object string, localContext interface{}) (model.ConfigValue, error)
+}
+
 type ConfigsManager struct {
 	managers.Manager
 	ConfigProviders map[string]config.IConfigProvider
@@ -74,74 +81,91 @@ func (s *ConfigsManager) Get(ctx context.Context, object string, field string, o
 	defer observ_utils.CloseSpanWithError(span, &err)
 	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
 	log.DebugfCtx(ctx, " M (Config): Get %v, config provider size %d", object, len(s.ConfigProviders))
+	var value model.ConfigValue
+	value, err = s.explain(ctx, object, field, overlays, localContext)
+	if err != nil {
+		return "", err
+	}
+	return value.Value, nil
+}
+
+// Explain resolves a config value like Get, with the chain of catalogs and overlays that were read and the catalog
+// that supplied each field of the value. Without a field, the whole object is resolved like GetObject, and a value
+// whose properties partly failed to evaluate is returned with the error.
+func (s *ConfigsManager) Explain(ctx context.Context, object string, field string, overlays []string, localContext interface{}) (model.ConfigValue, error) {
+	ctx, span := observability.StartSpan("Config Manager", ctx, &map[string]string{
+		"method": "Explain",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+	log.DebugfCtx(ctx, " M (Config): Explain %v, config provider size %d", object, len(s.ConfigProviders))
+	var value model.ConfigValue
+	value, err = s.explain(ctx, object, field, overlays, localContext)
+	return value, err
+}
+
+func (s *ConfigsManager) explain(ctx context.Context, object string, field string, overlays []string, localContext interface{}) (model.ConfigValue, error) {
 	if strings.Index(object, "::") > 0 {
 		parts := strings.Split(object, "::")
 		if len(parts) != 2 {
 			log.ErrorfCtx(ctx, " M (Config): Invalid object: %s", object)
-			return "", v1alpha2.NewCOAError(nil, fmt.Sprintf("Invalid object: %s", object), v1alpha2.BadRequest)
+			return model.ConfigValue{}, v1alpha2.NewCOAError(nil, fmt.Sprintf("Invalid object: %s", object), v1alpha2.BadRequest)
 		}
 		if provider, ok := s.ConfigProviders[parts[0]]; ok {
-			if field == "" {
-				configObj, err := s.getObjectWithOverlay(ctx, provider, parts[1], overlays, localContext)
-				if err != nil {
-					return "", err
-				}
-				return configObj, nil
-			} else {
-				return s.getWithOverlay(ctx, provider, parts[1], field, overlays, localContext)
-			}
+			return s.explainWithOverlay(ctx, provider, parts[1], field, overlays, localContext)
 		}
 		log.ErrorfCtx(ctx, " M (Config): Invalid provider: %s", parts[0])
-		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("Invalid provider: %s", parts[0]), v1alpha2.BadRequest)
-		return "", err
+		return model.ConfigValue{}, v1alpha2.NewCOAError(nil, fmt.Sprintf("Invalid provider: %s", parts[0]), v1alpha2.BadRequest)
 	}
 	if len(s.ConfigProviders) == 1 {
 		for _, provider := range s.ConfigProviders {
-			if field == "" {
-				configObj, err := s.getObjectWithOverlay(ctx, provider, object, overlays, localContext)
-				if err != nil {
-					return "", err
-				}
-				return configObj, nil
-			} else {
-				if value, err := s.getWithOverlay(ctx, provider, object, field, overlays, localContext); err == nil {
-					return value, nil
-				} else {
-					return "", err
-				}
-			}
+			return s.explainWithOverlay(ctx, provider, object, field, overlays, localContext)
 		}
 	}
 	for _, key := range s.Precedence {
 		if provider, ok := s.ConfigProviders[key]; ok {
-			if field == "" {
-				configObj, err := s.getObjectWithOverlay(ctx, provider, object, overlays, localContext)
-				if err != nil {
-					return "", err
-				}
-				return configObj, nil
-			} else {
-				if value, err := s.getWithOverlay(ctx, provider, object, field, overlays, localContext); err == nil {
-					return value, nil
-				}
+			value, err := s.explainWithOverlay(ctx, provider, object, field, overlays, localContext)
+			if field == "" || err == nil {
+				return value, err
 			}
 		}
 	}
 
 	log.ErrorfCtx(ctx, " M (Config): Invalid config object or key: %s, %s", object, field)
-	err = v1alpha2.NewCOAError(nil, fmt.Sprintf("Invalid config object or key: %s, %s", object, field), v1alpha2.BadRequest)
-	return "", err
+	return model.ConfigValue{}, v1alpha2.NewCOAError(nil, fmt.Sprintf("Invalid config object or key: %s, %s", object, field), v1alpha2.BadRequest)
 }
 
-func (s *ConfigsManager) getWithOverlay(ctx context.Context, provider config.IConfigProvider, object string, field string, overlays []string, localContext interface{}) (interface{}, error) {
-	if len(overlays) > 0 {
-		for _, overlay := range overlays {
-			if overlayObject, err := provider.Read(ctx, overlay, field, localContext); err == nil {
-				return overlayObject, nil
-			}
+func (s *ConfigsManager) explainWithOverlay(ctx context.Context, provider config.IConfigProvider, object string, field string, overlays []string, localContext interface{}) (model.ConfigValue, error) {
+	if field == "" {
+		return s.getObjectWithOverlay(ctx, provider, object, overlays, localContext)
+	}
+	return s.getWithOverlay(ctx, provider, object, field, overlays, localContext)
+}
+
+// getWithOverlay resolves a field of an object and combines it with the overlays that have the field, with the merge
+// mode of each overlay. Overlays take precedence in the order they are listed, and overlays without the field are
+// skipped.
+func (s *ConfigsManager) getWithOverlay(ctx context.Context, provider config.IConfigProvider, object string, field string, overlays []string, localContext interface{}) (model.ConfigValue, error) {
+	value, err := readConfigValue(ctx, provider, object, field, localContext)
+	resolved := err == nil
+	for i := len(overlays) - 1; i >= 0; i-- {
+		overlay, overlayErr := readConfigValue(ctx, provider, overlays[i], field, localContext)
+		if overlayErr != nil {
+			continue
+		}
+		overlay = overlay.AsOverlay()
+		if resolved {
+			value = value.Override(overlay, overlay.Chain[0].MergeMode)
+		} else {
+			value = overlay
+			resolved = true
 		}
 	}
-	return provider.Read(ctx, object, field, localContext)
+	if !resolved {
+		return model.ConfigValue{}, err
+	}
+	return value, nil
 }
 
 func (s *ConfigsManager) GetObject(ctx context.Context, object string, overlays []string, localContext interface{}) (map[string]interface{}, error) {
@@ -159,7 +183,7 @@ func (s *ConfigsManager) GetObject(ctx context.Context, object string, overlays
 			return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("Invalid object: %s", object), v1alpha2.BadRequest)
 		}
 		if provider, ok := s.ConfigProviders[parts[0]]; ok {
-			return s.getObjectWithOverlay(ctx, provider, parts[1], overlays, localContext)
+			return configObject(s.getObjectWithOverlay(ctx, provider, parts[1], overlays, localContext))
 		}
 		log.ErrorfCtx(ctx, " M (Config): Invalid provider: %s", parts[0])
 		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("Invalid provider: %s", parts[0]), v1alpha2.BadRequest)
@@ -167,12 +191,12 @@ func (s *ConfigsManager) GetObject(ctx context.Context, object string, overlays
 	}
 	if len(s.ConfigProviders) == 1 {
 		for _, provider := range s.ConfigProviders {
-			return s.getObjectWithOverlay(ctx, provider, object, overlays, localContext)
+			return configObject(s.getObjectWithOverlay(ctx, provider, object, overlays, localContext))
 		}
 	}
 	for _, key := range s.Precedence {
 		if provider, ok := s.ConfigProviders[key]; ok {
-			return s.getObjectWithOverlay(ctx, provider, object, overlays, localContext)
+			return configObject(s.getObjectWithOverlay(ctx, provider, object, overlays, localContext))
 		}
 	}
 
@@ -181,14 +205,61 @@ func (s *ConfigsManager) GetObject(ctx context.Context, object string, overlays
 	return nil, err
 }
 
-func (s *ConfigsManager) getObjectWithOverlay(ctx context.Context, provider config.IConfigProvider, object string, overlays []string, localContext interface{}) (map[string]interface{}, error) {
-	if len(overlays) > 0 {
-		for _, overlay := range overlays {
-			overlayObject, err := provider.ReadObject(ctx, overlay, localContext)
-			return overlayObject, err
+// getObjectWithOverlay resolves an object and combines it with its overlays, with the merge mode of each overlay.
+// Overlays take precedence in the order they are listed, and missing overlays are skipped. A value whose properties
+// partly failed to evaluate is returned with the error.
+func (s *ConfigsManager) getObjectWithOverlay(ctx context.Context, provider config.IConfigProvider, object string, overlays []string, localContext interface{}) (model.ConfigValue, error) {
+	value, err := readConfigObject(ctx, provider, object, localContext)
+	resolved := value.Value != nil
+	for i := len(overlays) - 1; i >= 0; i-- {
+		overlay, overlayErr := readConfigObject(ctx, provider, overlays[i], localContext)
+		if overlay.Value == nil {
+			continue
+		}
+		overlay = overlay.AsOverlay()
+		mode := overlay.Chain[0].MergeMode
+		if resolved {
+			value = value.Override(overlay, mode)
+			if overlayErr != nil || mode == model.MergeModeReplace {
+				err = overlayErr
+			}
+		} else {
+			// the overlay resolves the value, so the error of the missing base no longer applies
+			value = overlay
+			resolved = true
+			err = overlayErr
 		}
 	}
-	return provider.ReadObject(ctx, object, localContext)
+	return value, err
+}
+
+func configObject(value model.ConfigValue, err error) (map[string]interface{}, error) {
+	object, _ := value.Value.(map[string]interface{})
+	return object, err
+}
+
+// readConfigValue reads a field with the catalogs that supplied it, when the provider can tell them
+func readConfigValue(ctx context.Context, provider config.IConfigProvider, object string, field string, localContext interface{}) (model.ConfigValue, error) {
+	if explainProvider, ok := provider.(IExplainConfigProvider); ok {
+		return explainProvider.ExplainRead(ctx, object, field, localContext)
+	}
+	value, err := provider.Read(ctx, object, field, localContext)
+	if err != nil {
+		return model.ConfigValue{}, err
+	}
+	return model.NewConfigValue(value, object, model.MergeModeReplace), nil
+}
+
+// readConfigObject reads an object with the catalogs that supplied its fields, when the provider can tell them
+func readConfigObject(ctx context.Context, provider config.IConfigProvider, object string, localContext interface{}) (model.ConfigValue, error) {
+	if explainProvider, ok := provider.(IExplainConfigProvider); ok {
+		return explainProvider.ExplainReadObject(ctx, object, localContext)
+	}
+	value, err := provider.ReadObject(ctx, object, localContext)
+	if value == nil {
+		return model.ConfigValue{}, err
+	}
+	return model.NewConfigValue(value, object, model.MergeModeReplace), err
 }
 
 func (s *ConfigsManager) Set(ctx context.Context, object string, field string, value interface{}) error {
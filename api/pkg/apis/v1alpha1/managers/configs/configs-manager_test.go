@@ -355,6 +355,151 @@ func TestOverlayWithMultipleProvidersReversedPrecedence(t *testing.T) {
 	assert.Equal(t, object2, val2)
 }
 
+func TestOverlayOrder(t *testing.T) {
+	provider := memory.MemoryConfigProvider{}
+	err := provider.Init(memory.MemoryConfigProviderConfig{})
+	manager := ConfigsManager{
+		ConfigProviders: map[string]config.IConfigProvider{
+			"memory": &provider,
+		},
+	}
+	assert.Nil(t, err)
+
+	manager.Set(ctx, "obj", "field", "obj::field")
+	manager.Set(ctx, "overlay1", "field", "overlay1::field")
+	manager.Set(ctx, "overlay2", "field", "overlay2::field")
+	val, err := manager.Get(ctx, "obj", "field", []string{"missing", "overlay1", "overlay2"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "overlay1::field", val)
+
+	value, err := manager.Explain(ctx, "obj", "field", []string{"missing", "overlay1", "overlay2"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "overlay1::field", value.Value)
+	assert.Equal(t, []model.ConfigLayer{
+		{Catalog: "overlay1", MergeMode: "replace", Overlay: true},
+		{Catalog: "overlay2", MergeMode: "replace", Overlay: true},
+		{Catalog: "obj", MergeMode: "replace"},
+	}, value.Chain)
+	assert.Equal(t, map[string]string{"": "overlay1"}, value.Sources)
+
+	val, err = manager.Get(ctx, "missing", "field", []string{"overlay2"}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "overlay2::field", val)
+
+	_, err = manager.Get(ctx, "obj", "missing", []string{"overlay1"}, nil)
+	assert.NotNil(t, err)
+}
+
+func TestOverlayMergeModes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		switch r.URL.Path {
+		case "/catalogs/registry/config-v-v1":
+			response = model.CatalogState{
+				ObjectMeta: model.ObjectMeta{
+					Name: "config-v-v1",
+				},
+				Spec: &model.CatalogSpec{
+					Properties: map[string]interface{}{
+						"network": map[string]interface{}{
+							"dns":   "8.8.8.8",
+							"ports": []interface{}{80},
+						},
+						"region": "west",
+					},
+				},
+			}
+		case "/catalogs/registry/site-v-v1":
+			response = model.CatalogState{
+				ObjectMeta: model.ObjectMeta{
+					Name: "site-v-v1",
+				},
+				Spec: &model.CatalogSpec{
+					Metadata: map[string]string{
+						"mergeMode": "append-list",
+					},
+					Properties: map[string]interface{}{
+						"network": map[string]interface{}{
+							"ports": []interface{}{8080},
+						},
+					},
+				},
+			}
+		case "/catalogs/registry/line-v-v1":
+			response = model.CatalogState{
+				ObjectMeta: model.ObjectMeta{
+					Name: "line-v-v1",
+				},
+				Spec: &model.CatalogSpec{
+					Metadata: map[string]string{
+						"mergeMode": "deep-merge",
+					},
+					Properties: map[string]interface{}{
+						"network": map[string]interface{}{
+							"dns": "10.0.0.2",
+						},
+					},
+				},
+			}
+		case "/catalogs/registry/missing-v-v1":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		default:
+			response = AuthResponse{
+				AccessToken: "test-token",
+				TokenType:   "Bearer",
+				Username:    "test-user",
+				Roles:       []string{"role1", "role2"},
+			}
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer ts.Close()
+	os.Setenv(constants.SymphonyAPIUrlEnvName, ts.URL+"/")
+	os.Setenv(constants.UseServiceAccountTokenEnvName, "false")
+
+	evalContext, err := getMockEvalContext()
+	assert.Nil(t, err)
+	manager := evalContext.ConfigProvider.(*ConfigsManager)
+
+	// an overlay resolves the object of a missing base, whatever its merge mode
+	object, err := manager.GetObject(ctx, "missing:v1", []string{"line:v1"}, evalContext)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.2", object["network"].(map[string]interface{})["dns"])
+
+	val, err := manager.Get(ctx, "config:v1", "network", []string{"line:v1", "site:v1"}, evalContext)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"dns":   "10.0.0.2",
+		"ports": []interface{}{float64(80), float64(8080)},
+	}, val)
+
+	value, err := manager.Explain(ctx, "config:v1", "network", []string{"line:v1", "site:v1"}, evalContext)
+	assert.Nil(t, err)
+	assert.Equal(t, []model.ConfigLayer{
+		{Catalog: "line-v-v1", MergeMode: "deep-merge", Overlay: true},
+		{Catalog: "site-v-v1", MergeMode: "append-list", Overlay: true},
+		{Catalog: "config-v-v1", MergeMode: "replace"},
+	}, value.Chain)
+	assert.Equal(t, map[string]string{
+		"dns":      "line-v-v1",
+		"ports[0]": "config-v-v1",
+		"ports[1]": "site-v-v1",
+	}, value.Sources)
+
+	object, err = manager.GetObject(ctx, "config:v1", []string{"line:v1"}, evalContext)
+	assert.Nil(t, err)
+	assert.Equal(t, "west", object["region"])
+	assert.Equal(t, "10.0.0.2", object["network"].(map[string]interface{})["dns"])
+
+	value, err = manager.Explain(ctx, "config:v1", "", []string{"line:v1"}, evalContext)
+	assert.Nil(t, err)
+	assert.Equal(t, "config-v-v1", value.Sources["region"])
+	assert.Equal(t, "line-v-v1", value.Sources["network.dns"])
+	assert.Equal(t, "config-v-v1", value.Sources["network.ports[0]"])
+}
+
 func TestMultipleProvidersSameKey(t *testing.T) {
 	provider1 := memory.MemoryConfigProvider{}
 	err := provider1.Init(memory.MemoryConfigProviderConfig{})
@@ -129,8 +129,14 @@ func (t *InstancesManager) UpsertState(ctx context.Context, name string, state m
 }
 
 func (t *InstancesManager) ListState(ctx context.Context, namespace string) ([]model.InstanceState, error) {
+	ret, _, err := t.ListStatePage(ctx, namespace, states.PageOptions{})
+	return ret, err
+}
+
+// ListStatePage lists a page of instances sorted by the page options, and returns the continuation token of the next page
+func (t *InstancesManager) ListStatePage(ctx context.Context, namespace string, page states.PageOptions) ([]model.InstanceState, string, error) {
 	ctx, span := observability.StartSpan("Instances Manager", ctx, &map[string]string{
-		"method": "ListSpec",
+		"method": "ListStatePage",
 	})
 	var err error = nil
 	defer observ_utils.CloseSpanWithError(span, &err)
@@ -144,22 +150,24 @@ func (t *InstancesManager) ListState(ctx context.Context, namespace string) ([]m
 			"namespace": namespace,
 			"kind":      "Instance",
 		},
+		PageOptions: page,
 	}
 	var instances []states.StateEntry
-	instances, _, err = t.StateProvider.List(ctx, listRequest)
+	var token string
+	instances, token, err = t.StateProvider.List(ctx, listRequest)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	ret := make([]model.InstanceState, 0)
 	for _, t := range instances {
 		var rt model.InstanceState
 		rt, err = getInstanceState(t.Body, t.ETag)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		ret = append(ret, rt)
 	}
-	return ret, nil
+	return ret, token, nil
 }
 
 func getInstanceState(body interface{}, etag string) (model.InstanceState, error) {
@@ -0,0 +1,310 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	api_utils "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
+	observability "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability"
+	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
+)
+
+const (
+	// ProvidersScheduleState names the state provider that keeps the activation schedules. The schedules are kept by
+	// the persistent state provider if it isn't configured.
+	ProvidersScheduleState = "providers.schedulestate"
+	// ActivationScheduleEnabled turns off running the activation schedules when it's "false", such as on Kubernetes,
+	// where the schedules are kept as ActivationSchedule objects that the workflow controllers run
+	ActivationScheduleEnabled = "activationschedule.enabled"
+	scheduleResource          = "activationschedules"
+	scheduleKind              = "ActivationSchedule"
+)
+
+// getScheduleStateProvider returns the schedule state provider of the manager config, or nil if it isn't configured
+func getScheduleStateProvider(config managers.ManagerConfig, providers map[string]providers.IProvider) (states.IStateProvider, error) {
+	name, ok := config.Properties[ProvidersScheduleState]
+	if !ok {
+		return nil, nil
+	}
+	provider, ok := providers[name]
+	if !ok {
+		return nil, v1alpha2.NewCOAError(nil, "schedule state provider is not supplied", v1alpha2.MissingConfig)
+	}
+	stateProvider, ok := provider.(states.IStateProvider)
+	if !ok {
+		return nil, v1alpha2.NewCOAError(nil, "supplied schedule provider is not a state provider", v1alpha2.BadConfig)
+	}
+	return stateProvider, nil
+}
+
+func scheduleMetadata(namespace string) map[string]interface{} {
+	return map[string]interface{}{
+		"version":   "v1",
+		"group":     model.WorkflowGroup,
+		"resource":  scheduleResource,
+		"namespace": namespace,
+		"kind":      scheduleKind,
+	}
+}
+
+func getActivationSchedule(body interface{}) (model.ActivationScheduleState, error) {
+	var schedule model.ActivationScheduleState
+	data, _ := json.Marshal(body)
+	if err := json.Unmarshal(data, &schedule); err != nil {
+		return model.ActivationScheduleState{}, v1alpha2.NewCOAError(err, "invalid activation schedule", v1alpha2.InternalError)
+	}
+	return schedule, nil
+}
+
+func isActivationSchedule(body interface{}) bool {
+	var object struct {
+		Kind string `json:"kind"`
+	}
+	data, _ := json.Marshal(body)
+	if err := json.Unmarshal(data, &object); err != nil {
+		return false
+	}
+	return object.Kind == scheduleKind
+}
+
+// UpsertActivationSchedule creates or updates an activation schedule. The status of an existing schedule is kept.
+func (s *JobsManager) UpsertActivationSchedule(ctx context.Context, name string, schedule model.ActivationScheduleState) error {
+	ctx, span := observability.StartSpan("Job Manager", ctx, &map[string]string{
+		"method": "UpsertActivationSchedule",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	if schedule.ObjectMeta.Name != "" && schedule.ObjectMeta.Name != name {
+		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("Name in metadata (%s) does not match name in request (%s)", schedule.ObjectMeta.Name, name), v1alpha2.BadRequest)
+		return err
+	}
+	schedule.ObjectMeta.FixNames(name)
+	if schedule.Spec == nil {
+		err = v1alpha2.NewCOAError(nil, "activation schedule has no spec", v1alpha2.BadRequest)
+		return err
+	}
+	if err = schedule.Spec.Validate(); err != nil {
+		err = v1alpha2.NewCOAError(err, fmt.Sprintf("invalid activation schedule: %v", err), v1alpha2.BadRequest)
+		return err
+	}
+	log.InfofCtx(ctx, " M (Job): upsert activation schedule %s in namespace %s", name, schedule.ObjectMeta.Namespace)
+
+	body := map[string]interface{}{
+		"apiVersion": model.WorkflowGroup + "/v1",
+		"kind":       scheduleKind,
+		"metadata":   schedule.ObjectMeta,
+		"spec":       schedule.Spec,
+	}
+	existing, getErr := s.GetActivationSchedule(ctx, name, schedule.ObjectMeta.Namespace)
+	if getErr == nil && existing.Status != nil {
+		body["status"] = scheduleStatusBody(*existing.Status)
+	}
+	_, err = s.ScheduleStateProvider.Upsert(ctx, states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   name,
+			Body: body,
+		},
+		Metadata: scheduleMetadata(schedule.ObjectMeta.Namespace),
+	})
+	return err
+}
+
+// GetActivationSchedule returns an activation schedule
+func (s *JobsManager) GetActivationSchedule(ctx context.Context, name string, namespace string) (model.ActivationScheduleState, error) {
+	ctx, span := observability.StartSpan("Job Manager", ctx, &map[string]string{
+		"method": "GetActivationSchedule",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	var entry states.StateEntry
+	entry, err = s.ScheduleStateProvider.Get(ctx, states.GetRequest{
+		ID:       name,
+		Metadata: scheduleMetadata(namespace),
+	})
+	if err != nil {
+		return model.ActivationScheduleState{}, err
+	}
+	return getActivationSchedule(entry.Body)
+}
+
+// ListActivationSchedules lists the activation schedules in a namespace, or in all namespaces if it's empty
+func (s *JobsManager) ListActivationSchedules(ctx context.Context, namespace string) ([]model.ActivationScheduleState, error) {
+	ctx, span := observability.StartSpan("Job Manager", ctx, &map[string]string{
+		"method": "ListActivationSchedules",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	var entries []states.StateEntry
+	entries, _, err = s.ScheduleStateProvider.List(ctx, states.ListRequest{
+		Metadata: scheduleMetadata(namespace),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]model.ActivationScheduleState, 0)
+	for _, entry := range entries {
+		// the persistent state provider may keep other entries, like the scheduled stages
+		if !isActivationSchedule(entry.Body) {
+			continue
+		}
+		schedule, parseErr := getActivationSchedule(entry.Body)
+		if parseErr != nil || schedule.Spec == nil {
+			continue
+		}
+		ret = append(ret, schedule)
+	}
+	return ret, nil
+}
+
+// DeleteActivationSchedule deletes an activation schedule. The activations it created are kept.
+func (s *JobsManager) DeleteActivationSchedule(ctx context.Context, name string, namespace string) error {
+	ctx, span := observability.StartSpan("Job Manager", ctx, &map[string]string{
+		"method": "DeleteActivationSchedule",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	log.InfofCtx(ctx, " M (Job): delete activation schedule %s in namespace %s", name, namespace)
+	err = s.ScheduleStateProvider.Delete(ctx, states.DeleteRequest{
+		ID:       name,
+		Metadata: scheduleMetadata(namespace),
+	})
+	return err
+}
+
+func (s *JobsManager) pollActivationSchedules() []error {
+	ctx, span := observability.StartSpan("Job Manager", context.Background(), &map[string]string{
+		"method": "pollActivationSchedules",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	var schedules []model.ActivationScheduleState
+	schedules, err = s.ListActivationSchedules(ctx, "")
+	if err != nil {
+		log.ErrorfCtx(ctx, " M (Job): error listing activation schedules: %s", err.Error())
+		return []error{err}
+	}
+	errors := make([]error, 0)
+	now := time.Now().UTC()
+	for _, schedule := range schedules {
+		if runErr := s.runActivationSchedule(ctx, schedule, now); runErr != nil {
+			log.ErrorfCtx(ctx, " M (Job): error running activation schedule %s: %s", schedule.ObjectMeta.Name, runErr.Error())
+			errors = append(errors, runErr)
+		}
+	}
+	if len(errors) > 0 {
+		return errors
+	}
+	return nil
+}
+
+// runActivationSchedule creates the activation of a schedule that is due, applies its concurrency policy and deletes
+// the finished activations beyond its history limits
+func (s *JobsManager) runActivationSchedule(ctx context.Context, schedule model.ActivationScheduleState, now time.Time) error {
+	name := schedule.ObjectMeta.Name
+	namespace := schedule.ObjectMeta.Namespace
+	activations := make([]model.ScheduledActivation, 0)
+	if schedule.Status != nil {
+		for _, activation := range schedule.Status.Succeeded {
+			activations = append(activations, model.ScheduledActivation{Name: activation, Finished: true, Succeeded: true})
+		}
+		for _, activation := range schedule.Status.Failed {
+			activations = append(activations, model.ScheduledActivation{Name: activation, Finished: true})
+		}
+		for _, activation := range schedule.Status.Active {
+			state, err := s.apiClient.GetActivation(ctx, activation, namespace, s.user, s.password)
+			if err != nil {
+				if api_utils.IsNotFound(err) {
+					log.InfofCtx(ctx, " M (Job): activation %s of schedule %s is gone", activation, name)
+					continue
+				}
+				log.ErrorfCtx(ctx, " M (Job): error getting activation %s of schedule %s: %s", activation, name, err.Error())
+				activations = append(activations, model.ScheduledActivation{Name: activation})
+				continue
+			}
+			activations = append(activations, model.NewScheduledActivation(activation, state.Status))
+		}
+	}
+
+	plan, err := schedule.Plan(activations, now)
+	if err != nil {
+		return err
+	}
+	for _, activation := range plan.Cancel {
+		log.InfofCtx(ctx, " M (Job): schedule %s replaces running activation %s", name, activation)
+		if err = s.apiClient.CancelActivation(ctx, activation, namespace, s.user, s.password); err != nil && !api_utils.IsNotFound(err) {
+			log.ErrorfCtx(ctx, " M (Job): error cancelling activation %s: %s", activation, err.Error())
+		}
+	}
+	if plan.Create != "" {
+		log.InfofCtx(ctx, " M (Job): schedule %s creates activation %s", name, plan.Create)
+		payload, _ := json.Marshal(schedule.NewActivation(plan.Create))
+		if err = s.apiClient.CreateActivation(ctx, plan.Create, payload, namespace, s.user, s.password); err != nil {
+			// the status isn't saved, so the activation is created on the next poll
+			return err
+		}
+	}
+	for _, activation := range plan.Delete {
+		log.InfofCtx(ctx, " M (Job): schedule %s deletes activation %s beyond its history limit", name, activation)
+		if err = s.apiClient.DeleteActivation(ctx, activation, namespace, s.user, s.password); err != nil && !api_utils.IsNotFound(err) {
+			log.ErrorfCtx(ctx, " M (Job): error deleting activation %s: %s", activation, err.Error())
+		}
+	}
+
+	_, err = s.ScheduleStateProvider.Upsert(ctx, states.UpsertRequest{
+		Value: states.StateEntry{
+			ID: name,
+			Body: map[string]interface{}{
+				"apiVersion": model.WorkflowGroup + "/v1",
+				"kind":       scheduleKind,
+				"metadata":   schedule.ObjectMeta,
+				"spec":       schedule.Spec,
+				"status":     scheduleStatusBody(plan.Status),
+			},
+		},
+		Metadata: scheduleMetadata(namespace),
+		Options: states.UpsertOption{
+			UpdateStatusOnly: true,
+		},
+	})
+	return err
+}
+
+// scheduleStatusBody returns all the fields of the status, as the state providers merge the status fields
+func scheduleStatusBody(status model.ActivationScheduleStatus) map[string]interface{} {
+	emptyIfNil := func(names []string) []string {
+		if names == nil {
+			return []string{}
+		}
+		return names
+	}
+	return map[string]interface{}{
+		"lastScheduleTime": status.LastScheduleTime,
+		"nextScheduleTime": status.NextScheduleTime,
+		"active":           emptyIfNil(status.Active),
+		"succeeded":        emptyIfNil(status.Succeeded),
+		"failed":           emptyIfNil(status.Failed),
+	}
+}
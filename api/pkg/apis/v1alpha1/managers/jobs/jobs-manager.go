@@ -33,6 +33,7 @@ type JobsManager struct {
 	managers.Manager
 	PersistentStateProvider states.IStateProvider
 	VolatileStateProvider   states.IStateProvider
+	ScheduleStateProvider   states.IStateProvider
 	apiClient               utils.ApiClient
 	interval                int32
 	user                    string
@@ -61,6 +62,13 @@ func (s *JobsManager) Init(vContext *contexts.VendorContext, config managers.Man
 	} else {
 		return err
 	}
+	s.ScheduleStateProvider, err = getScheduleStateProvider(config, providers)
+	if err != nil {
+		return err
+	}
+	if s.ScheduleStateProvider == nil {
+		s.ScheduleStateProvider = s.PersistentStateProvider
+	}
 	if utils.ShouldUseUserCreds() {
 		user, err := utils.GetString(s.Manager.Config.Properties, "user")
 		if err != nil {
@@ -196,6 +204,12 @@ func (s *JobsManager) Poll() []error {
 		if len(errors) > 0 {
 			return errors
 		}
+		if s.Config.Properties[ActivationScheduleEnabled] != "false" {
+			errors = s.pollActivationSchedules()
+			if len(errors) > 0 {
+				return errors
+			}
+		}
 	}
 	return nil
 }
@@ -231,7 +245,7 @@ func (s *JobsManager) pollSchedules() []error {
 			log.ErrorfCtx(ctx, " M (Job): get bad ActivationData from state store")
 			continue
 		}
-		if activationData.Schedule != "" {
+		if activationData.IsScheduled() {
 			var fire bool
 			fire, err = activationData.ShouldFireNow()
 			if err != nil {
@@ -243,7 +257,7 @@ func (s *JobsManager) pollSchedules() []error {
 				// TODO: check if the activation is in paused state
 				//       if not paused, skip trigger event and delete scheduled event directly
 				log.InfofCtx(ctx, " M (Job): firing schedule %s", activationData.Activation)
-				activationData.Schedule = ""
+				activationData.ClearSchedule()
 				// trigger the activation first and then delete the schedule events in state store
 				s.Context.Publish("trigger", v1alpha2.Event{
 					Body:    activationData,
@@ -383,6 +397,12 @@ func (s *JobsManager) HandleScheduleEvent(ctx context.Context, event v1alpha2.Ev
 		log.ErrorfCtx(ctx, " M (Job): schedule event body is not an activation data: %v", event.Body)
 		return v1alpha2.NewCOAError(nil, "event body is not an activation data", v1alpha2.BadRequest)
 	}
+	// a cron schedule waits for its next occurrence from the time the stage is reached
+	err = activationData.ResolveSchedule(time.Now().UTC())
+	if err != nil {
+		log.ErrorfCtx(ctx, " M (Job): unable to resolve schedule %s of activation %s: %s", activationData.Schedule, activationData.Activation, err.Error())
+		return v1alpha2.NewCOAError(err, "invalid schedule", v1alpha2.BadRequest)
+	}
 	key := fmt.Sprintf("sch_%s-%s", activationData.Campaign, activationData.Activation)
 	_, err = s.PersistentStateProvider.Upsert(ctx, states.UpsertRequest{
 		Value: states.StateEntry{
@@ -9,9 +9,11 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -136,6 +138,243 @@ func TestHandleScheduleEvent(t *testing.T) {
 	assert.NotNil(t, schedule)
 }
 
+func TestHandleScheduleEventResolvesCron(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+
+	jobManager := JobsManager{}
+	err := jobManager.Init(nil, managers.ManagerConfig{
+		Properties: map[string]string{
+			"providers.volatilestate":   "state",
+			"providers.persistentstate": "state",
+			"baseUrl":                   "http://localhost:8082/v1alpha2/",
+			"password":                  "",
+			"user":                      "admin",
+			"interval":                  "#15",
+		},
+	}, map[string]providers.IProvider{
+		"state": stateProvider,
+	})
+	assert.Nil(t, err)
+	err = jobManager.HandleScheduleEvent(context.Background(), v1alpha2.Event{
+		Body: v1alpha2.ActivationData{Campaign: "campaign1", Activation: "activation1", Schedule: "0 2 * * *"},
+	})
+	assert.Nil(t, err)
+
+	entry, err := stateProvider.Get(context.Background(), states.GetRequest{ID: "sch_campaign1-activation1"})
+	assert.Nil(t, err)
+	var activationData v1alpha2.ActivationData
+	data, _ := json.Marshal(entry.Body)
+	err = json.Unmarshal(data, &activationData)
+	assert.Nil(t, err)
+	fireTime, err := time.Parse(time.RFC3339, activationData.Schedule)
+	assert.Nil(t, err)
+	assert.True(t, fireTime.After(time.Now()))
+	assert.Equal(t, 2, fireTime.Hour())
+}
+
+func TestPollScheduleWaitsForMaintenanceWindow(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+
+	jobManager := JobsManager{}
+	err := jobManager.Init(nil, managers.ManagerConfig{
+		Properties: map[string]string{
+			"providers.volatilestate":   "state",
+			"providers.persistentstate": "state",
+			"baseUrl":                   "http://localhost:8082/v1alpha2/",
+			"password":                  "",
+			"user":                      "admin",
+			"schedule.enabled":          "true",
+		},
+	}, map[string]providers.IProvider{
+		"state": stateProvider,
+	})
+	assert.Nil(t, err)
+	// the window is open for an hour, half a day away from now, so the due schedule keeps waiting
+	start := fmt.Sprintf("0 %d * * *", (time.Now().UTC().Hour()+12)%24)
+	err = jobManager.HandleScheduleEvent(context.Background(), v1alpha2.Event{
+		Body: v1alpha2.ActivationData{
+			Campaign:           "campaign1",
+			Activation:         "activation1",
+			Schedule:           "2006-01-02T15:04:05Z",
+			MaintenanceWindows: []v1alpha2.MaintenanceWindow{{Start: start, Duration: "1m"}},
+		},
+	})
+	assert.Nil(t, err)
+	errs := jobManager.Poll()
+	assert.Nil(t, errs)
+
+	_, err = stateProvider.Get(context.Background(), states.GetRequest{ID: "sch_campaign1-activation1"})
+	assert.Nil(t, err)
+}
+
+func TestUpsertActivationScheduleKeepsStatus(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	jobManager := JobsManager{
+		PersistentStateProvider: stateProvider,
+		ScheduleStateProvider:   stateProvider,
+	}
+	schedule := model.ActivationScheduleState{
+		ObjectMeta: model.ObjectMeta{Name: "drift"},
+		Spec: &model.ActivationScheduleSpec{
+			Schedule: "0 * * * *",
+			Campaign: "drift-detection:v1",
+		},
+	}
+	err := jobManager.UpsertActivationSchedule(context.Background(), "drift", model.ActivationScheduleState{
+		Spec: &model.ActivationScheduleSpec{Schedule: "hourly", Campaign: "drift-detection:v1"},
+	})
+	coaErr, ok := err.(v1alpha2.COAError)
+	assert.True(t, ok)
+	assert.Equal(t, v1alpha2.BadRequest, coaErr.State)
+
+	err = jobManager.UpsertActivationSchedule(context.Background(), "drift", schedule)
+	assert.Nil(t, err)
+	err = jobManager.runActivationSchedule(context.Background(), schedule, time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC))
+	assert.Nil(t, err)
+
+	schedule.Spec.ConcurrencyPolicy = model.ConcurrencyForbid
+	err = jobManager.UpsertActivationSchedule(context.Background(), "drift", schedule)
+	assert.Nil(t, err)
+	saved, err := jobManager.GetActivationSchedule(context.Background(), "drift", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, model.ConcurrencyForbid, saved.Spec.ConcurrencyPolicy)
+	assert.Equal(t, "2024-05-01T10:30:00Z", saved.Status.LastScheduleTime)
+
+	// other entries of the store aren't schedules, even if they have a schedule
+	_, err = stateProvider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   "stage",
+			Body: map[string]interface{}{"spec": map[string]interface{}{"schedule": "0 * * * *"}},
+		},
+		Metadata: scheduleMetadata("default"),
+	})
+	assert.Nil(t, err)
+	schedules, err := jobManager.ListActivationSchedules(context.Background(), "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(schedules))
+
+	err = jobManager.DeleteActivationSchedule(context.Background(), "drift", "default")
+	assert.Nil(t, err)
+	_, err = jobManager.GetActivationSchedule(context.Background(), "drift", "default")
+	assert.True(t, v1alpha2.IsNotFound(err))
+}
+
+func TestRunActivationSchedule(t *testing.T) {
+	created := make([]string, 0)
+	deleted := make([]string, 0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/activations/registry/")
+		switch {
+		case name == r.URL.Path:
+			json.NewEncoder(w).Encode(AuthResponse{AccessToken: "test-token", TokenType: "Bearer"})
+		case r.Method == http.MethodPost:
+			var activation model.ActivationState
+			json.NewDecoder(r.Body).Decode(&activation)
+			assert.Equal(t, "drift", activation.ObjectMeta.Labels[constants.ActivationSchedule])
+			created = append(created, name)
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, name)
+		case name == "drift-28576080":
+			json.NewEncoder(w).Encode(model.ActivationState{
+				ObjectMeta: model.ObjectMeta{Name: name},
+				Status:     &model.ActivationStatus{Status: v1alpha2.Done},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	os.Setenv(constants.SymphonyAPIUrlEnvName, ts.URL+"/")
+	os.Setenv(constants.UseServiceAccountTokenEnvName, "false")
+
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	jobManager := JobsManager{}
+	err := jobManager.Init(nil, managers.ManagerConfig{
+		Properties: map[string]string{
+			"providers.volatilestate":   "state",
+			"providers.persistentstate": "state",
+			"baseUrl":                   ts.URL + "/",
+			"password":                  "",
+			"user":                      "admin",
+		},
+	}, map[string]providers.IProvider{
+		"state": stateProvider,
+	})
+	assert.Nil(t, err)
+
+	succeededLimit := 1
+	schedule := model.ActivationScheduleState{
+		ObjectMeta: model.ObjectMeta{Name: "drift", Namespace: "default"},
+		Spec: &model.ActivationScheduleSpec{
+			Schedule:               "0 * * * *",
+			Campaign:               "drift-detection:v1",
+			SuccessfulHistoryLimit: &succeededLimit,
+		},
+		Status: &model.ActivationScheduleStatus{
+			LastScheduleTime: "2024-05-01T12:00:00Z",
+			Active:           []string{"drift-28576080"},
+			Succeeded:        []string{"drift-28576020"},
+		},
+	}
+	err = jobManager.UpsertActivationSchedule(context.Background(), "drift", schedule)
+	assert.Nil(t, err)
+	err = jobManager.runActivationSchedule(context.Background(), schedule, time.Date(2024, 5, 1, 13, 5, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"drift-28576140"}, created)
+	assert.Equal(t, []string{"drift-28576020"}, deleted)
+
+	saved, err := jobManager.GetActivationSchedule(context.Background(), "drift", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-05-01T13:00:00Z", saved.Status.LastScheduleTime)
+	assert.Equal(t, "2024-05-01T14:00:00Z", saved.Status.NextScheduleTime)
+	assert.Equal(t, []string{"drift-28576140"}, saved.Status.Active)
+	assert.Equal(t, []string{"drift-28576080"}, saved.Status.Succeeded)
+	assert.Equal(t, 0, len(saved.Status.Failed))
+}
+
+func TestPollSkipsActivationSchedules(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/activations/") {
+			t.Errorf("unexpected activation request %s %s", r.Method, r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(AuthResponse{AccessToken: "test-token", TokenType: "Bearer"})
+	}))
+	defer ts.Close()
+	os.Setenv(constants.SymphonyAPIUrlEnvName, ts.URL+"/")
+	os.Setenv(constants.UseServiceAccountTokenEnvName, "false")
+
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	jobManager := JobsManager{}
+	err := jobManager.Init(nil, managers.ManagerConfig{
+		Properties: map[string]string{
+			"providers.volatilestate":   "state",
+			"providers.persistentstate": "state",
+			"baseUrl":                   ts.URL + "/",
+			"password":                  "",
+			"user":                      "admin",
+			"schedule.enabled":          "true",
+			ActivationScheduleEnabled:   "false",
+		},
+	}, map[string]providers.IProvider{
+		"state": stateProvider,
+	})
+	assert.Nil(t, err)
+	err = jobManager.UpsertActivationSchedule(context.Background(), "drift", model.ActivationScheduleState{
+		Spec: &model.ActivationScheduleSpec{Schedule: "* * * * *", Campaign: "drift-detection:v1"},
+	})
+	assert.Nil(t, err)
+	errlist := jobManager.Poll()
+	assert.Nil(t, errlist)
+	saved, err := jobManager.GetActivationSchedule(context.Background(), "drift", "default")
+	assert.Nil(t, err)
+	assert.Nil(t, saved.Status)
+}
+
 func TestHandleheartbeatEvent(t *testing.T) {
 	stateProvider := &memorystate.MemoryStateProvider{}
 	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package solution
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+// stepExecutor runs the steps of a deployment plan concurrently. A step is dispatched once all
+// earlier steps it conflicts with have finished. Two steps conflict when they are on the same
+// target, or when one of them has a component of the other as a predecessor. Steps are always
+// dispatched in plan order, so a concurrency of 1 is equivalent to a sequential walk.
+type stepExecutor struct {
+	steps        []model.DeploymentStep
+	predecessors [][]int
+	concurrency  int
+}
+
+type stepOutcome struct {
+	index int
+	err   error
+}
+
+func newStepExecutor(steps []model.DeploymentStep, concurrency int) *stepExecutor {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &stepExecutor{
+		steps:        steps,
+		predecessors: planStepPredecessors(steps),
+		concurrency:  concurrency,
+	}
+}
+
+// planStepPredecessors returns, for each step, the indexes of the earlier steps that must finish
+// before the step can start.
+func planStepPredecessors(steps []model.DeploymentStep) [][]int {
+	ret := make([][]int, len(steps))
+	for j := range steps {
+		ret[j] = make([]int, 0)
+		for i := 0; i < j; i++ {
+			if stepsConflict(steps[i], steps[j]) {
+				ret[j] = append(ret[j], i)
+			}
+		}
+	}
+	return ret
+}
+
+func stepsConflict(a model.DeploymentStep, b model.DeploymentStep) bool {
+	if a.Target == b.Target {
+		return true
+	}
+	return stepDependsOn(a, b) || stepDependsOn(b, a)
+}
+
+func stepDependsOn(step model.DeploymentStep, other model.DeploymentStep) bool {
+	for _, ref := range step.Predecessors {
+		if ref.Target != other.Target {
+			continue
+		}
+		for _, c := range other.Components {
+			if c.Component.Name == ref.Component {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// run executes every step with the given function. Once a step fails, or the context is done, no
+// new steps are dispatched; steps that are already running are allowed to finish. The first error
+// encountered is returned.
+func (e *stepExecutor) run(ctx context.Context, execute func(index int, step model.DeploymentStep) error) error {
+	remaining := make([]int, len(e.steps))
+	dependents := make([][]int, len(e.steps))
+	ready := make([]int, 0)
+	for j, preds := range e.predecessors {
+		remaining[j] = len(preds)
+		for _, i := range preds {
+			dependents[i] = append(dependents[i], j)
+		}
+		if remaining[j] == 0 {
+			ready = append(ready, j)
+		}
+	}
+
+	outcomes := make(chan stepOutcome)
+	running := 0
+	var firstErr error
+	for {
+		for firstErr == nil && len(ready) > 0 && running < e.concurrency {
+			if ctx.Err() != nil {
+				firstErr = ctx.Err()
+				break
+			}
+			index := ready[0]
+			ready = ready[1:]
+			running++
+			go func(index int) {
+				outcomes <- stepOutcome{index: index, err: execute(index, e.steps[index])}
+			}(index)
+		}
+		if running == 0 {
+			break
+		}
+		outcome := <-outcomes
+		running--
+		if outcome.err != nil {
+			if firstErr == nil {
+				firstErr = outcome.err
+			}
+			continue
+		}
+		for _, j := range dependents[outcome.index] {
+			remaining[j]--
+			if remaining[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+		sort.Ints(ready)
+	}
+	return firstErr
+}
+
+// failedPredecessor returns the reason a step can't run when one of its predecessors did not report success, or an
+// empty string otherwise. Predecessors that were not part of this run, such as components on filtered targets, are
+// considered satisfied.
+func failedPredecessor(step model.DeploymentStep, nodeStates map[string]v1alpha2.State) string {
+	for _, ref := range step.Predecessors {
+		if state, ok := nodeStates[ref.String()]; ok && !isHealthyComponentState(state) {
+			return fmt.Sprintf("prerequisite component %s on target %s did not succeed: %s", ref.Component, ref.Target, state)
+		}
+	}
+	return ""
+}
+
+// recordNodeStates records the outcome of every component of a step. A component takes the status reported by the
+// provider; components the provider did not report on take the step error, or Updated/Deleted if the step succeeded.
+func recordNodeStates(step model.DeploymentStep, componentResults map[string]model.ComponentResultSpec, stepError error, nodeStates map[string]v1alpha2.State) {
+	for _, c := range step.Components {
+		state := v1alpha2.Updated
+		if c.Action == model.ComponentDelete {
+			state = v1alpha2.Deleted
+		}
+		if stepError != nil {
+			state = getErrorState(stepError)
+		}
+		if r, ok := componentResults[c.Component.Name]; ok && (stepError == nil || r.Status != v1alpha2.Untouched) {
+			state = r.Status
+		}
+		nodeStates[model.ComponentTargetRef{Component: c.Component.Name, Target: step.Target}.String()] = state
+	}
+}
+
+func hasNodeStates(step model.DeploymentStep, nodeStates map[string]v1alpha2.State) bool {
+	for _, c := range step.Components {
+		if _, ok := nodeStates[model.ComponentTargetRef{Component: c.Component.Name, Target: step.Target}.String()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// skipDependentStep reports a step as skipped because of a failed predecessor. Its components are recorded as not
+// reached, so that their own dependents are skipped as well.
+func skipDependentStep(summary *model.SummarySpec, step model.DeploymentStep, deploymentType string, reason string, nodeStates map[string]v1alpha2.State) {
+	componentResults := make(map[string]model.ComponentResultSpec)
+	for _, c := range step.Components {
+		componentResults[c.Component.Name] = model.ComponentResultSpec{
+			Status:  v1alpha2.Untouched,
+			Message: "skipped: " + reason,
+		}
+		nodeStates[model.ComponentTargetRef{Component: c.Component.Name, Target: step.Target}.String()] = v1alpha2.DeploymentNotReached
+	}
+	summary.UpdateTargetResult(step.Target, model.TargetResultSpec{
+		Status:           fmt.Sprintf("%s Skipped", deploymentType),
+		Message:          "skipped: " + reason,
+		ComponentResults: componentResults,
+	})
+}
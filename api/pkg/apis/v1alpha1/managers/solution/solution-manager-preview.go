@@ -0,0 +1,314 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package solution
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	api_utils "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability"
+	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/secret"
+)
+
+// Preview computes the plan Reconcile would execute for the deployment, together with the property changes of every
+// component, without applying anything and without recording a summary. Secrets are resolved so that their changes are
+// reported, but their values are redacted from the preview.
+func (s *SolutionManager) Preview(ctx context.Context, deployment model.DeploymentSpec, remove bool, namespace string, targetName string) (model.PreviewSpec, error) {
+	ctx, span := observability.StartSpan("Solution Manager", ctx, &map[string]string{
+		"method": "Preview",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	log.InfofCtx(ctx, " M (Solution): previewing deployment.InstanceName: %s, deployment.SolutionName: %s, remove: %t, namespace: %s, targetName: %s",
+		deployment.Instance.ObjectMeta.Name,
+		deployment.SolutionName,
+		remove,
+		namespace,
+		targetName)
+
+	ret := model.PreviewSpec{
+		Steps:     make([]model.StepPreviewSpec, 0),
+		IsRemoval: remove,
+	}
+
+	secretPaths := findSecretPaths(deployment)
+	secrets := &recordingSecretProvider{}
+	if s.VendorContext != nil && s.VendorContext.EvaluationContext != nil {
+		context := s.VendorContext.EvaluationContext.Clone()
+		if context.SecretProvider != nil {
+			secrets.provider = context.SecretProvider
+			context.SecretProvider = secrets
+		}
+		context.DeploymentSpec = deployment
+		context.Value = deployment
+		context.Component = ""
+		context.Namespace = namespace
+		context.Context = ctx
+		deployment, err = api_utils.EvaluateDeployment(*context)
+		if err != nil && !remove {
+			log.ErrorfCtx(ctx, " M (Solution): failed to evaluate deployment spec: %+v", err)
+			return ret, err
+		}
+	}
+
+	previousDesiredState := s.getPreviousState(ctx, deployment.Instance.ObjectMeta.Name, namespace)
+
+	var currentDesiredState, currentState model.DeploymentState
+	currentDesiredState, err = NewDeploymentState(deployment)
+	if err != nil {
+		log.ErrorfCtx(ctx, " M (Solution): failed to create target manager state from deployment spec: %+v", err)
+		return ret, err
+	}
+	currentState, _, err = s.Get(ctx, deployment, targetName)
+	if err != nil {
+		log.ErrorfCtx(ctx, " M (Solution): failed to get current state: %+v", err)
+		return ret, err
+	}
+	desiredState := currentDesiredState
+	if previousDesiredState != nil {
+		desiredState = MergeDeploymentStates(&previousDesiredState.State, currentDesiredState)
+	}
+	if remove {
+		desiredState.MarkRemoveAll()
+	}
+	mergedState := MergeDeploymentStates(&currentState, desiredState)
+	var plan model.DeploymentPlan
+	plan, err = PlanForDeployment(deployment, mergedState)
+	if err != nil {
+		log.ErrorfCtx(ctx, " M (Solution): failed to plan for deployment: %+v", err)
+		return ret, err
+	}
+
+	ret.Plan = model.DeploymentPlan{
+		Steps: make([]model.DeploymentStep, 0),
+	}
+	currentComponents := make(map[string][]model.ComponentSpec)
+	for _, step := range plan.Steps {
+		if s.IsTarget && !api_utils.ContainsString(s.TargetNames, step.Target) {
+			continue
+		}
+		if targetName != "" && targetName != step.Target {
+			continue
+		}
+		ret.Plan.Steps = append(ret.Plan.Steps, step)
+
+		components, ok := currentComponents[step.Target]
+		if !ok {
+			_, components, err = s.Get(ctx, deployment, step.Target)
+			if err != nil {
+				log.ErrorfCtx(ctx, " M (Solution): failed to get current components on target %s: %+v", step.Target, err)
+				return ret, err
+			}
+			currentComponents[step.Target] = components
+		}
+
+		stepPreview := model.StepPreviewSpec{
+			Target:     step.Target,
+			Role:       step.Role,
+			Components: make([]model.ComponentPreviewSpec, 0),
+		}
+		if previousDesiredState != nil {
+			provider, providerErr := s.getProviderForStep(step, deployment, previousDesiredState)
+			if providerErr != nil {
+				err = providerErr
+				log.ErrorfCtx(ctx, " M (Solution): failed to create provider: %+v", err)
+				return ret, err
+			}
+			testState := MergeDeploymentStates(&previousDesiredState.State, currentState)
+			stepPreview.Skipped = s.canSkipStep(ctx, step, step.Target, provider, previousDesiredState.State.Components, testState)
+		}
+		for _, c := range step.Components {
+			var current, desired *model.ComponentSpec
+			for i := range components {
+				if components[i].Name == c.Component.Name {
+					current = &components[i]
+					break
+				}
+			}
+			if c.Action == model.ComponentUpdate {
+				desired = &c.Component
+			}
+			stepPreview.Components = append(stepPreview.Components, model.ComponentPreviewSpec{
+				Name:    c.Component.Name,
+				Action:  c.Action,
+				Changes: diffComponents(current, desired),
+			})
+		}
+		ret.Steps = append(ret.Steps, stepPreview)
+	}
+	redactPreview(&ret, secretPaths, secrets.resolved())
+	return ret, nil
+}
+
+// recordingSecretProvider resolves secrets with another secret provider and remembers their values, so that they can be
+// redacted from the preview
+type recordingSecretProvider struct {
+	provider secret.IExtSecretProvider
+	lock     sync.Mutex
+	values   []string
+}
+
+func (r *recordingSecretProvider) Get(ctx context.Context, name string, field string, localContext interface{}) (string, error) {
+	value, err := r.provider.Get(ctx, name, field, localContext)
+	if err == nil && value != "" {
+		r.lock.Lock()
+		r.values = append(r.values, value)
+		r.lock.Unlock()
+	}
+	return value, err
+}
+
+// resolved returns the resolved secret values, longest first, so that a secret that contains a shorter one is redacted
+// as a whole
+func (r *recordingSecretProvider) resolved() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ret := append([]string{}, r.values...)
+	sort.SliceStable(ret, func(i, j int) bool {
+		return len(ret[i]) > len(ret[j])
+	})
+	return ret
+}
+
+// findSecretPaths returns the paths of the properties of every solution component that use a secret, keyed by component
+// name. Lists are compared as a whole, so a list that uses a secret is reported as a whole.
+func findSecretPaths(deployment model.DeploymentSpec) map[string][]string {
+	ret := make(map[string][]string)
+	if deployment.Solution.Spec == nil {
+		return ret
+	}
+	for i := range deployment.Solution.Spec.Components {
+		component := &deployment.Solution.Spec.Components[i]
+		var paths []string
+		collectSecretPaths("", toPropertyMap(component), &paths)
+		if len(paths) > 0 {
+			ret[component.Name] = paths
+		}
+	}
+	return ret
+}
+
+func collectSecretPaths(path string, value interface{}, paths *[]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			collectSecretPaths(p, item, paths)
+		}
+	default:
+		data, _ := json.Marshal(v)
+		if strings.Contains(string(data), "$secret(") {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+// redactPreview replaces the resolved secrets in the plan and in the property changes. The properties that use a secret
+// are redacted on both sides, so that a secret that is about to be replaced isn't reported either.
+func redactPreview(preview *model.PreviewSpec, secretPaths map[string][]string, secrets []string) {
+	for i := range preview.Plan.Steps {
+		for j := range preview.Plan.Steps[i].Components {
+			component := &preview.Plan.Steps[i].Components[j].Component
+			*component = redactComponent(*component, secrets)
+		}
+	}
+	for i := range preview.Steps {
+		for j := range preview.Steps[i].Components {
+			c := &preview.Steps[i].Components[j]
+			for k := range c.Changes {
+				change := &c.Changes[k]
+				if isSecretPath(change.Path, secretPaths[c.Name]) {
+					if change.Current != nil {
+						change.Current = model.RedactedValue
+					}
+					if change.Desired != nil {
+						change.Desired = model.RedactedValue
+					}
+					continue
+				}
+				change.Current = redactSecrets(change.Current, secrets)
+				change.Desired = redactSecrets(change.Desired, secrets)
+			}
+		}
+	}
+}
+
+// isSecretPath checks if a changed property is, contains or is part of a property that uses a secret
+func isSecretPath(path string, secretPaths []string) bool {
+	for _, p := range secretPaths {
+		if path == p || strings.HasPrefix(p, path+".") || strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func redactComponent(component model.ComponentSpec, secrets []string) model.ComponentSpec {
+	if len(secrets) == 0 {
+		return component
+	}
+	var ret model.ComponentSpec
+	data, _ := json.Marshal(redactSecrets(toPropertyMap(&component), secrets))
+	if err := json.Unmarshal(data, &ret); err != nil {
+		return component
+	}
+	return ret
+}
+
+// redactSecrets replaces every occurrence of the secrets in the strings of a JSON value
+func redactSecrets(value interface{}, secrets []string) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, secret := range secrets {
+			v = strings.ReplaceAll(v, secret, model.RedactedValue)
+		}
+		return v
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			ret[k] = redactSecrets(item, secrets)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, item := range v {
+			ret[i] = redactSecrets(item, secrets)
+		}
+		return ret
+	}
+	return value
+}
+
+// diffComponents compares the JSON representations of two components property by property. A nil component has no
+// properties, so all properties of the other component are reported as added or removed.
+func diffComponents(current *model.ComponentSpec, desired *model.ComponentSpec) []model.PropertyChange {
+	return model.DiffValues("", toPropertyMap(current), toPropertyMap(desired))
+}
+
+func toPropertyMap(component *model.ComponentSpec) map[string]interface{} {
+	ret := make(map[string]interface{})
+	if component == nil {
+		return ret
+	}
+	data, err := json.Marshal(component)
+	if err != nil {
+		return ret
+	}
+	json.Unmarshal(data, &ret)
+	return ret
+}
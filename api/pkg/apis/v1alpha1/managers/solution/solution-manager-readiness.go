@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package solution
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	tgt "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/target"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+const (
+	DefaultReadinessTimeout  = 5 * time.Minute
+	DefaultReadinessInterval = 5 * time.Second
+)
+
+// waitForReadiness waits for every updated component of a step that declares a readiness section to become ready. The
+// readiness result of each component is stored in the component results. An error is returned for the first component
+// that doesn't become ready before its timeout, in which case the remaining components are not checked.
+func (s *SolutionManager) waitForReadiness(ctx context.Context, provider tgt.ITargetProvider, deployment model.DeploymentSpec, step model.DeploymentStep, componentResults map[string]model.ComponentResultSpec) (map[string]model.ComponentResultSpec, error) {
+	if componentResults == nil {
+		componentResults = make(map[string]model.ComponentResultSpec)
+	}
+	for _, component := range step.GetUpdatedComponents() {
+		if component.Readiness == nil {
+			continue
+		}
+		log.InfofCtx(ctx, " M (Solution): waiting for component %s on target %s to become ready", component.Name, step.Target)
+		result, err := waitForComponentReadiness(ctx, provider, deployment, component)
+		c, ok := componentResults[component.Name]
+		if !ok {
+			c = model.ComponentResultSpec{Status: v1alpha2.Updated}
+		}
+		c.Readiness = &result
+		if err != nil {
+			c.Status = v1alpha2.DeploymentNotReached
+			c.Message = err.Error()
+		}
+		componentResults[component.Name] = c
+		if err != nil {
+			log.ErrorfCtx(ctx, " M (Solution): component %s on target %s is not ready: %+v", component.Name, step.Target, err)
+			return componentResults, v1alpha2.NewCOAError(err, fmt.Sprintf("component %s on target %s is not ready", component.Name, step.Target), v1alpha2.DeploymentNotReached)
+		}
+	}
+	return componentResults, nil
+}
+
+// waitForComponentReadiness asks the target provider whether a component is ready until it is or the timeout expires
+func waitForComponentReadiness(ctx context.Context, provider tgt.ITargetProvider, deployment model.DeploymentSpec, component model.ComponentSpec) (model.ReadinessResultSpec, error) {
+	ret := model.ReadinessResultSpec{}
+	spec := *component.Readiness
+	if err := spec.Validate(); err != nil {
+		ret.Message = err.Error()
+		ret.Time = time.Now().UTC()
+		return ret, v1alpha2.NewCOAError(err, "invalid readiness", v1alpha2.BadConfig)
+	}
+	// durations are already validated above
+	timeout := DefaultReadinessTimeout
+	if spec.Timeout != "" {
+		timeout, _ = time.ParseDuration(spec.Timeout)
+	}
+	interval := DefaultReadinessInterval
+	if spec.Interval != "" {
+		interval, _ = time.ParseDuration(spec.Interval)
+	}
+
+	// readiness is checked where the component runs, so probes are never sent from the API server
+	readinessProvider, ok := provider.(tgt.IReadinessProvider)
+	if !ok {
+		ret.Message = "the target provider doesn't support readiness checks"
+		ret.Time = time.Now().UTC()
+		return ret, v1alpha2.NewCOAError(nil, ret.Message, v1alpha2.BadConfig)
+	}
+	probe := func(ctx context.Context) (bool, string, error) {
+		ready, message, err := readinessProvider.CheckReadiness(ctx, deployment, component)
+		if err != nil {
+			return false, err.Error(), err
+		}
+		return ready, message, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		ret.Probes++
+		ready, message, err := probe(ctx)
+		ret.Ready = ready
+		ret.Message = message
+		ret.Time = time.Now().UTC()
+		if ready {
+			return ret, nil
+		}
+		// a probe that the target provider doesn't support never succeeds
+		if v1alpha2.IsBadConfig(err) {
+			return ret, err
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ret, fmt.Errorf("not ready after %s: %s", timeout, message)
+		}
+	}
+}
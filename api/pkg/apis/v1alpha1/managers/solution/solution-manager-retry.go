@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package solution
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+const (
+	DefaultInitialBackoff = 5 * time.Second
+	DefaultMaxBackoff     = 5 * time.Minute
+)
+
+// retryPolicy is the parsed form of a model.RetryPolicySpec
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	attemptTimeout time.Duration
+	terminalStates []v1alpha2.State
+}
+
+// getRetryPolicyForStep returns the retry policy of the step's target if it has one, or the retry policy of the
+// instance otherwise. Without any policy a step is attempted once.
+func getRetryPolicyForStep(deployment model.DeploymentSpec, target string) (retryPolicy, error) {
+	var spec *model.RetryPolicySpec
+	if t, ok := deployment.Targets[target]; ok && t.Spec != nil && t.Spec.RetryPolicy != nil {
+		spec = t.Spec.RetryPolicy
+	} else if deployment.Instance.Spec != nil {
+		spec = deployment.Instance.Spec.RetryPolicy
+	}
+	return newRetryPolicy(spec)
+}
+
+func newRetryPolicy(spec *model.RetryPolicySpec) (retryPolicy, error) {
+	ret := retryPolicy{
+		maxAttempts:    1,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+	}
+	if spec == nil {
+		return ret, nil
+	}
+	if err := spec.Validate(); err != nil {
+		return ret, v1alpha2.NewCOAError(err, "invalid retry policy", v1alpha2.BadConfig)
+	}
+	if spec.MaxAttempts > 0 {
+		ret.maxAttempts = spec.MaxAttempts
+	}
+	// durations are already validated above
+	if spec.InitialBackoff != "" {
+		ret.initialBackoff, _ = time.ParseDuration(spec.InitialBackoff)
+	}
+	if spec.MaxBackoff != "" {
+		ret.maxBackoff, _ = time.ParseDuration(spec.MaxBackoff)
+	}
+	if spec.AttemptTimeout != "" {
+		ret.attemptTimeout, _ = time.ParseDuration(spec.AttemptTimeout)
+	}
+	ret.terminalStates = spec.TerminalStates
+	return ret, nil
+}
+
+// backoff returns the delay before the given retry (1 for the first retry). The delay grows exponentially from the
+// initial backoff up to the max backoff, and a random jitter of up to half the delay is subtracted so that steps
+// failing at the same time don't retry in lockstep.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	delay := p.initialBackoff
+	for i := 1; i < retry && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// isTerminal reports whether an error must not be retried. A policy that lists terminal states decides which errors are
+// terminal, otherwise errors that can't be resolved by retrying are
+func (p retryPolicy) isTerminal(err error) bool {
+	if len(p.terminalStates) == 0 {
+		return !v1alpha2.IsRetriableErr(err)
+	}
+	state := getErrorState(err)
+	for _, s := range p.terminalStates {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+func getErrorState(err error) v1alpha2.State {
+	if coaE, ok := err.(v1alpha2.COAError); ok {
+		return coaE.State
+	}
+	return v1alpha2.InternalError
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package solution
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	api_utils "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+)
+
+const (
+	RollbackStatusOK     = "Rolled Back"
+	RollbackStatusFailed = "Rollback Failed"
+)
+
+// rollback restores the targets touched by a failed reconcile to the last successfully deployed state. The last-good
+// state is the deployment state persisted by the previous successful reconcile. Components that were added by the
+// failed deployment are removed, and components that existed before are re-applied with their last-good specs. When
+// there is no persisted state, all components of the failed deployment are removed from the touched targets.
+func (s *SolutionManager) rollback(ctx context.Context, deployment model.DeploymentSpec, previousDesiredState *SolutionManagerDeploymentState, touchedTargets map[string]bool, summary *model.SummarySpec) error {
+	log.InfofCtx(ctx, " M (Solution): rolling back deployment.InstanceName: %s on %d targets", deployment.Instance.ObjectMeta.Name, len(touchedTargets))
+
+	plan, lastGood, err := planForRollback(deployment, previousDesiredState, touchedTargets)
+	if err != nil {
+		log.ErrorfCtx(ctx, " M (Solution): failed to plan for rollback: %+v", err)
+		summary.SummaryMessage = appendSummaryMessage(summary.SummaryMessage, "failed to plan for rollback: "+err.Error())
+		return err
+	}
+
+	// the failed deployment is used to look up targets that are not part of the last-good deployment
+	failedDeploymentState := &SolutionManagerDeploymentState{Spec: deployment}
+	col := api_utils.MergeCollection(lastGood.Solution.Spec.Metadata, lastGood.Instance.Spec.Metadata)
+	rollbackState, err := NewDeploymentState(lastGood)
+	if err != nil {
+		log.ErrorfCtx(ctx, " M (Solution): failed to create rollback state: %+v", err)
+		return err
+	}
+
+	summary.RollbackResults = make(map[string]model.TargetResultSpec)
+	var resultLock sync.Mutex
+	executor := newStepExecutor(plan.Steps, s.MaxParallelSteps)
+	err = executor.run(ctx, func(index int, step model.DeploymentStep) error {
+		dep := s.getDeploymentForStep(lastGood, col, rollbackState, step.Target)
+		provider, err := s.getProviderForStep(step, lastGood, failedDeploymentState)
+		if err == nil {
+			var componentResults map[string]model.ComponentResultSpec
+			componentResults, err = provider.Apply(ctx, dep, step, false)
+			resultLock.Lock()
+			defer resultLock.Unlock()
+			if err == nil {
+				updateRollbackResult(summary, step.Target, model.TargetResultSpec{Status: RollbackStatusOK, ComponentResults: componentResults})
+				return nil
+			}
+			updateRollbackResult(summary, step.Target, model.TargetResultSpec{Status: RollbackStatusFailed, Message: err.Error(), ComponentResults: componentResults})
+		} else {
+			resultLock.Lock()
+			defer resultLock.Unlock()
+			updateRollbackResult(summary, step.Target, model.TargetResultSpec{Status: RollbackStatusFailed, Message: "failed to create provider: " + err.Error()})
+		}
+		log.ErrorfCtx(ctx, " M (Solution): failed to roll back step with role %s on target %s: %+v", step.Role, step.Target, err)
+		return err
+	})
+	if err != nil {
+		summary.SummaryMessage = appendSummaryMessage(summary.SummaryMessage, "failed to roll back to the last successful deployment: "+err.Error())
+		return err
+	}
+
+	summary.RolledBack = true
+	summary.SummaryMessage = appendSummaryMessage(summary.SummaryMessage, "rolled back to the last successful deployment")
+	log.InfofCtx(ctx, " M (Solution): rolled back deployment.InstanceName: %s", deployment.Instance.ObjectMeta.Name)
+	return nil
+}
+
+// planForRollback computes the reverse plan of a failed deployment, limited to the touched targets. It returns the plan
+// together with the last-good deployment spec the plan should be applied with.
+func planForRollback(deployment model.DeploymentSpec, previousDesiredState *SolutionManagerDeploymentState, touchedTargets map[string]bool) (model.DeploymentPlan, model.DeploymentSpec, error) {
+	failedState, err := NewDeploymentState(deployment)
+	if err != nil {
+		return model.DeploymentPlan{}, deployment, err
+	}
+
+	lastGood := deployment
+	var rollbackState model.DeploymentState
+	if previousDesiredState != nil {
+		lastGood = previousDesiredState.Spec
+		// components that only exist in the failed deployment are marked for removal
+		rollbackState = MergeDeploymentStates(&failedState, copyDeploymentState(previousDesiredState.State))
+	} else {
+		rollbackState = failedState
+		rollbackState.MarkRemoveAll()
+	}
+
+	plan, err := PlanForDeployment(lastGood, rollbackState)
+	if err != nil {
+		return model.DeploymentPlan{}, lastGood, err
+	}
+	ret := model.DeploymentPlan{
+		Steps: make([]model.DeploymentStep, 0),
+	}
+	for _, step := range plan.Steps {
+		if touchedTargets[step.Target] {
+			ret.Steps = append(ret.Steps, step)
+		}
+	}
+	return ret, lastGood, nil
+}
+
+func copyDeploymentState(state model.DeploymentState) model.DeploymentState {
+	ret := model.DeploymentState{
+		Components:      make([]model.ComponentSpec, len(state.Components)),
+		Targets:         make([]model.TargetDesc, len(state.Targets)),
+		TargetComponent: make(map[string]string, len(state.TargetComponent)),
+	}
+	copy(ret.Components, state.Components)
+	copy(ret.Targets, state.Targets)
+	for k, v := range state.TargetComponent {
+		ret.TargetComponent[k] = v
+	}
+	return ret
+}
+
+func updateRollbackResult(summary *model.SummarySpec, target string, spec model.TargetResultSpec) {
+	v, ok := summary.RollbackResults[target]
+	if !ok {
+		summary.RollbackResults[target] = spec
+		return
+	}
+	if spec.Status == RollbackStatusFailed {
+		v.Status = spec.Status
+	}
+	v.Message = appendSummaryMessage(v.Message, spec.Message)
+	if v.ComponentResults == nil {
+		v.ComponentResults = make(map[string]model.ComponentResultSpec)
+	}
+	for k, c := range spec.ComponentResults {
+		v.ComponentResults[k] = c
+	}
+	summary.RollbackResults[target] = v
+}
+
+func appendSummaryMessage(message string, addition string) string {
+	if message == "" {
+		return addition
+	}
+	if addition == "" {
+		return message
+	}
+	return fmt.Sprintf("%s; %s", message, addition)
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package solution
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+// rolloutWave is a group of deployment steps whose targets are deployed together
+type rolloutWave struct {
+	name    string
+	targets []string
+	steps   []model.DeploymentStep
+}
+
+// planRolloutWaves splits the steps into waves according to the rollout spec. Targets are assigned to waves in name
+// order, and targets that are not covered by any wave are deployed in a final wave. A target that a step of an earlier
+// wave depends on is deployed in that earlier wave. Without a rollout spec, all steps are deployed in a single wave.
+func planRolloutWaves(rollout *model.RolloutSpec, steps []model.DeploymentStep) ([]rolloutWave, error) {
+	targets := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, step := range steps {
+		if !seen[step.Target] {
+			seen[step.Target] = true
+			targets = append(targets, step.Target)
+		}
+	}
+	sort.Strings(targets)
+
+	if rollout == nil || len(rollout.Waves) == 0 {
+		return []rolloutWave{{name: "all", targets: targets, steps: steps}}, nil
+	}
+	if err := rollout.Validate(); err != nil {
+		return nil, v1alpha2.NewCOAError(err, "invalid rollout", v1alpha2.BadConfig)
+	}
+
+	waves := make([]rolloutWave, 0)
+	waveOfTarget := make(map[string]int)
+	next := 0
+	for i, w := range rollout.Waves {
+		size := getWaveSize(w, len(targets))
+		if next+size > len(targets) {
+			size = len(targets) - next
+		}
+		name := w.Name
+		if name == "" {
+			name = fmt.Sprintf("wave-%d", i+1)
+		}
+		wave := rolloutWave{name: name, targets: targets[next : next+size], steps: make([]model.DeploymentStep, 0)}
+		for _, t := range wave.targets {
+			waveOfTarget[t] = len(waves)
+		}
+		waves = append(waves, wave)
+		next += size
+	}
+	if next < len(targets) {
+		wave := rolloutWave{name: fmt.Sprintf("wave-%d", len(waves)+1), targets: targets[next:], steps: make([]model.DeploymentStep, 0)}
+		for _, t := range wave.targets {
+			waveOfTarget[t] = len(waves)
+		}
+		waves = append(waves, wave)
+	}
+	// a wave only waits for the steps in its own and earlier waves, so the targets of the predecessors of a step are
+	// moved up to the wave of the step. Moving a target up can move its own predecessors up, until no target moves.
+	for moved := true; moved; {
+		moved = false
+		for _, step := range steps {
+			for _, ref := range step.Predecessors {
+				if i, ok := waveOfTarget[ref.Target]; ok && i > waveOfTarget[step.Target] {
+					waveOfTarget[ref.Target] = waveOfTarget[step.Target]
+					moved = true
+				}
+			}
+		}
+	}
+	for i := range waves {
+		waves[i].targets = make([]string, 0)
+	}
+	for _, t := range targets {
+		i := waveOfTarget[t]
+		waves[i].targets = append(waves[i].targets, t)
+	}
+	for _, step := range steps {
+		i := waveOfTarget[step.Target]
+		waves[i].steps = append(waves[i].steps, step)
+	}
+
+	// waves that ended up without targets are dropped
+	ret := make([]rolloutWave, 0)
+	for _, w := range waves {
+		if len(w.targets) > 0 {
+			ret = append(ret, w)
+		}
+	}
+	return ret, nil
+}
+
+// getWaveSize returns the number of targets in a validated wave
+func getWaveSize(wave model.RolloutWaveSpec, totalTargets int) int {
+	if wave.Targets > 0 {
+		return wave.Targets
+	}
+	return int(math.Ceil(float64(totalTargets) * float64(wave.Percentage) / 100))
+}
+
+func getPauseBetweenWaves(rollout *model.RolloutSpec) time.Duration {
+	if rollout == nil || rollout.PauseBetweenWaves == "" {
+		return 0
+	}
+	// the rollout is validated when the waves are planned
+	pause, _ := time.ParseDuration(rollout.PauseBetweenWaves)
+	return pause
+}
+
+// checkWaveHealth is the gate between two waves. Every component result reported for the targets of the wave must be
+// successful, and every updated component must be reported by the target provider.
+func (s *SolutionManager) checkWaveHealth(ctx context.Context, deployment model.DeploymentSpec, wave rolloutWave, summary model.SummarySpec, previousDesiredState *SolutionManagerDeploymentState) error {
+	for _, t := range wave.targets {
+		if result, ok := summary.TargetResults[t]; ok {
+			for name, c := range result.ComponentResults {
+				if !isHealthyComponentState(c.Status) {
+					return v1alpha2.NewCOAError(nil, fmt.Sprintf("component %s on target %s reported %s: %s", name, t, c.Status, c.Message), v1alpha2.DeploymentNotReached)
+				}
+			}
+		}
+	}
+	for _, step := range wave.steps {
+		updated := step.GetUpdatedComponentSteps()
+		if len(updated) == 0 {
+			continue
+		}
+		provider, err := s.getProviderForStep(step, deployment, previousDesiredState)
+		if err != nil {
+			return err
+		}
+		dep := deployment
+		dep.ActiveTarget = step.Target
+		components, err := provider.Get(ctx, dep, updated)
+		if err != nil {
+			return v1alpha2.NewCOAError(err, fmt.Sprintf("failed to get components on target %s", step.Target), v1alpha2.DeploymentNotReached)
+		}
+		for _, u := range updated {
+			found := false
+			for _, c := range components {
+				if c.Name == u.Component.Name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return v1alpha2.NewCOAError(nil, fmt.Sprintf("component %s is not found on target %s", u.Component.Name, step.Target), v1alpha2.DeploymentNotReached)
+			}
+		}
+	}
+	return nil
+}
+
+func isHealthyComponentState(state v1alpha2.State) bool {
+	switch state {
+	case v1alpha2.OK, v1alpha2.Accepted, v1alpha2.Updated, v1alpha2.Deleted, v1alpha2.Untouched:
+		return true
+	default:
+		return false
+	}
+}
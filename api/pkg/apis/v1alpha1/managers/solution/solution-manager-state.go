@@ -52,7 +52,7 @@ func PlanForDeployment(deployment model.DeploymentSpec, state model.DeploymentSt
 			}
 		}
 	}
-	return ret.RevisedForDeletion(), nil
+	return ret.RevisedForDeletion().LinkPredecessors(), nil
 }
 
 func NewDeploymentState(deployment model.DeploymentSpec) (model.DeploymentState, error) {
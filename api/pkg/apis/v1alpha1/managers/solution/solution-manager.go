@@ -36,7 +36,6 @@ import (
 
 var (
 	log                 = logger.NewLogger("coa.runtime")
-	lock                sync.Mutex
 	apiOperationMetrics *metrics.Metrics
 )
 
@@ -53,6 +52,10 @@ const (
 
 	Summary         = "Summary"
 	DeploymentState = "DeployState"
+
+	// DefaultMaxParallelSteps is the number of deployment steps that can run at the same time when
+	// maxParallelSteps is not configured, so steps run one after another unless parallelism is opted in.
+	DefaultMaxParallelSteps = 1
 )
 
 type SolutionManager struct {
@@ -64,6 +67,17 @@ type SolutionManager struct {
 	IsTarget        bool
 	TargetNames     []string
 	ApiClientHttp   api_utils.ApiClient
+	// MaxParallelSteps limits how many independent deployment steps of a single instance run at the same time
+	MaxParallelSteps int
+	// instanceLocks holds a lock per instance so that reconciles of the same instance are serialized
+	// while different instances are reconciled independently. A lock is removed when its last holder releases it.
+	instanceLocks     map[string]*instanceLock
+	instanceLocksLock sync.Mutex
+}
+
+type instanceLock struct {
+	sync.Mutex
+	holders int
 }
 
 type SolutionManagerDeploymentState struct {
@@ -111,6 +125,15 @@ func (s *SolutionManager) Init(context *contexts.VendorContext, config managers.
 		}
 	}
 
+	s.MaxParallelSteps = DefaultMaxParallelSteps
+	if v, ok := config.Properties["maxParallelSteps"]; ok {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 1 {
+			return v1alpha2.NewCOAError(nil, "maxParallelSteps must be a positive integer", v1alpha2.BadConfig)
+		}
+		s.MaxParallelSteps = i
+	}
+
 	targetNames := ""
 
 	if v, ok := config.Properties["targetNames"]; ok {
@@ -142,6 +165,32 @@ func (s *SolutionManager) Init(context *contexts.VendorContext, config managers.
 	return nil
 }
 
+func (s *SolutionManager) lockInstance(namespace string, instance string) func() {
+	key := fmt.Sprintf("%s/%s", namespace, instance)
+	s.instanceLocksLock.Lock()
+	if s.instanceLocks == nil {
+		s.instanceLocks = make(map[string]*instanceLock)
+	}
+	l, ok := s.instanceLocks[key]
+	if !ok {
+		l = &instanceLock{}
+		s.instanceLocks[key] = l
+	}
+	l.holders++
+	s.instanceLocksLock.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		s.instanceLocksLock.Lock()
+		defer s.instanceLocksLock.Unlock()
+		l.holders--
+		if l.holders == 0 {
+			delete(s.instanceLocks, key)
+		}
+	}
+}
+
 func (s *SolutionManager) getPreviousState(ctx context.Context, instance string, namespace string) *SolutionManagerDeploymentState {
 	state, err := s.StateProvider.Get(ctx, states.GetRequest{
 		ID: instance,
@@ -285,8 +334,8 @@ func (s *SolutionManager) cleanupHeartbeat(ctx context.Context, id string, names
 }
 
 func (s *SolutionManager) Reconcile(ctx context.Context, deployment model.DeploymentSpec, remove bool, namespace string, targetName string) (model.SummarySpec, error) {
-	lock.Lock()
-	defer lock.Unlock()
+	unlock := s.lockInstance(namespace, deployment.Instance.ObjectMeta.Name)
+	defer unlock()
 
 	ctx, span := observability.StartSpan("Solution Manager", ctx, &map[string]string{
 		"method": "Reconcile",
@@ -400,8 +449,6 @@ func (s *SolutionManager) Reconcile(ctx context.Context, deployment model.Deploy
 	}
 
 	col := api_utils.MergeCollection(deployment.Solution.Spec.Metadata, deployment.Instance.Spec.Metadata)
-	dep := deployment
-	dep.Instance.Spec.Metadata = col
 	someStepsRan := false
 
 	targetResult := make(map[string]int)
@@ -418,8 +465,7 @@ func (s *SolutionManager) Reconcile(ctx context.Context, deployment model.Deploy
 	}
 	log.DebugfCtx(ctx, " M (Solution): reconcile save summary progress: start deploy, total %v deployments", summary.PlannedDeployment)
 
-	plannedCount := 0
-	planSuccessCount := 0
+	steps := make([]model.DeploymentStep, 0)
 	for _, step := range plan.Steps {
 		log.DebugfCtx(ctx, " M (Solution): processing step with Role %s on target %s", step.Role, step.Target)
 		for _, component := range step.Components {
@@ -432,116 +478,213 @@ func (s *SolutionManager) Reconcile(ctx context.Context, deployment model.Deploy
 		if targetName != "" && targetName != step.Target {
 			continue
 		}
+		steps = append(steps, step)
+	}
 
-		plannedCount++
+	// summaryLock guards the summary and the counters below, which are updated by steps running concurrently
+	var summaryLock sync.Mutex
+	plannedCount := 0
+	planSuccessCount := 0
+	touchedTargets := make(map[string]bool)
+	// nodeStates records the outcome of every (component, target) node, which is checked before dependents run
+	nodeStates := make(map[string]v1alpha2.State)
 
-		dep.ActiveTarget = step.Target
-		agent := findAgentFromDeploymentState(mergedState, step.Target)
-		if agent != "" {
-			col[ENV_NAME] = agent
-		} else {
-			delete(col, ENV_NAME)
+	executeStep := func(index int, step model.DeploymentStep) error {
+		summaryLock.Lock()
+		plannedCount++
+		reason := failedPredecessor(step, nodeStates)
+		if reason != "" {
+			skipDependentStep(&summary, step, deploymentType, reason, nodeStates)
 		}
-		var override tgt.ITargetProvider
-		role := step.Role
-		if role == "container" {
-			role = "instance"
+		summaryLock.Unlock()
+		if reason != "" {
+			log.ErrorfCtx(ctx, " M (Solution): skipping step with role %s on target %s: %s", step.Role, step.Target, reason)
+			return v1alpha2.NewCOAError(nil, reason, v1alpha2.DeploymentNotReached)
 		}
-		if v, ok := s.TargetProviders[role]; ok {
-			override = v
-		}
-		var provider providers.IProvider
-		if override == nil {
-			targetSpec := s.getTargetStateForStep(step, deployment, previousDesiredState)
-			provider, err = sp.CreateProviderForTargetRole(s.Context, step.Role, targetSpec, override)
-			if err != nil {
-				summary.SummaryMessage = "failed to create provider:" + err.Error()
-				log.ErrorfCtx(ctx, " M (Solution): failed to create provider: %+v", err)
-				return summary, err
-			}
-		} else {
-			provider = override
+
+		dep := s.getDeploymentForStep(deployment, col, mergedState, step.Target)
+		provider, providerErr := s.getProviderForStep(step, deployment, previousDesiredState)
+		if providerErr != nil {
+			summaryLock.Lock()
+			summary.SummaryMessage = "failed to create provider:" + providerErr.Error()
+			summaryLock.Unlock()
+			log.ErrorfCtx(ctx, " M (Solution): failed to create provider: %+v", providerErr)
+			return providerErr
 		}
 
 		if previousDesiredState != nil {
 			testState := MergeDeploymentStates(&previousDesiredState.State, currentState)
-			if s.canSkipStep(ctx, step, step.Target, provider.(tgt.ITargetProvider), previousDesiredState.State.Components, testState) {
+			if s.canSkipStep(ctx, step, step.Target, provider, previousDesiredState.State.Components, testState) {
 				log.InfofCtx(ctx, " M (Solution): skipping step with role %s on target %s", step.Role, step.Target)
+				summaryLock.Lock()
 				targetResult[step.Target] = 1
 				planSuccessCount++
-				continue
+				recordNodeStates(step, step.PrepareResultMap(), nil, nodeStates)
+				summaryLock.Unlock()
+				return nil
 			}
 		}
 		log.DebugfCtx(ctx, " M (Solution): applying step with Role %s on target %s", step.Role, step.Target)
+		summaryLock.Lock()
 		someStepsRan = true
-		retryCount := 1
-		//TODO: set to 1 for now. Although retrying can help to handle transient errors, in more cases
-		// an error condition can't be resolved quickly.
-		var stepError error
+		touchedTargets[step.Target] = true
+		summaryLock.Unlock()
 		var componentResults map[string]model.ComponentResultSpec
-
-		// for _, component := range step.Components {
-		// 	for k, v := range component.Component.Properties {
-		// 		if strV, ok := v.(string); ok {
-		// 			parser := api_utils.NewParser(strV)
-		// 			eCtx := s.VendorContext.EvaluationContext.Clone()
-		// 			eCtx.DeploymentSpec = deployment
-		// 			eCtx.Component = component.Component.Name
-		// 			val, err := parser.Eval(*eCtx)
-		// 			if err == nil {
-		// 				component.Component.Properties[k] = val
-		// 			} else {
-		// 				log.ErrorfCtx(ctx, " M (Solution): failed to evaluate property: %+v", err)
-		// 				summary.SummaryMessage = fmt.Sprintf("failed to evaluate property '%s' on component '%s: %s", k, component.Component.Name, err.Error())
-		// 				s.saveSummary(ctx, deployment, summary)
-		// 				return summary, err
-		// 			}
-		// 		}
-		// 	}
-		// }
-
-		for i := 0; i < retryCount; i++ {
-			componentResults, stepError = (provider.(tgt.ITargetProvider)).Apply(ctx, dep, step, deployment.IsDryRun)
-			if stepError == nil {
-				targetResult[step.Target] = 1
-				summary.AllAssignedDeployed = plannedCount == planSuccessCount
-				summary.UpdateTargetResult(step.Target, model.TargetResultSpec{Status: "OK", Message: "", ComponentResults: componentResults})
-				err = s.saveSummaryProgress(ctx, deployment.Instance.ObjectMeta.Name, deployment.Generation, deployment.Hash, summary, namespace)
-				if err != nil {
-					log.ErrorfCtx(ctx, " M (Solution): failed to save summary progress: %+v", err)
-					return summary, err
+		attempts := make([]model.AttemptResultSpec, 0)
+		policy, stepError := getRetryPolicyForStep(deployment, step.Target)
+		if stepError == nil {
+		attemptLoop:
+			for attempt := 1; attempt <= policy.maxAttempts; attempt++ {
+				attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+				if policy.attemptTimeout > 0 {
+					attemptCtx, cancel = context.WithTimeout(ctx, policy.attemptTimeout)
+				}
+				componentResults, stepError = provider.Apply(attemptCtx, dep, step, deployment.IsDryRun)
+				cancel()
+				if stepError == nil {
+					attempts = append(attempts, model.AttemptResultSpec{Attempt: attempt, Status: v1alpha2.OK, Time: time.Now().UTC()})
+					break
+				}
+				attempts = append(attempts, model.AttemptResultSpec{Attempt: attempt, Status: getErrorState(stepError), Message: stepError.Error(), Time: time.Now().UTC()})
+				if attempt == policy.maxAttempts || policy.isTerminal(stepError) {
+					break
+				}
+				backoff := policy.backoff(attempt)
+				log.InfofCtx(ctx, " M (Solution): attempt %d of step with role %s on target %s failed, retrying in %s: %+v", attempt, step.Role, step.Target, backoff, stepError)
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+					break attemptLoop
 				}
-				break
-			} else {
-				targetResult[step.Target] = 0
-				summary.AllAssignedDeployed = false
-				targetResultStatus := fmt.Sprintf("%s Failed", deploymentType)
-				targetResultMessage := fmt.Sprintf("An error occurred in %s, err: %s", deploymentType, stepError.Error())
-				summary.UpdateTargetResult(step.Target, model.TargetResultSpec{Status: targetResultStatus, Message: targetResultMessage, ComponentResults: componentResults}) // TODO: this keeps only the last error on the target
-				time.Sleep(5 * time.Second)                                                                                                                                   //TODO: make this configurable?
 			}
 		}
-		if stepError != nil {
-			log.ErrorfCtx(ctx, " M (Solution): failed to execute deployment step: %+v", stepError)
 
-			successCount := 0
-			for _, v := range targetResult {
-				successCount += v
-			}
-			summary.CurrentDeployed += successCount
-			summary.SuccessCount = successCount
+		// dependents only run once the components they depend on are ready
+		if stepError == nil && !deployment.IsDryRun {
+			componentResults, stepError = s.waitForReadiness(ctx, provider, dep, step, componentResults)
+		}
+
+		summaryLock.Lock()
+		recordNodeStates(step, componentResults, stepError, nodeStates)
+		if stepError == nil {
+			targetResult[step.Target] = 1
 			summary.AllAssignedDeployed = plannedCount == planSuccessCount
-			err = stepError
-			return summary, err
+			summary.UpdateTargetResult(step.Target, model.TargetResultSpec{Status: "OK", Message: "", ComponentResults: componentResults, Attempts: attempts})
+			saveErr := s.saveSummaryProgress(ctx, deployment.Instance.ObjectMeta.Name, deployment.Generation, deployment.Hash, summary, namespace)
+			summaryLock.Unlock()
+			if saveErr != nil {
+				log.ErrorfCtx(ctx, " M (Solution): failed to save summary progress: %+v", saveErr)
+				return saveErr
+			}
+		} else {
+			targetResult[step.Target] = 0
+			summary.AllAssignedDeployed = false
+			targetResultStatus := fmt.Sprintf("%s Failed", deploymentType)
+			targetResultMessage := fmt.Sprintf("An error occurred in %s, err: %s", deploymentType, stepError.Error())
+			summary.UpdateTargetResult(step.Target, model.TargetResultSpec{Status: targetResultStatus, Message: targetResultMessage, ComponentResults: componentResults, Attempts: attempts})
+			summaryLock.Unlock()
 		}
+		if stepError != nil {
+			log.ErrorfCtx(ctx, " M (Solution): failed to execute deployment step: %+v", stepError)
+			return stepError
+		}
+		summaryLock.Lock()
+		defer summaryLock.Unlock()
 		planSuccessCount++
 		summary.CurrentDeployed += len(step.Components)
-		err = s.saveSummaryProgress(ctx, deployment.Instance.ObjectMeta.Name, deployment.Generation, deployment.Hash, summary, namespace)
-		if err != nil {
-			log.ErrorfCtx(ctx, " M (Solution): failed to save summary progress: %+v", err)
-			return summary, err
+		saveErr := s.saveSummaryProgress(ctx, deployment.Instance.ObjectMeta.Name, deployment.Generation, deployment.Hash, summary, namespace)
+		if saveErr != nil {
+			log.ErrorfCtx(ctx, " M (Solution): failed to save summary progress: %+v", saveErr)
+			return saveErr
 		}
 		log.DebugfCtx(ctx, " M (Solution): reconcile save summary progress: current deployed %v out of total %v deployments", summary.PlannedDeployment, summary.CurrentDeployed)
+		return nil
+	}
+
+	// removals are never rolled out in waves
+	var rollout *model.RolloutSpec
+	if !remove {
+		rollout = deployment.Instance.Spec.Rollout
+	}
+	var waves []rolloutWave
+	waves, err = planRolloutWaves(rollout, steps)
+	if err != nil {
+		summary.SummaryMessage = "failed to plan rollout waves: " + err.Error()
+		log.ErrorfCtx(ctx, " M (Solution): failed to plan rollout waves: %+v", err)
+		return summary, err
+	}
+	if len(waves) > 1 {
+		for _, wave := range waves {
+			summary.Waves = append(summary.Waves, model.WaveResultSpec{Name: wave.name, Targets: wave.targets, Status: model.WaveStatePending})
+		}
+	}
+	pause := getPauseBetweenWaves(rollout)
+	updateWave := func(index int, status model.WaveState, message string) {
+		if index < len(summary.Waves) {
+			summary.Waves[index].Status = status
+			summary.Waves[index].Message = message
+		}
+	}
+
+	for i, wave := range waves {
+		if len(waves) > 1 {
+			log.InfofCtx(ctx, " M (Solution): rolling out wave %s to targets %v", wave.name, wave.targets)
+		}
+		updateWave(i, model.WaveStateRunning, "")
+		err = newStepExecutor(wave.steps, s.MaxParallelSteps).run(ctx, executeStep)
+		if err == nil && i < len(waves)-1 && !deployment.IsDryRun {
+			err = s.checkWaveHealth(ctx, deployment, wave, summary, previousDesiredState)
+			if err != nil {
+				log.ErrorfCtx(ctx, " M (Solution): wave %s failed the health gate: %+v", wave.name, err)
+				summary.AllAssignedDeployed = false
+				summary.SummaryMessage = fmt.Sprintf("rollout stopped: wave %s failed the health gate: %s", wave.name, err.Error())
+			}
+		}
+		if err != nil {
+			updateWave(i, model.WaveStateFailed, err.Error())
+			break
+		}
+		updateWave(i, model.WaveStateSucceeded, "")
+		if i < len(waves)-1 {
+			err = s.saveSummaryProgress(ctx, deployment.Instance.ObjectMeta.Name, deployment.Generation, deployment.Hash, summary, namespace)
+			if err != nil {
+				log.ErrorfCtx(ctx, " M (Solution): failed to save summary progress: %+v", err)
+				return summary, err
+			}
+			if pause > 0 {
+				log.InfofCtx(ctx, " M (Solution): wave %s passed the health gate, pausing for %s", wave.name, pause)
+				select {
+				case <-time.After(pause):
+				case <-ctx.Done():
+					err = ctx.Err()
+				}
+				if err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		// steps that never ran because a prerequisite failed are reported as skipped
+		for _, step := range steps {
+			if !hasNodeStates(step, nodeStates) {
+				if reason := failedPredecessor(step, nodeStates); reason != "" {
+					skipDependentStep(&summary, step, deploymentType, reason, nodeStates)
+				}
+			}
+		}
+		successCount := 0
+		for _, v := range targetResult {
+			successCount += v
+		}
+		summary.CurrentDeployed += successCount
+		summary.SuccessCount = successCount
+		summary.AllAssignedDeployed = plannedCount == planSuccessCount
+
+		if !remove && !deployment.IsDryRun && deployment.Instance.Spec.RollbackPolicy.IsEnabled() && len(touchedTargets) > 0 {
+			s.rollback(ctx, deployment, previousDesiredState, touchedTargets, &summary)
+		}
+		return summary, err
 	}
 
 	mergedState.ClearAllRemoved()
@@ -595,6 +738,43 @@ func (s *SolutionManager) Reconcile(ctx context.Context, deployment model.Deploy
 	return summary, nil
 }
 
+// getDeploymentForStep returns a copy of the deployment for a single step. Each step gets its own copy, as the
+// active target and the agent address differ between steps that run concurrently.
+func (s *SolutionManager) getDeploymentForStep(deployment model.DeploymentSpec, col map[string]string, state model.DeploymentState, target string) model.DeploymentSpec {
+	stepCol := make(map[string]string, len(col))
+	for k, v := range col {
+		stepCol[k] = v
+	}
+	agent := findAgentFromDeploymentState(state, target)
+	if agent != "" {
+		stepCol[ENV_NAME] = agent
+	} else {
+		delete(stepCol, ENV_NAME)
+	}
+	dep := deployment
+	instanceSpec := *deployment.Instance.Spec
+	instanceSpec.Metadata = stepCol
+	dep.Instance.Spec = &instanceSpec
+	dep.ActiveTarget = target
+	return dep
+}
+
+func (s *SolutionManager) getProviderForStep(step model.DeploymentStep, deployment model.DeploymentSpec, previousDeploymentState *SolutionManagerDeploymentState) (tgt.ITargetProvider, error) {
+	role := step.Role
+	if role == "container" {
+		role = "instance"
+	}
+	if v, ok := s.TargetProviders[role]; ok {
+		return v, nil
+	}
+	targetSpec := s.getTargetStateForStep(step, deployment, previousDeploymentState)
+	provider, err := sp.CreateProviderForTargetRole(s.Context, step.Role, targetSpec, nil)
+	if err != nil {
+		return nil, err
+	}
+	return provider.(tgt.ITargetProvider), nil
+}
+
 // The deployment spec may have changed, so the previous target is not in the new deployment anymore
 func (s *SolutionManager) getTargetStateForStep(step model.DeploymentStep, deployment model.DeploymentSpec, previousDeploymentState *SolutionManagerDeploymentState) model.TargetState {
 	//first find the target spec in the deployment
@@ -130,8 +130,14 @@ func (t *SolutionsManager) UpsertState(ctx context.Context, name string, state m
 }
 
 func (t *SolutionsManager) ListState(ctx context.Context, namespace string) ([]model.SolutionState, error) {
+	ret, _, err := t.ListStatePage(ctx, namespace, states.PageOptions{})
+	return ret, err
+}
+
+// ListStatePage lists a page of solutions sorted by the page options, and returns the continuation token of the next page
+func (t *SolutionsManager) ListStatePage(ctx context.Context, namespace string, page states.PageOptions) ([]model.SolutionState, string, error) {
 	ctx, span := observability.StartSpan("Solutions Manager", ctx, &map[string]string{
-		"method": "ListSpec",
+		"method": "ListStatePage",
 	})
 	var err error = nil
 	defer observ_utils.CloseSpanWithError(span, &err)
@@ -145,22 +151,24 @@ func (t *SolutionsManager) ListState(ctx context.Context, namespace string) ([]m
 			"namespace": namespace,
 			"kind":      "Solution",
 		},
+		PageOptions: page,
 	}
 	var solutions []states.StateEntry
-	solutions, _, err = t.StateProvider.List(ctx, listRequest)
+	var token string
+	solutions, token, err = t.StateProvider.List(ctx, listRequest)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	ret := make([]model.SolutionState, 0)
 	for _, t := range solutions {
 		var rt model.SolutionState
 		rt, err = getSolutionState(t.Body)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		ret = append(ret, rt)
 	}
-	return ret, nil
+	return ret, token, nil
 }
 
 func getSolutionState(body interface{}) (model.SolutionState, error) {
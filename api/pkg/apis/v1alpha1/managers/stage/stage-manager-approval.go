@@ -0,0 +1,203 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package stage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
+)
+
+func pausedStageKey(activation string) string {
+	return fmt.Sprintf("paused-stage-%s", activation)
+}
+
+func pendingTaskKey(triggerData v1alpha2.ActivationData) string {
+	return fmt.Sprintf("%s-%s-%s", triggerData.Campaign, triggerData.Activation, triggerData.ActivationGeneration)
+}
+
+// savePausedStage saves the trigger of a stage that is paused by its stage provider, so that a decision on the stage
+// can find the pending task of the stage.
+func (s *StageManager) savePausedStage(ctx context.Context, triggerData v1alpha2.ActivationData) error {
+	_, err := s.StateProvider.Upsert(ctx, states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   pausedStageKey(triggerData.Activation),
+			Body: triggerData,
+		},
+		Metadata: map[string]interface{}{
+			"namespace": triggerData.Namespace,
+		},
+	})
+	return err
+}
+
+// getPausedStage returns the trigger of the stage an activation is paused in, with the ETag of its entry. It returns nil
+// if no stage of the activation is paused by its stage provider.
+func (s *StageManager) getPausedStage(ctx context.Context, namespace string, activation string) (*v1alpha2.ActivationData, string, error) {
+	entry, err := s.StateProvider.Get(ctx, states.GetRequest{
+		ID: pausedStageKey(activation),
+		Metadata: map[string]interface{}{
+			"namespace": namespace,
+		},
+	})
+	if err != nil {
+		if utils.IsNotFound(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	var triggerData v1alpha2.ActivationData
+	jData, _ := json.Marshal(entry.Body)
+	if err = json.Unmarshal(jData, &triggerData); err != nil {
+		return nil, "", v1alpha2.NewCOAError(err, "paused stage is not an activation data", v1alpha2.InternalError)
+	}
+	return &triggerData, entry.ETag, nil
+}
+
+// claimPausedStage deletes the paused stage of an activation if it hasn't changed since it was read with the ETag. Only
+// one decision can claim a paused stage, a decision that loses the race gets a conflict.
+func (s *StageManager) claimPausedStage(ctx context.Context, triggerData v1alpha2.ActivationData, etag string) error {
+	err := s.StateProvider.Delete(ctx, states.DeleteRequest{
+		ID:   pausedStageKey(triggerData.Activation),
+		ETag: &etag,
+		Metadata: map[string]interface{}{
+			"namespace": triggerData.Namespace,
+		},
+	})
+	if err != nil && (utils.IsNotFound(err) || v1alpha2.IsConflict(err)) {
+		return v1alpha2.NewCOAError(err, fmt.Sprintf("stage %s of activation %s is already decided", triggerData.Stage, triggerData.Activation), v1alpha2.Conflict)
+	}
+	return err
+}
+
+func (s *StageManager) deletePausedStage(ctx context.Context, namespace string, activation string) error {
+	err := s.StateProvider.Delete(ctx, states.DeleteRequest{
+		ID: pausedStageKey(activation),
+		Metadata: map[string]interface{}{
+			"namespace": namespace,
+		},
+	})
+	if err != nil && !utils.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// DecideApproval approves or rejects the approval stage an activation is paused in, on behalf of an identity that is
+// one of the approvers of the stage or has one of its groups as a role. The approver and the comment are recorded in
+// the outputs of the returned stage status. An approved stage returns the trigger of the next stage, if any, while a
+// rejected stage stops the activation.
+func (s *StageManager) DecideApproval(ctx context.Context, cam model.CampaignSpec, activation model.ActivationState, identity v1alpha2.Identity, approved bool, decision model.ApprovalDecision) (model.StageStatus, *v1alpha2.ActivationData, error) {
+	if activation.Status == nil || len(activation.Status.StageHistory) == 0 || !activation.Status.StageHistory[len(activation.Status.StageHistory)-1].IsPendingApproval() {
+		return model.StageStatus{}, nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("activation %s is not waiting for an approval", activation.ObjectMeta.Name), v1alpha2.Conflict)
+	}
+	latest := activation.Status.StageHistory[len(activation.Status.StageHistory)-1]
+	if !latest.CanApprove(identity) {
+		return model.StageStatus{}, nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("user '%s' is not an approver of stage %s", identity.User, latest.Stage), v1alpha2.Unauthorized)
+	}
+	triggerData, etag, err := s.getPausedStage(ctx, activation.ObjectMeta.Namespace, activation.ObjectMeta.Name)
+	if err != nil {
+		return model.StageStatus{}, nil, err
+	}
+	if triggerData == nil || triggerData.Stage != latest.Stage {
+		return model.StageStatus{}, nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("activation %s is not waiting for an approval", activation.ObjectMeta.Name), v1alpha2.Conflict)
+	}
+	// concurrent decisions race to claim the paused stage, so the stage is resumed or rejected only once
+	if err = s.claimPausedStage(ctx, *triggerData, etag); err != nil {
+		return model.StageStatus{}, nil, err
+	}
+
+	status := latest
+	status.Outputs = make(map[string]interface{}, len(latest.Outputs)+3)
+	for k, v := range latest.Outputs {
+		status.Outputs[k] = v
+	}
+	status.Outputs[model.ApproverOutput] = identity.User
+	status.Outputs[model.ApprovalCommentOutput] = decision.Comment
+	status.Outputs[model.ApprovalTimeOutput] = time.Now().UTC().Format(time.RFC3339)
+	status.IsActive = false
+
+	if !approved {
+		log.InfofCtx(ctx, " M (Stage): stage %s of activation %s is rejected by %s", triggerData.Stage, triggerData.Activation, identity.User)
+		err = s.StateProvider.Delete(ctx, states.DeleteRequest{
+			ID: pendingTaskKey(*triggerData),
+			Metadata: map[string]interface{}{
+				"namespace": triggerData.Namespace,
+			},
+		})
+		if err != nil && !utils.IsNotFound(err) {
+			return model.StageStatus{}, nil, s.releasePausedStage(ctx, *triggerData, err)
+		}
+		status.Outputs[model.ApprovalOutput] = model.ApprovalRejected
+		status.Status = v1alpha2.Rejected
+		status.StatusMessage = v1alpha2.Rejected.String()
+		status.ErrorMessage = fmt.Sprintf("stage %s is rejected by %s", triggerData.Stage, identity.User)
+		status.NextStage = ""
+		return status, nil, nil
+	}
+
+	log.InfofCtx(ctx, " M (Stage): stage %s of activation %s is approved by %s", triggerData.Stage, triggerData.Activation, identity.User)
+	status.Outputs[model.ApprovalOutput] = model.ApprovalApproved
+	status.Status = v1alpha2.Done
+	status.StatusMessage = v1alpha2.Done.String()
+	status.ErrorMessage = ""
+
+	entry, err := s.StateProvider.Get(ctx, states.GetRequest{
+		ID: pendingTaskKey(*triggerData),
+		Metadata: map[string]interface{}{
+			"namespace": triggerData.Namespace,
+		},
+	})
+	if err != nil {
+		return model.StageStatus{}, nil, s.releasePausedStage(ctx, *triggerData, err)
+	}
+	var p PendingTask
+	jData, _ := json.Marshal(entry.Body)
+	if err = json.Unmarshal(jData, &p); err != nil {
+		return model.StageStatus{}, nil, s.releasePausedStage(ctx, *triggerData, v1alpha2.NewCOAError(err, "invalid pending task", v1alpha2.InternalError))
+	}
+
+	// one decision resumes the stage on every site it is paused on
+	var next *v1alpha2.ActivationData
+	for _, site := range p.Sites {
+		resumeStatus := status
+		resumeStatus.Outputs = make(map[string]interface{}, len(status.Outputs)+6)
+		for k, v := range status.Outputs {
+			resumeStatus.Outputs[k] = v
+		}
+		resumeStatus.Outputs["__campaign"] = triggerData.Campaign
+		resumeStatus.Outputs["__activation"] = triggerData.Activation
+		resumeStatus.Outputs["__activationGeneration"] = triggerData.ActivationGeneration
+		resumeStatus.Outputs["__stage"] = triggerData.Stage
+		resumeStatus.Outputs["__site"] = site
+		resumeStatus.Outputs["__namespace"] = triggerData.Namespace
+		next, err = s.ResumeStage(ctx, resumeStatus, cam)
+		if err != nil {
+			return model.StageStatus{}, nil, s.releasePausedStage(ctx, *triggerData, err)
+		}
+	}
+	if next != nil {
+		// the next stage is triggered by the activation inputs rather than the inputs of the approval stage
+		next.Inputs = triggerData.Inputs
+		status.NextStage = next.Stage
+	}
+	return status, next, nil
+}
+
+// releasePausedStage saves a claimed paused stage again after its decision failed, so that the decision can be retried
+func (s *StageManager) releasePausedStage(ctx context.Context, triggerData v1alpha2.ActivationData, err error) error {
+	if saveErr := s.savePausedStage(ctx, triggerData); saveErr != nil {
+		log.ErrorfCtx(ctx, " M (Stage): failed to save paused stage %s of activation %s again: %v", triggerData.Stage, triggerData.Activation, saveErr)
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package stage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
+)
+
+var errActivationCancelled = errors.New("activation is cancelled")
+
+func runningStageKey(namespace string, activation string, stage string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, activation, stage)
+}
+
+func parkedTriggerKey(activation string) string {
+	return fmt.Sprintf("parked-%s", activation)
+}
+
+// trackStage derives a cancellable context for a running stage, which is cancelled by CancelActivation. The returned
+// function must be called when the stage returns.
+func (s *StageManager) trackStage(ctx context.Context, triggerData v1alpha2.ActivationData) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	key := runningStageKey(triggerData.Namespace, triggerData.Activation, triggerData.Stage)
+	s.runningLock.Lock()
+	if s.running == nil {
+		s.running = make(map[string]context.CancelCauseFunc)
+	}
+	s.running[key] = cancel
+	s.runningLock.Unlock()
+	return ctx, func() {
+		s.runningLock.Lock()
+		delete(s.running, key)
+		s.runningLock.Unlock()
+		cancel(nil)
+	}
+}
+
+func isCancelled(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errActivationCancelled)
+}
+
+// CancelActivation cancels the contexts of the running stages of an activation, which are passed to the Process calls
+// of the stage providers on every site, and drops the stage the activation is paused in or parked before, if any.
+func (s *StageManager) CancelActivation(ctx context.Context, namespace string, activation string) error {
+	prefix := runningStageKey(namespace, activation, "")
+	s.runningLock.Lock()
+	for key, cancel := range s.running {
+		if strings.HasPrefix(key, prefix) {
+			log.InfofCtx(ctx, " M (Stage): cancelling stage %s of activation %s in namespace %s", strings.TrimPrefix(key, prefix), activation, namespace)
+			cancel(errActivationCancelled)
+		}
+	}
+	s.runningLock.Unlock()
+
+	if err := s.deletePausedStage(ctx, namespace, activation); err != nil {
+		return err
+	}
+	_, err := s.TakeParkedTrigger(ctx, namespace, activation)
+	return err
+}
+
+// ParkTrigger saves the trigger of the next stage of a paused activation, so that it can be triggered on resume
+func (s *StageManager) ParkTrigger(ctx context.Context, triggerData v1alpha2.ActivationData) error {
+	log.InfofCtx(ctx, " M (Stage): activation %s is paused before stage %s", triggerData.Activation, triggerData.Stage)
+	_, err := s.StateProvider.Upsert(ctx, states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   parkedTriggerKey(triggerData.Activation),
+			Body: triggerData,
+		},
+		Metadata: map[string]interface{}{
+			"namespace": triggerData.Namespace,
+		},
+	})
+	return err
+}
+
+// TakeParkedTrigger removes and returns the parked trigger of an activation. It returns nil if the activation isn't
+// parked before a stage.
+func (s *StageManager) TakeParkedTrigger(ctx context.Context, namespace string, activation string) (*v1alpha2.ActivationData, error) {
+	entry, err := s.StateProvider.Get(ctx, states.GetRequest{
+		ID: parkedTriggerKey(activation),
+		Metadata: map[string]interface{}{
+			"namespace": namespace,
+		},
+	})
+	if err != nil {
+		if utils.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	err = s.StateProvider.Delete(ctx, states.DeleteRequest{
+		ID: parkedTriggerKey(activation),
+		Metadata: map[string]interface{}{
+			"namespace": namespace,
+		},
+	})
+	if err != nil && !utils.IsNotFound(err) {
+		return nil, err
+	}
+	var triggerData v1alpha2.ActivationData
+	jData, _ := json.Marshal(entry.Body)
+	if err = json.Unmarshal(jData, &triggerData); err != nil {
+		return nil, v1alpha2.NewCOAError(err, "parked trigger is not an activation data", v1alpha2.InternalError)
+	}
+	return &triggerData, nil
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package stage
+
+import (
+	"context"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage/mock"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/contexts"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
+)
+
+// simulatedStageProvider stands in for the stage provider of a dry run stage, and returns the canned outputs of the
+// stage without any side effect
+type simulatedStageProvider struct {
+	Outputs map[string]interface{}
+}
+
+func (s *simulatedStageProvider) Init(config providers.IProviderConfig) error {
+	return nil
+}
+
+func (s *simulatedStageProvider) Process(ctx context.Context, mgrContext contexts.ManagerContext, inputs map[string]interface{}) (map[string]interface{}, bool, error) {
+	outputs := make(map[string]interface{}, len(s.Outputs))
+	for k, v := range s.Outputs {
+		outputs[k] = v
+	}
+	return outputs, false, nil
+}
+
+// dryRunProvider returns the simulated provider of a dry run stage. A stage without canned outputs behaves like the
+// mock stage provider, which echoes its inputs.
+func dryRunProvider(triggerData v1alpha2.ActivationData) (providers.IProvider, error) {
+	if outputs, ok := triggerData.DryRunOutputs[triggerData.Stage]; ok {
+		return &simulatedStageProvider{Outputs: outputs}, nil
+	}
+	provider := &mock.MockStageProvider{}
+	if err := provider.Init(mock.MockStageProviderConfig{}); err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package stage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+// processWithRetries runs the stage provider for a site under the timeout and retry policy of the stage, and returns
+// the result of the last attempt together with the record of every attempt.
+func (s *StageManager) processWithRetries(ctx context.Context, provider stage.IStageProvider, policy model.StageRetryPolicy, inputs map[string]interface{}, triggerData v1alpha2.ActivationData, site string) (map[string]interface{}, bool, []model.StageAttempt, error) {
+	attempts := make([]model.StageAttempt, 0, 1)
+	for attempt := 1; ; attempt++ {
+		inputCopy := copyInputs(inputs)
+		startTime := time.Now().UTC()
+		outputs, pause, err := s.processAttempt(ctx, provider, policy.Timeout, inputCopy)
+
+		result := TaskResult{
+			Outputs: outputs,
+			Site:    site,
+			Error:   err,
+		}
+		attemptErr := result.GetError()
+		record := model.StageAttempt{
+			Site:      site,
+			Attempt:   attempt,
+			Status:    v1alpha2.OK,
+			StartTime: startTime.Format(time.RFC3339),
+			EndTime:   time.Now().UTC().Format(time.RFC3339),
+		}
+		if pause {
+			record.Status = v1alpha2.Paused
+		}
+		if attemptErr != nil {
+			record.Status = v1alpha2.InternalError
+			if coaErr, ok := attemptErr.(v1alpha2.COAError); ok {
+				record.Status = coaErr.State
+			}
+			record.ErrorMessage = attemptErr.Error()
+		}
+		attempts = append(attempts, record)
+
+		if attemptErr == nil || pause || ctx.Err() != nil || attempt > policy.MaxRetries || !v1alpha2.IsRetriableErr(attemptErr) {
+			return outputs, pause, attempts, err
+		}
+
+		backoff := policy.Backoff(attempt)
+		log.InfofCtx(ctx, " M (Stage): attempt %d of stage %s in activation %s for site %s failed, retrying in %s: %v", attempt, triggerData.Stage, triggerData.Activation, site, backoff, attemptErr)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return outputs, pause, attempts, err
+		}
+	}
+}
+
+// processAttempt runs the stage provider once. With a timeout, the provider gets a context that is cancelled when the
+// timeout expires, and the attempt fails if it returns after that. The attempt always waits for the provider to return,
+// so an attempt never overlaps the next one.
+func (s *StageManager) processAttempt(ctx context.Context, provider stage.IStageProvider, timeout time.Duration, inputs map[string]interface{}) (map[string]interface{}, bool, error) {
+	if timeout <= 0 {
+		return provider.Process(ctx, *s.Manager.Context, inputs)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	outputs, pause, err := provider.Process(attemptCtx, *s.Manager.Context, inputs)
+	if ctx.Err() == nil && attemptCtx.Err() == context.DeadlineExceeded {
+		return nil, false, stageTimedOut(timeout)
+	}
+	return outputs, pause, err
+}
+
+// copyInputs copies the inputs of a stage with their nested maps and lists, so that an attempt can't change the inputs
+// of the next attempt
+func copyInputs(inputs map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(inputs))
+	for k, v := range inputs {
+		ret[k] = copyInputValue(v)
+	}
+	return ret
+}
+
+func copyInputValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return copyInputs(v)
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, item := range v {
+			ret[i] = copyInputValue(item)
+		}
+		return ret
+	default:
+		return v
+	}
+}
+
+func stageTimedOut(timeout time.Duration) error {
+	return v1alpha2.NewCOAError(nil, fmt.Sprintf("stage provider timed out after %s", timeout), v1alpha2.TimedOut)
+}
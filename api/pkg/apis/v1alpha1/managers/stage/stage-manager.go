@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,6 +29,7 @@ import (
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
 	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
+	coalogcontexts "github.com/eclipse-symphony/symphony/coa/pkg/logger/contexts"
 )
 
 var log = logger.NewLogger("coa.runtime")
@@ -36,12 +38,17 @@ type StageManager struct {
 	managers.Manager
 	StateProvider states.IStateProvider
 	apiClient     utils.ApiClient
+	runningLock   sync.Mutex
+	running       map[string]context.CancelCauseFunc
 }
 
 type TaskResult struct {
-	Outputs map[string]interface{}
-	Site    string
-	Error   error
+	Outputs  map[string]interface{}
+	Site     string
+	Error    error
+	Attempts []model.StageAttempt
+	// Pause is set when the stage is paused for the site
+	Pause bool
 }
 
 func (t *TaskResult) GetError() error {
@@ -259,6 +266,8 @@ func (s *StageManager) ResumeStage(ctx context.Context, status model.StageStatus
 						Outputs:              outputs,
 						TriggeringStage:      stage,
 						Schedule:             cam.Stages[nextStage].Schedule,
+						TimeZone:             cam.Stages[nextStage].TimeZone,
+						MaintenanceWindows:   cam.Stages[nextStage].MaintenanceWindows,
 						Namespace:            namespace,
 					}
 					log.InfofCtx(ctx, " M (Stage): Activating next stage: %s\n", activationData.Stage)
@@ -350,7 +359,7 @@ func (s *StageManager) HandleDirectTriggerEvent(ctx context.Context, triggerData
 		provider.(*remote.RemoteStageProvider).SetOutputsContext(triggerData.Outputs)
 	}
 
-	if triggerData.Schedule != "" && !isRemote {
+	if triggerData.IsScheduled() && !isRemote {
 		log.InfofCtx(ctx, " M (Stage): send schedule event and pause stage %s for site %s", triggerData.Stage, s.VendorContext.SiteInfo.SiteId)
 		s.Context.Publish("schedule", v1alpha2.Event{
 			Body:    triggerData,
@@ -419,6 +428,100 @@ func carryOutPutsToErrorStatus(outputs map[string]interface{}, err error, site s
 	}
 	return ret
 }
+
+// fanOutResult is the aggregated result of running a stage for the sites of its contexts
+type fanOutResult struct {
+	Outputs       map[string]interface{}
+	ErrorOutputs  map[string]interface{}
+	ErrorMessages []string
+	SiteResults   map[string]interface{}
+	Attempts      []model.StageAttempt
+	// Pause is set when the stage is paused for any site
+	Pause bool
+}
+
+// failure returns the error message of the stage if fewer sites succeeded than the policy requires, or an empty string
+func (r fanOutResult) failure(policy model.StageFanOutPolicy, sites int) string {
+	if len(r.ErrorMessages) == 0 {
+		return ""
+	}
+	succeeded := sites - len(r.ErrorMessages)
+	required := policy.RequiredSuccesses(sites)
+	if succeeded >= required {
+		return ""
+	}
+	errorMessage := strings.Join(r.ErrorMessages, "; ")
+	if policy.HasThreshold() {
+		errorMessage = fmt.Sprintf("%d of %d sites succeeded, %d required: %s", succeeded, sites, required, errorMessage)
+	}
+	return errorMessage
+}
+
+// aggregateSiteResults merges the outputs of the sites of a stage. Outputs of remote sites are prefixed with the site
+// name, and each site has its own status and error in SiteResults.
+func (s *StageManager) aggregateSiteResults(ctx context.Context, stage string, results <-chan TaskResult) fanOutResult {
+	ret := fanOutResult{
+		Outputs:       make(map[string]interface{}),
+		ErrorOutputs:  make(map[string]interface{}),
+		ErrorMessages: make([]string, 0),
+		SiteResults:   make(map[string]interface{}),
+	}
+	for result := range results {
+		ret.Attempts = append(ret.Attempts, result.Attempts...)
+		ret.Pause = ret.Pause || result.Pause
+		siteResult := map[string]interface{}{
+			"status": v1alpha2.OK.String(),
+		}
+		err := result.GetError()
+		if err != nil {
+			site := result.Site
+			if result.Site == s.Context.SiteInfo.SiteId {
+				site = ""
+			}
+			result.Outputs = carryOutPutsToErrorStatus(nil, err, site)
+			for k, v := range result.Outputs {
+				ret.ErrorOutputs[k] = v
+			}
+			ret.ErrorMessages = append(ret.ErrorMessages, fmt.Sprintf("%s: %s", result.Site, err.Error()))
+			siteResult["status"] = v1alpha2.InternalError.String()
+			if cErr, ok := err.(v1alpha2.COAError); ok {
+				siteResult["status"] = cErr.State.String()
+			}
+			siteResult["error"] = err.Error()
+			log.ErrorfCtx(ctx, " M (Stage): failed to process stage %s for site %s outputs: %v", stage, site, err)
+		}
+		ret.SiteResults[result.Site] = siteResult
+		for k, v := range result.Outputs {
+			if result.Site == s.Context.SiteInfo.SiteId {
+				ret.Outputs[k] = v
+			} else {
+				ret.Outputs[fmt.Sprintf("%s.%s", result.Site, k)] = v
+			}
+		}
+		if result.Site == s.Context.SiteInfo.SiteId {
+			if _, ok := result.Outputs["__status"]; !ok {
+				ret.Outputs["__status"] = v1alpha2.OK
+			}
+		} else {
+			key := fmt.Sprintf("%s.__status", result.Site)
+			if _, ok := result.Outputs[key]; !ok {
+				ret.Outputs[key] = v1alpha2.Untouched
+			}
+		}
+	}
+	sort.Strings(ret.ErrorMessages)
+	return ret
+}
+
+// withOwnDiagnosticLogContext returns a context with a copy of the diagnostic log context of ctx, so that spans
+// started from different goroutines don't write the same diagnostic log context
+func withOwnDiagnosticLogContext(ctx context.Context) context.Context {
+	if diagCtx, ok := ctx.Value(coalogcontexts.DiagnosticLogContextKey).(*coalogcontexts.DiagnosticLogContext); ok && diagCtx != nil {
+		return coalogcontexts.OverrideDiagnosticLogContextToCurrentContext(diagCtx.DeepCopy(), ctx)
+	}
+	return ctx
+}
+
 func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.CampaignSpec, triggerData v1alpha2.ActivationData) (model.StageStatus, *v1alpha2.ActivationData) {
 	ctx, span := observability.StartSpan("Stage Manager", ctx, &map[string]string{
 		"method": "HandleTriggerEvent",
@@ -428,6 +531,10 @@ func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.Ca
 	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
 
 	log.InfofCtx(ctx, " M (Stage): HandleTriggerEvent for campaign %s, activation %s, stage %s", triggerData.Campaign, triggerData.Activation, triggerData.Stage)
+	// events outlive the stage, so they keep the context that isn't cancelled when the stage returns
+	eventCtx := ctx
+	ctx, untrack := s.trackStage(ctx, triggerData)
+	defer untrack()
 	status := model.StageStatus{
 		Stage:         triggerData.Stage,
 		NextStage:     "",
@@ -506,6 +613,12 @@ func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.Ca
 		if triggerData.Schedule != "" {
 			inputs["__schedule"] = triggerData.Schedule
 		}
+		if triggerData.TimeZone != "" {
+			inputs["__timeZone"] = triggerData.TimeZone
+		}
+		if len(triggerData.MaintenanceWindows) > 0 {
+			inputs["__maintenanceWindows"] = triggerData.MaintenanceWindows
+		}
 
 		for k, v := range inputs {
 			var val interface{}
@@ -535,9 +648,35 @@ func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.Ca
 			}
 		}
 
+		var retryPolicy model.StageRetryPolicy
+		retryPolicy, err = currentStage.GetRetryPolicy()
+		if err != nil {
+			status.Status = v1alpha2.BadConfig
+			status.StatusMessage = v1alpha2.BadConfig.String()
+			status.ErrorMessage = err.Error()
+			status.IsActive = false
+			log.ErrorfCtx(ctx, " M (Stage): invalid retry policy: %v", err)
+			return status, activationData
+		}
+		var fanOutPolicy model.StageFanOutPolicy
+		fanOutPolicy, err = currentStage.GetFanOutPolicy()
+		if err != nil {
+			status.Status = v1alpha2.BadConfig
+			status.StatusMessage = v1alpha2.BadConfig.String()
+			status.ErrorMessage = err.Error()
+			status.IsActive = false
+			log.ErrorfCtx(ctx, " M (Stage): invalid fan-out policy: %v", err)
+			return status, activationData
+		}
+
 		factory := symproviders.SymphonyProviderFactory{}
 		var provider providers.IProvider
-		provider, err = factory.CreateProvider(triggerData.Provider, triggerData.Config)
+		if triggerData.DryRun {
+			log.InfofCtx(ctx, " M (Stage): dry run of stage %s in activation %s uses a simulated provider instead of %s", triggerData.Stage, triggerData.Activation, triggerData.Provider)
+			provider, err = dryRunProvider(triggerData)
+		} else {
+			provider, err = factory.CreateProvider(triggerData.Provider, triggerData.Config)
+		}
 		if err != nil {
 			status.Status = v1alpha2.InternalError
 			status.StatusMessage = v1alpha2.InternalError.String()
@@ -564,12 +703,27 @@ func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.Ca
 		numTasks := len(sites)
 		waitGroup := sync.WaitGroup{}
 		results := make(chan TaskResult, numTasks)
-		pauseRequested := false
+		// slots limits the sites that are processed at once to the maxParallel of the stage
+		var slots chan struct{}
+		if fanOutPolicy.MaxParallel > 0 && fanOutPolicy.MaxParallel < numTasks {
+			log.InfofCtx(ctx, " M (Stage): stage %s runs for %d sites, %d at a time", triggerData.Stage, numTasks, fanOutPolicy.MaxParallel)
+			slots = make(chan struct{}, fanOutPolicy.MaxParallel)
+		}
+		if _, ok := provider.(*remote.RemoteStageProvider); ok {
+			provider.(*remote.RemoteStageProvider).SetOutputsContext(triggerData.Outputs)
+		}
 
 		for _, site := range sites {
 			waitGroup.Add(1)
-			go func(wg *sync.WaitGroup, site string, results chan<- TaskResult) {
+			// each site starts its own spans, so it gets its own diagnostic log context
+			siteCtx := withOwnDiagnosticLogContext(ctx)
+			siteEventCtx := withOwnDiagnosticLogContext(eventCtx)
+			go func(wg *sync.WaitGroup, ctx context.Context, eventCtx context.Context, site string, results chan<- TaskResult) {
 				defer wg.Done()
+				if slots != nil {
+					slots <- struct{}{}
+					defer func() { <-slots }()
+				}
 				inputCopy := make(map[string]interface{})
 				for k, v := range inputs {
 					inputCopy[k] = v
@@ -577,13 +731,8 @@ func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.Ca
 				inputCopy["__site"] = site
 
 				for k, v := range inputCopy {
-					var val interface{}
-					val, err = s.traceValue(ctx, v, triggerData.Namespace, inputCopy, triggers, triggerData.Outputs)
+					val, err := s.traceValue(ctx, v, triggerData.Namespace, inputCopy, triggers, triggerData.Outputs)
 					if err != nil {
-						status.Status = v1alpha2.InternalError
-						status.StatusMessage = v1alpha2.InternalError.String()
-						status.ErrorMessage = err.Error()
-						status.IsActive = false
 						log.ErrorfCtx(ctx, " M (Stage): failed to evaluate input: %v", err)
 						results <- TaskResult{
 							Outputs: nil,
@@ -595,78 +744,56 @@ func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.Ca
 					inputCopy[k] = val
 				}
 
-				if _, ok := provider.(*remote.RemoteStageProvider); ok {
-					provider.(*remote.RemoteStageProvider).SetOutputsContext(triggerData.Outputs)
-				}
-
-				if triggerData.Schedule != "" {
+				// a dry run doesn't wait for the schedule of the stage
+				if triggerData.IsScheduled() && !triggerData.DryRun {
 					log.InfofCtx(ctx, " M (Stage): send schedule event and pause stage %s for site %s", triggerData.Stage, site)
 					s.Context.Publish("schedule", v1alpha2.Event{
 						Body:    triggerData,
-						Context: ctx,
+						Context: eventCtx,
 					})
-					pauseRequested = true
 					results <- TaskResult{
 						Outputs: nil,
 						Error:   nil,
 						Site:    site,
+						Pause:   true,
 					}
 				} else {
-					var outputs map[string]interface{}
-					var pause bool
-					outputs, pause, err = provider.(stage.IStageProvider).Process(ctx, *s.Manager.Context, inputCopy)
+					outputs, pause, attempts, err := s.processWithRetries(ctx, provider.(stage.IStageProvider), retryPolicy, inputCopy, triggerData, site)
 
 					if pause {
 						log.InfofCtx(ctx, " M (Stage): stage %s in activation %s for site %s get paused result from stage provider", triggerData.Stage, triggerData.Activation, site)
-						pauseRequested = true
 					}
 					results <- TaskResult{
-						Outputs: outputs,
-						Error:   err,
-						Site:    site,
+						Outputs:  outputs,
+						Error:    err,
+						Site:     site,
+						Attempts: attempts,
+						Pause:    pause,
 					}
 				}
-			}(&waitGroup, site, results)
+			}(&waitGroup, siteCtx, siteEventCtx, site, results)
 		}
 
 		waitGroup.Wait()
 		close(results)
 
-		outputs := make(map[string]interface{})
+		fanOut := s.aggregateSiteResults(ctx, triggerData.Stage, results)
+		outputs := fanOut.Outputs
+		status.Attempts = fanOut.Attempts
 		delayedExit := false
-		for result := range results {
-			err = result.GetError()
-			if err != nil {
-				status.Status = v1alpha2.InternalError
-				status.StatusMessage = v1alpha2.InternalError.String()
-				status.ErrorMessage = fmt.Sprintf("%s: %s", result.Site, err.Error())
-				status.IsActive = false
-				site := result.Site
-				if result.Site == s.Context.SiteInfo.SiteId {
-					site = ""
-				}
-				status.Outputs = carryOutPutsToErrorStatus(nil, err, site)
-				result.Outputs = carryOutPutsToErrorStatus(nil, err, site)
-				log.ErrorfCtx(ctx, " M (Stage): failed to process stage %s for site %s outputs: %v", triggerData.Stage, site, err)
-				delayedExit = true
-			}
-			for k, v := range result.Outputs {
-				if result.Site == s.Context.SiteInfo.SiteId {
-					outputs[k] = v
-				} else {
-					outputs[fmt.Sprintf("%s.%s", result.Site, k)] = v
-				}
-			}
-			if result.Site == s.Context.SiteInfo.SiteId {
-				if _, ok := result.Outputs["__status"]; !ok {
-					outputs["__status"] = v1alpha2.OK
-				}
-			} else {
-				key := fmt.Sprintf("%s.__status", result.Site)
-				if _, ok := result.Outputs[key]; !ok {
-					outputs[key] = v1alpha2.Untouched
-				}
-			}
+		if errorMessage := fanOut.failure(fanOutPolicy, numTasks); errorMessage != "" {
+			status.Status = v1alpha2.InternalError
+			status.StatusMessage = v1alpha2.InternalError.String()
+			status.ErrorMessage = errorMessage
+			status.IsActive = false
+			status.Outputs = fanOut.ErrorOutputs
+			delayedExit = true
+		} else if len(fanOut.ErrorMessages) > 0 {
+			log.InfofCtx(ctx, " M (Stage): stage %s succeeded for %d of %d sites, which meets the success threshold %s", triggerData.Stage, numTasks-len(fanOut.ErrorMessages), numTasks, currentStage.SuccessThreshold)
+		}
+		if currentStage.Contexts != "" {
+			// each site of the contexts has its own result, failed sites may be tolerated by the success threshold
+			outputs[model.SiteResultsOutput] = fanOut.SiteResults
 		}
 
 		for k, v := range outputs {
@@ -674,12 +801,24 @@ func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.Ca
 				status.Outputs[k] = v
 			}
 		}
+		sort.SliceStable(status.Attempts, func(i, j int) bool {
+			return status.Attempts[i].Site < status.Attempts[j].Site
+		})
+		if isCancelled(ctx) {
+			status.Status = v1alpha2.Cancelled
+			status.StatusMessage = v1alpha2.Cancelled.String()
+			status.ErrorMessage = errActivationCancelled.Error()
+			status.NextStage = ""
+			status.IsActive = false
+			log.InfofCtx(ctx, " M (Stage): stage %s of activation %s is cancelled", triggerData.Stage, triggerData.Activation)
+			return status, nil
+		}
 		if triggerData.Outputs == nil {
 			triggerData.Outputs = make(map[string]map[string]interface{})
 		}
 		triggerData.Outputs[triggerData.Stage] = outputs
 		// If stage is paused, save the pending task and return paused status
-		if pauseRequested {
+		if fanOut.Pause {
 			pendingTask := PendingTask{
 				Sites:         sites,
 				OutputContext: triggerData.Outputs,
@@ -701,6 +840,15 @@ func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.Ca
 				log.ErrorfCtx(ctx, " M (Stage): failed to save pending task: %v", err)
 				return status, activationData
 			}
+			err = s.savePausedStage(ctx, triggerData)
+			if err != nil {
+				status.Status = v1alpha2.InternalError
+				status.StatusMessage = v1alpha2.InternalError.String()
+				status.ErrorMessage = err.Error()
+				status.IsActive = false
+				log.ErrorfCtx(ctx, " M (Stage): failed to save paused stage: %v", err)
+				return status, activationData
+			}
 			status.Status = v1alpha2.Paused
 			status.StatusMessage = v1alpha2.Paused.String()
 			status.IsActive = false
@@ -750,7 +898,11 @@ func (s *StageManager) HandleTriggerEvent(ctx context.Context, campaign model.Ca
 							Config:               nextStage.Config,
 							TriggeringStage:      triggerData.Stage,
 							Schedule:             nextStage.Schedule,
+							TimeZone:             nextStage.TimeZone,
+							MaintenanceWindows:   nextStage.MaintenanceWindows,
 							Namespace:            triggerData.Namespace,
+							DryRun:               triggerData.DryRun,
+							DryRunOutputs:        triggerData.DryRunOutputs,
 						}
 					} else {
 						status.Status = v1alpha2.InternalError
@@ -875,7 +1027,11 @@ func (s *StageManager) HandleActivationEvent(ctx context.Context, actData v1alph
 			Config:               stageSpec.Config,
 			TriggeringStage:      stage,
 			Schedule:             stageSpec.Schedule,
+			TimeZone:             stageSpec.TimeZone,
+			MaintenanceWindows:   stageSpec.MaintenanceWindows,
 			Namespace:            actData.Namespace,
+			DryRun:               activation.Spec.DryRun,
+			DryRunOutputs:        activation.Spec.DryRunOutputs,
 		}, nil
 	}
 	return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("stage %s is not found", stage), v1alpha2.BadRequest)
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -462,6 +463,336 @@ func TestCampaignWithDelay(t *testing.T) {
 	// assert.Equal(t, "test", status.Outputs["__stage"])
 	// assert.Equal(t, "fake", status.Outputs["__site"])
 }
+func TestCancelCampaignWithDelay(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager := StageManager{
+		StateProvider: stateProvider,
+	}
+	manager.VendorContext = &contexts.VendorContext{
+		EvaluationContext: &coa_utils.EvaluationContext{},
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "fake",
+		},
+	}
+	manager.Context = &contexts.ManagerContext{
+		VencorContext: manager.VendorContext,
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "fake",
+		},
+	}
+	activation := v1alpha2.ActivationData{
+		Campaign:             "test-campaign",
+		Activation:           "test-activation",
+		Stage:                "test",
+		ActivationGeneration: "1",
+		Outputs:              nil,
+		Provider:             "providers.stage.delay",
+		Namespace:            "fakens",
+	}
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		err := manager.CancelActivation(context.Background(), "fakens", "test-activation")
+		assert.Nil(t, err)
+	}()
+	timeStamp := time.Now().UTC()
+	status, next := manager.HandleTriggerEvent(context.Background(), model.CampaignSpec{
+		SelfDriving: true,
+		FirstStage:  "test",
+		Stages: map[string]model.StageSpec{
+			"test": {
+				Provider:      "providers.stage.delay",
+				StageSelector: "test",
+				Inputs: map[string]interface{}{
+					"delay": "1m",
+				},
+			},
+		},
+	}, activation)
+	assert.Nil(t, next)
+	assert.Equal(t, v1alpha2.Cancelled, status.Status)
+	assert.Equal(t, "", status.NextStage)
+	assert.False(t, status.IsActive)
+	assert.True(t, time.Now().UTC().Sub(timeStamp) < 30*time.Second)
+}
+func TestCampaignWithTimeout(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager := StageManager{
+		StateProvider: stateProvider,
+	}
+	manager.VendorContext = &contexts.VendorContext{
+		EvaluationContext: &coa_utils.EvaluationContext{},
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "fake",
+		},
+	}
+	manager.Context = &contexts.ManagerContext{
+		VencorContext: manager.VendorContext,
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "fake",
+		},
+	}
+	activation := v1alpha2.ActivationData{
+		Campaign:             "test-campaign",
+		Activation:           "test-activation",
+		Stage:                "test",
+		ActivationGeneration: "1",
+		Outputs:              nil,
+		Provider:             "providers.stage.delay",
+		Namespace:            "fakens",
+	}
+	timeStamp := time.Now().UTC()
+	status, next := manager.HandleTriggerEvent(context.Background(), model.CampaignSpec{
+		SelfDriving: true,
+		FirstStage:  "test",
+		Stages: map[string]model.StageSpec{
+			"test": {
+				Provider:      "providers.stage.delay",
+				StageSelector: "test2",
+				Timeout:       "200ms",
+				MaxRetries:    1,
+				RetryBackoff:  "10ms",
+				Inputs: map[string]interface{}{
+					"delay": "1m",
+				},
+			},
+			"test2": {
+				Provider: "providers.stage.mock",
+			},
+		},
+	}, activation)
+	assert.Nil(t, next)
+	assert.Equal(t, v1alpha2.InternalError, status.Status)
+	assert.True(t, time.Now().UTC().Sub(timeStamp) < 30*time.Second)
+	assert.Equal(t, 2, len(status.Attempts))
+	for i, attempt := range status.Attempts {
+		assert.Equal(t, i+1, attempt.Attempt)
+		assert.Equal(t, "fake", attempt.Site)
+		assert.Equal(t, v1alpha2.TimedOut, attempt.Status)
+	}
+}
+
+type flakyStageProvider struct {
+	failures int
+	calls    int
+	inputs   []interface{}
+}
+
+func (p *flakyStageProvider) Process(ctx context.Context, mgrContext contexts.ManagerContext, inputs map[string]interface{}) (map[string]interface{}, bool, error) {
+	p.calls++
+	if sites, ok := inputs["sites"].([]interface{}); ok {
+		p.inputs = append(p.inputs, sites[0])
+		sites[0] = "changed"
+	}
+	if p.calls <= p.failures {
+		return map[string]interface{}{
+			"__status": v1alpha2.InternalError,
+			"__error":  "transient failure",
+		}, false, nil
+	}
+	return map[string]interface{}{
+		"calls": p.calls,
+	}, false, nil
+}
+
+func TestProcessWithRetries(t *testing.T) {
+	manager := StageManager{}
+	manager.Context = &contexts.ManagerContext{}
+	provider := &flakyStageProvider{failures: 2}
+	policy := model.StageRetryPolicy{
+		MaxRetries:      3,
+		RetryBackoff:    10 * time.Millisecond,
+		MaxRetryBackoff: 20 * time.Millisecond,
+	}
+	inputs := map[string]interface{}{
+		"sites": []interface{}{"east"},
+	}
+	outputs, pause, attempts, err := manager.processWithRetries(context.Background(), provider, policy, inputs, v1alpha2.ActivationData{Stage: "test"}, "fake")
+	assert.Nil(t, err)
+	assert.False(t, pause)
+	assert.Equal(t, []interface{}{"east", "east", "east"}, provider.inputs)
+	assert.Equal(t, 3, outputs["calls"])
+	assert.Equal(t, 3, len(attempts))
+	assert.Equal(t, v1alpha2.InternalError, attempts[0].Status)
+	assert.Contains(t, attempts[0].ErrorMessage, "transient failure")
+	assert.Equal(t, v1alpha2.InternalError, attempts[1].Status)
+	assert.Equal(t, v1alpha2.OK, attempts[2].Status)
+}
+
+func TestProcessWithRetriesExhausted(t *testing.T) {
+	manager := StageManager{}
+	manager.Context = &contexts.ManagerContext{}
+	provider := &flakyStageProvider{failures: 5}
+	policy := model.StageRetryPolicy{
+		MaxRetries:      1,
+		RetryBackoff:    10 * time.Millisecond,
+		MaxRetryBackoff: 10 * time.Millisecond,
+	}
+	outputs, _, attempts, err := manager.processWithRetries(context.Background(), provider, policy, map[string]interface{}{}, v1alpha2.ActivationData{Stage: "test"}, "fake")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.InternalError, outputs["__status"])
+	assert.Equal(t, 2, len(attempts))
+	assert.Equal(t, 2, provider.calls)
+}
+
+func createApprovalStageManager(t *testing.T) (*StageManager, *httptest.Server) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager := StageManager{
+		StateProvider: stateProvider,
+	}
+	ts := InitializeMockSymphonyAPI()
+	os.Setenv(constants.SymphonyAPIUrlEnvName, ts.URL+"/")
+	os.Setenv(constants.UseServiceAccountTokenEnvName, "false")
+	manager.VendorContext = &contexts.VendorContext{
+		EvaluationContext: &coa_utils.EvaluationContext{},
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "fake",
+			CurrentSite: v1alpha2.SiteConnection{
+				BaseUrl:  ts.URL + "/",
+				Username: "admin",
+				Password: "",
+			},
+		},
+	}
+	manager.Context = &contexts.ManagerContext{
+		VencorContext: manager.VendorContext,
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "fake",
+		},
+	}
+	var err error
+	manager.apiClient, err = utils.GetApiClient()
+	assert.Nil(t, err)
+	return &manager, ts
+}
+
+func pauseOnApprovalStage(t *testing.T, manager *StageManager) (model.CampaignSpec, model.ActivationState) {
+	campaign := model.CampaignSpec{
+		SelfDriving: true,
+		FirstStage:  "approve",
+		Stages: map[string]model.StageSpec{
+			"approve": {
+				Provider:      "providers.stage.approval",
+				StageSelector: "deploy",
+				Inputs: map[string]interface{}{
+					"approvers": []interface{}{"alice"},
+					"groups":    "release-managers",
+				},
+			},
+			"deploy": {
+				Provider:      "providers.stage.mock",
+				StageSelector: "",
+			},
+		},
+	}
+	status, _ := manager.HandleTriggerEvent(context.Background(), campaign, v1alpha2.ActivationData{
+		Campaign:             "campaign1",
+		Activation:           "activation1",
+		Stage:                "approve",
+		ActivationGeneration: "1",
+		Inputs: map[string]interface{}{
+			"wave": "production",
+		},
+		Provider:  "providers.stage.approval",
+		Namespace: "default",
+	})
+	assert.Equal(t, v1alpha2.Paused, status.Status)
+	assert.True(t, status.IsPendingApproval())
+	return campaign, model.ActivationState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "activation1",
+			Namespace: "default",
+		},
+		Spec: &model.ActivationSpec{
+			Campaign: "campaign1",
+		},
+		Status: &model.ActivationStatus{
+			Status:       v1alpha2.Paused,
+			StageHistory: []model.StageStatus{status},
+		},
+	}
+}
+
+func TestApproveApprovalStage(t *testing.T) {
+	manager, ts := createApprovalStageManager(t)
+	defer ts.Close()
+	campaign, activation := pauseOnApprovalStage(t, manager)
+
+	_, _, err := manager.DecideApproval(context.Background(), campaign, activation, v1alpha2.Identity{User: "mallory", Roles: []string{"operator"}}, true, model.ApprovalDecision{})
+	assert.NotNil(t, err)
+	assert.Equal(t, v1alpha2.Unauthorized, err.(v1alpha2.COAError).State)
+
+	status, next, err := manager.DecideApproval(context.Background(), campaign, activation, v1alpha2.Identity{User: "bob", Roles: []string{"release-managers"}}, true, model.ApprovalDecision{Comment: "go"})
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.Done, status.Status)
+	assert.Equal(t, "deploy", status.NextStage)
+	assert.Equal(t, model.ApprovalApproved, status.Outputs[model.ApprovalOutput])
+	assert.Equal(t, "bob", status.Outputs[model.ApproverOutput])
+	assert.Equal(t, "go", status.Outputs[model.ApprovalCommentOutput])
+	assert.NotNil(t, next)
+	assert.Equal(t, "deploy", next.Stage)
+	assert.Equal(t, "production", next.Inputs["wave"])
+	assert.Equal(t, model.ApprovalApproved, next.Outputs["approve"][model.ApprovalOutput])
+
+	// the stage can be decided on only once
+	_, _, err = manager.DecideApproval(context.Background(), campaign, activation, v1alpha2.Identity{User: "alice"}, true, model.ApprovalDecision{})
+	assert.NotNil(t, err)
+	assert.Equal(t, v1alpha2.Conflict, err.(v1alpha2.COAError).State)
+}
+
+func TestConcurrentApprovalDecisions(t *testing.T) {
+	manager, ts := createApprovalStageManager(t)
+	defer ts.Close()
+	campaign, activation := pauseOnApprovalStage(t, manager)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _, errs[i] = manager.DecideApproval(context.Background(), campaign, activation, v1alpha2.Identity{User: "alice"}, i%2 == 0, model.ApprovalDecision{})
+		}(i)
+	}
+	wg.Wait()
+
+	decided := 0
+	for _, err := range errs {
+		if err == nil {
+			decided++
+			continue
+		}
+		assert.Equal(t, v1alpha2.Conflict, err.(v1alpha2.COAError).State)
+	}
+	assert.Equal(t, 1, decided)
+}
+
+func TestRejectApprovalStage(t *testing.T) {
+	manager, ts := createApprovalStageManager(t)
+	defer ts.Close()
+	campaign, activation := pauseOnApprovalStage(t, manager)
+
+	status, next, err := manager.DecideApproval(context.Background(), campaign, activation, v1alpha2.Identity{User: "alice"}, false, model.ApprovalDecision{Comment: "not during the freeze"})
+	assert.Nil(t, err)
+	assert.Nil(t, next)
+	assert.Equal(t, v1alpha2.Rejected, status.Status)
+	assert.Equal(t, "", status.NextStage)
+	assert.False(t, status.IsActive)
+	assert.Equal(t, model.ApprovalRejected, status.Outputs[model.ApprovalOutput])
+	assert.Equal(t, "alice", status.Outputs[model.ApproverOutput])
+	assert.Equal(t, "not during the freeze", status.Outputs[model.ApprovalCommentOutput])
+
+	_, err = manager.StateProvider.Get(context.Background(), states.GetRequest{
+		ID: "campaign1-activation1-1",
+		Metadata: map[string]interface{}{
+			"namespace": "default",
+		},
+	})
+	assert.True(t, utils.IsNotFound(err))
+}
+
 func TestErrorHandler(t *testing.T) {
 	stateProvider := &memorystate.MemoryStateProvider{}
 	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
@@ -1393,3 +1724,180 @@ func InitializeMockSymphonyAPI() *httptest.Server {
 	}))
 	return ts
 }
+func TestCampaignDryRun(t *testing.T) {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager := StageManager{
+		StateProvider: stateProvider,
+	}
+	manager.VendorContext = &contexts.VendorContext{
+		EvaluationContext: &coa_utils.EvaluationContext{},
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "fake",
+		},
+	}
+	manager.Context = &contexts.ManagerContext{
+		VencorContext: manager.VendorContext,
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "fake",
+		},
+	}
+	campaign := model.CampaignSpec{
+		SelfDriving: true,
+		FirstStage:  "deploy",
+		Stages: map[string]model.StageSpec{
+			"deploy": {
+				Provider: "providers.stage.http",
+				// a dry run doesn't wait for the schedule
+				Schedule:      "2100-01-01T00:00:00Z",
+				StageSelector: "${{$if($equal($output(deploy, status), 500), rollback, verify)}}",
+				Inputs: map[string]interface{}{
+					"method": "POST",
+					"url":    "http://invalid.localhost/deploy",
+				},
+			},
+			"verify": {
+				Provider: "providers.stage.http",
+				Inputs: map[string]interface{}{
+					"url": "http://invalid.localhost/verify",
+				},
+			},
+			"rollback": {
+				Provider: "providers.stage.script",
+				Inputs: map[string]interface{}{
+					"status": "${{$output(deploy, status)}}",
+				},
+			},
+		},
+	}
+	activation, err := manager.HandleActivationEvent(context.Background(), v1alpha2.ActivationData{
+		Campaign:             "test-campaign",
+		Activation:           "test-activation",
+		ActivationGeneration: "1",
+		Namespace:            "default",
+	}, campaign, model.ActivationState{
+		Spec: &model.ActivationSpec{
+			DryRun: true,
+			DryRunOutputs: map[string]map[string]interface{}{
+				"deploy": {"status": 500},
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, activation.DryRun)
+
+	path := []string{}
+	var status model.StageStatus
+	for activation != nil {
+		status, activation = manager.HandleTriggerEvent(context.Background(), campaign, *activation)
+		assert.Equal(t, v1alpha2.Done, status.Status)
+		path = append(path, status.Stage)
+	}
+	assert.Equal(t, []string{"deploy", "rollback"}, path)
+	// the rollback stage runs like the mock stage provider, which echoes its evaluated inputs
+	assert.Equal(t, 500, status.Inputs["status"])
+	assert.Equal(t, 500, status.Outputs["status"])
+}
+
+func createFanOutStageManager() *StageManager {
+	stateProvider := &memorystate.MemoryStateProvider{}
+	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
+	manager := StageManager{
+		StateProvider: stateProvider,
+	}
+	manager.VendorContext = &contexts.VendorContext{
+		EvaluationContext: &coa_utils.EvaluationContext{},
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "hq",
+		},
+	}
+	manager.Context = &contexts.ManagerContext{
+		VencorContext: manager.VendorContext,
+		SiteInfo: v1alpha2.SiteInfo{
+			SiteId: "hq",
+		},
+	}
+	return &manager
+}
+
+func TestFanOutMaxParallel(t *testing.T) {
+	manager := createFanOutStageManager()
+	timeStamp := time.Now()
+	status, _ := manager.HandleTriggerEvent(context.Background(), model.CampaignSpec{
+		SelfDriving: true,
+		FirstStage:  "deploy",
+		Stages: map[string]model.StageSpec{
+			"deploy": {
+				Provider:      "providers.stage.delay",
+				StageSelector: "",
+				Contexts:      "${{$trigger(sites, '')}}",
+				MaxParallel:   2,
+				Inputs: map[string]interface{}{
+					"delay": "200ms",
+				},
+			},
+		},
+	}, v1alpha2.ActivationData{
+		Campaign:   "test-campaign",
+		Activation: "test-activation",
+		Stage:      "deploy",
+		Inputs: map[string]interface{}{
+			"sites": []interface{}{"site1", "site2", "site3", "site4"},
+		},
+		Provider: "providers.stage.delay",
+	})
+	assert.Equal(t, v1alpha2.Done, status.Status)
+	assert.True(t, time.Since(timeStamp) >= 400*time.Millisecond)
+	siteResults, ok := status.Outputs[model.SiteResultsOutput].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, 4, len(siteResults))
+	assert.Equal(t, v1alpha2.OK.String(), siteResults["site1"].(map[string]interface{})["status"])
+}
+
+func aggregateResultsWithFailedSite(manager *StageManager) fanOutResult {
+	results := make(chan TaskResult, 4)
+	for _, site := range []string{"site1", "site2", "site4"} {
+		results <- TaskResult{
+			Outputs: map[string]interface{}{"version": "v2"},
+			Site:    site,
+		}
+	}
+	results <- TaskResult{
+		Outputs: map[string]interface{}{
+			"__status": v1alpha2.InternalError,
+			"__error":  "site is unreachable",
+		},
+		Site: "site3",
+	}
+	close(results)
+	return manager.aggregateSiteResults(context.Background(), "deploy", results)
+}
+
+func TestAggregateSiteResults(t *testing.T) {
+	manager := createFanOutStageManager()
+	result := aggregateResultsWithFailedSite(manager)
+	assert.Equal(t, "v2", result.Outputs["site1.version"])
+	assert.Equal(t, v1alpha2.Untouched, result.Outputs["site1.__status"])
+	assert.Equal(t, []string{"site3: Internal Error: site is unreachable"}, result.ErrorMessages)
+	assert.Equal(t, 4, len(result.SiteResults))
+	assert.Equal(t, v1alpha2.OK.String(), result.SiteResults["site1"].(map[string]interface{})["status"])
+	assert.Equal(t, v1alpha2.InternalError.String(), result.SiteResults["site3"].(map[string]interface{})["status"])
+	assert.Equal(t, "Internal Error: site is unreachable", result.SiteResults["site3"].(map[string]interface{})["error"])
+}
+
+func TestFanOutSuccessThreshold(t *testing.T) {
+	manager := createFanOutStageManager()
+	result := aggregateResultsWithFailedSite(manager)
+
+	policy, err := model.StageSpec{SuccessThreshold: "75%"}.GetFanOutPolicy()
+	assert.Nil(t, err)
+	assert.Equal(t, "", result.failure(policy, 4))
+
+	policy, err = model.StageSpec{SuccessThreshold: "4"}.GetFanOutPolicy()
+	assert.Nil(t, err)
+	assert.Equal(t, "3 of 4 sites succeeded, 4 required: site3: Internal Error: site is unreachable", result.failure(policy, 4))
+
+	policy, err = model.StageSpec{}.GetFanOutPolicy()
+	assert.Nil(t, err)
+	assert.Equal(t, "site3: Internal Error: site is unreachable", result.failure(policy, 4))
+}
@@ -195,8 +195,14 @@ func (t *TargetsManager) ReportState(ctx context.Context, current model.TargetSt
 	return targetState, nil
 }
 func (t *TargetsManager) ListState(ctx context.Context, namespace string) ([]model.TargetState, error) {
+	ret, _, err := t.ListStatePage(ctx, namespace, states.PageOptions{})
+	return ret, err
+}
+
+// ListStatePage lists a page of targets sorted by the page options, and returns the continuation token of the next page
+func (t *TargetsManager) ListStatePage(ctx context.Context, namespace string, page states.PageOptions) ([]model.TargetState, string, error) {
 	ctx, span := observability.StartSpan("Targets Manager", ctx, &map[string]string{
-		"method": "ListSpec",
+		"method": "ListStatePage",
 	})
 	var err error = nil
 	defer observ_utils.CloseSpanWithError(span, &err)
@@ -210,22 +216,24 @@ func (t *TargetsManager) ListState(ctx context.Context, namespace string) ([]mod
 			"namespace": namespace,
 			"kind":      "Target",
 		},
+		PageOptions: page,
 	}
 	var targets []states.StateEntry
-	targets, _, err = t.StateProvider.List(ctx, listRequest)
+	var token string
+	targets, token, err = t.StateProvider.List(ctx, listRequest)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	ret := make([]model.TargetState, 0)
 	for _, t := range targets {
 		var rt model.TargetState
 		rt, err = getTargetState(t.Body, t.ETag)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		ret = append(ret, rt)
 	}
-	return ret, nil
+	return ret, token, nil
 }
 
 func getTargetState(body interface{}, etag string) (model.TargetState, error) {
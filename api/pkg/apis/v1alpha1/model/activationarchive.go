@@ -0,0 +1,266 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+// RedactedValue replaces secret values in activation archives and deployment previews
+const RedactedValue = "***"
+
+// ActivationArchive is the compacted record of a completed activation, which is kept for audits after the activation
+// itself is deleted
+type ActivationArchive struct {
+	// ID identifies the archive, since an activation name can be reused after the activation is deleted
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	Namespace        string                 `json:"namespace,omitempty"`
+	Campaign         string                 `json:"campaign,omitempty"`
+	Inputs           map[string]interface{} `json:"inputs,omitempty"`
+	Status           v1alpha2.State         `json:"status,omitempty"`
+	StatusMessage    string                 `json:"statusMessage,omitempty"`
+	StartTime        string                 `json:"startTime,omitempty"`
+	EndTime          string                 `json:"endTime,omitempty"`
+	ArchiveTime      string                 `json:"archiveTime,omitempty"`
+	ParentActivation string                 `json:"parentActivation,omitempty"`
+	ChildActivations []string               `json:"childActivations,omitempty"`
+	Stages           []ArchivedStage        `json:"stages,omitempty"`
+}
+
+// ArchivedStage is the status of a stage of an archived activation, with the time span of its attempts
+type ArchivedStage struct {
+	Stage         string                 `json:"stage,omitempty"`
+	NextStage     string                 `json:"nextStage,omitempty"`
+	Status        v1alpha2.State         `json:"status,omitempty"`
+	StatusMessage string                 `json:"statusMessage,omitempty"`
+	ErrorMessage  string                 `json:"errorMessage,omitempty"`
+	StartTime     string                 `json:"startTime,omitempty"`
+	EndTime       string                 `json:"endTime,omitempty"`
+	Inputs        map[string]interface{} `json:"inputs,omitempty"`
+	Outputs       map[string]interface{} `json:"outputs,omitempty"`
+	Attempts      []StageAttempt         `json:"attempts,omitempty"`
+}
+
+// ActivationArchiveQuery selects archived activations. Empty fields match any archive.
+type ActivationArchiveQuery struct {
+	// Name is the name of the archived activation
+	Name     string
+	Campaign string
+	// Outcome is the status message of the activation, like "Done" or "Cancelled"
+	Outcome string
+	// Since and Until limit the end time of the activation
+	Since time.Time
+	Until time.Time
+}
+
+// Matches tells if the archive is selected by the query
+func (q ActivationArchiveQuery) Matches(archive ActivationArchive) bool {
+	if q.Name != "" && archive.Name != q.Name {
+		return false
+	}
+	if q.Campaign != "" && archive.Campaign != q.Campaign {
+		return false
+	}
+	if q.Outcome != "" && !strings.EqualFold(archive.StatusMessage, q.Outcome) {
+		return false
+	}
+	if !q.Since.IsZero() || !q.Until.IsZero() {
+		endTime, err := time.Parse(time.RFC3339, archive.EndTime)
+		if err != nil {
+			return false
+		}
+		if !q.Since.IsZero() && endTime.Before(q.Since) {
+			return false
+		}
+		if !q.Until.IsZero() && endTime.After(q.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewActivationArchive compacts a completed activation into an archive. The inputs and outputs are redacted: values of
+// keys that name a secret, the stage inputs in secretInputs (stage name to input keys) and any other copy of those
+// values are replaced by RedactedValue.
+func NewActivationArchive(activation ActivationState, secretInputs map[string][]string) ActivationArchive {
+	archive := ActivationArchive{
+		Name:        activation.ObjectMeta.Name,
+		Namespace:   activation.ObjectMeta.Namespace,
+		ArchiveTime: time.Now().UTC().Format(time.RFC3339),
+	}
+	archive.ID = archiveID(archive)
+	if activation.Spec != nil {
+		archive.Campaign = activation.Spec.Campaign
+	}
+	if activation.Status == nil {
+		if activation.Spec != nil {
+			archive.Inputs = redactValues(activation.Spec.Inputs, nil, nil)
+		}
+		return archive
+	}
+	archive.Status = activation.Status.Status
+	archive.StatusMessage = activation.Status.StatusMessage
+	archive.EndTime = activation.Status.UpdateTime
+	archive.ID = archiveID(archive)
+	archive.ParentActivation = activation.Status.ParentActivation
+	archive.ChildActivations = activation.Status.ChildActivations
+
+	// collect the secret values first, so that their copies in other inputs and outputs are redacted too
+	collected := map[string]bool{}
+	for _, stage := range activation.Status.StageHistory {
+		for _, key := range secretInputs[stage.Stage] {
+			collectSecrets(stage.Inputs[key], collected)
+		}
+		collectNamedSecrets(stage.Inputs, collected)
+	}
+	if activation.Spec != nil {
+		collectNamedSecrets(activation.Spec.Inputs, collected)
+	}
+	// longer secrets are redacted first, so that a secret that contains a shorter one is redacted as a whole
+	secrets := make([]string, 0, len(collected))
+	for secret := range collected {
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		if len(secrets[i]) != len(secrets[j]) {
+			return len(secrets[i]) > len(secrets[j])
+		}
+		return secrets[i] < secrets[j]
+	})
+	if activation.Spec != nil {
+		archive.Inputs = redactValues(activation.Spec.Inputs, nil, secrets)
+	}
+
+	for _, stage := range activation.Status.StageHistory {
+		archived := ArchivedStage{
+			Stage:         stage.Stage,
+			NextStage:     stage.NextStage,
+			Status:        stage.Status,
+			StatusMessage: stage.StatusMessage,
+			ErrorMessage:  stage.ErrorMessage,
+			Inputs:        redactValues(stage.Inputs, secretInputs[stage.Stage], secrets),
+			Outputs:       redactValues(stage.Outputs, nil, secrets),
+			Attempts:      stage.Attempts,
+		}
+		for _, attempt := range stage.Attempts {
+			if attempt.StartTime != "" && (archived.StartTime == "" || attempt.StartTime < archived.StartTime) {
+				archived.StartTime = attempt.StartTime
+			}
+			if attempt.EndTime > archived.EndTime {
+				archived.EndTime = attempt.EndTime
+			}
+		}
+		if archived.StartTime != "" && (archive.StartTime == "" || archived.StartTime < archive.StartTime) {
+			archive.StartTime = archived.StartTime
+		}
+		archive.Stages = append(archive.Stages, archived)
+	}
+	return archive
+}
+
+// archiveID is the activation name with the time the activation finished, or with the archive time if the activation
+// didn't record when it finished
+func archiveID(archive ActivationArchive) string {
+	t, err := time.Parse(time.RFC3339, archive.EndTime)
+	if err != nil {
+		t, _ = time.Parse(time.RFC3339, archive.ArchiveTime)
+	}
+	return fmt.Sprintf("%s-%d", archive.Name, t.Unix())
+}
+
+// isSecretKey tells if an input or output key names a secret, like "password" or "apiToken"
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"password", "secret", "token", "credential", "apikey", "connectionstring"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectNamedSecrets collects the values of the keys that name a secret, at any depth
+func collectNamedSecrets(value interface{}, secrets map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if isSecretKey(k) {
+				collectSecrets(item, secrets)
+			} else {
+				collectNamedSecrets(item, secrets)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectNamedSecrets(item, secrets)
+		}
+	}
+}
+
+func collectSecrets(value interface{}, secrets map[string]bool) {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			secrets[v] = true
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			collectSecrets(item, secrets)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectSecrets(item, secrets)
+		}
+	}
+}
+
+func redactValues(values map[string]interface{}, secretKeys []string, secrets []string) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+	ret := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		redacted := isSecretKey(k)
+		for _, key := range secretKeys {
+			if key == k {
+				redacted = true
+			}
+		}
+		if redacted {
+			ret[k] = RedactedValue
+		} else {
+			ret[k] = redactValue(v, secrets)
+		}
+	}
+	return ret
+}
+
+// redactValue replaces every occurrence of a secret in a value, such as a token in a URL or a password in a log line
+func redactValue(value interface{}, secrets []string) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, secret := range secrets {
+			v = strings.ReplaceAll(v, secret, RedactedValue)
+		}
+		return v
+	case map[string]interface{}:
+		return redactValues(v, nil, secrets)
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, item := range v {
+			ret[i] = redactValue(item, secrets)
+		}
+		return ret
+	}
+	return value
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewActivationArchiveRedactsSecrets(t *testing.T) {
+	archive := NewActivationArchive(ActivationState{
+		ObjectMeta: ObjectMeta{Name: "a1", Namespace: "default"},
+		Spec: &ActivationSpec{
+			Campaign: "c1:v1",
+			Inputs: map[string]interface{}{
+				"dbPassword": "hunter2",
+				"region":     "west",
+			},
+		},
+		Status: &ActivationStatus{
+			Status:        v1alpha2.Done,
+			StatusMessage: v1alpha2.Done.String(),
+			UpdateTime:    "2024-01-01T00:10:00Z",
+			StageHistory: []StageStatus{
+				{
+					Stage: "s1",
+					Inputs: map[string]interface{}{
+						"conn":     "server=db;pwd=x",
+						"settings": map[string]interface{}{"authToken": "t0k"},
+					},
+					Outputs: map[string]interface{}{
+						"copy":  []interface{}{"hunter2", "t0k", "server=db;pwd=x"},
+						"url":   "https://db.local/?token=t0k&user=admin",
+						"log":   "connecting with server=db;pwd=x",
+						"count": 3,
+					},
+				},
+			},
+		},
+	}, map[string][]string{"s1": {"conn"}})
+
+	assert.Equal(t, "c1:v1", archive.Campaign)
+	assert.Equal(t, RedactedValue, archive.Inputs["dbPassword"])
+	assert.Equal(t, "west", archive.Inputs["region"])
+	assert.Equal(t, RedactedValue, archive.Stages[0].Inputs["conn"])
+	assert.Equal(t, map[string]interface{}{"authToken": RedactedValue}, archive.Stages[0].Inputs["settings"])
+	assert.Equal(t, []interface{}{RedactedValue, RedactedValue, RedactedValue}, archive.Stages[0].Outputs["copy"])
+	assert.Equal(t, "https://db.local/?token=***&user=admin", archive.Stages[0].Outputs["url"])
+	assert.Equal(t, "connecting with ***", archive.Stages[0].Outputs["log"])
+	assert.Equal(t, 3, archive.Stages[0].Outputs["count"])
+	assert.Equal(t, "a1-1704067800", archive.ID)
+}
+
+func TestActivationArchiveQueryMatches(t *testing.T) {
+	archive := ActivationArchive{
+		Name:          "a1",
+		Campaign:      "c1:v1",
+		StatusMessage: v1alpha2.Done.String(),
+		EndTime:       "2024-01-01T00:10:00Z",
+	}
+	assert.True(t, ActivationArchiveQuery{}.Matches(archive))
+	assert.True(t, ActivationArchiveQuery{Campaign: "c1:v1", Outcome: "done"}.Matches(archive))
+	assert.False(t, ActivationArchiveQuery{Campaign: "c2:v1"}.Matches(archive))
+	assert.True(t, ActivationArchiveQuery{Name: "a1"}.Matches(archive))
+	assert.False(t, ActivationArchiveQuery{Name: "a2"}.Matches(archive))
+	assert.False(t, ActivationArchiveQuery{Outcome: v1alpha2.Cancelled.String()}.Matches(archive))
+	assert.True(t, ActivationArchiveQuery{
+		Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}.Matches(archive))
+	assert.False(t, ActivationArchiveQuery{Since: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}.Matches(archive))
+}
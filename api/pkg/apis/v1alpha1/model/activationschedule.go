@@ -0,0 +1,292 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/constants"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+// Concurrency policies of an activation schedule, which decide what happens when the schedule fires while an activation
+// it created before is still running
+const (
+	// ConcurrencyAllow creates the new activation next to the running ones
+	ConcurrencyAllow = "Allow"
+	// ConcurrencyForbid skips the new activation
+	ConcurrencyForbid = "Forbid"
+	// ConcurrencyReplace cancels the running activations and creates the new one
+	ConcurrencyReplace = "Replace"
+)
+
+const (
+	DefaultSuccessfulHistoryLimit = 3
+	DefaultFailedHistoryLimit     = 1
+)
+
+// ActivationScheduleState creates activations of a campaign on a cron schedule
+type ActivationScheduleState struct {
+	ObjectMeta ObjectMeta                `json:"metadata,omitempty"`
+	Spec       *ActivationScheduleSpec   `json:"spec,omitempty"`
+	Status     *ActivationScheduleStatus `json:"status,omitempty"`
+}
+
+type ActivationScheduleSpec struct {
+	// Schedule is a cron expression for the times an activation is created
+	Schedule string `json:"schedule,omitempty"`
+	// TimeZone is the IANA time zone the schedule is evaluated in, UTC by default
+	TimeZone string                 `json:"timeZone,omitempty"`
+	Campaign string                 `json:"campaign,omitempty"`
+	Stage    string                 `json:"stage,omitempty"`
+	Inputs   map[string]interface{} `json:"inputs,omitempty"`
+	// ConcurrencyPolicy is Allow, Forbid or Replace, Allow by default
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+	// Suspend stops the schedule from creating activations. Occurrences missed while the schedule is suspended are
+	// skipped.
+	Suspend bool `json:"suspend,omitempty"`
+	// SuccessfulHistoryLimit and FailedHistoryLimit are the numbers of finished activations that are kept, the older
+	// ones are deleted
+	SuccessfulHistoryLimit *int `json:"successfulHistoryLimit,omitempty"`
+	FailedHistoryLimit     *int `json:"failedHistoryLimit,omitempty"`
+}
+
+type ActivationScheduleStatus struct {
+	LastScheduleTime string `json:"lastScheduleTime,omitempty"`
+	NextScheduleTime string `json:"nextScheduleTime,omitempty"`
+	// Active are the activations created by the schedule that haven't finished yet
+	Active []string `json:"active,omitempty"`
+	// Succeeded and Failed are the finished activations that are kept, the oldest first
+	Succeeded []string `json:"succeeded,omitempty"`
+	Failed    []string `json:"failed,omitempty"`
+}
+
+// Validate checks the schedule, the concurrency policy and the history limits
+func (s ActivationScheduleSpec) Validate() error {
+	if s.Campaign == "" {
+		return errors.New("campaign is required")
+	}
+	if !v1alpha2.IsCronSchedule(s.Schedule) {
+		return fmt.Errorf("schedule '%s' is not a cron expression", s.Schedule)
+	}
+	if _, err := v1alpha2.ParseCronSchedule(s.Schedule, s.TimeZone); err != nil {
+		return err
+	}
+	switch s.ConcurrencyPolicy {
+	case "", ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace:
+	default:
+		return fmt.Errorf("concurrencyPolicy '%s' is not one of %s, %s or %s", s.ConcurrencyPolicy, ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace)
+	}
+	if s.SuccessfulHistoryLimit != nil && *s.SuccessfulHistoryLimit < 0 {
+		return fmt.Errorf("successfulHistoryLimit %d is negative", *s.SuccessfulHistoryLimit)
+	}
+	if s.FailedHistoryLimit != nil && *s.FailedHistoryLimit < 0 {
+		return fmt.Errorf("failedHistoryLimit %d is negative", *s.FailedHistoryLimit)
+	}
+	return nil
+}
+
+// HistoryLimits returns the numbers of succeeded and failed activations that are kept
+func (s ActivationScheduleSpec) HistoryLimits() (int, int) {
+	succeeded := DefaultSuccessfulHistoryLimit
+	if s.SuccessfulHistoryLimit != nil {
+		succeeded = *s.SuccessfulHistoryLimit
+	}
+	failed := DefaultFailedHistoryLimit
+	if s.FailedHistoryLimit != nil {
+		failed = *s.FailedHistoryLimit
+	}
+	return succeeded, failed
+}
+
+func (s ActivationScheduleSpec) DeepEquals(other IDeepEquals) (bool, error) {
+	otherS, ok := other.(ActivationScheduleSpec)
+	if !ok {
+		return false, errors.New("parameter is not a ActivationScheduleSpec type")
+	}
+	if s.Schedule != otherS.Schedule || s.TimeZone != otherS.TimeZone {
+		return false, nil
+	}
+	if s.Campaign != otherS.Campaign || s.Stage != otherS.Stage {
+		return false, nil
+	}
+	if !reflect.DeepEqual(s.Inputs, otherS.Inputs) {
+		return false, nil
+	}
+	if s.ConcurrencyPolicy != otherS.ConcurrencyPolicy || s.Suspend != otherS.Suspend {
+		return false, nil
+	}
+	if !reflect.DeepEqual(s.SuccessfulHistoryLimit, otherS.SuccessfulHistoryLimit) {
+		return false, nil
+	}
+	if !reflect.DeepEqual(s.FailedHistoryLimit, otherS.FailedHistoryLimit) {
+		return false, nil
+	}
+	return true, nil
+}
+
+func (s ActivationScheduleState) DeepEquals(other IDeepEquals) (bool, error) {
+	otherS, ok := other.(ActivationScheduleState)
+	if !ok {
+		return false, errors.New("parameter is not a ActivationScheduleState type")
+	}
+	equal, err := s.ObjectMeta.DeepEquals(otherS.ObjectMeta)
+	if err != nil || !equal {
+		return equal, err
+	}
+	if s.Spec == nil || otherS.Spec == nil {
+		return s.Spec == otherS.Spec, nil
+	}
+	return s.Spec.DeepEquals(*otherS.Spec)
+}
+
+// ScheduledActivation is an activation created by a schedule
+type ScheduledActivation struct {
+	Name      string
+	Finished  bool
+	Succeeded bool
+}
+
+// NewScheduledActivation sums up an activation for its schedule. An activation succeeded if it's done.
+func NewScheduledActivation(name string, status *ActivationStatus) ScheduledActivation {
+	if status == nil || status.IsActive() {
+		return ScheduledActivation{Name: name}
+	}
+	return ScheduledActivation{
+		Name:      name,
+		Finished:  true,
+		Succeeded: status.Status == v1alpha2.Done,
+	}
+}
+
+// ActivationSchedulePlan is what a schedule does at a point in time, and its status afterwards
+type ActivationSchedulePlan struct {
+	// Create is the name of the activation to create, if the schedule fires
+	Create string
+	// Cancel are the running activations that are replaced by the new activation
+	Cancel []string
+	// Delete are the finished activations beyond the history limits
+	Delete []string
+	Status ActivationScheduleStatus
+}
+
+// ActivationScheduleName returns the name of the activation a schedule creates for an occurrence
+func ActivationScheduleName(schedule string, occurrence time.Time) string {
+	return fmt.Sprintf("%s-%d", schedule, occurrence.Unix()/60)
+}
+
+// Plan decides what the schedule does at the given time, given the activations it created before. A schedule that
+// hasn't run before starts counting its occurrences from now. When several occurrences were missed, only the latest
+// one creates an activation.
+func (s ActivationScheduleState) Plan(activations []ScheduledActivation, now time.Time) (ActivationSchedulePlan, error) {
+	plan := ActivationSchedulePlan{}
+	if s.Spec == nil {
+		return plan, errors.New("activation schedule has no spec")
+	}
+	cron, err := v1alpha2.ParseCronSchedule(s.Spec.Schedule, s.Spec.TimeZone)
+	if err != nil {
+		return plan, err
+	}
+
+	// activation names end with the minute of their occurrence, so they sort from the oldest
+	sorted := make([]ScheduledActivation, len(activations))
+	copy(sorted, activations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return scheduledBefore(sorted[i].Name, sorted[j].Name)
+	})
+	active := make([]string, 0)
+	succeeded := make([]string, 0)
+	failed := make([]string, 0)
+	for _, activation := range sorted {
+		switch {
+		case !activation.Finished:
+			active = append(active, activation.Name)
+		case activation.Succeeded:
+			succeeded = append(succeeded, activation.Name)
+		default:
+			failed = append(failed, activation.Name)
+		}
+	}
+
+	last := now
+	if s.Status != nil && s.Status.LastScheduleTime != "" {
+		if last, err = time.Parse(time.RFC3339, s.Status.LastScheduleTime); err != nil {
+			return plan, fmt.Errorf("invalid lastScheduleTime '%s': %v", s.Status.LastScheduleTime, err)
+		}
+		var due time.Time
+		for next := cron.Next(last); !next.IsZero() && !next.After(now); next = cron.Next(next) {
+			due = next
+		}
+		if !due.IsZero() {
+			last = due
+			switch {
+			case s.Spec.Suspend:
+				// a suspended schedule skips the occurrence
+			case len(active) > 0 && s.Spec.ConcurrencyPolicy == ConcurrencyForbid:
+				// the running activation keeps going and the occurrence is skipped
+			default:
+				if s.Spec.ConcurrencyPolicy == ConcurrencyReplace {
+					plan.Cancel = active
+					failed = append(failed, active...)
+					active = make([]string, 0)
+				}
+				plan.Create = ActivationScheduleName(s.ObjectMeta.Name, due)
+				active = append(active, plan.Create)
+			}
+		}
+	}
+	plan.Status.LastScheduleTime = last.UTC().Format(time.RFC3339)
+	if next := cron.Next(last); !next.IsZero() {
+		plan.Status.NextScheduleTime = next.UTC().Format(time.RFC3339)
+	}
+
+	succeededLimit, failedLimit := s.Spec.HistoryLimits()
+	if len(succeeded) > succeededLimit {
+		plan.Delete = append(plan.Delete, succeeded[:len(succeeded)-succeededLimit]...)
+		succeeded = succeeded[len(succeeded)-succeededLimit:]
+	}
+	if len(failed) > failedLimit {
+		plan.Delete = append(plan.Delete, failed[:len(failed)-failedLimit]...)
+		failed = failed[len(failed)-failedLimit:]
+	}
+	plan.Status.Active = active
+	plan.Status.Succeeded = succeeded
+	plan.Status.Failed = failed
+	return plan, nil
+}
+
+// NewActivation returns the activation the schedule creates with the given name
+func (s ActivationScheduleState) NewActivation(name string) ActivationState {
+	activation := ActivationState{
+		ObjectMeta: ObjectMeta{
+			Name:      name,
+			Namespace: s.ObjectMeta.Namespace,
+			Labels: map[string]string{
+				constants.ActivationSchedule: s.ObjectMeta.Name,
+			},
+		},
+		Spec: &ActivationSpec{},
+	}
+	if s.Spec != nil {
+		activation.Spec.Campaign = s.Spec.Campaign
+		activation.Spec.Stage = s.Spec.Stage
+		activation.Spec.Inputs = s.Spec.Inputs
+	}
+	return activation
+}
+
+// scheduledBefore compares activation names by their length first, so that the minutes at their end compare as numbers
+func scheduledBefore(a string, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/constants"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/stretchr/testify/assert"
+)
+
+func hourlySchedule(policy string, lastScheduleTime string) ActivationScheduleState {
+	return ActivationScheduleState{
+		ObjectMeta: ObjectMeta{
+			Name:      "drift",
+			Namespace: "default",
+		},
+		Spec: &ActivationScheduleSpec{
+			Schedule:          "0 * * * *",
+			Campaign:          "drift-detection:v1",
+			ConcurrencyPolicy: policy,
+			Inputs: map[string]interface{}{
+				"scope": "all",
+			},
+		},
+		Status: &ActivationScheduleStatus{
+			LastScheduleTime: lastScheduleTime,
+		},
+	}
+}
+
+func TestActivationScheduleValidate(t *testing.T) {
+	spec := hourlySchedule(ConcurrencyForbid, "").Spec
+	assert.Nil(t, spec.Validate())
+
+	negative := -1
+	for _, invalid := range []ActivationScheduleSpec{
+		{Schedule: "0 * * * *"},
+		{Schedule: "2024-01-01T00:00:00Z", Campaign: "c"},
+		{Schedule: "0 25 * * *", Campaign: "c"},
+		{Schedule: "0 * * * *", Campaign: "c", TimeZone: "Nowhere/Town"},
+		{Schedule: "0 * * * *", Campaign: "c", ConcurrencyPolicy: "Queue"},
+		{Schedule: "0 * * * *", Campaign: "c", FailedHistoryLimit: &negative},
+	} {
+		assert.NotNil(t, invalid.Validate(), "%+v", invalid)
+	}
+}
+
+func TestActivationSchedulePlanStartsFromNow(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	plan, err := hourlySchedule("", "").Plan(nil, now)
+	assert.Nil(t, err)
+	assert.Equal(t, "", plan.Create)
+	assert.Equal(t, "2024-05-01T10:30:00Z", plan.Status.LastScheduleTime)
+	assert.Equal(t, "2024-05-01T11:00:00Z", plan.Status.NextScheduleTime)
+}
+
+func TestActivationSchedulePlanFiresLatestOccurrence(t *testing.T) {
+	now := time.Date(2024, 5, 1, 13, 5, 0, 0, time.UTC)
+	plan, err := hourlySchedule("", "2024-05-01T10:30:00Z").Plan(nil, now)
+	assert.Nil(t, err)
+	occurrence := time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)
+	assert.Equal(t, ActivationScheduleName("drift", occurrence), plan.Create)
+	assert.Equal(t, "2024-05-01T13:00:00Z", plan.Status.LastScheduleTime)
+	assert.Equal(t, "2024-05-01T14:00:00Z", plan.Status.NextScheduleTime)
+	assert.Equal(t, []string{plan.Create}, plan.Status.Active)
+
+	plan, err = hourlySchedule("", "2024-05-01T13:00:00Z").Plan(nil, now)
+	assert.Nil(t, err)
+	assert.Equal(t, "", plan.Create)
+	assert.Equal(t, "2024-05-01T13:00:00Z", plan.Status.LastScheduleTime)
+}
+
+func TestActivationScheduleConcurrencyPolicy(t *testing.T) {
+	now := time.Date(2024, 5, 1, 13, 5, 0, 0, time.UTC)
+	running := []ScheduledActivation{{Name: "drift-28576080"}}
+
+	plan, err := hourlySchedule(ConcurrencyAllow, "2024-05-01T12:00:00Z").Plan(running, now)
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", plan.Create)
+	assert.Equal(t, []string{"drift-28576080", plan.Create}, plan.Status.Active)
+
+	plan, err = hourlySchedule(ConcurrencyForbid, "2024-05-01T12:00:00Z").Plan(running, now)
+	assert.Nil(t, err)
+	assert.Equal(t, "", plan.Create)
+	assert.Equal(t, []string{"drift-28576080"}, plan.Status.Active)
+	assert.Equal(t, "2024-05-01T13:00:00Z", plan.Status.LastScheduleTime)
+
+	plan, err = hourlySchedule(ConcurrencyReplace, "2024-05-01T12:00:00Z").Plan(running, now)
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", plan.Create)
+	assert.Equal(t, []string{"drift-28576080"}, plan.Cancel)
+	assert.Equal(t, []string{plan.Create}, plan.Status.Active)
+	assert.Equal(t, []string{"drift-28576080"}, plan.Status.Failed)
+}
+
+func TestActivationScheduleSuspend(t *testing.T) {
+	schedule := hourlySchedule("", "2024-05-01T12:00:00Z")
+	schedule.Spec.Suspend = true
+	plan, err := schedule.Plan(nil, time.Date(2024, 5, 1, 13, 5, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, "", plan.Create)
+	assert.Equal(t, "2024-05-01T13:00:00Z", plan.Status.LastScheduleTime)
+}
+
+func TestActivationScheduleHistoryLimits(t *testing.T) {
+	schedule := hourlySchedule("", "2024-05-01T13:00:00Z")
+	succeededLimit := 2
+	schedule.Spec.SuccessfulHistoryLimit = &succeededLimit
+	plan, err := schedule.Plan([]ScheduledActivation{
+		{Name: "drift-28576080", Finished: true, Succeeded: true},
+		{Name: "drift-28575960", Finished: true, Succeeded: true},
+		{Name: "drift-28576020", Finished: true},
+		{Name: "drift-28576140", Finished: true, Succeeded: true},
+		{Name: "drift-28575900", Finished: true},
+	}, time.Date(2024, 5, 1, 13, 5, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"drift-28575960", "drift-28575900"}, plan.Delete)
+	assert.Equal(t, []string{"drift-28576080", "drift-28576140"}, plan.Status.Succeeded)
+	assert.Equal(t, []string{"drift-28576020"}, plan.Status.Failed)
+}
+
+func TestNewScheduledActivation(t *testing.T) {
+	assert.False(t, NewScheduledActivation("a", nil).Finished)
+	assert.False(t, NewScheduledActivation("a", &ActivationStatus{Status: v1alpha2.Running}).Finished)
+	done := NewScheduledActivation("a", &ActivationStatus{Status: v1alpha2.Done})
+	assert.True(t, done.Finished)
+	assert.True(t, done.Succeeded)
+	failed := NewScheduledActivation("a", &ActivationStatus{Status: v1alpha2.InternalError})
+	assert.True(t, failed.Finished)
+	assert.False(t, failed.Succeeded)
+}
+
+func TestActivationScheduleNewActivation(t *testing.T) {
+	activation := hourlySchedule("", "").NewActivation("drift-28576140")
+	assert.Equal(t, "drift-28576140", activation.ObjectMeta.Name)
+	assert.Equal(t, "default", activation.ObjectMeta.Namespace)
+	assert.Equal(t, "drift", activation.ObjectMeta.Labels[constants.ActivationSchedule])
+	assert.Equal(t, "drift-detection:v1", activation.Spec.Campaign)
+	assert.Equal(t, "all", activation.Spec.Inputs["scope"])
+}
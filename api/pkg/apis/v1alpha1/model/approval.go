@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"fmt"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+// Outputs of an approval stage. A paused approval stage records who may decide on it, and the decision records who
+// decided, when and why.
+const (
+	ApprovalOutput        = "approval"
+	ApproversOutput       = "approvers"
+	ApprovalGroupsOutput  = "groups"
+	ApproverOutput        = "approver"
+	ApprovalCommentOutput = "comment"
+	ApprovalTimeOutput    = "decisionTime"
+
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// ApprovalDecision is the body of an approve or reject call on an activation that waits for an approval
+type ApprovalDecision struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// IsPendingApproval tells if the stage is an approval stage that is paused until a decision arrives
+func (s StageStatus) IsPendingApproval() bool {
+	return s.Status == v1alpha2.Paused && s.Outputs != nil && s.Outputs[ApprovalOutput] == ApprovalPending
+}
+
+// CanApprove tells if the identity is one of the approvers of the stage, or has a role that is one of its groups
+func (s StageStatus) CanApprove(identity v1alpha2.Identity) bool {
+	if identity.User == "" {
+		return false
+	}
+	for _, approver := range ToStringList(s.Outputs[ApproversOutput]) {
+		if approver == identity.User {
+			return true
+		}
+	}
+	for _, group := range ToStringList(s.Outputs[ApprovalGroupsOutput]) {
+		for _, role := range identity.Roles {
+			if group == role {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ToStringList reads a list of strings from a value that is either a []string or a JSON array
+func ToStringList(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		ret := make([]string, 0, len(v))
+		for _, item := range v {
+			ret = append(ret, fmt.Sprintf("%v", item))
+		}
+		return ret
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPendingApproval(t *testing.T) {
+	status := StageStatus{
+		Status: v1alpha2.Paused,
+		Outputs: map[string]interface{}{
+			ApprovalOutput: ApprovalPending,
+		},
+	}
+	assert.True(t, status.IsPendingApproval())
+
+	status.Outputs[ApprovalOutput] = ApprovalApproved
+	assert.False(t, status.IsPendingApproval())
+
+	status = StageStatus{Status: v1alpha2.Paused}
+	assert.False(t, status.IsPendingApproval())
+}
+
+func TestCanApprove(t *testing.T) {
+	status := StageStatus{
+		Status: v1alpha2.Paused,
+		Outputs: map[string]interface{}{
+			ApprovalOutput:       ApprovalPending,
+			ApproversOutput:      []interface{}{"alice"},
+			ApprovalGroupsOutput: []string{"release-managers"},
+		},
+	}
+	assert.True(t, status.CanApprove(v1alpha2.Identity{User: "alice"}))
+	assert.True(t, status.CanApprove(v1alpha2.Identity{User: "bob", Roles: []string{"operator", "release-managers"}}))
+	assert.False(t, status.CanApprove(v1alpha2.Identity{User: "bob", Roles: []string{"operator"}}))
+	assert.False(t, status.CanApprove(v1alpha2.Identity{Roles: []string{"release-managers"}}))
+}
@@ -10,7 +10,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
@@ -34,7 +37,152 @@ type StageSpec struct {
 	StageSelector string                 `json:"stageSelector,omitempty"`
 	Inputs        map[string]interface{} `json:"inputs,omitempty"`
 	HandleErrors  bool                   `json:"handleErrors,omitempty"`
-	Schedule      string                 `json:"schedule,omitempty"`
+	// Schedule is either an RFC 3339 timestamp or a cron expression
+	Schedule string `json:"schedule,omitempty"`
+	// TimeZone is the IANA time zone the cron expressions are evaluated in, UTC by default
+	TimeZone           string                       `json:"timeZone,omitempty"`
+	MaintenanceWindows []v1alpha2.MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// Timeout limits each run of the stage provider for a site, as a duration like "10m"
+	Timeout string `json:"timeout,omitempty"`
+	// MaxRetries is the number of times a failed or timed out stage provider is run again before the stage fails
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// RetryBackoff is the wait before the first retry, which is doubled on each retry up to MaxRetryBackoff
+	RetryBackoff    string `json:"retryBackoff,omitempty"`
+	MaxRetryBackoff string `json:"maxRetryBackoff,omitempty"`
+	// MaxParallel limits the number of sites of the contexts the stage runs for at once, no limit by default
+	MaxParallel int `json:"maxParallel,omitempty"`
+	// SuccessThreshold is the number of sites, like "498", or the percentage of sites, like "99%", that have to succeed
+	// for the stage to succeed. All sites have to succeed by default.
+	SuccessThreshold string `json:"successThreshold,omitempty"`
+}
+
+// SiteResultsOutput is the stage output with the status and error of each site of the stage contexts
+const SiteResultsOutput = "siteResults"
+
+const (
+	DefaultRetryBackoff    = 5 * time.Second
+	DefaultMaxRetryBackoff = 5 * time.Minute
+)
+
+// StageRetryPolicy is the parsed timeout and retry policy of a stage
+type StageRetryPolicy struct {
+	Timeout         time.Duration
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// Backoff returns the wait before the given retry, starting from 1
+func (p StageRetryPolicy) Backoff(retry int) time.Duration {
+	backoff := p.RetryBackoff
+	for i := 1; i < retry && backoff < p.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.MaxRetryBackoff {
+		backoff = p.MaxRetryBackoff
+	}
+	return backoff
+}
+
+// GetRetryPolicy parses the timeout and retry fields of the stage. A zero Timeout means the stage provider isn't
+// limited.
+func (s StageSpec) GetRetryPolicy() (StageRetryPolicy, error) {
+	policy := StageRetryPolicy{
+		MaxRetries:      s.MaxRetries,
+		RetryBackoff:    DefaultRetryBackoff,
+		MaxRetryBackoff: DefaultMaxRetryBackoff,
+	}
+	if s.MaxRetries < 0 {
+		return policy, fmt.Errorf("maxRetries %d is negative", s.MaxRetries)
+	}
+	var err error
+	if s.Timeout != "" {
+		if policy.Timeout, err = parsePositiveDuration("timeout", s.Timeout); err != nil {
+			return policy, err
+		}
+	}
+	if s.RetryBackoff != "" {
+		if policy.RetryBackoff, err = parsePositiveDuration("retryBackoff", s.RetryBackoff); err != nil {
+			return policy, err
+		}
+	}
+	if s.MaxRetryBackoff != "" {
+		if policy.MaxRetryBackoff, err = parsePositiveDuration("maxRetryBackoff", s.MaxRetryBackoff); err != nil {
+			return policy, err
+		}
+	}
+	if policy.MaxRetryBackoff < policy.RetryBackoff {
+		policy.MaxRetryBackoff = policy.RetryBackoff
+	}
+	return policy, nil
+}
+
+// StageFanOutPolicy is the parsed concurrency and success policy of a stage that runs for multiple sites
+type StageFanOutPolicy struct {
+	// MaxParallel is the number of sites the stage runs for at once, 0 means no limit
+	MaxParallel int
+	// SuccessCount or SuccessPercent is the threshold of succeeded sites, both are 0 when all sites have to succeed
+	SuccessCount   int
+	SuccessPercent float64
+}
+
+// RequiredSuccesses returns the number of sites that have to succeed out of the given number of sites. A count that is
+// larger than the number of sites requires all sites to succeed.
+func (p StageFanOutPolicy) RequiredSuccesses(sites int) int {
+	required := sites
+	if p.SuccessPercent > 0 {
+		required = int(math.Ceil(float64(sites) * p.SuccessPercent / 100))
+	} else if p.SuccessCount > 0 {
+		required = p.SuccessCount
+	}
+	if required > sites {
+		required = sites
+	}
+	return required
+}
+
+// HasThreshold tells if the stage tolerates failed sites
+func (p StageFanOutPolicy) HasThreshold() bool {
+	return p.SuccessCount > 0 || p.SuccessPercent > 0
+}
+
+// GetFanOutPolicy parses the maxParallel and successThreshold fields of the stage
+func (s StageSpec) GetFanOutPolicy() (StageFanOutPolicy, error) {
+	policy := StageFanOutPolicy{
+		MaxParallel: s.MaxParallel,
+	}
+	if s.MaxParallel < 0 {
+		return policy, fmt.Errorf("maxParallel %d is negative", s.MaxParallel)
+	}
+	threshold := strings.TrimSpace(s.SuccessThreshold)
+	if threshold == "" {
+		return policy, nil
+	}
+	if strings.HasSuffix(threshold, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(threshold, "%")), 64)
+		if err != nil || percent <= 0 || percent > 100 {
+			return policy, fmt.Errorf("successThreshold %s is not a percentage between 0%% and 100%%", s.SuccessThreshold)
+		}
+		policy.SuccessPercent = percent
+		return policy, nil
+	}
+	count, err := strconv.Atoi(threshold)
+	if err != nil || count <= 0 {
+		return policy, fmt.Errorf("successThreshold %s is not a positive number of sites or a percentage", s.SuccessThreshold)
+	}
+	policy.SuccessCount = count
+	return policy, nil
+}
+
+func parsePositiveDuration(field string, value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s %s is not a valid duration", field, value)
+	}
+	if duration <= 0 {
+		return 0, fmt.Errorf("%s %s is not positive", field, value)
+	}
+	return duration, nil
 }
 
 // UnmarshalJSON customizes the JSON unmarshalling for StageSpec
@@ -48,11 +196,15 @@ func (s *StageSpec) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
-	// validate if Schedule meet RFC 3339
-	if s.Schedule != "" {
-		if _, err := time.Parse(time.RFC3339, s.Schedule); err != nil {
-			return v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid timestamp format: %v", err), v1alpha2.BadConfig)
-		}
+	// validate if Schedule is an RFC 3339 timestamp or a cron expression
+	if err := v1alpha2.ValidateSchedule(s.Schedule, s.TimeZone, s.MaintenanceWindows); err != nil {
+		return v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid schedule: %v", err), v1alpha2.BadConfig)
+	}
+	if _, err := s.GetRetryPolicy(); err != nil {
+		return v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid retry policy: %v", err), v1alpha2.BadConfig)
+	}
+	if _, err := s.GetFanOutPolicy(); err != nil {
+		return v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid fan-out policy: %v", err), v1alpha2.BadConfig)
 	}
 	return nil
 }
@@ -60,10 +212,14 @@ func (s *StageSpec) UnmarshalJSON(data []byte) error {
 // MarshalJSON customizes the JSON marshalling for StageSpec
 func (s StageSpec) MarshalJSON() ([]byte, error) {
 	type Alias StageSpec
-	if s.Schedule != "" {
-		if _, err := time.Parse(time.RFC3339, s.Schedule); err != nil {
-			return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid timestamp format: %v", err), v1alpha2.BadConfig)
-		}
+	if err := v1alpha2.ValidateSchedule(s.Schedule, s.TimeZone, s.MaintenanceWindows); err != nil {
+		return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid schedule: %v", err), v1alpha2.BadConfig)
+	}
+	if _, err := s.GetRetryPolicy(); err != nil {
+		return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid retry policy: %v", err), v1alpha2.BadConfig)
+	}
+	if _, err := s.GetFanOutPolicy(); err != nil {
+		return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid fan-out policy: %v", err), v1alpha2.BadConfig)
 	}
 	return json.Marshal(&struct {
 		*Alias
@@ -102,6 +258,38 @@ func (s StageSpec) DeepEquals(other IDeepEquals) (bool, error) {
 		return false, nil
 	}
 
+	if s.TimeZone != otherS.TimeZone {
+		return false, nil
+	}
+
+	if !reflect.DeepEqual(s.MaintenanceWindows, otherS.MaintenanceWindows) {
+		return false, nil
+	}
+
+	if s.Timeout != otherS.Timeout {
+		return false, nil
+	}
+
+	if s.MaxRetries != otherS.MaxRetries {
+		return false, nil
+	}
+
+	if s.RetryBackoff != otherS.RetryBackoff {
+		return false, nil
+	}
+
+	if s.MaxRetryBackoff != otherS.MaxRetryBackoff {
+		return false, nil
+	}
+
+	if s.MaxParallel != otherS.MaxParallel {
+		return false, nil
+	}
+
+	if s.SuccessThreshold != otherS.SuccessThreshold {
+		return false, nil
+	}
+
 	return true, nil
 }
 
@@ -111,7 +299,20 @@ type ActivationStatus struct {
 	Status               v1alpha2.State `json:"status,omitempty"`
 	StatusMessage        string         `json:"statusMessage,omitempty"`
 	StageHistory         []StageStatus  `json:"stageHistory,omitempty"`
+	// PauseRequested is set when an operator pauses the activation. The running stage finishes, and the activation
+	// stops before its next stage until it's resumed.
+	PauseRequested bool `json:"pauseRequested,omitempty"`
+	// ParentActivation is the activation that started this activation from a campaign stage
+	ParentActivation string `json:"parentActivation,omitempty"`
+	// ChildActivations are the activations started by the campaign stages of this activation
+	ChildActivations []string `json:"childActivations,omitempty"`
 }
+
+// IsActive checks if the activation hasn't finished yet, so it can still be cancelled or paused
+func (s ActivationStatus) IsActive() bool {
+	return s.Status == v1alpha2.None || s.Status == v1alpha2.Untouched || s.Status == v1alpha2.Running || s.Status == v1alpha2.Paused
+}
+
 type StageStatus struct {
 	Stage         string                 `json:"stage,omitempty"`
 	NextStage     string                 `json:"nextStage,omitempty"`
@@ -121,12 +322,28 @@ type StageStatus struct {
 	IsActive      bool                   `json:"isActive,omitempty"`
 	StatusMessage string                 `json:"statusMessage,omitempty"`
 	ErrorMessage  string                 `json:"errorMessage,omitempty"`
+	Attempts      []StageAttempt         `json:"attempts,omitempty"`
+}
+
+// StageAttempt records a run of the stage provider for a site. A stage is run again on failures up to the MaxRetries
+// of the stage.
+type StageAttempt struct {
+	Site         string         `json:"site,omitempty"`
+	Attempt      int            `json:"attempt,omitempty"`
+	Status       v1alpha2.State `json:"status,omitempty"`
+	ErrorMessage string         `json:"errorMessage,omitempty"`
+	StartTime    string         `json:"startTime,omitempty"`
+	EndTime      string         `json:"endTime,omitempty"`
 }
 
 type ActivationSpec struct {
 	Campaign string                 `json:"campaign,omitempty"`
 	Stage    string                 `json:"stage,omitempty"`
 	Inputs   map[string]interface{} `json:"inputs,omitempty"`
+	// DryRun runs every stage with a simulated provider instead of its stage provider. A simulated provider returns the
+	// DryRunOutputs of its stage, keyed by stage name, or echoes its inputs like the mock stage provider.
+	DryRun        bool                              `json:"dryRun,omitempty"`
+	DryRunOutputs map[string]map[string]interface{} `json:"dryRunOutputs,omitempty"`
 }
 
 func (c ActivationSpec) DeepEquals(other IDeepEquals) (bool, error) {
@@ -147,6 +364,14 @@ func (c ActivationSpec) DeepEquals(other IDeepEquals) (bool, error) {
 		return false, errors.New("inputs doesn't match")
 	}
 
+	if c.DryRun != otherC.DryRun {
+		return false, errors.New("dryRun doesn't match")
+	}
+
+	if !reflect.DeepEqual(c.DryRunOutputs, otherC.DryRunOutputs) {
+		return false, errors.New("dryRunOutputs doesn't match")
+	}
+
 	return true, nil
 }
 func (c ActivationState) DeepEquals(other IDeepEquals) (bool, error) {
@@ -7,8 +7,11 @@
 package model
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -221,6 +224,93 @@ func TestStageNotMatch(t *testing.T) {
 	equal, err = stage1.DeepEquals(stage2)
 	assert.Nil(t, err)
 	assert.False(t, equal)
+
+	// maintenance windows not match
+	stage2.Schedule = stage1.Schedule
+	stage1.MaintenanceWindows = []v1alpha2.MaintenanceWindow{{Start: "0 22 * * *", Duration: "4h"}}
+	equal, err = stage1.DeepEquals(stage2)
+	assert.Nil(t, err)
+	assert.False(t, equal)
+
+	// retry policy not match
+	stage2.MaintenanceWindows = stage1.MaintenanceWindows
+	stage1.MaxRetries = 3
+	equal, err = stage1.DeepEquals(stage2)
+	assert.Nil(t, err)
+	assert.False(t, equal)
+}
+
+func TestStageScheduleUnmarshal(t *testing.T) {
+	var stage StageSpec
+	err := json.Unmarshal([]byte(`{"schedule":"0 2 * * MON-FRI","timeZone":"UTC","maintenanceWindows":[{"start":"0 1 * * *","duration":"3h"}]}`), &stage)
+	assert.Nil(t, err)
+	assert.Equal(t, "0 2 * * MON-FRI", stage.Schedule)
+	assert.Equal(t, 1, len(stage.MaintenanceWindows))
+
+	err = json.Unmarshal([]byte(`{"schedule":"2020-10-31T12:00:00-07:00"}`), &stage)
+	assert.Nil(t, err)
+
+	err = json.Unmarshal([]byte(`{"schedule":"0 25 * * *"}`), &stage)
+	assert.NotNil(t, err)
+	err = json.Unmarshal([]byte(`{"schedule":"0 2 * * *","timeZone":"Nowhere/Town"}`), &stage)
+	assert.NotNil(t, err)
+}
+
+func TestStageRetryPolicy(t *testing.T) {
+	var stage StageSpec
+	err := json.Unmarshal([]byte(`{"timeout":"10m","maxRetries":3,"retryBackoff":"2s","maxRetryBackoff":"5s"}`), &stage)
+	assert.Nil(t, err)
+	policy, err := stage.GetRetryPolicy()
+	assert.Nil(t, err)
+	assert.Equal(t, 10*time.Minute, policy.Timeout)
+	assert.Equal(t, 3, policy.MaxRetries)
+	assert.Equal(t, 2*time.Second, policy.Backoff(1))
+	assert.Equal(t, 4*time.Second, policy.Backoff(2))
+	assert.Equal(t, 5*time.Second, policy.Backoff(3))
+
+	policy, err = StageSpec{}.GetRetryPolicy()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), policy.Timeout)
+	assert.Equal(t, DefaultRetryBackoff, policy.Backoff(1))
+
+	err = json.Unmarshal([]byte(`{"timeout":"ten minutes"}`), &stage)
+	assert.NotNil(t, err)
+	err = json.Unmarshal([]byte(`{"timeout":"-1s"}`), &stage)
+	assert.NotNil(t, err)
+	err = json.Unmarshal([]byte(`{"maxRetries":-1}`), &stage)
+	assert.NotNil(t, err)
+}
+
+func TestStageFanOutPolicy(t *testing.T) {
+	var stage StageSpec
+	err := json.Unmarshal([]byte(`{"maxParallel":20,"successThreshold":"99%"}`), &stage)
+	assert.Nil(t, err)
+	policy, err := stage.GetFanOutPolicy()
+	assert.Nil(t, err)
+	assert.Equal(t, 20, policy.MaxParallel)
+	assert.True(t, policy.HasThreshold())
+	assert.Equal(t, 495, policy.RequiredSuccesses(500))
+	assert.Equal(t, 1, policy.RequiredSuccesses(1))
+
+	policy, err = StageSpec{SuccessThreshold: "498"}.GetFanOutPolicy()
+	assert.Nil(t, err)
+	assert.Equal(t, 498, policy.RequiredSuccesses(500))
+	assert.Equal(t, 3, policy.RequiredSuccesses(3))
+
+	policy, err = StageSpec{}.GetFanOutPolicy()
+	assert.Nil(t, err)
+	assert.False(t, policy.HasThreshold())
+	assert.Equal(t, 0, policy.MaxParallel)
+	assert.Equal(t, 500, policy.RequiredSuccesses(500))
+
+	err = json.Unmarshal([]byte(`{"maxParallel":-1}`), &stage)
+	assert.NotNil(t, err)
+	err = json.Unmarshal([]byte(`{"successThreshold":"120%"}`), &stage)
+	assert.NotNil(t, err)
+	err = json.Unmarshal([]byte(`{"successThreshold":"most"}`), &stage)
+	assert.NotNil(t, err)
+	err = json.Unmarshal([]byte(`{"successThreshold":"0"}`), &stage)
+	assert.NotNil(t, err)
 }
 
 func TestStageMatchOneEmpty(t *testing.T) {
@@ -0,0 +1,59 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"encoding/json"
+)
+
+// CatalogRevision is an immutable copy of a catalog that is recorded each time the catalog is written
+type CatalogRevision struct {
+	Catalog   string `json:"catalog"`
+	Namespace string `json:"namespace,omitempty"`
+	// Revision counts the writes of the catalog from 1
+	Revision int `json:"revision"`
+	// Author is the user that wrote the catalog, empty when the write wasn't authenticated
+	Author    string `json:"author,omitempty"`
+	Timestamp string `json:"timestamp"`
+	// PreviousETag is the ETag of the catalog that the write replaced, empty when the write created the catalog
+	PreviousETag string `json:"previousETag,omitempty"`
+	// RestoredFrom is the revision that the write restored
+	RestoredFrom int          `json:"restoredFrom,omitempty"`
+	Spec         *CatalogSpec `json:"spec"`
+}
+
+// CatalogRevisionDiff lists the changes of a catalog from one revision to another. Paths are relative to the catalog
+// spec, such as "properties.network.dns".
+type CatalogRevisionDiff struct {
+	Catalog string           `json:"catalog"`
+	From    int              `json:"from"`
+	To      int              `json:"to"`
+	Changes []PropertyChange `json:"changes"`
+}
+
+// NewCatalogRevisionDiff compares the specs of two revisions of a catalog
+func NewCatalogRevisionDiff(from CatalogRevision, to CatalogRevision) CatalogRevisionDiff {
+	return CatalogRevisionDiff{
+		Catalog: to.Catalog,
+		From:    from.Revision,
+		To:      to.Revision,
+		Changes: DiffValues("", toSpecMap(from.Spec), toSpecMap(to.Spec)),
+	}
+}
+
+func toSpecMap(spec *CatalogSpec) map[string]interface{} {
+	ret := make(map[string]interface{})
+	if spec == nil {
+		return ret
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return ret
+	}
+	json.Unmarshal(data, &ret)
+	return ret
+}
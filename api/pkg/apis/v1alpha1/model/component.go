@@ -22,6 +22,7 @@ type ComponentSpec struct {
 	Dependencies []string               `json:"dependencies,omitempty"`
 	Skills       []string               `json:"skills,omitempty"`
 	Sidecars     []SidecarSpec          `json:"sidecars,omitempty"`
+	Readiness    *ReadinessSpec         `json:"readiness,omitempty"`
 }
 
 func (c ComponentSpec) DeepEquals(other IDeepEquals) (bool, error) { // avoid using reflect, which has performance problems
@@ -48,6 +49,15 @@ func (c ComponentSpec) DeepEquals(other IDeepEquals) (bool, error) { // avoid us
 	if !SlicesEqual(c.Sidecars, otherC.Sidecars) {
 		return false, nil
 	}
+
+	if (c.Readiness == nil) != (otherC.Readiness == nil) {
+		return false, nil
+	}
+	if c.Readiness != nil {
+		if equal, err := c.Readiness.DeepEquals(*otherC.Readiness); err != nil || !equal {
+			return equal, err
+		}
+	}
 	// if c.Constraints != otherC.Constraints {	Can't compare constraints as components from actual envrionments don't have constraints
 	// 	return false, nil
 	// }
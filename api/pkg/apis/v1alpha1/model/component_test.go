@@ -200,3 +200,57 @@ func TestComponentDeepNotEqualMetadata(t *testing.T) {
 	assert.Nil(t, err)
 	assert.False(t, equal)
 }
+
+func TestComponentDeepNotEqualReadiness(t *testing.T) {
+	c1 := ComponentSpec{
+		Name:      "symphony-agent",
+		Readiness: &ReadinessSpec{Provider: true},
+	}
+	c2 := ComponentSpec{
+		Name:      "symphony-agent",
+		Readiness: &ReadinessSpec{Provider: true, Timeout: "10m"},
+	}
+	equal, err := c1.DeepEquals(c2)
+	assert.Nil(t, err)
+	assert.False(t, equal)
+
+	equal, err = c1.DeepEquals(ComponentSpec{Name: "symphony-agent"})
+	assert.Nil(t, err)
+	assert.False(t, equal)
+
+	c2.Readiness.Timeout = ""
+	equal, err = c1.DeepEquals(c2)
+	assert.Nil(t, err)
+	assert.True(t, equal)
+}
+
+func TestReadinessValidate(t *testing.T) {
+	readiness := ReadinessSpec{
+		HTTP:     &HTTPProbeSpec{Port: 8080, Path: "/healthz"},
+		Timeout:  "1m",
+		Interval: "5s",
+	}
+	assert.Nil(t, readiness.Validate())
+
+	readiness = ReadinessSpec{
+		Script: &ScriptProbeSpec{Script: "ready.sh"},
+	}
+	assert.Nil(t, readiness.Validate())
+}
+
+func TestReadinessValidateInvalid(t *testing.T) {
+	readinesses := []ReadinessSpec{
+		{},
+		{HTTP: &HTTPProbeSpec{Path: "/healthz"}, Provider: true},
+		{HTTP: &HTTPProbeSpec{Path: "healthz"}},
+		{HTTP: &HTTPProbeSpec{Port: 70000}},
+		{Provider: true, Timeout: "later"},
+		{Provider: true, Interval: "0s"},
+		{Script: &ScriptProbeSpec{}},
+		{Script: &ScriptProbeSpec{Script: "../ready.sh"}},
+		{Script: &ScriptProbeSpec{Script: "ready.sh"}, Provider: true},
+	}
+	for _, readiness := range readinesses {
+		assert.NotNil(t, readiness.Validate())
+	}
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Merge modes of a config catalog, which decide how its values are combined with the values it overrides: the values
+// of its parent catalog, or the values below it when it's used as an overlay
+const (
+	// MergeModeReplace replaces the overridden value, it's the default
+	MergeModeReplace = "replace"
+	// MergeModeDeepMerge merges nested maps, and replaces lists and other values
+	MergeModeDeepMerge = "deep-merge"
+	// MergeModeAppendList merges nested maps like MergeModeDeepMerge, and appends lists to the overridden lists
+	MergeModeAppendList = "append-list"
+)
+
+// MergeModeMetadataKey is the catalog metadata that sets the merge mode of a catalog
+const MergeModeMetadataKey = "mergeMode"
+
+// MergeMode returns the merge mode of a catalog
+func (c CatalogSpec) MergeMode() string {
+	if mode, ok := c.Metadata[MergeModeMetadataKey]; ok && mode != "" {
+		return mode
+	}
+	return MergeModeReplace
+}
+
+// IsMergeMode tells whether a merge mode is known
+func IsMergeMode(mode string) bool {
+	switch mode {
+	case MergeModeReplace, MergeModeDeepMerge, MergeModeAppendList:
+		return true
+	}
+	return false
+}
+
+// ConfigLayer is a catalog that was read to resolve a config value
+type ConfigLayer struct {
+	Catalog   string `json:"catalog"`
+	MergeMode string `json:"mergeMode"`
+	// Overlay is set on the catalogs that were read as overlays, and on their parents
+	Overlay bool `json:"overlay,omitempty"`
+}
+
+// ConfigValue is a resolved config value with its provenance. Chain are the catalogs that were read, with the
+// catalogs that take precedence first, and Sources maps the path of each field of the value to the catalog that
+// supplied it. Paths are like 'network.ports[0].port', and the empty path is the value itself.
+type ConfigValue struct {
+	Value   interface{}       `json:"value"`
+	Chain   []ConfigLayer     `json:"chain,omitempty"`
+	Sources map[string]string `json:"sources,omitempty"`
+}
+
+// NewConfigValue returns a value whose fields are all supplied by one catalog
+func NewConfigValue(value interface{}, catalog string, mergeMode string) ConfigValue {
+	ret := ConfigValue{
+		Value:   value,
+		Chain:   []ConfigLayer{{Catalog: catalog, MergeMode: mergeMode}},
+		Sources: make(map[string]string),
+	}
+	setConfigSources(value, catalog, "", ret.Sources)
+	return ret
+}
+
+// Override returns the value of over combined with the value it overrides according to the merge mode. The chain of
+// over comes first.
+func (c ConfigValue) Override(over ConfigValue, mode string) ConfigValue {
+	ret := ConfigValue{
+		Chain:   append(append([]ConfigLayer{}, over.Chain...), c.Chain...),
+		Sources: make(map[string]string),
+	}
+	ret.Value = mergeConfigValue(c.Value, c.Sources, over.Value, over.Sources, mode, "", ret.Sources)
+	return ret
+}
+
+// AsOverlay marks the catalogs of the value as overlays
+func (c ConfigValue) AsOverlay() ConfigValue {
+	chain := make([]ConfigLayer, len(c.Chain))
+	for i, layer := range c.Chain {
+		layer.Overlay = true
+		chain[i] = layer
+	}
+	c.Chain = chain
+	return c
+}
+
+func mergeConfigValue(base interface{}, baseSources map[string]string, over interface{}, overSources map[string]string, mode string, path string, sources map[string]string) interface{} {
+	if mode != MergeModeDeepMerge && mode != MergeModeAppendList {
+		copyConfigSources(overSources, path, path, sources)
+		return over
+	}
+	baseMap, baseIsMap := base.(map[string]interface{})
+	overMap, overIsMap := over.(map[string]interface{})
+	if baseIsMap && overIsMap {
+		ret := make(map[string]interface{}, len(baseMap)+len(overMap))
+		for k, v := range baseMap {
+			if _, ok := overMap[k]; !ok {
+				ret[k] = v
+				copyConfigSources(baseSources, configFieldPath(path, k), configFieldPath(path, k), sources)
+			}
+		}
+		for k, v := range overMap {
+			child := configFieldPath(path, k)
+			if b, ok := baseMap[k]; ok {
+				ret[k] = mergeConfigValue(b, baseSources, v, overSources, mode, child, sources)
+			} else {
+				ret[k] = v
+				copyConfigSources(overSources, child, child, sources)
+			}
+		}
+		return ret
+	}
+	baseList, baseIsList := base.([]interface{})
+	overList, overIsList := over.([]interface{})
+	if baseIsList && overIsList && mode == MergeModeAppendList {
+		ret := make([]interface{}, 0, len(baseList)+len(overList))
+		ret = append(ret, baseList...)
+		ret = append(ret, overList...)
+		for i := range baseList {
+			p := configIndexPath(path, i)
+			copyConfigSources(baseSources, p, p, sources)
+		}
+		for i := range overList {
+			copyConfigSources(overSources, configIndexPath(path, i), configIndexPath(path, len(baseList)+i), sources)
+		}
+		if len(ret) == 0 {
+			copyConfigSources(overSources, path, path, sources)
+		}
+		return ret
+	}
+	copyConfigSources(overSources, path, path, sources)
+	return over
+}
+
+// setConfigSources sets the source of each leaf field of a value
+func setConfigSources(value interface{}, catalog string, path string, sources map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			for k, item := range v {
+				setConfigSources(item, catalog, configFieldPath(path, k), sources)
+			}
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, item := range v {
+				setConfigSources(item, catalog, configIndexPath(path, i), sources)
+			}
+			return
+		}
+	}
+	sources[path] = catalog
+}
+
+// copyConfigSources copies the sources of the fields at a path to another path
+func copyConfigSources(from map[string]string, path string, to string, sources map[string]string) {
+	for p, catalog := range from {
+		if p == path {
+			sources[to] = catalog
+			continue
+		}
+		if path == "" {
+			sources[p] = catalog
+			continue
+		}
+		if rest := strings.TrimPrefix(p, path); rest != p && (strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "[")) {
+			sources[to+rest] = catalog
+		}
+	}
+}
+
+func configFieldPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+func configIndexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
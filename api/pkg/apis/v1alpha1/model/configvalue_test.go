@@ -0,0 +1,69 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogMergeMode(t *testing.T) {
+	spec := CatalogSpec{}
+	assert.Equal(t, MergeModeReplace, spec.MergeMode())
+
+	spec.Metadata = map[string]string{
+		"mergeMode": "deep-merge",
+	}
+	assert.Equal(t, MergeModeDeepMerge, spec.MergeMode())
+	assert.True(t, IsMergeMode(spec.MergeMode()))
+	assert.False(t, IsMergeMode("merge"))
+}
+
+func TestConfigValueOverride(t *testing.T) {
+	base := NewConfigValue(map[string]interface{}{
+		"dns":   "8.8.8.8",
+		"ports": []interface{}{80},
+		"tags":  map[string]interface{}{"env": "dev"},
+	}, "base", MergeModeReplace)
+	over := NewConfigValue(map[string]interface{}{
+		"ports": []interface{}{8080},
+		"tags":  map[string]interface{}{"site": "s1"},
+	}, "site", MergeModeReplace)
+
+	replaced := base.Override(over, MergeModeReplace)
+	assert.Equal(t, over.Value, replaced.Value)
+	assert.Equal(t, map[string]string{"ports[0]": "site", "tags.site": "site"}, replaced.Sources)
+	assert.Equal(t, []ConfigLayer{
+		{Catalog: "site", MergeMode: MergeModeReplace},
+		{Catalog: "base", MergeMode: MergeModeReplace},
+	}, replaced.Chain)
+
+	merged := base.Override(over, MergeModeDeepMerge)
+	assert.Equal(t, map[string]interface{}{
+		"dns":   "8.8.8.8",
+		"ports": []interface{}{8080},
+		"tags":  map[string]interface{}{"env": "dev", "site": "s1"},
+	}, merged.Value)
+	assert.Equal(t, map[string]string{
+		"dns":       "base",
+		"ports[0]":  "site",
+		"tags.env":  "base",
+		"tags.site": "site",
+	}, merged.Sources)
+
+	appended := base.Override(over, MergeModeAppendList)
+	assert.Equal(t, []interface{}{80, 8080}, appended.Value.(map[string]interface{})["ports"])
+	assert.Equal(t, "base", appended.Sources["ports[0]"])
+	assert.Equal(t, "site", appended.Sources["ports[1]"])
+}
+
+func TestConfigValueAsOverlay(t *testing.T) {
+	value := NewConfigValue("value", "overlay", MergeModeReplace).AsOverlay()
+	assert.True(t, value.Chain[0].Overlay)
+	assert.Equal(t, map[string]string{"": "overlay"}, value.Sources)
+}
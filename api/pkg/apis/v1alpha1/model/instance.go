@@ -8,6 +8,8 @@ package model
 
 import (
 	"errors"
+	"fmt"
+	"time"
 )
 
 type (
@@ -32,6 +34,18 @@ type (
 		Topologies  []TopologySpec    `json:"topologies,omitempty"`
 		Pipelines   []PipelineSpec    `json:"pipelines,omitempty"`
 		IsDryRun    bool              `json:"isDryRun,omitempty"`
+
+		// Optional RollbackPolicy to specify whether targets should be restored to the last successfully
+		// deployed state when a deployment step fails.
+		RollbackPolicy *RollbackPolicySpec `json:"rollbackPolicy,omitempty"`
+
+		// Optional RetryPolicy to specify how failed deployment steps are retried. A retry policy on a target
+		// takes precedence over this one.
+		RetryPolicy *RetryPolicySpec `json:"retryPolicy,omitempty"`
+
+		// Optional Rollout to deploy to the targets selected by the instance in waves. A wave only starts after
+		// the targets of the previous wave pass the health gate.
+		Rollout *RolloutSpec `json:"rollout,omitempty"`
 	}
 
 	// TargertRefSpec defines the target the instance will deploy to
@@ -41,6 +55,31 @@ type (
 		Selector map[string]string `json:"selector,omitempty"`
 	}
 
+	// RollbackPolicySpec defines how a failed deployment of the instance is recovered
+	// +kubebuilder:object:generate=true
+	RollbackPolicySpec struct {
+		// Enabled turns on rolling back to the last successfully deployed component specs on every target
+		// that was touched by the failed deployment
+		Enabled bool `json:"enabled,omitempty"`
+	}
+
+	// RolloutSpec defines the waves in which a deployment is rolled out across targets
+	// +kubebuilder:object:generate=true
+	RolloutSpec struct {
+		Waves []RolloutWaveSpec `json:"waves,omitempty"`
+		// PauseBetweenWaves is how long to wait after a wave passes its health gate, such as "10m"
+		PauseBetweenWaves string `json:"pauseBetweenWaves,omitempty"`
+	}
+
+	// RolloutWaveSpec defines the size of a wave, either as a number of targets or as a percentage of all targets.
+	// Targets that are not covered by any wave are deployed in a final wave.
+	// +kubebuilder:object:generate=true
+	RolloutWaveSpec struct {
+		Name       string `json:"name,omitempty"`
+		Targets    int    `json:"targets,omitempty"`
+		Percentage int    `json:"percentage,omitempty"`
+	}
+
 	// PipelineSpec defines the desired pipeline of the instance
 	// +kubebuilder:object:generate=true
 	PipelineSpec struct {
@@ -67,6 +106,39 @@ func (c TargetSelector) DeepEquals(other IDeepEquals) (bool, error) {
 	return true, nil
 }
 
+// Validate checks that every wave has either a target count or a percentage, and that the pause is a valid duration
+func (r RolloutSpec) Validate() error {
+	for i, w := range r.Waves {
+		name := w.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		if w.Targets < 0 || w.Percentage < 0 || w.Percentage > 100 {
+			return fmt.Errorf("rollout wave %s has an invalid size", name)
+		}
+		if w.Targets > 0 && w.Percentage > 0 {
+			return fmt.Errorf("rollout wave %s can't have both targets and percentage", name)
+		}
+		if w.Targets == 0 && w.Percentage == 0 {
+			return fmt.Errorf("rollout wave %s must have either targets or percentage", name)
+		}
+	}
+	if r.PauseBetweenWaves != "" {
+		pause, err := time.ParseDuration(r.PauseBetweenWaves)
+		if err != nil {
+			return fmt.Errorf("pauseBetweenWaves is not a valid duration: %s", err.Error())
+		}
+		if pause < 0 {
+			return fmt.Errorf("pauseBetweenWaves must not be negative")
+		}
+	}
+	return nil
+}
+
+func (p *RollbackPolicySpec) IsEnabled() bool {
+	return p != nil && p.Enabled
+}
+
 func (c TopologySpec) DeepEquals(other IDeepEquals) (bool, error) {
 	otherC, ok := other.(TopologySpec)
 	if !ok {
@@ -154,6 +226,15 @@ func (c InstanceSpec) DeepEquals(other IDeepEquals) (bool, error) {
 		return false, nil
 	}
 
+	if (c.RetryPolicy == nil) != (otherC.RetryPolicy == nil) {
+		return false, nil
+	}
+	if c.RetryPolicy != nil {
+		if equal, err := c.RetryPolicy.DeepEquals(*otherC.RetryPolicy); err != nil || !equal {
+			return equal, err
+		}
+	}
+
 	return true, nil
 }
 
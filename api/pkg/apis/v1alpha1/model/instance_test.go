@@ -518,3 +518,78 @@ func TestTopologySpecDeepEqualsBindingsNotMatch(t *testing.T) {
 	assert.Nil(t, err)
 	assert.False(t, res)
 }
+
+func TestRolloutValidate(t *testing.T) {
+	rollout := RolloutSpec{
+		Waves: []RolloutWaveSpec{
+			{Name: "canary", Targets: 1},
+			{Percentage: 50},
+		},
+		PauseBetweenWaves: "30s",
+	}
+	assert.Nil(t, rollout.Validate())
+}
+
+func TestRolloutValidateInvalid(t *testing.T) {
+	rollouts := []RolloutSpec{
+		{Waves: []RolloutWaveSpec{{Name: "empty"}}},
+		{Waves: []RolloutWaveSpec{{Targets: 1, Percentage: 10}}},
+		{Waves: []RolloutWaveSpec{{Percentage: 101}}},
+		{Waves: []RolloutWaveSpec{{Targets: -1}}},
+		{Waves: []RolloutWaveSpec{{Targets: 1}}, PauseBetweenWaves: "soon"},
+		{Waves: []RolloutWaveSpec{{Targets: 1}}, PauseBetweenWaves: "-1s"},
+	}
+	for _, rollout := range rollouts {
+		assert.NotNil(t, rollout.Validate())
+	}
+}
+
+func TestInstanceDeepEqualsRetryPolicyNotMatch(t *testing.T) {
+	Instance := InstanceState{
+		ObjectMeta: ObjectMeta{
+			Namespace: "Default",
+			Name:      "InstanceName",
+		},
+		Spec: &InstanceSpec{
+			DisplayName: "InstanceDisplayName",
+			Solution:    "SolutionName",
+			Target: TargetSelector{
+				Name: "TargetName",
+			},
+			RetryPolicy: &RetryPolicySpec{
+				MaxAttempts: 3,
+			},
+		},
+	}
+	other := InstanceState{
+		ObjectMeta: ObjectMeta{
+			Namespace: "Default",
+			Name:      "InstanceName",
+		},
+		Spec: &InstanceSpec{
+			DisplayName: "InstanceDisplayName",
+			Solution:    "SolutionName",
+			Target: TargetSelector{
+				Name: "TargetName",
+			},
+			RetryPolicy: &RetryPolicySpec{
+				MaxAttempts: 5,
+			},
+		},
+	}
+	res, err := Instance.DeepEquals(other)
+	assert.Nil(t, err)
+	assert.False(t, res)
+
+	other.Spec.RetryPolicy = nil
+	res, err = Instance.DeepEquals(other)
+	assert.Nil(t, err)
+	assert.False(t, res)
+
+	other.Spec.RetryPolicy = &RetryPolicySpec{
+		MaxAttempts: 3,
+	}
+	res, err = Instance.DeepEquals(other)
+	assert.Nil(t, err)
+	assert.True(t, res)
+}
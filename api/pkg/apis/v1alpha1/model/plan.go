@@ -21,6 +21,18 @@ type DeploymentStep struct {
 	Components []ComponentStep
 	Role       string
 	IsFirst    bool
+	// Predecessors are the components on other steps that must be deployed successfully before this step can run
+	Predecessors []ComponentTargetRef
+}
+
+// ComponentTargetRef references a component on a target, which is a node in the deployment graph
+type ComponentTargetRef struct {
+	Component string `json:"component"`
+	Target    string `json:"target"`
+}
+
+func (r ComponentTargetRef) String() string {
+	return fmt.Sprintf("%s::%s", r.Component, r.Target)
 }
 
 type ComponentAction string
@@ -117,6 +129,10 @@ func (p DeploymentPlan) FindLastTargetRole(target, role string) int {
 	}
 	return -1
 }
+
+// CanAppendToStep checks if a component can be applied with a step. It can't when one of its dependencies isn't applied
+// up to that step, or when it's applied in that step and declares a readiness section, because readiness is only
+// checked once the whole step is applied.
 func (p DeploymentPlan) CanAppendToStep(step int, component ComponentSpec) bool {
 	canAppend := true
 	for _, d := range component.Dependencies {
@@ -124,6 +140,9 @@ func (p DeploymentPlan) CanAppendToStep(step int, component ComponentSpec) bool
 		for j := 0; j <= step; j++ {
 			for _, c := range p.Steps[j].Components {
 				if c.Component.Name == d && c.Action == ComponentUpdate {
+					if j == step && c.Component.Readiness != nil {
+						return false
+					}
 					resolved = true
 					break
 				}
@@ -164,6 +183,51 @@ func (p DeploymentPlan) RevisedForDeletion() DeploymentPlan {
 	}
 	return ret
 }
+
+// LinkPredecessors sets the predecessors of every step. A component update waits for the updates of its dependencies
+// on all targets, and a component deletion waits for the deletions of the components that depend on it.
+func (p DeploymentPlan) LinkPredecessors() DeploymentPlan {
+	for j := range p.Steps {
+		var refs []ComponentTargetRef
+		for _, cs := range p.Steps[j].Components {
+			for i := 0; i < j; i++ {
+				for _, c := range p.Steps[i].Components {
+					if c.Action != cs.Action {
+						continue
+					}
+					if (cs.Action == ComponentUpdate && containsString(cs.Component.Dependencies, c.Component.Name)) ||
+						(cs.Action == ComponentDelete && containsString(c.Component.Dependencies, cs.Component.Name)) {
+						ref := ComponentTargetRef{Component: c.Component.Name, Target: p.Steps[i].Target}
+						if !containsRef(refs, ref) {
+							refs = append(refs, ref)
+						}
+					}
+				}
+			}
+		}
+		p.Steps[j].Predecessors = refs
+	}
+	return p
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRef(refs []ComponentTargetRef, ref ComponentTargetRef) bool {
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
 func makeUpdateStep(step DeploymentStep) DeploymentStep {
 	ret := DeploymentStep{
 		Target:     step.Target,
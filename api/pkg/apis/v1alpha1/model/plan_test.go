@@ -327,6 +327,14 @@ func TestCanAppendToStep(t *testing.T) {
 	})
 	assert.Equal(t, false, canAppend)
 
+	// has dependencies in the plan component, but it waits for readiness, can not add
+	p.Steps[0].Components[0].Component.Readiness = &ReadinessSpec{Provider: true}
+	canAppend = p.CanAppendToStep(0, ComponentSpec{
+		Name:         "sample-grpc-solution2",
+		Type:         "instance",
+		Dependencies: []string{"sample-grpc-solution"},
+	})
+	assert.Equal(t, false, canAppend)
 }
 
 func TestRevisedForDeletion(t *testing.T) {
@@ -379,3 +387,40 @@ func TestRevisedForDeletion(t *testing.T) {
 	assert.Equal(t, p.Steps[1].Components[1].Component.Type, "instance")
 	assert.Equal(t, p.Steps[1].Components[1].Component.Properties["file.content"], "hello world")
 }
+
+func TestLinkPredecessors(t *testing.T) {
+	plan := DeploymentPlan{
+		Steps: []DeploymentStep{
+			{
+				Target:     "T1",
+				Components: []ComponentStep{{Action: ComponentUpdate, Component: ComponentSpec{Name: "a"}}},
+			},
+			{
+				Target:     "T2",
+				Components: []ComponentStep{{Action: ComponentUpdate, Component: ComponentSpec{Name: "a"}}},
+			},
+			{
+				Target:     "T3",
+				Components: []ComponentStep{{Action: ComponentUpdate, Component: ComponentSpec{Name: "b", Dependencies: []string{"a"}}}},
+			},
+			{
+				Target:     "T3",
+				Components: []ComponentStep{{Action: ComponentDelete, Component: ComponentSpec{Name: "d", Dependencies: []string{"c"}}}},
+			},
+			{
+				Target:     "T1",
+				Components: []ComponentStep{{Action: ComponentDelete, Component: ComponentSpec{Name: "c"}}},
+			},
+		},
+	}
+	plan = plan.LinkPredecessors()
+	assert.Nil(t, plan.Steps[0].Predecessors)
+	assert.Nil(t, plan.Steps[1].Predecessors)
+	assert.Equal(t, []ComponentTargetRef{{Component: "a", Target: "T1"}, {Component: "a", Target: "T2"}}, plan.Steps[2].Predecessors)
+	assert.Nil(t, plan.Steps[3].Predecessors)
+	assert.Equal(t, []ComponentTargetRef{{Component: "d", Target: "T3"}}, plan.Steps[4].Predecessors)
+}
+
+func TestComponentTargetRefString(t *testing.T) {
+	assert.Equal(t, "a::T1", ComponentTargetRef{Component: "a", Target: "T1"}.String())
+}
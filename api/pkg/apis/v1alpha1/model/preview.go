@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"reflect"
+	"sort"
+)
+
+// PreviewSpec describes what a reconcile would do without applying anything
+type PreviewSpec struct {
+	Plan      DeploymentPlan    `json:"plan"`
+	Steps     []StepPreviewSpec `json:"steps"`
+	IsRemoval bool              `json:"isRemoval"`
+}
+
+// StepPreviewSpec lists the components a deployment step would update or delete on a target
+type StepPreviewSpec struct {
+	Target     string                 `json:"target"`
+	Role       string                 `json:"role"`
+	Skipped    bool                   `json:"skipped"`
+	Components []ComponentPreviewSpec `json:"components"`
+}
+
+// ComponentPreviewSpec is the planned action on a component, with the property changes between the current state
+// reported by the target and the desired spec
+type ComponentPreviewSpec struct {
+	Name    string           `json:"name"`
+	Action  ComponentAction  `json:"action"`
+	Changes []PropertyChange `json:"changes,omitempty"`
+}
+
+type PropertyChangeType string
+
+const (
+	PropertyAdded   PropertyChangeType = "added"
+	PropertyRemoved PropertyChangeType = "removed"
+	PropertyChanged PropertyChangeType = "changed"
+)
+
+// PropertyChange is a single difference between the current and the desired component. Path is the dotted JSON path of
+// the property, such as "properties.container.image".
+type PropertyChange struct {
+	Path    string             `json:"path"`
+	Type    PropertyChangeType `json:"type"`
+	Current interface{}        `json:"current,omitempty"`
+	Desired interface{}        `json:"desired,omitempty"`
+}
+
+// DiffValues compares two JSON values property by property, under a dotted path. An object that is added or removed is
+// reported property by property, and other values are compared as a whole.
+func DiffValues(path string, current interface{}, desired interface{}) []PropertyChange {
+	changes := make([]PropertyChange, 0)
+	diffValues(path, current, desired, &changes)
+	return changes
+}
+
+func diffValues(path string, current interface{}, desired interface{}, changes *[]PropertyChange) {
+	currentMap, currentIsMap := current.(map[string]interface{})
+	desiredMap, desiredIsMap := desired.(map[string]interface{})
+	if currentIsMap && desired == nil {
+		desiredMap, desiredIsMap = map[string]interface{}{}, true
+	}
+	if desiredIsMap && current == nil {
+		currentMap, currentIsMap = map[string]interface{}{}, true
+	}
+	if currentIsMap && desiredIsMap {
+		keys := make([]string, 0)
+		for k := range currentMap {
+			keys = append(keys, k)
+		}
+		for k := range desiredMap {
+			if _, ok := currentMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			diffValues(p, currentMap[k], desiredMap[k], changes)
+		}
+		return
+	}
+	switch {
+	case current == nil && desired == nil:
+	case current == nil:
+		*changes = append(*changes, PropertyChange{Path: path, Type: PropertyAdded, Desired: desired})
+	case desired == nil:
+		*changes = append(*changes, PropertyChange{Path: path, Type: PropertyRemoved, Current: current})
+	case !reflect.DeepEqual(current, desired):
+		*changes = append(*changes, PropertyChange{Path: path, Type: PropertyChanged, Current: current, Desired: desired})
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ReadinessSpec defines how to check that a component is ready after it has been applied. Readiness is checked by the
+// target provider, where the component runs. Exactly one of HTTP, Script or Provider must be set.
+// +kubebuilder:object:generate=true
+type ReadinessSpec struct {
+	// HTTP probes a service of the component until it returns the expected status code
+	HTTP *HTTPProbeSpec `json:"http,omitempty"`
+	// Script runs a script of the target provider until it exits with 0
+	Script *ScriptProbeSpec `json:"script,omitempty"`
+	// Provider asks the target provider to check readiness natively, such as the rollout status of a Helm release or
+	// a Kubernetes deployment
+	Provider bool `json:"provider,omitempty"`
+	// Timeout is how long to wait for the component to become ready, such as "5m". Defaults to 5 minutes.
+	Timeout string `json:"timeout,omitempty"`
+	// Interval is the delay between two probes, such as "5s". Defaults to 5 seconds.
+	Interval string `json:"interval,omitempty"`
+}
+
+// HTTPProbeSpec defines an HTTP readiness probe of a Kubernetes service of the component. The target provider sends
+// the probe through the API server of its cluster, to a service in the namespace of the deployment.
+// +kubebuilder:object:generate=true
+type HTTPProbeSpec struct {
+	// Service is the name of the service, which defaults to the component name
+	Service string `json:"service,omitempty"`
+	// Port is the service port, which defaults to the first port of the service
+	Port   int    `json:"port,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Method string `json:"method,omitempty"`
+	// ExpectedStatus is the status code that indicates readiness. Any 2xx status is accepted when it's not set.
+	ExpectedStatus int `json:"expectedStatus,omitempty"`
+}
+
+// ScriptProbeSpec defines a readiness probe that runs a script where the component runs. Only scripts that the target
+// provider already has can be run, such as the scripts in the script folder of a script target.
+// +kubebuilder:object:generate=true
+type ScriptProbeSpec struct {
+	// Script is the file name of the script, which is run with the same arguments as the apply script
+	Script string `json:"script"`
+}
+
+// ReadinessResultSpec records the outcome of the readiness check of a component
+type ReadinessResultSpec struct {
+	Ready   bool      `json:"ready"`
+	Probes  int       `json:"probes"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+func (c ReadinessSpec) DeepEquals(other IDeepEquals) (bool, error) {
+	otherC, ok := other.(ReadinessSpec)
+	if !ok {
+		return false, errors.New("parameter is not a ReadinessSpec type")
+	}
+	return reflect.DeepEqual(c, otherC), nil
+}
+
+// Validate checks that exactly one probe is set and that the durations can be parsed
+func (c ReadinessSpec) Validate() error {
+	probes := 0
+	if c.HTTP != nil {
+		probes++
+		if c.HTTP.Port < 0 || c.HTTP.Port > 65535 {
+			return fmt.Errorf("http probe port %d is not valid", c.HTTP.Port)
+		}
+		if c.HTTP.Path != "" && !strings.HasPrefix(c.HTTP.Path, "/") {
+			return fmt.Errorf("http probe path must start with '/'")
+		}
+	}
+	if c.Script != nil {
+		probes++
+		if c.Script.Script == "" {
+			return fmt.Errorf("script probe must have a script")
+		}
+		if strings.ContainsAny(c.Script.Script, `/\`) || c.Script.Script == ".." {
+			return fmt.Errorf("script probe must be a file name")
+		}
+	}
+	if c.Provider {
+		probes++
+	}
+	if probes != 1 {
+		return fmt.Errorf("readiness must have exactly one of http, script or provider")
+	}
+	durations := []struct {
+		name  string
+		value string
+	}{
+		{"timeout", c.Timeout},
+		{"interval", c.Interval},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(duration.value)
+		if err != nil {
+			return fmt.Errorf("%s is not a valid duration: %s", duration.name, err.Error())
+		}
+		if d <= 0 {
+			return fmt.Errorf("%s must be positive", duration.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+// RetryPolicySpec defines how a failed deployment step is retried. It can be set on an instance and on a target; the
+// target policy takes precedence for steps on that target.
+// +kubebuilder:object:generate=true
+type RetryPolicySpec struct {
+	// MaxAttempts is the total number of attempts, including the first one. Defaults to 1.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// InitialBackoff is the delay before the first retry, such as "5s". The delay doubles after every attempt.
+	InitialBackoff string `json:"initialBackoff,omitempty"`
+	// MaxBackoff caps the delay between two attempts.
+	MaxBackoff string `json:"maxBackoff,omitempty"`
+	// AttemptTimeout bounds the duration of a single attempt. No timeout is applied when it's empty.
+	AttemptTimeout string `json:"attemptTimeout,omitempty"`
+	// TerminalStates lists the error states that are never retried, all other errors are retried. When it's empty,
+	// errors that retrying can't resolve, such as bad requests and bad configs, are not retried.
+	TerminalStates []v1alpha2.State `json:"terminalStates,omitempty"`
+}
+
+func (c RetryPolicySpec) DeepEquals(other IDeepEquals) (bool, error) {
+	otherC, ok := other.(RetryPolicySpec)
+	if !ok {
+		return false, errors.New("parameter is not a RetryPolicySpec type")
+	}
+
+	if c.MaxAttempts != otherC.MaxAttempts {
+		return false, nil
+	}
+
+	if c.InitialBackoff != otherC.InitialBackoff {
+		return false, nil
+	}
+
+	if c.MaxBackoff != otherC.MaxBackoff {
+		return false, nil
+	}
+
+	if c.AttemptTimeout != otherC.AttemptTimeout {
+		return false, nil
+	}
+
+	if len(c.TerminalStates) != len(otherC.TerminalStates) {
+		return false, nil
+	}
+	for i := range c.TerminalStates {
+		if c.TerminalStates[i] != otherC.TerminalStates[i] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Validate checks that the attempt count is not negative and that all durations can be parsed
+func (c RetryPolicySpec) Validate() error {
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("maxAttempts must not be negative")
+	}
+	durations := []struct {
+		name  string
+		value string
+	}{
+		{"initialBackoff", c.InitialBackoff},
+		{"maxBackoff", c.MaxBackoff},
+		{"attemptTimeout", c.AttemptTimeout},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+		name := duration.name
+		d, err := time.ParseDuration(duration.value)
+		if err != nil {
+			return fmt.Errorf("%s is not a valid duration: %s", name, err.Error())
+		}
+		if d < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	return nil
+}
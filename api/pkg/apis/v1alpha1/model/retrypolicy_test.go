@@ -0,0 +1,65 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package model
+
+import (
+	"testing"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyValidate(t *testing.T) {
+	policy := RetryPolicySpec{
+		MaxAttempts:    3,
+		InitialBackoff: "1s",
+		MaxBackoff:     "1m",
+		AttemptTimeout: "30s",
+	}
+	assert.Nil(t, policy.Validate())
+}
+
+func TestRetryPolicyValidateNegativeAttempts(t *testing.T) {
+	policy := RetryPolicySpec{
+		MaxAttempts: -1,
+	}
+	assert.NotNil(t, policy.Validate())
+}
+
+func TestRetryPolicyValidateBadDuration(t *testing.T) {
+	policy := RetryPolicySpec{
+		InitialBackoff: "soon",
+	}
+	err := policy.Validate()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "initialBackoff")
+}
+
+func TestRetryPolicyDeepEquals(t *testing.T) {
+	policy := RetryPolicySpec{
+		MaxAttempts:    3,
+		TerminalStates: []v1alpha2.State{v1alpha2.BadConfig},
+	}
+	other := RetryPolicySpec{
+		MaxAttempts:    3,
+		TerminalStates: []v1alpha2.State{v1alpha2.BadConfig},
+	}
+	equal, err := policy.DeepEquals(other)
+	assert.Nil(t, err)
+	assert.True(t, equal)
+
+	other.TerminalStates = []v1alpha2.State{v1alpha2.InternalError}
+	equal, err = policy.DeepEquals(other)
+	assert.Nil(t, err)
+	assert.False(t, equal)
+}
+
+func TestRetryPolicyDeepEqualsNotRetryPolicy(t *testing.T) {
+	policy := RetryPolicySpec{}
+	_, err := policy.DeepEquals(TargetSpec{})
+	assert.NotNil(t, err)
+}
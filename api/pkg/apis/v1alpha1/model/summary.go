@@ -15,13 +15,23 @@ import (
 )
 
 type ComponentResultSpec struct {
-	Status  v1alpha2.State `json:"status"`
-	Message string         `json:"message"`
+	Status    v1alpha2.State       `json:"status"`
+	Message   string               `json:"message"`
+	Readiness *ReadinessResultSpec `json:"readiness,omitempty"`
 }
 type TargetResultSpec struct {
 	Status           string                         `json:"status"`
 	Message          string                         `json:"message,omitempty"`
 	ComponentResults map[string]ComponentResultSpec `json:"components,omitempty"`
+	Attempts         []AttemptResultSpec            `json:"attempts,omitempty"`
+}
+
+// AttemptResultSpec records the outcome of a single attempt to apply a deployment step on a target
+type AttemptResultSpec struct {
+	Attempt int            `json:"attempt"`
+	Status  v1alpha2.State `json:"status"`
+	Message string         `json:"message,omitempty"`
+	Time    time.Time      `json:"time"`
 }
 type SummarySpec struct {
 	TargetCount         int                         `json:"targetCount"`
@@ -34,7 +44,28 @@ type SummarySpec struct {
 	Skipped             bool                        `json:"skipped"`
 	IsRemoval           bool                        `json:"isRemoval"`
 	AllAssignedDeployed bool                        `json:"allAssignedDeployed"`
+	RolledBack          bool                        `json:"rolledBack,omitempty"`
+	RollbackResults     map[string]TargetResultSpec `json:"rollbackResults,omitempty"`
+	Waves               []WaveResultSpec            `json:"waves,omitempty"`
+}
+
+// WaveResultSpec records the outcome of a rollout wave
+type WaveResultSpec struct {
+	Name    string    `json:"name"`
+	Targets []string  `json:"targets"`
+	Status  WaveState `json:"status"`
+	Message string    `json:"message,omitempty"`
 }
+
+type WaveState string
+
+const (
+	WaveStatePending   WaveState = "Pending"
+	WaveStateRunning   WaveState = "Running"
+	WaveStateSucceeded WaveState = "Succeeded"
+	WaveStateFailed    WaveState = "Failed"
+)
+
 type SummaryResult struct {
 	Summary        SummarySpec  `json:"summary"`
 	Generation     string       `json:"generation"`
@@ -68,7 +99,11 @@ func (s *SummarySpec) UpdateTargetResult(target string, spec TargetResultSpec) {
 		}
 		v.Status = status
 		v.Message = message
+		if v.ComponentResults == nil {
+			v.ComponentResults = make(map[string]ComponentResultSpec)
+		}
 		maps.Copy(v.ComponentResults, spec.ComponentResults)
+		v.Attempts = append(v.Attempts, spec.Attempts...)
 		s.TargetResults[target] = v
 	}
 }
@@ -31,6 +31,7 @@ type (
 		Topologies    []TopologySpec    `json:"topologies,omitempty"`
 		ForceRedeploy bool              `json:"forceRedeploy,omitempty"`
 		IsDryRun      bool              `json:"isDryRun,omitempty"`
+		RetryPolicy   *RetryPolicySpec  `json:"retryPolicy,omitempty"`
 	}
 )
 
@@ -72,6 +73,15 @@ func (c TargetSpec) DeepEquals(other IDeepEquals) (bool, error) {
 		return false, nil
 	}
 
+	if (c.RetryPolicy == nil) != (otherC.RetryPolicy == nil) {
+		return false, nil
+	}
+	if c.RetryPolicy != nil {
+		if equal, err := c.RetryPolicy.DeepEquals(*otherC.RetryPolicy); err != nil || !equal {
+			return equal, err
+		}
+	}
+
 	return true, nil
 }
 
@@ -426,3 +426,34 @@ func TestTargetDeepEqualsForceRedeployNotMatch(t *testing.T) {
 	assert.Nil(t, err)
 	assert.False(t, res)
 }
+
+func TestTargetDeepEqualsRetryPolicyNotMatch(t *testing.T) {
+	Target := TargetState{
+		Spec: &TargetSpec{
+			DisplayName: "TargetName",
+			Scope:       "Default",
+			RetryPolicy: &RetryPolicySpec{
+				MaxAttempts:    3,
+				InitialBackoff: "5s",
+			},
+		},
+	}
+	other := TargetState{
+		Spec: &TargetSpec{
+			DisplayName: "TargetName",
+			Scope:       "Default",
+			RetryPolicy: &RetryPolicySpec{
+				MaxAttempts:    3,
+				InitialBackoff: "10s",
+			},
+		},
+	}
+	res, err := Target.DeepEquals(other)
+	assert.Nil(t, err)
+	assert.False(t, res)
+
+	other.Spec.RetryPolicy = nil
+	res, err = Target.DeepEquals(other)
+	assert.Nil(t, err)
+	assert.False(t, res)
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/eclipse-symphony/symphony/api/constants"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
@@ -91,22 +92,116 @@ func CatalogConfigProviderConfigFromMap(properties map[string]string) (CatalogCo
 	return ret, nil
 }
 
-func (m *CatalogConfigProvider) unwindOverrides(ctx context.Context, override string, field string, namespace string, localcontext interface{}, dependencyList map[string]map[string]bool) (interface{}, error) {
-	override = utils.ConvertReferenceToObjectName(override)
-	catalog, err := m.ApiClient.GetCatalog(ctx, override, namespace, m.Config.User, m.Config.Password)
+// getCatalog fetches the catalog of a reference. A reference like 'catalog:v1@3' fetches the third revision of the
+// catalog. The name of the returned catalog is its object name, followed by the revision if there's one.
+func (m *CatalogConfigProvider) getCatalog(ctx context.Context, reference string, namespace string) (model.CatalogState, error) {
+	reference, revision, err := utils.SplitRevisionReference(reference)
 	if err != nil {
-		clog.ErrorCtx(ctx, "  P (Catalog): Unwind overrides error:", err)
-		return "", err
+		return model.CatalogState{}, err
+	}
+	name := utils.ConvertReferenceToObjectName(reference)
+	if revision == 0 {
+		catalog, err := m.ApiClient.GetCatalog(ctx, name, namespace, m.Config.User, m.Config.Password)
+		if err != nil {
+			return model.CatalogState{}, err
+		}
+		catalog.ObjectMeta.Name = name
+		return catalog, nil
+	}
+	old, err := m.ApiClient.GetCatalogRevision(ctx, name, revision, namespace, m.Config.User, m.Config.Password)
+	if err != nil {
+		return model.CatalogState{}, err
+	}
+	if old.Spec == nil {
+		old.Spec = &model.CatalogSpec{}
+	}
+	return model.CatalogState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      fmt.Sprintf("%s%s%d", name, constants.RevisionSeparator, revision),
+			Namespace: old.Namespace,
+		},
+		Spec: old.Spec,
+	}, nil
+}
+
+// readField resolves a field of a catalog and of the catalogs it overrides, combining their values with the merge mode
+// of each catalog
+func (m *CatalogConfigProvider) readField(ctx context.Context, catalog model.CatalogState, field string, namespace string, localcontext interface{}, dependencyList map[string]map[string]bool) (model.ConfigValue, error) {
+	name := catalog.ObjectMeta.Name
+	mode := catalog.Spec.MergeMode()
+	v, ok := utils.JsonParseProperty(catalog.Spec.Properties, field)
+	if !ok && catalog.Spec.ParentName == "" {
+		err := v1alpha2.NewCOAError(nil, fmt.Sprintf("field '%s' is not found in configuration '%s'", field, name), v1alpha2.NotFound)
+		clog.ErrorCtx(ctx, "  P (Catalog): Read field error:", err)
+		return model.ConfigValue{}, err
+	}
+	var own model.ConfigValue
+	if ok {
+		tv, err := m.traceValue(ctx, v, localcontext, dependencyList)
+		if err != nil {
+			return model.ConfigValue{}, err
+		}
+		own = model.NewConfigValue(tv, name, mode)
+		if mode == model.MergeModeReplace || catalog.Spec.ParentName == "" {
+			return own, nil
+		}
 	}
-	if v, ok := utils.JsonParseProperty(catalog.Spec.Properties, field); ok {
-		return m.traceValue(ctx, v, localcontext, dependencyList)
+
+	parent, err := m.getCatalog(ctx, catalog.Spec.ParentName, namespace)
+	if err != nil {
+		clog.ErrorCtx(ctx, "  P (Catalog): Read parent error:", err)
+		return model.ConfigValue{}, err
 	}
-	if catalog.Spec.ParentName != "" {
-		return m.unwindOverrides(ctx, catalog.Spec.ParentName, field, namespace, localcontext, dependencyList)
+	inherited, err := m.readField(ctx, parent, field, namespace, localcontext, dependencyList)
+	if !ok {
+		if err != nil {
+			return model.ConfigValue{}, err
+		}
+		inherited.Chain = append([]model.ConfigLayer{{Catalog: name, MergeMode: mode}}, inherited.Chain...)
+		return inherited, nil
 	}
-	err = v1alpha2.NewCOAError(nil, fmt.Sprintf("field '%s' is not found in configuration '%s'", field, override), v1alpha2.NotFound)
-	clog.ErrorCtx(ctx, "  P (Catalog): Unwind overrides error:", err)
-	return "", err
+	if err != nil {
+		if v1alpha2.IsNotFound(err) {
+			return own, nil
+		}
+		return model.ConfigValue{}, err
+	}
+	return inherited.Override(own, mode), nil
+}
+
+// readObject resolves the properties of a catalog. Catalogs that don't replace the catalogs they override are combined
+// with the properties of their parent catalog. A property that fails to evaluate is set to its error.
+func (m *CatalogConfigProvider) readObject(ctx context.Context, catalog model.CatalogState, namespace string, localcontext interface{}) (model.ConfigValue, []error, error) {
+	name := catalog.ObjectMeta.Name
+	mode := catalog.Spec.MergeMode()
+	errList := make([]error, 0)
+	properties := map[string]interface{}{}
+	for k, v := range catalog.Spec.Properties {
+		tv, err := m.traceValue(ctx, v, localcontext, nil)
+		if err != nil {
+			// Wrap the error using fmt.Errorf("%w", err)
+			wrappedErr := fmt.Errorf("%w", err)
+			errList = append(errList, wrappedErr)
+			tv = err.Error()
+		}
+
+		properties[k] = tv
+	}
+	own := model.NewConfigValue(properties, name, mode)
+	if mode == model.MergeModeReplace || catalog.Spec.ParentName == "" {
+		return own, errList, nil
+	}
+
+	parent, err := m.getCatalog(ctx, catalog.Spec.ParentName, namespace)
+	if err != nil {
+		clog.ErrorCtx(ctx, "  P (Catalog): ReadObject parent error:", err)
+		return model.ConfigValue{}, nil, err
+	}
+	inherited, parentErrList, err := m.readObject(ctx, parent, namespace, localcontext)
+	if err != nil {
+		return model.ConfigValue{}, nil, err
+	}
+	return inherited.Override(own, mode), append(parentErrList, errList...), nil
 }
 
 func (m *CatalogConfigProvider) Read(ctx context.Context, object string, field string, localcontext interface{}) (interface{}, error) {
@@ -117,13 +212,34 @@ func (m *CatalogConfigProvider) Read(ctx context.Context, object string, field s
 	defer observ_utils.CloseSpanWithError(span, &err)
 	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
 	clog.DebugfCtx(ctx, "  P (Catalog): Read, object: %s, field: %s", object, field)
+	value, err := m.explainRead(ctx, object, field, localcontext)
+	if err != nil {
+		return "", err
+	}
+	return value.Value, nil
+}
+
+// ExplainRead reads a field like Read, with the catalogs that supplied its value
+func (m *CatalogConfigProvider) ExplainRead(ctx context.Context, object string, field string, localcontext interface{}) (model.ConfigValue, error) {
+	ctx, span := observability.StartSpan("Catalog Provider", ctx, &map[string]string{
+		"method": "ExplainRead",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+	clog.DebugfCtx(ctx, "  P (Catalog): ExplainRead, object: %s, field: %s", object, field)
+	value, err := m.explainRead(ctx, object, field, localcontext)
+	return value, err
+}
+
+func (m *CatalogConfigProvider) explainRead(ctx context.Context, object string, field string, localcontext interface{}) (model.ConfigValue, error) {
 	namespace := utils.GetNamespaceFromContext(localcontext)
-	object = utils.ConvertReferenceToObjectName(object)
-	catalog, err := m.ApiClient.GetCatalog(ctx, object, namespace, m.Config.User, m.Config.Password)
+	catalog, err := m.getCatalog(ctx, object, namespace)
 	if err != nil {
 		clog.ErrorCtx(ctx, "  P (Catalog): Read error:", err)
-		return "", err
+		return model.ConfigValue{}, err
 	}
+	object = catalog.ObjectMeta.Name
 
 	// check circular dependency
 	var dependencyList map[string]map[string]bool = nil
@@ -131,29 +247,14 @@ func (m *CatalogConfigProvider) Read(ctx context.Context, object string, field s
 		if evalContext, ok := localcontext.(coa_utils.EvaluationContext); ok {
 			if coa_utils.HasCircularDependency(object, field, evalContext) {
 				clog.ErrorfCtx(ctx, "  P (Catalog): Read detect circular dependency. Object: %s, field: %s, ", object, field)
-				return "", v1alpha2.NewCOAError(nil, fmt.Sprintf("Detect circular dependency, object: %s, field: %s", object, field), v1alpha2.BadConfig)
+				return model.ConfigValue{}, v1alpha2.NewCOAError(nil, fmt.Sprintf("Detect circular dependency, object: %s, field: %s", object, field), v1alpha2.BadConfig)
 			}
 			dependencyList = coa_utils.DeepCopyDependencyList(evalContext.ParentConfigs)
 			dependencyList = coa_utils.UpdateDependencyList(object, field, dependencyList)
 		}
 	}
 
-	if v, ok := utils.JsonParseProperty(catalog.Spec.Properties, field); ok {
-		return m.traceValue(ctx, v, localcontext, dependencyList)
-	}
-
-	if catalog.Spec.ParentName != "" {
-		overrid, err := m.unwindOverrides(ctx, catalog.Spec.ParentName, field, namespace, localcontext, dependencyList)
-		if err != nil {
-			return "", err
-		} else {
-			return overrid, nil
-		}
-	}
-
-	err = v1alpha2.NewCOAError(nil, fmt.Sprintf("field '%s' is not found in configuration '%s'", field, object), v1alpha2.NotFound)
-	clog.ErrorCtx(ctx, "  P (Catalog): Read error:", err)
-	return "", err
+	return m.readField(ctx, catalog, field, namespace, localcontext, dependencyList)
 }
 
 func (m *CatalogConfigProvider) ReadObject(ctx context.Context, object string, localcontext interface{}) (map[string]interface{}, error) {
@@ -164,26 +265,36 @@ func (m *CatalogConfigProvider) ReadObject(ctx context.Context, object string, l
 	defer observ_utils.CloseSpanWithError(span, &err)
 	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
 	clog.DebugfCtx(ctx, "  P (Catalog): ReadObject, object: %s", object)
-	namespace := utils.GetNamespaceFromContext(localcontext)
-	object = utils.ConvertReferenceToObjectName(object)
+	value, err := m.explainReadObject(ctx, object, localcontext)
+	if value.Value == nil {
+		return nil, err
+	}
+	return value.Value.(map[string]interface{}), err
+}
 
-	catalog, err := m.ApiClient.GetCatalog(ctx, object, namespace, m.Config.User, m.Config.Password)
+// ExplainReadObject reads an object like ReadObject, with the catalogs that supplied each of its fields
+func (m *CatalogConfigProvider) ExplainReadObject(ctx context.Context, object string, localcontext interface{}) (model.ConfigValue, error) {
+	ctx, span := observability.StartSpan("Catalog Provider", ctx, &map[string]string{
+		"method": "ExplainReadObject",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+	clog.DebugfCtx(ctx, "  P (Catalog): ExplainReadObject, object: %s", object)
+	value, err := m.explainReadObject(ctx, object, localcontext)
+	return value, err
+}
+
+func (m *CatalogConfigProvider) explainReadObject(ctx context.Context, object string, localcontext interface{}) (model.ConfigValue, error) {
+	namespace := utils.GetNamespaceFromContext(localcontext)
+	catalog, err := m.getCatalog(ctx, object, namespace)
 	if err != nil {
 		clog.ErrorCtx(ctx, "  P (Catalog): ReadObject error:", err)
-		return nil, err
+		return model.ConfigValue{}, err
 	}
-	errList := make([]error, 0)
-	ret := map[string]interface{}{}
-	for k, v := range catalog.Spec.Properties {
-		tv, err := m.traceValue(ctx, v, localcontext, nil)
-		if err != nil {
-			// Wrap the error using fmt.Errorf("%w", err)
-			wrappedErr := fmt.Errorf("%w", err)
-			errList = append(errList, wrappedErr)
-			tv = err.Error()
-		}
-
-		ret[k] = tv
+	ret, errList, err := m.readObject(ctx, catalog, namespace, localcontext)
+	if err != nil {
+		return model.ConfigValue{}, err
 	}
 
 	if len(errList) > 0 {
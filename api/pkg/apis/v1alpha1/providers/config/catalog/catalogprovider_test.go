@@ -200,6 +200,207 @@ func TestReadObject(t *testing.T) {
 	assert.Equal(t, "name", res["components"].(map[string]interface{})["Name"])
 }
 
+func TestReadMergeModes(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		switch r.URL.Path {
+		case "/catalogs/registry/site-v-v1":
+			response = model.CatalogState{
+				ObjectMeta: model.ObjectMeta{
+					Name: "site-v-v1",
+				},
+				Spec: &model.CatalogSpec{
+					ParentName: "region:v1",
+					Metadata: map[string]string{
+						"mergeMode": "append-list",
+					},
+					Properties: map[string]interface{}{
+						"network": map[string]interface{}{
+							"ports": []interface{}{8080},
+						},
+					},
+				},
+			}
+		case "/catalogs/registry/region-v-v1":
+			response = model.CatalogState{
+				ObjectMeta: model.ObjectMeta{
+					Name: "region-v-v1",
+				},
+				Spec: &model.CatalogSpec{
+					ParentName: "base:v1",
+					Metadata: map[string]string{
+						"mergeMode": "deep-merge",
+					},
+					Properties: map[string]interface{}{
+						"network": map[string]interface{}{
+							"dns":   "10.0.0.2",
+							"ports": []interface{}{443},
+						},
+						"region": "west",
+					},
+				},
+			}
+		case "/catalogs/registry/base-v-v1":
+			response = model.CatalogState{
+				ObjectMeta: model.ObjectMeta{
+					Name: "base-v-v1",
+				},
+				Spec: &model.CatalogSpec{
+					Properties: map[string]interface{}{
+						"network": map[string]interface{}{
+							"dns":   "8.8.8.8",
+							"mtu":   1500,
+							"ports": []interface{}{80},
+						},
+						"owner": "ops",
+					},
+				},
+			}
+		default:
+			response = AuthResponse{
+				AccessToken: "test-token",
+				TokenType:   "Bearer",
+				Username:    "test-user",
+				Roles:       []string{"role1", "role2"},
+			}
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer ts.Close()
+	os.Setenv(constants.SymphonyAPIUrlEnvName, ts.URL+"/")
+	os.Setenv(constants.UseServiceAccountTokenEnvName, "false")
+	provider := CatalogConfigProvider{}
+	err := provider.Init(CatalogConfigProviderConfig{})
+	provider.Context = &contexts.ManagerContext{
+		VencorContext: &contexts.VendorContext{
+			EvaluationContext: &utils.EvaluationContext{},
+		},
+	}
+	assert.Nil(t, err)
+
+	res, err := provider.ExplainRead(ctx, "site:v1", "network", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"dns":   "10.0.0.2",
+		"mtu":   float64(1500),
+		"ports": []interface{}{float64(443), float64(8080)},
+	}, res.Value)
+	assert.Equal(t, []model.ConfigLayer{
+		{Catalog: "site-v-v1", MergeMode: "append-list"},
+		{Catalog: "region-v-v1", MergeMode: "deep-merge"},
+		{Catalog: "base-v-v1", MergeMode: "replace"},
+	}, res.Chain)
+	assert.Equal(t, map[string]string{
+		"dns":      "region-v-v1",
+		"mtu":      "base-v-v1",
+		"ports[0]": "region-v-v1",
+		"ports[1]": "site-v-v1",
+	}, res.Sources)
+
+	// the region catalog replaces lists, and the base catalog replaces its parent
+	res, err = provider.ExplainRead(ctx, "region:v1", "network", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{float64(443)}, res.Value.(map[string]interface{})["ports"])
+
+	value, err := provider.Read(ctx, "site:v1", "owner", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "ops", value)
+
+	object, err := provider.ExplainReadObject(ctx, "site:v1", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "west", object.Value.(map[string]interface{})["region"])
+	assert.Equal(t, "base-v-v1", object.Sources["owner"])
+	assert.Equal(t, "site-v-v1", object.Sources["network.ports[1]"])
+	assert.Equal(t, 3, len(object.Chain))
+
+	_, err = provider.Read(ctx, "site:v1", "notExist", nil)
+	coaErr := err.(v1alpha2.COAError)
+	assert.Equal(t, v1alpha2.NotFound, coaErr.State)
+}
+
+func TestReadRevision(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var response interface{}
+		switch r.URL.Path {
+		case "/catalogs/registry/site-v-v1":
+			response = model.CatalogState{
+				ObjectMeta: model.ObjectMeta{
+					Name: "site-v-v1",
+				},
+				Spec: &model.CatalogSpec{
+					Properties: map[string]interface{}{
+						"dns": "10.0.0.2",
+					},
+				},
+			}
+		case "/catalogs/revisions/site-v-v1/1":
+			response = model.CatalogRevision{
+				Catalog:  "site-v-v1",
+				Revision: 1,
+				Spec: &model.CatalogSpec{
+					ParentName: "region:v1@2",
+					Properties: map[string]interface{}{
+						"dns": "10.0.0.1",
+					},
+				},
+			}
+		case "/catalogs/revisions/region-v-v1/2":
+			response = model.CatalogRevision{
+				Catalog:  "region-v-v1",
+				Revision: 2,
+				Spec: &model.CatalogSpec{
+					Properties: map[string]interface{}{
+						"ntp": "10.0.1.1",
+					},
+				},
+			}
+		default:
+			response = AuthResponse{
+				AccessToken: "test-token",
+				TokenType:   "Bearer",
+				Username:    "test-user",
+				Roles:       []string{"role1", "role2"},
+			}
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer ts.Close()
+	os.Setenv(constants.SymphonyAPIUrlEnvName, ts.URL+"/")
+	os.Setenv(constants.UseServiceAccountTokenEnvName, "false")
+	provider := CatalogConfigProvider{}
+	err := provider.Init(CatalogConfigProviderConfig{})
+	provider.Context = &contexts.ManagerContext{
+		VencorContext: &contexts.VendorContext{
+			EvaluationContext: &utils.EvaluationContext{},
+		},
+	}
+	assert.Nil(t, err)
+
+	res, err := provider.Read(ctx, "site:v1", "dns", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.2", res)
+
+	res, err = provider.Read(ctx, "site:v1@1", "dns", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1", res)
+
+	value, err := provider.ExplainRead(ctx, "site:v1@1", "ntp", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.1.1", value.Value)
+	assert.Equal(t, "region-v-v1@2", value.Sources[""])
+	assert.Equal(t, "site-v-v1@1", value.Chain[0].Catalog)
+
+	object, err := provider.ReadObject(ctx, "site:v1@1", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1", object["dns"])
+
+	_, err = provider.Read(ctx, "site:v1@latest", "dns", nil)
+	coaErr := err.(v1alpha2.COAError)
+	assert.Equal(t, v1alpha2.BadRequest, coaErr.State)
+}
+
 func TestSetandRemove(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var response interface{}
@@ -13,6 +13,9 @@ import (
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	catalogconfig "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/config/catalog"
 	memorygraph "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/graph/memory"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage"
+	approvalstage "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage/approval"
+	campaignstage "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage/campaign"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage/counter"
 	symphonystage "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage/create"
 	delaystage "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage/delay"
@@ -50,6 +53,7 @@ import (
 	httpreporter "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/reporter/http"
 	k8sreporter "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/reporter/k8s"
 	mocksecret "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/secret/mock"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/filestate"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/httpstate"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/memorystate"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/redisstate"
@@ -94,6 +98,10 @@ func TestCreateProvider(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, *provider.(*httpstate.HttpStateProvider))
 
+	provider, err = providerfactory.CreateProvider("providers.state.file", filestate.FileStateProviderConfig{Path: t.TempDir()})
+	assert.Nil(t, err)
+	assert.NotNil(t, provider.(*filestate.FileStateProvider))
+
 	if getTestMiniKubeEnabled == "" {
 		t.Log("Skipping providers.reference.k8s test as TEST_MINIKUBE_ENABLED is not set")
 	} else {
@@ -258,6 +266,14 @@ func TestCreateProvider(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, *provider.(*waitstage.WaitStageProvider))
 
+	provider, err = providerfactory.CreateProvider("providers.stage.campaign", campaignstage.CampaignStageProviderConfig{})
+	assert.Nil(t, err)
+	assert.NotNil(t, *provider.(*campaignstage.CampaignStageProvider))
+
+	provider, err = providerfactory.CreateProvider("providers.stage.approval", approvalstage.ApprovalStageProviderConfig{})
+	assert.Nil(t, err)
+	assert.NotNil(t, *provider.(*approvalstage.ApprovalStageProvider))
+
 	provider, err = providerfactory.CreateProvider("providers.stage.delay", delaystage.DelayStageProviderConfig{})
 	assert.Nil(t, err)
 	assert.NotNil(t, *provider.(*delaystage.DelayStageProvider))
@@ -535,6 +551,22 @@ func TestCreateProviderForTargetRole(t *testing.T) {
 								"password": "",
 							},
 						},
+						{
+							Role:     "campaignstage",
+							Provider: "providers.stage.campaign",
+							Config: map[string]string{
+								"baseUrl":  "fakeUrl",
+								"user":     "admin",
+								"password": "",
+							},
+						},
+						{
+							Role:     "approvalstage",
+							Provider: "providers.stage.approval",
+							Config: map[string]string{
+								"approvers": "admin",
+							},
+						},
 						{
 							Role:     "delaystage",
 							Provider: "providers.stage.delay",
@@ -746,6 +778,14 @@ func TestCreateProviderForTargetRole(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, *provider.(*waitstage.WaitStageProvider))
 
+	provider, err = CreateProviderForTargetRole(nil, "campaignstage", targetState, nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, *provider.(*campaignstage.CampaignStageProvider))
+
+	provider, err = CreateProviderForTargetRole(nil, "approvalstage", targetState, nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, *provider.(*approvalstage.ApprovalStageProvider))
+
 	provider, err = CreateProviderForTargetRole(nil, "delaystage", targetState, nil)
 	assert.Nil(t, err)
 	assert.NotNil(t, *provider.(*delaystage.DelayStageProvider))
@@ -770,3 +810,13 @@ func TestCreateProviderForTargetRole(t *testing.T) {
 		assert.NotNil(t, *provider.(*k8sreporter.K8sReporter))
 	}
 }
+
+func TestCreateStageProviderTypes(t *testing.T) {
+	providerfactory := SymphonyProviderFactory{}
+	for _, providerType := range stage.ProviderTypes {
+		provider, err := providerfactory.CreateProvider(providerType, map[string]interface{}{})
+		assert.Nil(t, err, providerType)
+		_, ok := provider.(stage.IStageProvider)
+		assert.True(t, ok, providerType)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/metrics"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/contexts"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability"
+	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
+)
+
+const (
+	loggerName   = "providers.stage.approval"
+	providerName = "P (Approval Stage)"
+	approval     = "approval"
+)
+
+var (
+	log                      = logger.NewLogger(loggerName)
+	mwLock                   sync.Mutex
+	once                     sync.Once
+	providerOperationMetrics *metrics.Metrics
+)
+
+type ApprovalStageProviderConfig struct {
+	Approvers []string `json:"approvers,omitempty"`
+	Groups    []string `json:"groups,omitempty"`
+}
+
+// ApprovalStageProvider pauses the activation until an approver approves or rejects the stage through the approve or
+// reject call of the stage vendor.
+type ApprovalStageProvider struct {
+	Config  ApprovalStageProviderConfig
+	Context *contexts.ManagerContext
+}
+
+func (s *ApprovalStageProvider) Init(config providers.IProviderConfig) error {
+	ctx, span := observability.StartSpan("[Stage] Approval Provider", context.TODO(), &map[string]string{
+		"method": "Init",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	mwLock.Lock()
+	defer mwLock.Unlock()
+	var approvalConfig ApprovalStageProviderConfig
+	approvalConfig, err = toApprovalStageProviderConfig(config)
+	if err != nil {
+		return err
+	}
+	s.Config = approvalConfig
+	once.Do(func() {
+		if providerOperationMetrics == nil {
+			providerOperationMetrics, err = metrics.New()
+			if err != nil {
+				log.ErrorfCtx(ctx, "  P (Approval Stage): failed to create metrics: %+v", err)
+			}
+		}
+	})
+	return err
+}
+func (s *ApprovalStageProvider) SetContext(ctx *contexts.ManagerContext) {
+	s.Context = ctx
+}
+func toApprovalStageProviderConfig(config providers.IProviderConfig) (ApprovalStageProviderConfig, error) {
+	ret := ApprovalStageProviderConfig{}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return ret, err
+	}
+	err = json.Unmarshal(data, &ret)
+	return ret, err
+}
+func (i *ApprovalStageProvider) InitWithMap(properties map[string]string) error {
+	config, err := ApprovalStageProviderConfigFromMap(properties)
+	if err != nil {
+		return err
+	}
+	return i.Init(config)
+}
+func ApprovalStageProviderConfigFromMap(properties map[string]string) (ApprovalStageProviderConfig, error) {
+	ret := ApprovalStageProviderConfig{}
+	ret.Approvers = splitList(properties["approvers"])
+	ret.Groups = splitList(properties["groups"])
+	return ret, nil
+}
+
+// Process pauses the activation and records the approvers and groups that may decide on the stage. The "approvers"
+// and "groups" inputs, as lists or comma-separated strings, are added to the approvers and groups of the provider
+// config, and the optional "message" input is shown to the approvers.
+func (i *ApprovalStageProvider) Process(ctx context.Context, mgrContext contexts.ManagerContext, inputs map[string]interface{}) (map[string]interface{}, bool, error) {
+	ctx, span := observability.StartSpan("[Stage] Approval Provider", ctx, &map[string]string{
+		"method": "Process",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	log.InfoCtx(ctx, "  P (Approval Stage): processing inputs")
+	processTime := time.Now().UTC()
+	functionName := observ_utils.GetFunctionName()
+	defer providerOperationMetrics.ProviderOperationLatency(
+		processTime,
+		approval,
+		metrics.ProcessOperation,
+		metrics.RunOperationType,
+		functionName,
+	)
+
+	var approvers, groups []string
+	approvers, err = mergeList(i.Config.Approvers, inputs["approvers"])
+	if err == nil {
+		groups, err = mergeList(i.Config.Groups, inputs["groups"])
+	}
+	if err == nil && len(approvers) == 0 && len(groups) == 0 {
+		err = v1alpha2.NewCOAError(nil, "approval stage requires at least one approver or group", v1alpha2.BadRequest)
+	}
+	if err != nil {
+		providerOperationMetrics.ProviderOperationErrors(
+			approval,
+			functionName,
+			metrics.ProcessOperation,
+			metrics.ValidateOperationType,
+			v1alpha2.BadRequest.String(),
+		)
+		return nil, false, err
+	}
+
+	outputs := map[string]interface{}{
+		model.ApprovalOutput:       model.ApprovalPending,
+		model.ApproversOutput:      approvers,
+		model.ApprovalGroupsOutput: groups,
+	}
+	if v, ok := inputs["message"]; ok {
+		outputs["message"] = v
+	}
+	log.InfofCtx(ctx, "  P (Approval Stage): stage %v of activation %v waits for approvers %v or groups %v", inputs["__stage"], inputs["__activation"], approvers, groups)
+	return outputs, true, nil
+}
+
+func mergeList(list []string, value interface{}) ([]string, error) {
+	ret := append([]string{}, list...)
+	var items []string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		items = splitList(v)
+	case []string, []interface{}:
+		items = model.ToStringList(v)
+	default:
+		return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("%v is not a valid list", value), v1alpha2.BadRequest)
+	}
+	for _, item := range items {
+		found := false
+		for _, r := range ret {
+			if r == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, item)
+		}
+	}
+	return ret, nil
+}
+
+func splitList(value string) []string {
+	ret := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package approval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/contexts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalConfigFromMap(t *testing.T) {
+	config, err := ApprovalStageProviderConfigFromMap(map[string]string{
+		"approvers": "alice, bob",
+		"groups":    "release-managers",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, config.Approvers)
+	assert.Equal(t, []string{"release-managers"}, config.Groups)
+}
+
+func TestApprovalProcess(t *testing.T) {
+	provider := ApprovalStageProvider{}
+	err := provider.InitWithMap(map[string]string{
+		"approvers": "alice",
+	})
+	assert.Nil(t, err)
+
+	outputs, pause, err := provider.Process(context.Background(), contexts.ManagerContext{}, map[string]interface{}{
+		"approvers": []interface{}{"alice", "bob"},
+		"groups":    "release-managers,operators",
+		"message":   "approve the production wave",
+	})
+	assert.Nil(t, err)
+	assert.True(t, pause)
+	assert.Equal(t, model.ApprovalPending, outputs[model.ApprovalOutput])
+	assert.Equal(t, []string{"alice", "bob"}, outputs[model.ApproversOutput])
+	assert.Equal(t, []string{"release-managers", "operators"}, outputs[model.ApprovalGroupsOutput])
+	assert.Equal(t, "approve the production wave", outputs["message"])
+}
+
+func TestApprovalProcessInvalidInputs(t *testing.T) {
+	provider := ApprovalStageProvider{}
+	err := provider.InitWithMap(map[string]string{})
+	assert.Nil(t, err)
+
+	_, pause, err := provider.Process(context.Background(), contexts.ManagerContext{}, map[string]interface{}{})
+	assert.NotNil(t, err)
+	assert.False(t, pause)
+
+	_, _, err = provider.Process(context.Background(), contexts.ManagerContext{}, map[string]interface{}{
+		"approvers": 42,
+	})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,304 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/constants"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/metrics"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage"
+	api_utils "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/contexts"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability"
+	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	loggerName          = "providers.stage.campaign"
+	providerName        = "P (Campaign Stage)"
+	campaign            = "campaign"
+	defaultWaitInterval = 5
+)
+
+var (
+	log                      = logger.NewLogger(loggerName)
+	mwLock                   sync.Mutex
+	once                     sync.Once
+	providerOperationMetrics *metrics.Metrics
+)
+
+type CampaignStageProviderConfig struct {
+	User         string `json:"user"`
+	Password     string `json:"password"`
+	WaitInterval int    `json:"wait.interval,omitempty"`
+}
+
+// CampaignStageProvider starts another campaign as a child activation of the running activation, and waits for it
+// to finish unless the stage fires and forgets.
+type CampaignStageProvider struct {
+	Config    CampaignStageProviderConfig
+	Context   *contexts.ManagerContext
+	ApiClient api_utils.ApiClient
+}
+
+func (s *CampaignStageProvider) Init(config providers.IProviderConfig) error {
+	ctx, span := observability.StartSpan("[Stage] Campaign Provider", context.TODO(), &map[string]string{
+		"method": "Init",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	mwLock.Lock()
+	defer mwLock.Unlock()
+	var campaignConfig CampaignStageProviderConfig
+	campaignConfig, err = toCampaignStageProviderConfig(config)
+	if err != nil {
+		return err
+	}
+	s.Config = campaignConfig
+	s.ApiClient, err = api_utils.GetApiClient()
+	if err != nil {
+		return err
+	}
+	once.Do(func() {
+		if providerOperationMetrics == nil {
+			providerOperationMetrics, err = metrics.New()
+			if err != nil {
+				log.ErrorfCtx(ctx, "  P (Campaign Stage): failed to create metrics: %+v", err)
+			}
+		}
+	})
+	return err
+}
+func (s *CampaignStageProvider) SetContext(ctx *contexts.ManagerContext) {
+	s.Context = ctx
+}
+func toCampaignStageProviderConfig(config providers.IProviderConfig) (CampaignStageProviderConfig, error) {
+	ret := CampaignStageProviderConfig{}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return ret, err
+	}
+	err = json.Unmarshal(data, &ret)
+	return ret, err
+}
+func (i *CampaignStageProvider) InitWithMap(properties map[string]string) error {
+	config, err := CampaignStageProviderConfigFromMap(properties)
+	if err != nil {
+		return err
+	}
+	return i.Init(config)
+}
+func CampaignStageProviderConfigFromMap(properties map[string]string) (CampaignStageProviderConfig, error) {
+	ret := CampaignStageProviderConfig{}
+	user, err := api_utils.GetString(properties, "user")
+	if err != nil {
+		return ret, err
+	}
+	ret.User = user
+	if ret.User == "" {
+		return ret, v1alpha2.NewCOAError(nil, "user is required", v1alpha2.BadConfig)
+	}
+	password, err := api_utils.GetString(properties, "password")
+	if err != nil {
+		return ret, err
+	}
+	ret.Password = password
+	if v, ok := properties["wait.interval"]; ok {
+		interval, err := strconv.Atoi(v)
+		if err != nil {
+			return ret, v1alpha2.NewCOAError(err, fmt.Sprintf("failed to parse wait interval %v", v), v1alpha2.BadConfig)
+		}
+		ret.WaitInterval = interval
+	}
+	return ret, nil
+}
+
+// Process starts the child activation of the campaign in the "campaign" input, with the "inputs" input as activation
+// inputs and the optional "stage" input as the first stage. The child is named after the "activation" input, or
+// after the parent activation and the stage. Unless "wait" is false, the stage waits for the child to finish and
+// returns the outputs of its last stage.
+func (i *CampaignStageProvider) Process(ctx context.Context, mgrContext contexts.ManagerContext, inputs map[string]interface{}) (map[string]interface{}, bool, error) {
+	ctx, span := observability.StartSpan("[Stage] Campaign Provider", ctx, &map[string]string{
+		"method": "Process",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	log.InfoCtx(ctx, "  P (Campaign Stage): processing inputs")
+	processTime := time.Now().UTC()
+	functionName := observ_utils.GetFunctionName()
+	defer providerOperationMetrics.ProviderOperationLatency(
+		processTime,
+		campaign,
+		metrics.ProcessOperation,
+		metrics.RunOperationType,
+		functionName,
+	)
+
+	campaignName, ok := inputs["campaign"].(string)
+	if !ok || campaignName == "" {
+		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("campaign is not a valid string: %v", inputs["campaign"]), v1alpha2.BadRequest)
+		providerOperationMetrics.ProviderOperationErrors(
+			campaign,
+			functionName,
+			metrics.ProcessOperation,
+			metrics.ValidateOperationType,
+			v1alpha2.BadConfig.String(),
+		)
+		return nil, false, err
+	}
+	wait := true
+	if v, ok := inputs["wait"]; ok {
+		wait, err = strconv.ParseBool(fmt.Sprintf("%v", v))
+		if err != nil {
+			err = v1alpha2.NewCOAError(err, fmt.Sprintf("wait is not a valid bool: %v", v), v1alpha2.BadRequest)
+			return nil, false, err
+		}
+	}
+	var childInputs map[string]interface{}
+	if v, ok := inputs["inputs"]; ok && v != nil {
+		if childInputs, ok = v.(map[string]interface{}); !ok {
+			err = v1alpha2.NewCOAError(nil, fmt.Sprintf("inputs is not a valid map: %v", v), v1alpha2.BadRequest)
+			return nil, false, err
+		}
+	}
+	firstStage, _ := inputs["stage"].(string)
+	namespace := stage.GetNamespace(inputs)
+	if namespace == "" {
+		namespace = "default"
+	}
+	parent, _ := inputs["__activation"].(string)
+	child, _ := inputs["activation"].(string)
+	if child == "" {
+		child = childActivationName(parent, inputs["__stage"], inputs["__site"], campaignName)
+	}
+
+	childState := model.ActivationState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      child,
+			Namespace: namespace,
+		},
+		Spec: &model.ActivationSpec{
+			Campaign: campaignName,
+			Stage:    firstStage,
+			Inputs:   childInputs,
+		},
+	}
+	if parent != "" {
+		childState.ObjectMeta.Annotations = map[string]string{
+			constants.ParentActivation: parent,
+		}
+	}
+	payload, _ := json.Marshal(childState)
+	log.InfofCtx(ctx, "  P (Campaign Stage): starting activation %s of campaign %s in namespace %s", child, campaignName, namespace)
+	err = i.ApiClient.CreateActivation(ctx, child, payload, namespace, i.Config.User, i.Config.Password)
+	if err != nil {
+		log.ErrorfCtx(ctx, "  P (Campaign Stage): failed to start activation %s: %v", child, err)
+		providerOperationMetrics.ProviderOperationErrors(
+			campaign,
+			functionName,
+			metrics.ProcessOperation,
+			metrics.RunOperationType,
+			v1alpha2.InternalError.String(),
+		)
+		return nil, false, err
+	}
+
+	outputs := map[string]interface{}{
+		"activation": child,
+	}
+	if !wait {
+		return outputs, false, nil
+	}
+
+	var childStatus *model.ActivationStatus
+	childStatus, err = i.waitForActivation(ctx, child, namespace)
+	if err != nil {
+		if ctx.Err() != nil {
+			// the stage is cancelled or timed out, so nobody waits for the child anymore
+			if cErr := i.ApiClient.CancelActivation(context.WithoutCancel(ctx), child, namespace, i.Config.User, i.Config.Password); cErr != nil {
+				log.InfofCtx(ctx, "  P (Campaign Stage): activation %s is not cancelled: %v", child, cErr)
+			}
+		}
+		return nil, false, err
+	}
+
+	if len(childStatus.StageHistory) > 0 {
+		for k, v := range childStatus.StageHistory[len(childStatus.StageHistory)-1].Outputs {
+			outputs[k] = v
+		}
+	}
+	outputs["activation"] = child
+	outputs["status"] = childStatus.Status.String()
+	if childStatus.Status != v1alpha2.Done {
+		message := fmt.Sprintf("activation %s is %s", child, childStatus.Status.String())
+		if len(childStatus.StageHistory) > 0 && childStatus.StageHistory[len(childStatus.StageHistory)-1].ErrorMessage != "" {
+			message = fmt.Sprintf("%s: %s", message, childStatus.StageHistory[len(childStatus.StageHistory)-1].ErrorMessage)
+		}
+		err = v1alpha2.NewCOAError(nil, message, v1alpha2.InternalError)
+		log.ErrorfCtx(ctx, "  P (Campaign Stage): %s", message)
+		return outputs, false, err
+	}
+	log.InfofCtx(ctx, "  P (Campaign Stage): activation %s is done", child)
+	return outputs, false, nil
+}
+
+// waitForActivation polls the child activation until it isn't active anymore
+func (i *CampaignStageProvider) waitForActivation(ctx context.Context, name string, namespace string) (*model.ActivationStatus, error) {
+	interval := i.Config.WaitInterval
+	if interval <= 0 {
+		interval = defaultWaitInterval
+	}
+	for {
+		state, err := i.ApiClient.GetActivation(ctx, name, namespace, i.Config.User, i.Config.Password)
+		if err != nil {
+			log.ErrorfCtx(ctx, "  P (Campaign Stage): failed to get activation %s: %v", name, err)
+			return nil, err
+		}
+		if state.Status != nil && !state.Status.IsActive() {
+			return state.Status, nil
+		}
+		timer := time.NewTimer(time.Duration(interval) * time.Second)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// childActivationName names a child activation after its parent, stage and site. A random suffix keeps the names of
+// the children that are started at the same time apart, like those of a stage that fans out to many sites.
+func childActivationName(parent string, stageName interface{}, siteName interface{}, campaignName string) string {
+	base := parent
+	if base == "" {
+		base = api_utils.ConvertReferenceToObjectName(campaignName)
+	}
+	if s, ok := stageName.(string); ok && s != "" {
+		base = fmt.Sprintf("%s-%s", base, s)
+	}
+	if s, ok := siteName.(string); ok && s != "" {
+		base = fmt.Sprintf("%s-%s", base, s)
+	}
+	return strings.ToLower(fmt.Sprintf("%s-%d-%s", base, time.Now().UTC().Unix(), uuid.New().String()[:8]))
+}
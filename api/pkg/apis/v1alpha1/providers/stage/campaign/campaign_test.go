@@ -0,0 +1,246 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse-symphony/symphony/api/constants"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/contexts"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSymphonyAPI struct {
+	lock      sync.Mutex
+	created   map[string]model.ActivationState
+	gets      int
+	cancelled []string
+	// status returns the status of the child activation on each get
+	status func(gets int) model.ActivationStatus
+}
+
+func (m *mockSymphonyAPI) serve() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		var response interface{}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/activations/registry/") && r.Method == http.MethodPost:
+			var state model.ActivationState
+			body, _ := io.ReadAll(r.Body)
+			json.Unmarshal(body, &state)
+			m.created[strings.TrimPrefix(r.URL.Path, "/activations/registry/")] = state
+			response = state
+		case strings.HasPrefix(r.URL.Path, "/activations/registry/"):
+			m.gets++
+			status := m.status(m.gets)
+			response = model.ActivationState{
+				Spec:   &model.ActivationSpec{},
+				Status: &status,
+			}
+		case strings.HasPrefix(r.URL.Path, "/activations/cancel/"):
+			m.cancelled = append(m.cancelled, strings.TrimPrefix(r.URL.Path, "/activations/cancel/"))
+			response = model.ActivationState{}
+		default:
+			response = map[string]interface{}{
+				"accessToken": "test-token",
+				"tokenType":   "Bearer",
+			}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+}
+
+func createProvider(t *testing.T, api *mockSymphonyAPI) (*CampaignStageProvider, *httptest.Server) {
+	ts := api.serve()
+	os.Setenv(constants.SymphonyAPIUrlEnvName, ts.URL+"/")
+	os.Setenv(constants.UseServiceAccountTokenEnvName, "false")
+	provider := CampaignStageProvider{}
+	err := provider.InitWithMap(map[string]string{
+		"user":          "admin",
+		"password":      "",
+		"wait.interval": "1",
+	})
+	assert.Nil(t, err)
+	return &provider, ts
+}
+
+func TestCampaignConfigFromMap(t *testing.T) {
+	config, err := CampaignStageProviderConfigFromMap(map[string]string{
+		"user":          "admin",
+		"password":      "",
+		"wait.interval": "10",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "admin", config.User)
+	assert.Equal(t, 10, config.WaitInterval)
+
+	_, err = CampaignStageProviderConfigFromMap(map[string]string{
+		"user": "",
+	})
+	assert.NotNil(t, err)
+
+	_, err = CampaignStageProviderConfigFromMap(map[string]string{
+		"user":          "admin",
+		"password":      "",
+		"wait.interval": "abc",
+	})
+	assert.NotNil(t, err)
+}
+
+func TestCampaignProcessWait(t *testing.T) {
+	api := &mockSymphonyAPI{
+		created: map[string]model.ActivationState{},
+		status: func(gets int) model.ActivationStatus {
+			if gets < 2 {
+				return model.ActivationStatus{Status: v1alpha2.Running}
+			}
+			return model.ActivationStatus{
+				Status: v1alpha2.Done,
+				StageHistory: []model.StageStatus{
+					{
+						Stage:   "verify",
+						Outputs: map[string]interface{}{"healthy": true},
+					},
+				},
+			}
+		},
+	}
+	provider, ts := createProvider(t, api)
+	defer ts.Close()
+
+	outputs, pause, err := provider.Process(context.Background(), contexts.ManagerContext{}, map[string]interface{}{
+		"campaign":     "drain-upgrade:v1",
+		"activation":   "child",
+		"inputs":       map[string]interface{}{"site": "site1"},
+		"__activation": "parent",
+		"__namespace":  "test",
+	})
+	assert.Nil(t, err)
+	assert.False(t, pause)
+	assert.Equal(t, "child", outputs["activation"])
+	assert.Equal(t, v1alpha2.Done.String(), outputs["status"])
+	assert.Equal(t, true, outputs["healthy"])
+
+	child := api.created["child"]
+	assert.Equal(t, "drain-upgrade:v1", child.Spec.Campaign)
+	assert.Equal(t, "site1", child.Spec.Inputs["site"])
+	assert.Equal(t, "test", child.ObjectMeta.Namespace)
+	assert.Equal(t, "parent", child.ObjectMeta.Annotations[constants.ParentActivation])
+}
+
+func TestCampaignProcessFireAndForget(t *testing.T) {
+	api := &mockSymphonyAPI{
+		created: map[string]model.ActivationState{},
+	}
+	provider, ts := createProvider(t, api)
+	defer ts.Close()
+
+	outputs, _, err := provider.Process(context.Background(), contexts.ManagerContext{}, map[string]interface{}{
+		"campaign":     "drain-upgrade:v1",
+		"wait":         false,
+		"__activation": "parent",
+		"__stage":      "upgrade",
+	})
+	assert.Nil(t, err)
+	name := outputs["activation"].(string)
+	assert.True(t, strings.HasPrefix(name, "parent-upgrade-"))
+	assert.Equal(t, 1, len(api.created))
+	assert.Equal(t, 0, api.gets)
+}
+
+func TestCampaignProcessChildFailed(t *testing.T) {
+	api := &mockSymphonyAPI{
+		created: map[string]model.ActivationState{},
+		status: func(gets int) model.ActivationStatus {
+			return model.ActivationStatus{
+				Status: v1alpha2.InternalError,
+				StageHistory: []model.StageStatus{
+					{
+						Stage:        "upgrade",
+						Status:       v1alpha2.InternalError,
+						ErrorMessage: "upgrade failed",
+					},
+				},
+			}
+		},
+	}
+	provider, ts := createProvider(t, api)
+	defer ts.Close()
+
+	outputs, _, err := provider.Process(context.Background(), contexts.ManagerContext{}, map[string]interface{}{
+		"campaign":   "drain-upgrade:v1",
+		"activation": "child",
+	})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "upgrade failed")
+	assert.Equal(t, v1alpha2.InternalError.String(), outputs["status"])
+}
+
+func TestCampaignProcessCancelled(t *testing.T) {
+	api := &mockSymphonyAPI{
+		created: map[string]model.ActivationState{},
+		status: func(gets int) model.ActivationStatus {
+			return model.ActivationStatus{Status: v1alpha2.Running}
+		},
+	}
+	provider, ts := createProvider(t, api)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	_, _, err := provider.Process(ctx, contexts.ManagerContext{}, map[string]interface{}{
+		"campaign":   "drain-upgrade:v1",
+		"activation": "child",
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []string{"child"}, api.cancelled)
+}
+
+func TestCampaignProcessInvalidInputs(t *testing.T) {
+	api := &mockSymphonyAPI{
+		created: map[string]model.ActivationState{},
+	}
+	provider, ts := createProvider(t, api)
+	defer ts.Close()
+
+	_, _, err := provider.Process(context.Background(), contexts.ManagerContext{}, map[string]interface{}{})
+	assert.NotNil(t, err)
+	_, _, err = provider.Process(context.Background(), contexts.ManagerContext{}, map[string]interface{}{
+		"campaign": "drain-upgrade:v1",
+		"wait":     "sometimes",
+	})
+	assert.NotNil(t, err)
+	_, _, err = provider.Process(context.Background(), contexts.ManagerContext{}, map[string]interface{}{
+		"campaign": "drain-upgrade:v1",
+		"inputs":   "site1",
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, len(api.created))
+}
+
+func TestChildActivationName(t *testing.T) {
+	name := childActivationName("parent", "fan", "Site1", "drain-upgrade:v1")
+	assert.True(t, strings.HasPrefix(name, "parent-fan-site1-"))
+	// children of a stage that fans out to a site at the same second don't collide
+	assert.NotEqual(t, name, childActivationName("parent", "fan", "Site1", "drain-upgrade:v1"))
+	assert.True(t, strings.HasPrefix(childActivationName("", nil, nil, "drain-upgrade:v1"), "drain-upgrade-v-v1-"))
+}
@@ -140,19 +140,36 @@ func (i *DelayStageProvider) Process(ctx context.Context, mgrContext contexts.Ma
 				}
 			}
 			observ_utils.EmitUserAuditsLogs(ctx, "  P (Delay Stage): Delaying for %s", duration)
-			time.Sleep(duration)
+			err = sleep(ctx, duration)
 		case int:
 			observ_utils.EmitUserAuditsLogs(ctx, "  P (Delay Stage): Delaying for %d seconds", vs)
-			time.Sleep(time.Duration(vs) * time.Second)
+			err = sleep(ctx, time.Duration(vs)*time.Second)
 		case int32:
 			observ_utils.EmitUserAuditsLogs(ctx, "  P (Delay Stage): Delaying for %d seconds", vs)
-			time.Sleep(time.Duration(vs) * time.Second)
+			err = sleep(ctx, time.Duration(vs)*time.Second)
 		case int64:
 			observ_utils.EmitUserAuditsLogs(ctx, "  P (Delay Stage): Delaying for %d seconds", vs)
-			time.Sleep(time.Duration(vs) * time.Second)
+			err = sleep(ctx, time.Duration(vs)*time.Second)
 		}
 	}
 
+	if err != nil && ctx.Err() != nil {
+		mLog.InfofCtx(ctx, "  P (Delay Stage) process is cancelled: %+v", err)
+		return nil, false, err
+	}
+
 	mLog.InfoCtx(ctx, "  P (Delay Stage) process completed")
 	return outputs, false, nil
 }
+
+// sleep waits for the duration, or until the context is cancelled
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -70,6 +70,23 @@ func TestDelayProcess(t *testing.T) {
 	assert.GreaterOrEqual(t, dt2.Sub(dt1).Seconds(), 1.0)
 }
 
+func TestDelayProcessCancelled(t *testing.T) {
+	provider := DelayStageProvider{}
+	err := provider.InitWithMap(map[string]string{})
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+	dt1 := time.Now()
+	_, _, err = provider.Process(ctx, contexts.ManagerContext{}, map[string]interface{}{
+		"delay": "1m",
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Less(t, time.Since(dt1).Seconds(), 10.0)
+}
+
 func TestDelayProcessFailedCase(t *testing.T) {
 	provider := DelayStageProvider{}
 	err := provider.InitWithMap(map[string]string{})
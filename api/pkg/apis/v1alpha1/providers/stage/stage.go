@@ -38,3 +38,30 @@ func GetNamespace(inputs map[string]interface{}) string {
 	}
 	return ReadInputString(inputs, "__namespace")
 }
+
+// ProviderTypes lists the stage providers the provider factory can create for a campaign stage
+var ProviderTypes = []string{
+	"providers.stage.approval",
+	"providers.stage.campaign",
+	"providers.stage.counter",
+	"providers.stage.create",
+	"providers.stage.delay",
+	"providers.stage.http",
+	"providers.stage.list",
+	"providers.stage.materialize",
+	"providers.stage.mock",
+	"providers.stage.patch",
+	"providers.stage.remote",
+	"providers.stage.script",
+	"providers.stage.wait",
+}
+
+// IsProviderType tells if the provider type is one of the registered stage providers
+func IsProviderType(providerType string) bool {
+	for _, t := range ProviderTypes {
+		if t == providerType {
+			return true
+		}
+	}
+	return false
+}
@@ -27,6 +27,7 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -201,6 +202,18 @@ func (s *K8sStateProvider) Upsert(ctx context.Context, entry states.UpsertReques
 	j, _ := json.Marshal(entry.Value.Body)
 	var item *unstructured.Unstructured
 	item, err = s.DynamicClient.Resource(resourceId).Namespace(namespace).Get(ctx, entry.Value.ID, metav1.GetOptions{})
+	// the etag of an entry is the resource version of its object, which the API server checks on updates; an empty etag
+	// only matches an object that doesn't exist yet
+	if entry.ETag != nil {
+		if err != nil && *entry.ETag != "" || err == nil && *entry.ETag == "" {
+			err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' has changed, its etag is not %s", entry.Value.ID, *entry.ETag), v1alpha2.Conflict)
+			sLog.ErrorfCtx(ctx, "  P (K8s State): failed to upsert state: %v", err)
+			return "", err
+		}
+		if err == nil {
+			item.SetResourceVersion(*entry.ETag)
+		}
+	}
 	if err != nil {
 		template := fmt.Sprintf(`{"apiVersion":"%s/v1", "kind": "%s", "metadata": {}}`, group, kind)
 		var unc *unstructured.Unstructured
@@ -231,6 +244,9 @@ func (s *K8sStateProvider) Upsert(ctx context.Context, entry states.UpsertReques
 		_, err = s.DynamicClient.Resource(resourceId).Namespace(namespace).Create(ctx, unc, metav1.CreateOptions{})
 		if err != nil {
 			sLog.ErrorfCtx(ctx, "  P (K8s State): failed to create object: %v", err)
+			if entry.ETag != nil && k8s_errors.IsAlreadyExists(err) {
+				err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' has changed, its etag is not %s", entry.Value.ID, *entry.ETag), v1alpha2.Conflict)
+			}
 			return "", err
 		}
 		//Note: state is ignored for new object
@@ -262,6 +278,9 @@ func (s *K8sStateProvider) Upsert(ctx context.Context, entry states.UpsertReques
 			_, err = s.DynamicClient.Resource(resourceId).Namespace(namespace).Update(ctx, item, metav1.UpdateOptions{})
 			if err != nil {
 				sLog.ErrorfCtx(ctx, "  P (K8s State): failed to update object: %v", err)
+				if entry.ETag != nil && k8s_errors.IsConflict(err) {
+					err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' has changed, its etag is not %s", entry.Value.ID, *entry.ETag), v1alpha2.Conflict)
+				}
 				return "", err
 			}
 			getResourceVersion = true
@@ -291,6 +310,9 @@ func (s *K8sStateProvider) Upsert(ctx context.Context, entry states.UpsertReques
 				_, err = s.DynamicClient.Resource(resourceId).Namespace(namespace).UpdateStatus(ctx, status, v1.UpdateOptions{})
 				if err != nil {
 					sLog.ErrorfCtx(ctx, "  P (K8s State): failed to update object status: %v", err)
+					if entry.ETag != nil && k8s_errors.IsConflict(err) {
+						err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' has changed, its etag is not %s", entry.Value.ID, *entry.ETag), v1alpha2.Conflict)
+					}
 					return "", err
 				}
 			} else {
@@ -343,6 +365,10 @@ func (s *K8sStateProvider) List(ctx context.Context, request states.ListRequest)
 	} else {
 		namespaces = []string{namespace}
 	}
+	// Kubernetes pages a list of a single namespace in name order. Lists of all namespaces, lists that are sorted by
+	// another key and lists that are filtered by spec or status are paged after all the entries are read.
+	nativePaging := namespace != "" && request.SortBy == "" && request.FilterType != "spec" && request.FilterType != "status"
+	continueToken := ""
 	for _, namespace := range namespaces {
 		resourceId := schema.GroupVersionResource{
 			Group:    group,
@@ -372,11 +398,16 @@ func (s *K8sStateProvider) List(ctx context.Context, request states.ListRequest)
 			sLog.ErrorfCtx(ctx, "  P (K8s State): invalid filter type: %s", request.FilterType)
 			return nil, "", v1alpha2.NewCOAError(nil, "invalid filter type", v1alpha2.BadRequest)
 		}
+		if nativePaging {
+			options.Limit = request.PageSize
+			options.Continue = request.ContinuationToken
+		}
 		items, err := s.DynamicClient.Resource(resourceId).Namespace(namespace).List(ctx, options)
 		if err != nil {
 			sLog.ErrorfCtx(ctx, "  P (K8s State): failed to list objects in namespace %s: %v ", namespace, err)
 			return nil, "", err
 		}
+		continueToken = items.GetContinue()
 		for _, v := range items.Items {
 
 			if filterValue != "" {
@@ -408,26 +439,15 @@ func (s *K8sStateProvider) List(ctx context.Context, request states.ListRequest)
 				}
 			}
 
-			metadata := model.ObjectMeta{
-				Name:          v.GetName(),
-				Namespace:     v.GetNamespace(),
-				Labels:        v.GetLabels(),
-				Annotations:   v.GetAnnotations(),
-				ObjGeneration: v.GetGeneration(),
-			}
-			entry := states.StateEntry{
-				ETag: v.GetResourceVersion(),
-				ID:   v.GetName(),
-				Body: map[string]interface{}{
-					"spec":     v.Object["spec"],
-					"status":   v.Object["status"],
-					"metadata": metadata,
-				},
-			}
-			entities = append(entities, entry)
+			entities = append(entities, toStateEntry(&v))
 		}
 	}
-	return entities, "", nil
+	if nativePaging {
+		return entities, continueToken, nil
+	}
+	var token string
+	entities, token, err = states.PageEntries(entities, request.PageOptions)
+	return entities, token, err
 }
 
 func (s *K8sStateProvider) Delete(ctx context.Context, request states.DeleteRequest) error {
@@ -459,9 +479,21 @@ func (s *K8sStateProvider) Delete(ctx context.Context, request states.DeleteRequ
 		return err
 	}
 
-	err = s.DynamicClient.Resource(resourceId).Namespace(namespace).Delete(ctx, request.ID, metav1.DeleteOptions{})
+	options := metav1.DeleteOptions{}
+	if request.ETag != nil {
+		// the object is only deleted if its resource version is still the etag it was read with
+		options.Preconditions = &metav1.Preconditions{ResourceVersion: request.ETag}
+	}
+	err = s.DynamicClient.Resource(resourceId).Namespace(namespace).Delete(ctx, request.ID, options)
 	if err != nil {
 		sLog.ErrorfCtx(ctx, "  P (K8s State): failed to delete objects: %v", err)
+		if request.ETag != nil {
+			if k8s_errors.IsConflict(err) {
+				err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' has changed, its etag is not %s", request.ID, *request.ETag), v1alpha2.Conflict)
+			} else if k8s_errors.IsNotFound(err) {
+				err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' is not found", request.ID), v1alpha2.NotFound)
+			}
+		}
 		return err
 	}
 	return nil
@@ -509,6 +541,96 @@ func (s *K8sStateProvider) Get(ctx context.Context, request states.GetRequest) (
 		return states.StateEntry{}, coaError
 	}
 
+	ret := toStateEntry(item)
+	ret.ID = request.ID
+	return ret, nil
+}
+
+// Watch streams the changes of a resource with a Kubernetes watch. An empty namespace watches all namespaces. The
+// resource version of an event is the resource version of the object.
+func (s *K8sStateProvider) Watch(ctx context.Context, request states.WatchRequest) (<-chan states.WatchEvent, error) {
+	namespace := model.ReadPropertyCompat(request.Metadata, "namespace", nil)
+	group := model.ReadPropertyCompat(request.Metadata, "group", nil)
+	version := model.ReadPropertyCompat(request.Metadata, "version", nil)
+	resource := model.ReadPropertyCompat(request.Metadata, "resource", nil)
+
+	sLog.InfofCtx(ctx, "  P (K8s State): watch state for %s.%s in namespace %s", resource, group, namespace)
+
+	resourceId := schema.GroupVersionResource{
+		Group:    group,
+		Version:  version,
+		Resource: resource,
+	}
+	options := metav1.ListOptions{
+		ResourceVersion: request.ResourceVersion,
+	}
+	var watcher watch.Interface
+	var err error
+	if namespace == "" {
+		watcher, err = s.DynamicClient.Resource(resourceId).Watch(ctx, options)
+	} else {
+		watcher, err = s.DynamicClient.Resource(resourceId).Namespace(namespace).Watch(ctx, options)
+	}
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (K8s State): failed to watch objects: %v", err)
+		return nil, err
+	}
+
+	events := make(chan states.WatchEvent)
+	go func() {
+		defer close(events)
+		defer watcher.Stop()
+		for {
+			var e watch.Event
+			var ok bool
+			select {
+			case e, ok = <-watcher.ResultChan():
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			var eventType states.WatchEventType
+			switch e.Type {
+			case watch.Added:
+				eventType = states.WatchEventAdded
+			case watch.Modified:
+				eventType = states.WatchEventUpdated
+			case watch.Deleted:
+				eventType = states.WatchEventDeleted
+			case watch.Error:
+				sLog.ErrorfCtx(ctx, "  P (K8s State): watch for %s.%s failed: %v", resource, group, k8s_errors.FromObject(e.Object))
+				return
+			default:
+				continue
+			}
+			item, ok := e.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			event := states.WatchEvent{
+				Type:            eventType,
+				Entry:           toStateEntry(item),
+				ResourceVersion: item.GetResourceVersion(),
+				Metadata: map[string]interface{}{
+					"namespace": item.GetNamespace(),
+					"group":     group,
+					"version":   version,
+					"resource":  resource,
+				},
+			}
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func toStateEntry(item *unstructured.Unstructured) states.StateEntry {
 	metadata := model.ObjectMeta{
 		Name:          item.GetName(),
 		Namespace:     item.GetNamespace(),
@@ -516,9 +638,8 @@ func (s *K8sStateProvider) Get(ctx context.Context, request states.GetRequest) (
 		Annotations:   item.GetAnnotations(),
 		ObjGeneration: item.GetGeneration(),
 	}
-
-	ret := states.StateEntry{
-		ID:   request.ID,
+	return states.StateEntry{
+		ID:   item.GetName(),
 		ETag: item.GetResourceVersion(),
 		Body: map[string]interface{}{
 			"spec":     item.Object["spec"],
@@ -526,5 +647,4 @@ func (s *K8sStateProvider) Get(ctx context.Context, request states.GetRequest) (
 			"metadata": metadata,
 		},
 	}
-	return ret, nil
 }
@@ -16,8 +16,17 @@ import (
 	"time"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
 	"github.com/stretchr/testify/assert"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestK8sStateProviderConfigFromMapNil(t *testing.T) {
@@ -1044,3 +1053,162 @@ func TestTargetUpSertWithStateOnly(t *testing.T) {
 	})
 	assert.Nil(t, err)
 }
+
+func TestWatch(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "solution.symphony", Version: "v1", Resource: "instances"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gvr: "InstanceList",
+	})
+	provider := K8sStateProvider{DynamicClient: client}
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := provider.Watch(ctx, states.WatchRequest{
+		Metadata: map[string]interface{}{
+			"namespace": "default",
+			"group":     gvr.Group,
+			"version":   gvr.Version,
+			"resource":  gvr.Resource,
+		},
+	})
+	assert.Nil(t, err)
+
+	instance := &unstructured.Unstructured{}
+	instance.SetAPIVersion("solution.symphony/v1")
+	instance.SetKind("Instance")
+	instance.SetName("instance1")
+	instance.SetNamespace("default")
+	instance.SetResourceVersion("1")
+	instance.Object["spec"] = map[string]interface{}{"solution": "solution1"}
+	_, err = client.Resource(gvr).Namespace("default").Create(ctx, instance, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	err = client.Resource(gvr).Namespace("default").Delete(ctx, "instance1", metav1.DeleteOptions{})
+	assert.Nil(t, err)
+
+	event := <-events
+	assert.Equal(t, states.WatchEventAdded, event.Type)
+	assert.Equal(t, "instance1", event.Entry.ID)
+	assert.Equal(t, "1", event.ResourceVersion)
+	assert.Equal(t, "default", event.Metadata["namespace"])
+	body := event.Entry.Body.(map[string]interface{})
+	assert.Equal(t, "solution1", body["spec"].(map[string]interface{})["solution"])
+	event = <-events
+	assert.Equal(t, states.WatchEventDeleted, event.Type)
+	assert.Equal(t, "instance1", event.Entry.ID)
+
+	cancel()
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
+// preconditionClient checks the resource version precondition of deletes, which the fake client ignores
+type preconditionClient struct {
+	dynamic.Interface
+	resourceVersion string
+}
+
+type preconditionResource struct {
+	dynamic.NamespaceableResourceInterface
+	resourceVersion string
+}
+
+type preconditionNamespacedResource struct {
+	dynamic.ResourceInterface
+	resourceVersion string
+}
+
+func (c preconditionClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return preconditionResource{c.Interface.Resource(resource), c.resourceVersion}
+}
+
+func (r preconditionResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return preconditionNamespacedResource{r.NamespaceableResourceInterface.Namespace(namespace), r.resourceVersion}
+}
+
+func (r preconditionNamespacedResource) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	if options.Preconditions != nil && options.Preconditions.ResourceVersion != nil && *options.Preconditions.ResourceVersion != r.resourceVersion {
+		return k8s_errors.NewConflict(schema.GroupResource{Group: "solution.symphony", Resource: "instances"}, name, fmt.Errorf("resource version changed"))
+	}
+	return r.ResourceInterface.Delete(ctx, name, options, subresources...)
+}
+
+// newETagTestProvider returns a provider with an instance whose resource version is 5. The fake client doesn't check
+// resource versions, so the API server checks are replaced by a reactor and a client that reject any other version.
+func newETagTestProvider(t *testing.T) (K8sStateProvider, map[string]interface{}) {
+	gvr := schema.GroupVersionResource{Group: "solution.symphony", Version: "v1", Resource: "instances"}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		gvr: "InstanceList",
+	})
+	instance := &unstructured.Unstructured{}
+	instance.SetAPIVersion("solution.symphony/v1")
+	instance.SetKind("Instance")
+	instance.SetName("instance1")
+	instance.SetNamespace("default")
+	instance.SetResourceVersion("5")
+	instance.Object["spec"] = map[string]interface{}{"solution": "solution1"}
+	_, err := client.Resource(gvr).Namespace("default").Create(context.Background(), instance, metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	conflict := k8s_errors.NewConflict(gvr.GroupResource(), "instance1", fmt.Errorf("resource version changed"))
+	client.PrependReactor("update", "instances", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		object := action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		return object.GetResourceVersion() != "5", nil, conflict
+	})
+	return K8sStateProvider{DynamicClient: preconditionClient{client, "5"}}, map[string]interface{}{
+		"namespace": "default",
+		"group":     gvr.Group,
+		"version":   gvr.Version,
+		"resource":  gvr.Resource,
+		"kind":      "Instance",
+	}
+}
+
+func TestUpsertWithETag(t *testing.T) {
+	provider, metadata := newETagTestProvider(t)
+	body := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "instance1", "namespace": "default"},
+		"spec":     map[string]interface{}{"solution": "solution2"},
+	}
+	absent := ""
+	_, err := provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance1", Body: body},
+		ETag:     &absent,
+		Metadata: metadata,
+	})
+	assert.True(t, v1alpha2.IsConflict(err))
+
+	stale := "4"
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance1", Body: body},
+		ETag:     &stale,
+		Metadata: metadata,
+	})
+	assert.True(t, v1alpha2.IsConflict(err))
+
+	current := "5"
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance1", Body: body},
+		ETag:     &current,
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+
+	// an entry that doesn't exist only matches an empty etag
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance2", Body: body},
+		ETag:     &current,
+		Metadata: metadata,
+	})
+	assert.True(t, v1alpha2.IsConflict(err))
+}
+
+func TestDeleteWithETag(t *testing.T) {
+	provider, metadata := newETagTestProvider(t)
+	stale := "4"
+	err := provider.Delete(context.Background(), states.DeleteRequest{ID: "instance1", ETag: &stale, Metadata: metadata})
+	assert.True(t, v1alpha2.IsConflict(err))
+
+	current := "5"
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "instance1", ETag: &current, Metadata: metadata})
+	assert.Nil(t, err)
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "instance1", ETag: &current, Metadata: metadata})
+	assert.True(t, v1alpha2.IsNotFound(err))
+}
@@ -7,6 +7,7 @@
 package helm
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -23,6 +24,7 @@ import (
 	"github.com/eclipse-symphony/symphony/api/constants"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/metrics"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/target/k8s"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils/metahelper"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/contexts"
@@ -36,12 +38,14 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/kube"
 	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/repo"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -249,6 +253,90 @@ func (i *HelmTargetProvider) createActionConfig(ctx context.Context, namespace s
 	return actionConfig, nil
 }
 
+// CheckReadiness checks that the release of a component is deployed and that all the resources of the release are
+// ready, such as deployments with all replicas available. A component with an HTTP readiness probe is then probed
+// through its service in the namespace of the release.
+func (i *HelmTargetProvider) CheckReadiness(ctx context.Context, deployment model.DeploymentSpec, component model.ComponentSpec) (bool, string, error) {
+	ctx, span := observability.StartSpan(
+		"Helm Target Provider",
+		ctx,
+		&map[string]string{
+			"method": "CheckReadiness",
+		},
+	)
+	var err error
+	defer utils.CloseSpanWithError(span, &err)
+	defer utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	if component.Readiness != nil && component.Readiness.Script != nil {
+		err = v1alpha2.NewCOAError(nil, "the helm target doesn't support script readiness probes", v1alpha2.BadConfig)
+		return false, "", err
+	}
+	var actionConfig *action.Configuration
+	actionConfig, err = i.createActionConfig(ctx, deployment.Instance.Spec.Scope)
+	if err != nil {
+		sLog.ErrorCtx(ctx, err)
+		return false, "", err
+	}
+	var rel *release.Release
+	rel, err = action.NewStatus(actionConfig).Run(component.Name)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			err = nil
+			return false, fmt.Sprintf("release %s is not found", component.Name), nil
+		}
+		sLog.ErrorfCtx(ctx, "  P (Helm Target): failed to get status of release %s: %+v", component.Name, err)
+		return false, "", err
+	}
+	if rel.Info == nil || rel.Info.Status != release.StatusDeployed {
+		status := release.StatusUnknown
+		if rel.Info != nil {
+			status = rel.Info.Status
+		}
+		return false, fmt.Sprintf("release %s is %s", component.Name, status), nil
+	}
+	probe := component.Readiness != nil && component.Readiness.HTTP != nil
+	kubeClient, ok := actionConfig.KubeClient.(*kube.Client)
+	if !ok {
+		if probe {
+			err = v1alpha2.NewCOAError(nil, "http readiness probes need a Kubernetes client", v1alpha2.BadConfig)
+			return false, "", err
+		}
+		return true, "", nil
+	}
+	var resources kube.ResourceList
+	resources, err = kubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (Helm Target): failed to build resources of release %s: %+v", component.Name, err)
+		return false, "", err
+	}
+	var clientSet *kubernetes.Clientset
+	clientSet, err = kubeClient.Factory.KubernetesClientSet()
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (Helm Target): failed to create client set: %+v", err)
+		return false, "", err
+	}
+	checker := kube.NewReadyChecker(clientSet, sLog.Debugf, kube.PausedAsReady(true), kube.CheckJobs(true))
+	for _, r := range resources {
+		var ready bool
+		ready, err = checker.IsReady(ctx, r)
+		if err != nil {
+			sLog.ErrorfCtx(ctx, "  P (Helm Target): failed to check readiness of %s %s: %+v", r.Mapping.GroupVersionKind.Kind, r.Name, err)
+			return false, "", err
+		}
+		if !ready {
+			return false, fmt.Sprintf("%s %s of release %s is not ready", r.Mapping.GroupVersionKind.Kind, r.Name, component.Name), nil
+		}
+	}
+	if probe {
+		var ready bool
+		var message string
+		ready, message, err = k8s.ProbeService(ctx, clientSet, rel.Namespace, component.Name, *component.Readiness.HTTP)
+		return ready, message, err
+	}
+	return true, "", nil
+}
+
 // getActionConfig returns an action configuration
 func getActionConfig(ctx context.Context, namespace string, config *rest.Config) (*action.Configuration, error) {
 	actionConfig := new(action.Configuration)
@@ -8,8 +8,11 @@ package k8s
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 	"sort"
 
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
 	apps "k8s.io/api/apps/v1"
 	appsv1 "k8s.io/api/apps/v1"
@@ -17,6 +20,7 @@ import (
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
 	appsclient "k8s.io/client-go/kubernetes/typed/apps/v1"
 )
 
@@ -192,3 +196,32 @@ func ResolveFenceposts(maxSurge, maxUnavailable *intstrutil.IntOrString, desired
 
 	return int32(surge), int32(unavailable), nil
 }
+
+// ProbeService sends the HTTP readiness probe of a component to a service of the component through the API server,
+// which keeps the probe within the namespace of the deployment. A service that can't be reached or that returns an
+// unexpected status is not ready.
+func ProbeService(ctx context.Context, client kubernetes.Interface, namespace string, defaultService string, spec model.HTTPProbeSpec) (bool, string, error) {
+	name := spec.Service
+	if name == "" {
+		name = defaultService
+	}
+	target := name
+	if spec.Port != 0 {
+		target = fmt.Sprintf("%s:%d", name, spec.Port)
+	}
+	method := spec.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	status := 0
+	result := client.CoreV1().RESTClient().Verb(method).Namespace(namespace).Resource("services").Name(target).SubResource("proxy").Suffix(spec.Path).Do(ctx)
+	result.StatusCode(&status)
+	if status == 0 {
+		return false, "", result.Error()
+	}
+	if spec.ExpectedStatus != 0 && status != spec.ExpectedStatus ||
+		spec.ExpectedStatus == 0 && (status < 200 || status >= 300) {
+		return false, fmt.Sprintf("%s %s of service %s in namespace %s returned status %d", method, spec.Path, name, namespace, status), nil
+	}
+	return true, "", nil
+}
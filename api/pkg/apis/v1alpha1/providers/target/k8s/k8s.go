@@ -814,6 +814,68 @@ func (i *K8sTargetProvider) deployComponents(ctx context.Context, namespace stri
 	}
 	return nil
 }
+
+// CheckReadiness checks the rollout status of the deployment of a component. A deployment is ready when all its
+// replicas are updated and available. A component with an HTTP readiness probe is probed through its service instead.
+func (i *K8sTargetProvider) CheckReadiness(ctx context.Context, dep model.DeploymentSpec, component model.ComponentSpec) (bool, string, error) {
+	ctx, span := observability.StartSpan("K8s Target Provider", ctx, &map[string]string{
+		"method": "CheckReadiness",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	namespace := dep.Instance.Spec.Scope
+	name := dep.Instance.ObjectMeta.Name
+	switch i.Config.DeploymentStrategy {
+	case SERVICES:
+		name = component.Name
+	case SERVICES_NS:
+		namespace = dep.Instance.ObjectMeta.Name
+		name = component.Name
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	if component.Readiness != nil && component.Readiness.Script != nil {
+		err = v1alpha2.NewCOAError(nil, "the k8s target doesn't support script readiness probes", v1alpha2.BadConfig)
+		return false, "", err
+	}
+	if component.Readiness != nil && component.Readiness.HTTP != nil {
+		service := utils.ReadString(component.Metadata, "service.name", name)
+		log.DebugfCtx(ctx, "  P (K8s Target): probing service of component %s in namespace %s", component.Name, namespace)
+		var ready bool
+		var message string
+		ready, message, err = ProbeService(ctx, i.Client, namespace, service, *component.Readiness.HTTP)
+		return ready, message, err
+	}
+	log.DebugfCtx(ctx, "  P (K8s Target): checking readiness of deployment %s in namespace %s", name, namespace)
+
+	var deployment *v1.Deployment
+	deployment, err = i.Client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			err = nil
+			return false, fmt.Sprintf("deployment %s is not found", name), nil
+		}
+		log.ErrorfCtx(ctx, "  P (K8s Target): failed to get deployment %s: %s", name, err.Error())
+		return false, "", err
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false, fmt.Sprintf("deployment %s is waiting for the rollout to be observed", name), nil
+	}
+	if deployment.Status.UpdatedReplicas < replicas {
+		return false, fmt.Sprintf("deployment %s has %d of %d replicas updated", name, deployment.Status.UpdatedReplicas, replicas), nil
+	}
+	if deployment.Status.AvailableReplicas < replicas {
+		return false, fmt.Sprintf("deployment %s has %d of %d replicas available", name, deployment.Status.AvailableReplicas, replicas), nil
+	}
+	return true, "", nil
+}
 func (i *K8sTargetProvider) GetValidationRule(ctx context.Context) model.ValidationRule {
 	return model.ValidationRule{
 		AllowSidecar: i.Config.DeploymentStrategy == SERVICES,
@@ -8,6 +8,8 @@ package k8s
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -19,7 +21,9 @@ import (
 	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 )
 
 func TestK8sTargetProviderConfigFromMapNil(t *testing.T) {
@@ -539,3 +543,99 @@ func TestConformanceSuite(t *testing.T) {
 	// assert.Nil(t, err) okay if provider is not fully initialized
 	conformance.ConformanceSuite(t, provider)
 }
+
+func TestCheckReadiness(t *testing.T) {
+	provider := &K8sTargetProvider{}
+	_ = provider.Init(K8sTargetProviderConfig{})
+	client := fake.NewSimpleClientset()
+	provider.Client = client
+	dep := model.DeploymentSpec{
+		Instance: model.InstanceState{
+			ObjectMeta: model.ObjectMeta{
+				Name: "instance1",
+			},
+			Spec: &model.InstanceSpec{
+				Scope: "default",
+			},
+		},
+	}
+	component := model.ComponentSpec{Name: "a"}
+
+	ready, message, err := provider.CheckReadiness(context.Background(), dep, component)
+	assert.Nil(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "deployment instance1 is not found", message)
+
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "instance1",
+			Namespace: "default",
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.DeploymentStatus{
+			UpdatedReplicas:   2,
+			AvailableReplicas: 1,
+		},
+	}
+	_, err = client.AppsV1().Deployments("default").Create(context.Background(), deployment, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	ready, message, err = provider.CheckReadiness(context.Background(), dep, component)
+	assert.Nil(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "deployment instance1 has 1 of 2 replicas available", message)
+
+	deployment.Status.AvailableReplicas = 2
+	_, err = client.AppsV1().Deployments("default").UpdateStatus(context.Background(), deployment, metav1.UpdateOptions{})
+	assert.Nil(t, err)
+	ready, _, err = provider.CheckReadiness(context.Background(), dep, component)
+	assert.Nil(t, err)
+	assert.True(t, ready)
+}
+
+func TestCheckReadinessHTTPProbe(t *testing.T) {
+	paths := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if len(paths) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	assert.Nil(t, err)
+	provider := K8sTargetProvider{
+		Config: K8sTargetProviderConfig{DeploymentStrategy: SERVICES},
+		Client: client,
+	}
+	deployment := model.DeploymentSpec{
+		Instance: model.InstanceState{
+			ObjectMeta: model.ObjectMeta{
+				Name: "instance",
+			},
+			Spec: &model.InstanceSpec{
+				Scope: "sites",
+			},
+		},
+	}
+	component := model.ComponentSpec{
+		Name: "web",
+		Readiness: &model.ReadinessSpec{
+			HTTP: &model.HTTPProbeSpec{Port: 8080, Path: "/healthz"},
+		},
+	}
+
+	ready, message, err := provider.CheckReadiness(context.Background(), deployment, component)
+	assert.Nil(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "GET /healthz of service web in namespace sites returned status 503", message)
+
+	ready, _, err = provider.CheckReadiness(context.Background(), deployment, component)
+	assert.Nil(t, err)
+	assert.True(t, ready)
+	assert.Equal(t, "/api/v1/namespaces/sites/services/web:8080/proxy/healthz", paths[1])
+}
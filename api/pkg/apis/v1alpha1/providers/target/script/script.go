@@ -396,6 +396,64 @@ func (i *ScriptProvider) Apply(ctx context.Context, deployment model.DeploymentS
 	}
 	return ret, nil
 }
+
+// CheckReadiness runs the readiness script of a component with the same arguments as the apply script. The component is
+// ready when the script exits with 0, otherwise the output of the script explains why it's not.
+func (i *ScriptProvider) CheckReadiness(ctx context.Context, deployment model.DeploymentSpec, component model.ComponentSpec) (bool, string, error) {
+	ctx, span := observability.StartSpan("Script Provider", ctx, &map[string]string{
+		"method": "CheckReadiness",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	if component.Readiness == nil || component.Readiness.Script == nil {
+		err = v1alpha2.NewCOAError(nil, "the script target only supports script readiness probes", v1alpha2.BadConfig)
+		return false, "", err
+	}
+	probe := component.Readiness.Script.Script
+	sLog.DebugfCtx(ctx, "  P (Script Target): running readiness script %s of component %s", probe, component.Name)
+
+	scriptAbs, _ := filepath.Abs(filepath.Join(i.Config.ScriptFolder, probe))
+	if strings.HasPrefix(i.Config.ScriptFolder, "http") {
+		scriptAbs, _ = filepath.Abs(filepath.Join(i.Config.StagingFolder, probe))
+		if _, statErr := os.Stat(scriptAbs); statErr != nil {
+			err = downloadFile(i.Config.ScriptFolder, probe, i.Config.StagingFolder)
+			if err != nil {
+				sLog.ErrorfCtx(ctx, "  P (Script Target): failed to download readiness script %s, error: %+v", probe, err)
+				return false, "", err
+			}
+		}
+	}
+
+	id := uuid.New().String()
+	stagingDeployment := filepath.Join(i.Config.StagingFolder, id+".json")
+	file, _ := json.MarshalIndent(deployment, "", " ")
+	_ = os.WriteFile(stagingDeployment, file, 0644)
+	stagingRef := filepath.Join(i.Config.StagingFolder, id+"-ref.json")
+	file, _ = json.MarshalIndent([]model.ComponentSpec{component}, "", " ")
+	_ = os.WriteFile(stagingRef, file, 0644)
+	absDeployment, _ := filepath.Abs(stagingDeployment)
+	absRef, _ := filepath.Abs(stagingRef)
+	defer os.Remove(absDeployment)
+	defer os.Remove(absRef)
+
+	o, runErr := i.runCommand(scriptAbs, absDeployment, absRef)
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if errors.As(runErr, &exitErr) {
+			message := strings.TrimSpace(string(o))
+			if message == "" {
+				message = runErr.Error()
+			}
+			return false, message, nil
+		}
+		sLog.ErrorfCtx(ctx, "  P (Script Target): failed to run readiness script %s: %+v", probe, runErr)
+		err = runErr
+		return false, "", err
+	}
+	return true, "", nil
+}
 func (*ScriptProvider) GetValidationRule(ctx context.Context) model.ValidationRule {
 	return model.ValidationRule{
 		AllowSidecar: false,
@@ -14,6 +14,7 @@ import (
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/target/conformance"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -178,6 +179,40 @@ func TestApplyScript(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+// TestCheckReadiness tests that the readiness script of a component decides if it's ready
+func TestCheckReadiness(t *testing.T) {
+	folder := t.TempDir()
+	err := os.WriteFile(filepath.Join(folder, "ready.sh"), []byte("#!/bin/bash\nif [ -f \"$(dirname \"$0\")/ready\" ]; then exit 0; fi\necho \"not ready yet\"\nexit 1\n"), 0755)
+	require.Nil(t, err)
+	provider := ScriptProvider{}
+	err = provider.Init(ScriptProviderConfig{
+		ScriptFolder:  folder,
+		StagingFolder: folder,
+	})
+	require.Nil(t, err)
+	component := model.ComponentSpec{
+		Name: "com1",
+		Readiness: &model.ReadinessSpec{
+			Script: &model.ScriptProbeSpec{Script: "ready.sh"},
+		},
+	}
+
+	ready, message, err := provider.CheckReadiness(context.Background(), model.DeploymentSpec{}, component)
+	assert.Nil(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "not ready yet", message)
+
+	err = os.WriteFile(filepath.Join(folder, "ready"), []byte{}, 0644)
+	require.Nil(t, err)
+	ready, _, err = provider.CheckReadiness(context.Background(), model.DeploymentSpec{}, component)
+	assert.Nil(t, err)
+	assert.True(t, ready)
+
+	component.Readiness = &model.ReadinessSpec{Provider: true}
+	_, _, err = provider.CheckReadiness(context.Background(), model.DeploymentSpec{}, component)
+	assert.True(t, v1alpha2.IsBadConfig(err))
+}
+
 func TestGetScriptFromUrl(t *testing.T) {
 	testScriptProvider := os.Getenv("TEST_SCRIPT_PROVIDER")
 	if testScriptProvider == "" {
@@ -22,3 +22,11 @@ type ITargetProvider interface {
 	// apply components to a target
 	Apply(ctx context.Context, deployment model.DeploymentSpec, step model.DeploymentStep, isDryRun bool) (map[string]model.ComponentResultSpec, error)
 }
+
+// IReadinessProvider is implemented by target providers that can check whether an applied component is ready, such as
+// the rollout status of a Helm release or a Kubernetes deployment
+type IReadinessProvider interface {
+	// check if a component is ready with the probe of its readiness spec, returning a message that explains why it's
+	// not
+	CheckReadiness(ctx context.Context, deployment model.DeploymentSpec, component model.ComponentSpec) (bool, string, error)
+}
@@ -18,6 +18,7 @@ import (
 	"net/url"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/eclipse-symphony/symphony/api/constants"
@@ -73,7 +74,11 @@ type (
 		CatalogHook(ctx context.Context, payload []byte, user string, password string) error
 		PublishActivationEvent(ctx context.Context, event v1alpha2.ActivationData, user string, password string) error
 		GetActivation(ctx context.Context, activation string, namespace string, user string, password string) (model.ActivationState, error)
+		CreateActivation(ctx context.Context, activation string, payload []byte, namespace string, user string, password string) error
+		CancelActivation(ctx context.Context, activation string, namespace string, user string, password string) error
+		DeleteActivation(ctx context.Context, activation string, namespace string, user string, password string) error
 		GetCatalog(ctx context.Context, catalog string, namespace string, user string, password string) (model.CatalogState, error)
+		GetCatalogRevision(ctx context.Context, catalog string, revision int, namespace string, user string, password string) (model.CatalogRevision, error)
 		UpsertCatalog(ctx context.Context, catalog string, payload []byte, user string, password string) error
 		DeleteCatalog(ctx context.Context, catalog string, user string, password string) error
 		UpsertSolution(ctx context.Context, solution string, payload []byte, namespace string, user string, password string) error
@@ -633,6 +638,48 @@ func (a *apiClient) GetActivation(ctx context.Context, activation string, namesp
 	return ret, nil
 }
 
+func (a *apiClient) CreateActivation(ctx context.Context, activation string, payload []byte, namespace string, user string, password string) error {
+	token, err := a.tokenProvider(ctx, a.baseUrl, a.client, user, password)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.callRestAPI(ctx, "activations/registry/"+url.QueryEscape(activation)+"?namespace="+url.QueryEscape(namespace), "POST", payload, token)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *apiClient) CancelActivation(ctx context.Context, activation string, namespace string, user string, password string) error {
+	token, err := a.tokenProvider(ctx, a.baseUrl, a.client, user, password)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.callRestAPI(ctx, "activations/cancel/"+url.QueryEscape(activation)+"?namespace="+url.QueryEscape(namespace), "POST", nil, token)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *apiClient) DeleteActivation(ctx context.Context, activation string, namespace string, user string, password string) error {
+	token, err := a.tokenProvider(ctx, a.baseUrl, a.client, user, password)
+	if err != nil {
+		return err
+	}
+
+	_, err = a.callRestAPI(ctx, "activations/registry/"+url.QueryEscape(activation)+"?namespace="+url.QueryEscape(namespace), "DELETE", nil, token)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (a *apiClient) GetCatalog(ctx context.Context, catalog string, namespace string, user string, password string) (model.CatalogState, error) {
 	ret := model.CatalogState{}
 	token, err := a.tokenProvider(ctx, a.baseUrl, a.client, user, password)
@@ -662,6 +709,30 @@ func (a *apiClient) GetCatalog(ctx context.Context, catalog string, namespace st
 	return ret, nil
 }
 
+func (a *apiClient) GetCatalogRevision(ctx context.Context, catalog string, revision int, namespace string, user string, password string) (model.CatalogRevision, error) {
+	ret := model.CatalogRevision{}
+	token, err := a.tokenProvider(ctx, a.baseUrl, a.client, user, password)
+
+	if err != nil {
+		return ret, err
+	}
+
+	path := "catalogs/revisions/" + url.QueryEscape(catalog) + "/" + strconv.Itoa(revision)
+	if namespace != "" {
+		path = path + "?namespace=" + url.QueryEscape(namespace)
+	}
+	response, err := a.callRestAPI(ctx, path, "GET", nil, token)
+	if err != nil {
+		return ret, err
+	}
+
+	err = json.Unmarshal(response, &ret)
+	if err != nil {
+		return ret, err
+	}
+	return ret, nil
+}
+
 func (a *apiClient) GetCatalogsWithFilter(ctx context.Context, namespace string, filterType string, filterValue string, user string, password string) ([]model.CatalogState, error) {
 	ret := make([]model.CatalogState, 0)
 	token, err := a.tokenProvider(ctx, a.baseUrl, a.client, user, password)
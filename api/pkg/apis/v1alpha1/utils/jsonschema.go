@@ -0,0 +1,578 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+)
+
+// maxSchemaRefDepth limits the $refs followed for one value, so that a schema that refers to itself without
+// descending into the value fails instead of looping
+const maxSchemaRefDepth = 32
+
+// JSONSchema is a JSON Schema (draft 2020-12) document. A $ref that doesn't start with '#' refers to another schema
+// document by name, like 'network-schema:v1' or 'network-schema:v1#/$defs/port', which is read with Lookup.
+type JSONSchema struct {
+	Document interface{}
+	Lookup   func(ctx context.Context, name string) (interface{}, error)
+}
+
+// IsJSONSchema tells whether a schema document is a JSON Schema, rather than the rules of a Schema
+func IsJSONSchema(document interface{}) bool {
+	switch d := document.(type) {
+	case bool:
+		return true
+	case map[string]interface{}:
+		_, ok := d["rules"]
+		return !ok
+	}
+	return false
+}
+
+// CheckProperties validates properties against the schema. The errors are keyed by the paths of the properties they
+// are found in, like 'network.ports[0].port', where the empty path is the properties themselves. It returns an error if
+// the schema itself is invalid, like a bad pattern or a $ref that can't be resolved.
+func (s *JSONSchema) CheckProperties(ctx context.Context, properties map[string]interface{}) (SchemaResult, error) {
+	v := &jsonSchemaValidator{
+		ctx:       ctx,
+		lookup:    s.Lookup,
+		documents: make(map[string]interface{}),
+		patterns:  make(map[string]*regexp.Regexp),
+	}
+	// the properties and the schema may hold typed values, they are compared in their JSON form
+	instance, err := toJSONValue(properties)
+	if err != nil {
+		return SchemaResult{}, err
+	}
+	document, err := toJSONValue(s.Document)
+	if err != nil {
+		return SchemaResult{}, err
+	}
+	errors, err := v.validate(document, document, instance, "", 0)
+	if err != nil {
+		return SchemaResult{}, err
+	}
+	ret := SchemaResult{Valid: len(errors) == 0, Errors: make(map[string]RuleResult)}
+	for _, e := range errors {
+		if r, ok := ret.Errors[e.path]; ok {
+			r.Error = r.Error + "; " + e.message
+			ret.Errors[e.path] = r
+			continue
+		}
+		ret.Errors[e.path] = RuleResult{Valid: false, Error: e.message}
+	}
+	return ret, nil
+}
+
+type schemaError struct {
+	path    string
+	message string
+}
+
+type jsonSchemaValidator struct {
+	ctx    context.Context
+	lookup func(ctx context.Context, name string) (interface{}, error)
+	// documents are the schema documents read by name, and patterns the compiled patterns
+	documents map[string]interface{}
+	patterns  map[string]*regexp.Regexp
+}
+
+// validate checks an instance against a schema, where root is the document the schema is part of. It returns the
+// violations, or an error if the schema is invalid.
+func (v *jsonSchemaValidator) validate(schema interface{}, root interface{}, instance interface{}, path string, refDepth int) ([]schemaError, error) {
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			return []schemaError{{path, "no value is allowed"}}, nil
+		}
+		return nil, nil
+	case map[string]interface{}:
+		return v.validateObject(s, root, instance, path, refDepth)
+	case nil:
+		return nil, nil
+	}
+	return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("schema for '%s' is not an object or a boolean", displayPath(path)), v1alpha2.BadConfig)
+}
+
+func (v *jsonSchemaValidator) validateObject(s map[string]interface{}, root interface{}, instance interface{}, path string, refDepth int) ([]schemaError, error) {
+	errors := make([]schemaError, 0)
+	fail := func(format string, args ...interface{}) {
+		errors = append(errors, schemaError{path, fmt.Sprintf(format, args...)})
+	}
+	// check runs a subschema whose errors are the errors of the instance
+	check := func(schema interface{}, instance interface{}, path string) error {
+		errs, err := v.validate(schema, root, instance, path, 0)
+		errors = append(errors, errs...)
+		return err
+	}
+	// matches runs a subschema to see whether the instance matches it
+	matches := func(schema interface{}) (bool, error) {
+		errs, err := v.validate(schema, root, instance, path, refDepth)
+		return len(errs) == 0, err
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		if refDepth >= maxSchemaRefDepth {
+			return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("$ref '%s' at '%s' refers to itself", ref, displayPath(path)), v1alpha2.BadConfig)
+		}
+		target, targetRoot, err := v.resolve(ref, root)
+		if err != nil {
+			return nil, err
+		}
+		errs, err := v.validate(target, targetRoot, instance, path, refDepth+1)
+		if err != nil {
+			return nil, err
+		}
+		errors = append(errors, errs...)
+	}
+
+	if t, ok := s["type"]; ok {
+		types := make([]string, 0)
+		switch tv := t.(type) {
+		case string:
+			types = append(types, tv)
+		case []interface{}:
+			for _, item := range tv {
+				types = append(types, FormatAsString(item))
+			}
+		}
+		matched := false
+		for _, name := range types {
+			if jsonTypeMatches(name, instance) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("value is %s, expected %s", jsonTypeName(instance), strings.Join(types, " or "))
+			// the other keywords would report the same mismatch
+			return errors, nil
+		}
+	}
+	if enum, ok := s["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			if reflect.DeepEqual(item, instance) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value %s is not one of %s", jsonText(instance), jsonText(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !reflect.DeepEqual(c, instance) {
+		fail("value %s is not %s", jsonText(instance), jsonText(c))
+	}
+
+	switch val := instance.(type) {
+	case float64:
+		if m, ok := schemaNumber(s, "multipleOf"); ok && m > 0 {
+			if q := val / m; math.Abs(q-math.Round(q)) > 1e-9 {
+				fail("value %s is not a multiple of %s", jsonText(val), jsonText(m))
+			}
+		}
+		if m, ok := schemaNumber(s, "minimum"); ok && val < m {
+			fail("value %s is less than the minimum %s", jsonText(val), jsonText(m))
+		}
+		if m, ok := schemaNumber(s, "exclusiveMinimum"); ok && val <= m {
+			fail("value %s is not greater than %s", jsonText(val), jsonText(m))
+		}
+		if m, ok := schemaNumber(s, "maximum"); ok && val > m {
+			fail("value %s is greater than the maximum %s", jsonText(val), jsonText(m))
+		}
+		if m, ok := schemaNumber(s, "exclusiveMaximum"); ok && val >= m {
+			fail("value %s is not less than %s", jsonText(val), jsonText(m))
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(val))
+		if m, ok := schemaNumber(s, "minLength"); ok && length < m {
+			fail("value is shorter than %s characters", jsonText(m))
+		}
+		if m, ok := schemaNumber(s, "maxLength"); ok && length > m {
+			fail("value is longer than %s characters", jsonText(m))
+		}
+		if pattern, ok := s["pattern"].(string); ok {
+			re, err := v.pattern(pattern)
+			if err != nil {
+				return nil, err
+			}
+			if !re.MatchString(val) {
+				fail("value does not match pattern: %s", pattern)
+			}
+		}
+	case []interface{}:
+		length := float64(len(val))
+		if m, ok := schemaNumber(s, "minItems"); ok && length < m {
+			fail("array has fewer than %s items", jsonText(m))
+		}
+		if m, ok := schemaNumber(s, "maxItems"); ok && length > m {
+			fail("array has more than %s items", jsonText(m))
+		}
+		if unique, ok := s["uniqueItems"].(bool); ok && unique {
+		duplicates:
+			for i := range val {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(val[i], val[j]) {
+						fail("items %d and %d are equal", j, i)
+						break duplicates
+					}
+				}
+			}
+		}
+		prefix, _ := s["prefixItems"].([]interface{})
+		for i := 0; i < len(prefix) && i < len(val); i++ {
+			if err := check(prefix[i], val[i], indexPath(path, i)); err != nil {
+				return nil, err
+			}
+		}
+		if items, ok := s["items"]; ok {
+			for i := len(prefix); i < len(val); i++ {
+				if err := check(items, val[i], indexPath(path, i)); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if contains, ok := s["contains"]; ok {
+			count := 0
+			for _, item := range val {
+				errs, err := v.validate(contains, root, item, path, 0)
+				if err != nil {
+					return nil, err
+				}
+				if len(errs) == 0 {
+					count++
+				}
+			}
+			minContains := 1.0
+			if m, ok := schemaNumber(s, "minContains"); ok {
+				minContains = m
+			}
+			if float64(count) < minContains {
+				fail("array has fewer than %s items that match contains", jsonText(minContains))
+			}
+			if m, ok := schemaNumber(s, "maxContains"); ok && float64(count) > m {
+				fail("array has more than %s items that match contains", jsonText(m))
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		length := float64(len(val))
+		if m, ok := schemaNumber(s, "minProperties"); ok && length < m {
+			fail("object has fewer than %s properties", jsonText(m))
+		}
+		if m, ok := schemaNumber(s, "maxProperties"); ok && length > m {
+			fail("object has more than %s properties", jsonText(m))
+		}
+		if required, ok := s["required"].([]interface{}); ok {
+			for _, r := range required {
+				name := FormatAsString(r)
+				if _, ok := val[name]; !ok {
+					errors = append(errors, schemaError{propertyPath(path, name), "missing required property"})
+				}
+			}
+		}
+		if dependent, ok := s["dependentRequired"].(map[string]interface{}); ok {
+			for _, k := range sortedKeys(dependent) {
+				if _, ok := val[k]; !ok {
+					continue
+				}
+				required, _ := dependent[k].([]interface{})
+				for _, r := range required {
+					name := FormatAsString(r)
+					if _, ok := val[name]; !ok {
+						errors = append(errors, schemaError{propertyPath(path, name), fmt.Sprintf("missing property required by '%s'", k)})
+					}
+				}
+			}
+		}
+		if dependent, ok := s["dependentSchemas"].(map[string]interface{}); ok {
+			for _, k := range sortedKeys(dependent) {
+				if _, ok := val[k]; ok {
+					if err := check(dependent[k], val, path); err != nil {
+						return nil, err
+					}
+				}
+			}
+		}
+		properties, _ := s["properties"].(map[string]interface{})
+		patternProperties, _ := s["patternProperties"].(map[string]interface{})
+		additional, hasAdditional := s["additionalProperties"]
+		for _, k := range keys {
+			evaluated := false
+			if p, ok := properties[k]; ok {
+				evaluated = true
+				if err := check(p, val[k], propertyPath(path, k)); err != nil {
+					return nil, err
+				}
+			}
+			for _, pattern := range sortedKeys(patternProperties) {
+				re, err := v.pattern(pattern)
+				if err != nil {
+					return nil, err
+				}
+				if re.MatchString(k) {
+					evaluated = true
+					if err := check(patternProperties[pattern], val[k], propertyPath(path, k)); err != nil {
+						return nil, err
+					}
+				}
+			}
+			if !evaluated && hasAdditional {
+				if allowed, ok := additional.(bool); ok && !allowed {
+					errors = append(errors, schemaError{propertyPath(path, k), "property is not allowed"})
+				} else if err := check(additional, val[k], propertyPath(path, k)); err != nil {
+					return nil, err
+				}
+			}
+			if names, ok := s["propertyNames"]; ok {
+				errs, err := v.validate(names, root, k, path, 0)
+				if err != nil {
+					return nil, err
+				}
+				if len(errs) > 0 {
+					errors = append(errors, schemaError{propertyPath(path, k), fmt.Sprintf("property name is invalid: %s", errs[0].message)})
+				}
+			}
+		}
+	}
+
+	if allOf, ok := s["allOf"].([]interface{}); ok {
+		for _, sub := range allOf {
+			errs, err := v.validate(sub, root, instance, path, refDepth)
+			if err != nil {
+				return nil, err
+			}
+			errors = append(errors, errs...)
+		}
+	}
+	if anyOf, ok := s["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			m, err := matches(sub)
+			if err != nil {
+				return nil, err
+			}
+			if m {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("value doesn't match any of the anyOf schemas")
+		}
+	}
+	if oneOf, ok := s["oneOf"].([]interface{}); ok {
+		count := 0
+		for _, sub := range oneOf {
+			m, err := matches(sub)
+			if err != nil {
+				return nil, err
+			}
+			if m {
+				count++
+			}
+		}
+		if count != 1 {
+			fail("value matches %d of the oneOf schemas, expected exactly 1", count)
+		}
+	}
+	if not, ok := s["not"]; ok {
+		m, err := matches(not)
+		if err != nil {
+			return nil, err
+		}
+		if m {
+			fail("value matches the schema in not")
+		}
+	}
+	if cond, ok := s["if"]; ok {
+		m, err := matches(cond)
+		if err != nil {
+			return nil, err
+		}
+		branch, ok := s["else"]
+		if m {
+			branch, ok = s["then"]
+		}
+		if ok {
+			errs, err := v.validate(branch, root, instance, path, refDepth)
+			if err != nil {
+				return nil, err
+			}
+			errors = append(errors, errs...)
+		}
+	}
+	return errors, nil
+}
+
+// resolve returns the schema a $ref refers to, and the document it's part of
+func (v *jsonSchemaValidator) resolve(ref string, root interface{}) (interface{}, interface{}, error) {
+	name, pointer, _ := strings.Cut(ref, "#")
+	document := root
+	if name != "" {
+		var ok bool
+		if document, ok = v.documents[name]; !ok {
+			if v.lookup == nil {
+				return nil, nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("unable to resolve $ref '%s'", ref), v1alpha2.BadConfig)
+			}
+			doc, err := v.lookup(v.ctx, name)
+			if err == nil {
+				doc, err = toJSONValue(doc)
+			}
+			if err != nil {
+				return nil, nil, v1alpha2.NewCOAError(err, fmt.Sprintf("unable to resolve $ref '%s'", ref), v1alpha2.BadConfig)
+			}
+			v.documents[name] = doc
+			document = doc
+		}
+	}
+	target := document
+	if pointer != "" {
+		for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+			switch t := target.(type) {
+			case map[string]interface{}:
+				next, ok := t[token]
+				if !ok {
+					return nil, nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("unable to resolve $ref '%s'", ref), v1alpha2.BadConfig)
+				}
+				target = next
+			case []interface{}:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || i >= len(t) {
+					return nil, nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("unable to resolve $ref '%s'", ref), v1alpha2.BadConfig)
+				}
+				target = t[i]
+			default:
+				return nil, nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("unable to resolve $ref '%s'", ref), v1alpha2.BadConfig)
+			}
+		}
+	}
+	return target, document, nil
+}
+
+func (v *jsonSchemaValidator) pattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, v1alpha2.NewCOAError(err, fmt.Sprintf("invalid pattern '%s'", pattern), v1alpha2.BadConfig)
+	}
+	v.patterns[pattern] = re
+	return re, nil
+}
+
+func jsonTypeMatches(name string, instance interface{}) bool {
+	switch name {
+	case "null":
+		return instance == nil
+	case "boolean":
+		_, ok := instance.(bool)
+		return ok
+	case "object":
+		_, ok := instance.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := instance.([]interface{})
+		return ok
+	case "number":
+		_, ok := instance.(float64)
+		return ok
+	case "integer":
+		f, ok := instance.(float64)
+		return ok && f == math.Trunc(f)
+	case "string":
+		_, ok := instance.(string)
+		return ok
+	}
+	return false
+}
+
+func jsonTypeName(instance interface{}) string {
+	switch i := instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case float64:
+		if i == math.Trunc(i) {
+			return "an integer"
+		}
+		return "a number"
+	case string:
+		return "a string"
+	}
+	return "unknown"
+}
+
+func toJSONValue(val interface{}) (interface{}, error) {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	var ret interface{}
+	err = json.Unmarshal(data, &ret)
+	return ret, err
+}
+
+func schemaNumber(s map[string]interface{}, keyword string) (float64, bool) {
+	n, ok := s[keyword].(float64)
+	return n, ok
+}
+
+func jsonText(val interface{}) string {
+	data, _ := json.Marshal(val)
+	return string(data)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func propertyPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseSchemaDocument(t *testing.T, text string) interface{} {
+	var document interface{}
+	assert.Nil(t, json.Unmarshal([]byte(text), &document))
+	return document
+}
+
+const siteSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["name", "network"],
+	"properties": {
+		"name": {"type": "string", "pattern": "^[a-z0-9-]+$", "maxLength": 10},
+		"tier": {"enum": ["gold", "silver"]},
+		"replicas": {"type": "integer", "minimum": 1, "maximum": 5},
+		"network": {
+			"type": "object",
+			"required": ["mtu"],
+			"properties": {
+				"mtu": {"type": "integer", "exclusiveMinimum": 0},
+				"ports": {"type": "array", "items": {"$ref": "#/$defs/port"}, "uniqueItems": true}
+			},
+			"additionalProperties": false
+		}
+	},
+	"if": {"properties": {"tier": {"const": "gold"}}, "required": ["tier"]},
+	"then": {"required": ["replicas"]},
+	"$defs": {
+		"port": {
+			"type": "object",
+			"required": ["port"],
+			"properties": {"port": {"type": "integer", "minimum": 1, "maximum": 65535}}
+		}
+	}
+}`
+
+func TestIsJSONSchema(t *testing.T) {
+	assert.True(t, IsJSONSchema(parseSchemaDocument(t, siteSchema)))
+	assert.True(t, IsJSONSchema(true))
+	assert.False(t, IsJSONSchema(map[string]interface{}{"rules": map[string]interface{}{}}))
+	assert.False(t, IsJSONSchema("rules"))
+}
+
+func TestJSONSchemaValid(t *testing.T) {
+	schema := JSONSchema{Document: parseSchemaDocument(t, siteSchema)}
+	result, err := schema.CheckProperties(context.Background(), map[string]interface{}{
+		"name":     "site-1",
+		"tier":     "gold",
+		"replicas": 3,
+		"network": map[string]interface{}{
+			"mtu":   1500,
+			"ports": []interface{}{map[string]interface{}{"port": 80}, map[string]interface{}{"port": 443}},
+		},
+	})
+	assert.Nil(t, err)
+	assert.True(t, result.Valid)
+	assert.Empty(t, result.Errors)
+}
+
+func TestJSONSchemaNestedErrors(t *testing.T) {
+	schema := JSONSchema{Document: parseSchemaDocument(t, siteSchema)}
+	result, err := schema.CheckProperties(context.Background(), map[string]interface{}{
+		"name": "Site_1",
+		"tier": "gold",
+		"network": map[string]interface{}{
+			"mtu":    0,
+			"vlan":   10,
+			"ports":  []interface{}{map[string]interface{}{"port": 80}, map[string]interface{}{"port": 70000}},
+			"bridge": "br0",
+		},
+	})
+	assert.Nil(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, "value does not match pattern: ^[a-z0-9-]+$", result.Errors["name"].Error)
+	assert.Equal(t, "missing required property", result.Errors["replicas"].Error)
+	assert.Equal(t, "value 0 is not greater than 0", result.Errors["network.mtu"].Error)
+	assert.Equal(t, "property is not allowed", result.Errors["network.vlan"].Error)
+	assert.Equal(t, "property is not allowed", result.Errors["network.bridge"].Error)
+	assert.Equal(t, "value 70000 is greater than the maximum 65535", result.Errors["network.ports[1].port"].Error)
+	assert.Equal(t, 6, len(result.Errors))
+
+	result, err = schema.CheckProperties(context.Background(), map[string]interface{}{
+		"tier":     "bronze",
+		"replicas": 1.5,
+		"network":  map[string]interface{}{"ports": []interface{}{map[string]interface{}{}, map[string]interface{}{}}},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "missing required property", result.Errors["name"].Error)
+	assert.Equal(t, `value "bronze" is not one of ["gold","silver"]`, result.Errors["tier"].Error)
+	assert.Equal(t, "value is a number, expected integer", result.Errors["replicas"].Error)
+	assert.Equal(t, "missing required property", result.Errors["network.mtu"].Error)
+	assert.Equal(t, "items 0 and 1 are equal", result.Errors["network.ports"].Error)
+	assert.Equal(t, "missing required property", result.Errors["network.ports[0].port"].Error)
+}
+
+func TestJSONSchemaCombinators(t *testing.T) {
+	schema := JSONSchema{Document: parseSchemaDocument(t, `{
+		"properties": {
+			"endpoint": {"anyOf": [{"type": "string", "format": "uri"}, {"type": "null"}]},
+			"size": {"oneOf": [{"type": "integer"}, {"type": "number", "minimum": 10}]},
+			"mode": {"not": {"const": "debug"}},
+			"tags": {"type": "array", "contains": {"const": "edge"}, "maxContains": 1, "minItems": 1},
+			"labels": {"type": "object", "propertyNames": {"pattern": "^[a-z]+$"}, "maxProperties": 2}
+		},
+		"dependentRequired": {"certificate": ["key"]}
+	}`)}
+	result, err := schema.CheckProperties(context.Background(), map[string]interface{}{
+		"endpoint":    true,
+		"size":        20,
+		"mode":        "debug",
+		"tags":        []interface{}{"edge", "edge"},
+		"labels":      map[string]interface{}{"Zone": "a"},
+		"certificate": "cert",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "value doesn't match any of the anyOf schemas", result.Errors["endpoint"].Error)
+	assert.Equal(t, "value matches 2 of the oneOf schemas, expected exactly 1", result.Errors["size"].Error)
+	assert.Equal(t, "value matches the schema in not", result.Errors["mode"].Error)
+	assert.Equal(t, "array has more than 1 items that match contains", result.Errors["tags"].Error)
+	assert.Equal(t, "property name is invalid: value does not match pattern: ^[a-z]+$", result.Errors["labels.Zone"].Error)
+	assert.Equal(t, "missing property required by 'certificate'", result.Errors["key"].Error)
+}
+
+func TestJSONSchemaCatalogReferences(t *testing.T) {
+	documents := map[string]interface{}{
+		"port-schema:v1": parseSchemaDocument(t, `{
+			"$defs": {"port": {"type": "integer", "minimum": 1, "maximum": 65535}},
+			"type": "object",
+			"properties": {"port": {"$ref": "#/$defs/port"}}
+		}`),
+	}
+	lookups := 0
+	schema := JSONSchema{
+		Document: parseSchemaDocument(t, `{
+			"properties": {
+				"http": {"$ref": "port-schema:v1"},
+				"https": {"$ref": "port-schema:v1"},
+				"admin": {"$ref": "port-schema:v1#/$defs/port"}
+			}
+		}`),
+		Lookup: func(ctx context.Context, name string) (interface{}, error) {
+			lookups++
+			if doc, ok := documents[name]; ok {
+				return doc, nil
+			}
+			return nil, errors.New("not found")
+		},
+	}
+	result, err := schema.CheckProperties(context.Background(), map[string]interface{}{
+		"http":  map[string]interface{}{"port": 80},
+		"https": map[string]interface{}{"port": 0},
+		"admin": 70000,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, lookups)
+	assert.Equal(t, 2, len(result.Errors))
+	assert.Equal(t, "value 0 is less than the minimum 1", result.Errors["https.port"].Error)
+	assert.Equal(t, "value 70000 is greater than the maximum 65535", result.Errors["admin"].Error)
+
+	schema.Document = map[string]interface{}{"$ref": "missing-schema:v1"}
+	_, err = schema.CheckProperties(context.Background(), map[string]interface{}{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unable to resolve $ref 'missing-schema:v1'")
+}
+
+func TestJSONSchemaInvalidSchema(t *testing.T) {
+	schema := JSONSchema{Document: map[string]interface{}{"$ref": "#"}}
+	_, err := schema.CheckProperties(context.Background(), map[string]interface{}{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "refers to itself")
+
+	schema = JSONSchema{Document: map[string]interface{}{"properties": map[string]interface{}{"a": map[string]interface{}{"pattern": "("}}}}
+	_, err = schema.CheckProperties(context.Background(), map[string]interface{}{"a": "b"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid pattern '('")
+}
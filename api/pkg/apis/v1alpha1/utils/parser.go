@@ -7,15 +7,21 @@
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/scanner"
+	"time"
+	"unicode"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
@@ -67,12 +73,90 @@ var opNames = map[Token]string{
 	TILDE:      "~",
 }
 
+// tokenNames are the names of the tokens the parser expects, for parse errors
+var tokenNames = map[Token]string{
+	DOLLAR:   "'$'",
+	IDENT:    "a name",
+	OPAREN:   "'('",
+	CPAREN:   "')'",
+	CBRACKET: "']'",
+	CCURLY:   "'}'",
+}
+
 type Node interface {
 	Eval(context utils.EvaluationContext) (interface{}, error)
 }
 
+// Span is the position of a node in the parsed text, from the offset of its first character to the offset after its
+// last character
+type Span struct {
+	Start int
+	End   int
+}
+
+// NodeSpan returns the position of a node in the parsed text
+func NodeSpan(node Node) Span {
+	switch n := node.(type) {
+	case *NumberNode:
+		return n.Span
+	case *IntNode:
+		return n.Span
+	case *IdentifierNode:
+		return n.Span
+	case *TextNode:
+		return n.Span
+	case *UnaryNode:
+		return n.Span
+	case *BinaryNode:
+		return n.Span
+	case *FunctionNode:
+		return n.Span
+	}
+	return Span{}
+}
+
+// expressionError is an error of a sub-expression, which keeps the position of the innermost sub-expression that failed
+type expressionError struct {
+	err  error
+	span Span
+}
+
+func (e expressionError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.err.Error(), e.span.Start+1)
+}
+
+// withSpan adds the position of a node to an error, unless a sub-expression of the node already added its own
+func withSpan(err error, span Span) error {
+	if _, ok := err.(expressionError); ok {
+		return err
+	}
+	return expressionError{err: err, span: span}
+}
+
+// positionError turns an error of an expression in the source text into an error that gives the column and the text of
+// the sub-expression that failed. A COAError keeps its state.
+func positionError(err error, source string) error {
+	exprErr, ok := err.(expressionError)
+	if !ok {
+		return err
+	}
+	position := fmt.Sprintf("at column %d", exprErr.span.Start+1)
+	if exprErr.span.Start >= 0 && exprErr.span.End <= len(source) && exprErr.span.Start < exprErr.span.End {
+		position = fmt.Sprintf("%s in '%s'", position, source[exprErr.span.Start:exprErr.span.End])
+	}
+	if coaErr, ok := exprErr.err.(v1alpha2.COAError); ok {
+		return v1alpha2.COAError{
+			InnerError: coaErr.InnerError,
+			Message:    fmt.Sprintf("%s %s", coaErr.Message, position),
+			State:      coaErr.State,
+		}
+	}
+	return fmt.Errorf("%w %s", exprErr.err, position)
+}
+
 type NumberNode struct {
 	Value float64
+	Span  Span
 }
 
 func (n *NumberNode) Eval(context utils.EvaluationContext) (interface{}, error) {
@@ -81,6 +165,7 @@ func (n *NumberNode) Eval(context utils.EvaluationContext) (interface{}, error)
 
 type IntNode struct {
 	Value int64
+	Span  Span
 }
 
 func (n *IntNode) Eval(context utils.EvaluationContext) (interface{}, error) {
@@ -89,6 +174,7 @@ func (n *IntNode) Eval(context utils.EvaluationContext) (interface{}, error) {
 
 type IdentifierNode struct {
 	Value string
+	Span  Span
 }
 
 func removeQuotes(s string) string {
@@ -110,6 +196,16 @@ func (n *IdentifierNode) Eval(context utils.EvaluationContext) (interface{}, err
 type NullNode struct {
 }
 
+// TextNode is a plain text segment around the expressions of a parsed text
+type TextNode struct {
+	Value string
+	Span  Span
+}
+
+func (n *TextNode) Eval(context utils.EvaluationContext) (interface{}, error) {
+	return n.Value, nil
+}
+
 func (n *NullNode) Eval(context utils.EvaluationContext) (interface{}, error) {
 	return "", nil
 }
@@ -117,9 +213,18 @@ func (n *NullNode) Eval(context utils.EvaluationContext) (interface{}, error) {
 type UnaryNode struct {
 	Op   Token
 	Expr Node
+	Span Span
 }
 
 func (n *UnaryNode) Eval(context utils.EvaluationContext) (interface{}, error) {
+	val, err := n.eval(context)
+	if err != nil {
+		return val, withSpan(err, n.Span)
+	}
+	return val, nil
+}
+
+func (n *UnaryNode) eval(context utils.EvaluationContext) (interface{}, error) {
 	switch n.Op {
 	case PLUS:
 		if n.Expr != nil {
@@ -161,9 +266,18 @@ type BinaryNode struct {
 	Op    Token
 	Left  Node
 	Right Node
+	Span  Span
 }
 
 func (n *BinaryNode) Eval(context utils.EvaluationContext) (interface{}, error) {
+	val, err := n.eval(context)
+	if err != nil {
+		return val, withSpan(err, n.Span)
+	}
+	return val, nil
+}
+
+func (n *BinaryNode) eval(context utils.EvaluationContext) (interface{}, error) {
 	switch n.Op {
 	case PLUS:
 		var lv interface{} = ""
@@ -399,6 +513,7 @@ func (n *BinaryNode) Eval(context utils.EvaluationContext) (interface{}, error)
 type FunctionNode struct {
 	Name string
 	Args []Node
+	Span Span
 }
 
 func readProperty(properties map[string]string, key string) (string, error) {
@@ -483,6 +598,14 @@ func formatFloats(left interface{}, right interface{}, operator string) interfac
 }
 
 func (n *FunctionNode) Eval(context utils.EvaluationContext) (interface{}, error) {
+	val, err := n.eval(context)
+	if err != nil {
+		return val, withSpan(err, n.Span)
+	}
+	return val, nil
+}
+
+func (n *FunctionNode) eval(context utils.EvaluationContext) (interface{}, error) {
 	switch n.Name {
 	case "param":
 		if len(n.Args) == 1 {
@@ -863,19 +986,438 @@ func (n *FunctionNode) Eval(context utils.EvaluationContext) (interface{}, error
 			return fmt.Sprintf("%v", val), nil
 		}
 		return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("$str() expects 1 argument, found %d", len(n.Args)), v1alpha2.BadConfig)
+	case "map", "filter":
+		return n.evalCollection(context)
+	}
+	if f, ok := StdlibFunctions[n.Name]; ok {
+		args := make([]interface{}, 0, len(n.Args))
+		for _, arg := range n.Args {
+			val, err := arg.Eval(context)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, val)
+		}
+		args, err := f.CheckArgs(n.Name, args)
+		if err != nil {
+			return nil, err
+		}
+		return f.call(args)
 	}
 	return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid function name: '%s'", n.Name), v1alpha2.BadConfig)
 }
 
+// ExpressionType is the type of a value in an expression
+type ExpressionType string
+
+const (
+	TypeAny    ExpressionType = "any"
+	TypeString ExpressionType = "string"
+	TypeInt    ExpressionType = "int"
+	TypeBool   ExpressionType = "bool"
+	TypeList   ExpressionType = "list"
+	TypeMap    ExpressionType = "map"
+	// TypeSized is a string, a list or a map
+	TypeSized ExpressionType = "string, list or map"
+)
+
+// StdlibFunction is a function of the expression standard library. Its arguments are evaluated and checked against
+// their types before it's called.
+type StdlibFunction struct {
+	Args []ExpressionType
+	// Optional is the number of trailing arguments that may be left out
+	Optional int
+	Returns  ExpressionType
+	call     func(args []interface{}) (interface{}, error)
+}
+
+// StdlibFunctions are the standard library functions, by name. $map() and $filter() evaluate their second argument
+// for each item, so they are evaluated by FunctionNode.Eval instead.
+var StdlibFunctions = map[string]StdlibFunction{
+	"split": {Args: []ExpressionType{TypeString, TypeString}, Returns: TypeList, call: func(args []interface{}) (interface{}, error) {
+		ret := make([]interface{}, 0)
+		for _, s := range strings.Split(args[0].(string), args[1].(string)) {
+			ret = append(ret, s)
+		}
+		return ret, nil
+	}},
+	"join": {Args: []ExpressionType{TypeList, TypeString}, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		items := make([]string, 0)
+		for _, item := range args[0].([]interface{}) {
+			items = append(items, FormatAsString(item))
+		}
+		return strings.Join(items, args[1].(string)), nil
+	}},
+	"replace": {Args: []ExpressionType{TypeString, TypeString, TypeString}, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		return strings.ReplaceAll(args[0].(string), args[1].(string), args[2].(string)), nil
+	}},
+	"lower": {Args: []ExpressionType{TypeString}, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		return strings.ToLower(args[0].(string)), nil
+	}},
+	"upper": {Args: []ExpressionType{TypeString}, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		return strings.ToUpper(args[0].(string)), nil
+	}},
+	"substring": {Args: []ExpressionType{TypeString, TypeInt, TypeInt}, Optional: 1, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		runes := []rune(args[0].(string))
+		start := args[1].(int64)
+		end := int64(len(runes))
+		if len(args) > 2 {
+			end = args[2].(int64)
+		}
+		if start < 0 || end > int64(len(runes)) || start > end {
+			return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("$substring() range [%d:%d] is out of bounds for '%s'", start, end, args[0]), v1alpha2.BadConfig)
+		}
+		return string(runes[start:end]), nil
+	}},
+	"regexMatch": {Args: []ExpressionType{TypeString, TypeString}, Returns: TypeBool, call: func(args []interface{}) (interface{}, error) {
+		re, err := regexp.Compile(args[1].(string))
+		if err != nil {
+			return nil, v1alpha2.NewCOAError(err, fmt.Sprintf("$regexMatch() pattern '%s' is invalid", args[1]), v1alpha2.BadConfig)
+		}
+		return re.MatchString(args[0].(string)), nil
+	}},
+	"len": {Args: []ExpressionType{TypeSized}, Returns: TypeInt, call: func(args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case string:
+			return int64(len([]rune(v))), nil
+		case []interface{}:
+			return int64(len(v)), nil
+		}
+		return int64(len(args[0].(map[string]interface{}))), nil
+	}},
+	"first": {Args: []ExpressionType{TypeList}, Returns: TypeAny, call: func(args []interface{}) (interface{}, error) {
+		list := args[0].([]interface{})
+		if len(list) == 0 {
+			return nil, v1alpha2.NewCOAError(nil, "$first() expects a list with at least 1 item", v1alpha2.BadConfig)
+		}
+		return list[0], nil
+	}},
+	"last": {Args: []ExpressionType{TypeList}, Returns: TypeAny, call: func(args []interface{}) (interface{}, error) {
+		list := args[0].([]interface{})
+		if len(list) == 0 {
+			return nil, v1alpha2.NewCOAError(nil, "$last() expects a list with at least 1 item", v1alpha2.BadConfig)
+		}
+		return list[len(list)-1], nil
+	}},
+	"keys": {Args: []ExpressionType{TypeMap}, Returns: TypeList, call: func(args []interface{}) (interface{}, error) {
+		keys := make([]string, 0)
+		for k := range args[0].(map[string]interface{}) {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		ret := make([]interface{}, len(keys))
+		for i, k := range keys {
+			ret[i] = k
+		}
+		return ret, nil
+	}},
+	"sha256": {Args: []ExpressionType{TypeString}, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		sum := sha256.Sum256([]byte(args[0].(string)))
+		return hex.EncodeToString(sum[:]), nil
+	}},
+	"base64Encode": {Args: []ExpressionType{TypeString}, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		return base64.StdEncoding.EncodeToString([]byte(args[0].(string))), nil
+	}},
+	"base64Decode": {Args: []ExpressionType{TypeString}, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		data, err := base64.StdEncoding.DecodeString(args[0].(string))
+		if err != nil {
+			return nil, v1alpha2.NewCOAError(err, fmt.Sprintf("$base64Decode() argument '%s' is not base64", args[0]), v1alpha2.BadConfig)
+		}
+		return string(data), nil
+	}},
+	"now": {Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		return time.Now().UTC().Format(time.RFC3339), nil
+	}},
+	"addDuration": {Args: []ExpressionType{TypeString, TypeString}, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		t, err := parseExpressionTime("addDuration", args[0].(string))
+		if err != nil {
+			return nil, err
+		}
+		d, err := time.ParseDuration(args[1].(string))
+		if err != nil {
+			return nil, v1alpha2.NewCOAError(err, fmt.Sprintf("$addDuration() argument '%s' is not a duration", args[1]), v1alpha2.BadConfig)
+		}
+		return t.Add(d).Format(time.RFC3339), nil
+	}},
+	"formatTime": {Args: []ExpressionType{TypeString, TypeString}, Returns: TypeString, call: func(args []interface{}) (interface{}, error) {
+		t, err := parseExpressionTime("formatTime", args[0].(string))
+		if err != nil {
+			return nil, err
+		}
+		return t.Format(args[1].(string)), nil
+	}},
+}
+
+func parseExpressionTime(function string, value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return t, v1alpha2.NewCOAError(err, fmt.Sprintf("$%s() argument '%s' is not an RFC 3339 time", function, value), v1alpha2.BadConfig)
+	}
+	return t, nil
+}
+
+// CheckArgs checks the number of arguments and converts the arguments to their types: lists to []interface{} and
+// whole numbers to int64
+func (f StdlibFunction) CheckArgs(name string, args []interface{}) ([]interface{}, error) {
+	if len(args) < len(f.Args)-f.Optional || len(args) > len(f.Args) {
+		if f.Optional > 0 {
+			return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects %d to %d arguments, found %d", name, len(f.Args)-f.Optional, len(f.Args), len(args)), v1alpha2.BadConfig)
+		}
+		return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects %d arguments, found %d", name, len(f.Args), len(args)), v1alpha2.BadConfig)
+	}
+	ret := make([]interface{}, len(args))
+	for i, arg := range args {
+		val, ok := toExpressionType(arg, f.Args[i])
+		if !ok {
+			return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects argument %d to be a %s, found '%v'", name, i+1, f.Args[i], arg), v1alpha2.BadConfig)
+		}
+		ret[i] = val
+	}
+	return ret, nil
+}
+
+func toExpressionType(val interface{}, t ExpressionType) (interface{}, bool) {
+	switch t {
+	case TypeString:
+		s, ok := val.(string)
+		return s, ok
+	case TypeInt:
+		switch v := val.(type) {
+		case int64:
+			return v, true
+		case int:
+			return int64(v), true
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v), true
+			}
+		}
+		return nil, false
+	case TypeBool:
+		return toBool(val)
+	case TypeList:
+		return toList(val)
+	case TypeMap:
+		m, ok := val.(map[string]interface{})
+		return m, ok
+	case TypeSized:
+		if _, ok := val.(string); ok {
+			return val, true
+		}
+		if m, ok := val.(map[string]interface{}); ok {
+			return m, true
+		}
+		return toList(val)
+	}
+	return val, true
+}
+
+func toList(val interface{}) ([]interface{}, bool) {
+	switch v := val.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		ret := make([]interface{}, len(v))
+		for i, s := range v {
+			ret[i] = s
+		}
+		return ret, true
+	}
+	return nil, false
+}
+
+// evalCollection evaluates $map() and $filter(), which evaluate their second argument with $val() set to each item of
+// the list in their first argument
+func (n *FunctionNode) evalCollection(context utils.EvaluationContext) (interface{}, error) {
+	if len(n.Args) != 2 {
+		return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects 2 arguments, found %d", n.Name, len(n.Args)), v1alpha2.BadConfig)
+	}
+	val, err := n.Args[0].Eval(context)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := toList(val)
+	if !ok {
+		return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects argument 1 to be a %s, found '%v'", n.Name, TypeList, val), v1alpha2.BadConfig)
+	}
+	ret := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		itemContext := context
+		itemContext.Value = item
+		result, err := n.Args[1].Eval(itemContext)
+		if err != nil {
+			return nil, err
+		}
+		if n.Name == "map" {
+			ret = append(ret, result)
+			continue
+		}
+		keep, ok := toBool(result)
+		if !ok {
+			return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("$filter() expects argument 2 to be a %s, found '%v'", TypeBool, result), v1alpha2.BadConfig)
+		}
+		if keep {
+			ret = append(ret, item)
+		}
+	}
+	return ret, nil
+}
+
+// builtinArity is the smallest and the largest number of arguments of the functions that aren't in the standard
+// library, where -1 is any number
+var builtinArity = map[string][2]int{
+	"param":    {1, 1},
+	"property": {1, 1},
+	"input":    {1, 1},
+	"output":   {2, 2},
+	"trigger":  {2, 2},
+	"equal":    {2, 2},
+	"and":      {2, 2},
+	"or":       {2, 2},
+	"not":      {1, 1},
+	"gt":       {2, 2},
+	"ge":       {2, 2},
+	"lt":       {2, 2},
+	"le":       {2, 2},
+	"if":       {3, 3},
+	"in":       {2, -1},
+	"between":  {3, 3},
+	"config":   {2, -1},
+	"secret":   {2, 2},
+	"instance": {0, 0},
+	"val":      {0, 1},
+	"context":  {0, 1},
+	"json":     {1, 1},
+	"str":      {1, 1},
+	"map":      {2, 2},
+	"filter":   {2, 2},
+}
+
+// builtinReturns are the types of the functions that aren't in the standard library and always return the same type
+var builtinReturns = map[string]ExpressionType{
+	"equal":   TypeBool,
+	"and":     TypeBool,
+	"or":      TypeBool,
+	"not":     TypeBool,
+	"gt":      TypeBool,
+	"ge":      TypeBool,
+	"lt":      TypeBool,
+	"le":      TypeBool,
+	"in":      TypeBool,
+	"between": TypeBool,
+	"str":     TypeString,
+	"map":     TypeList,
+	"filter":  TypeList,
+}
+
+// validateNode checks the functions of an expression tree without evaluating it, and returns the type of the node
+// where it's known before evaluation
+func validateNode(node Node) (ExpressionType, error) {
+	switch n := node.(type) {
+	case *IntNode:
+		return TypeInt, nil
+	case *IdentifierNode, *TextNode:
+		return TypeString, nil
+	case *UnaryNode:
+		if _, err := validateNode(n.Expr); err != nil {
+			return TypeAny, err
+		}
+	case *BinaryNode:
+		if _, err := validateNode(n.Left); err != nil {
+			return TypeAny, err
+		}
+		if _, err := validateNode(n.Right); err != nil {
+			return TypeAny, err
+		}
+	case *FunctionNode:
+		t, err := n.validate()
+		if err != nil {
+			return t, withSpan(err, n.Span)
+		}
+		return t, nil
+	}
+	return TypeAny, nil
+}
+
+func (n *FunctionNode) validate() (ExpressionType, error) {
+	types := make([]ExpressionType, len(n.Args))
+	for i, arg := range n.Args {
+		t, err := validateNode(arg)
+		if err != nil {
+			return TypeAny, err
+		}
+		types[i] = t
+	}
+	if arity, ok := builtinArity[n.Name]; ok {
+		if len(n.Args) < arity[0] || (arity[1] >= 0 && len(n.Args) > arity[1]) {
+			expected := fmt.Sprintf("%d to %d", arity[0], arity[1])
+			switch {
+			case arity[1] < 0:
+				expected = fmt.Sprintf("at least %d", arity[0])
+			case arity[0] == arity[1]:
+				expected = fmt.Sprintf("%d", arity[0])
+			}
+			return TypeAny, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects %s arguments, found %d", n.Name, expected, len(n.Args)), v1alpha2.BadConfig)
+		}
+		if (n.Name == "map" || n.Name == "filter") && !typeAccepts(TypeList, types[0]) {
+			return TypeAny, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects argument 1 to be a %s, found type %s", n.Name, TypeList, types[0]), v1alpha2.BadConfig)
+		}
+		if t, ok := builtinReturns[n.Name]; ok {
+			return t, nil
+		}
+		return TypeAny, nil
+	}
+	f, ok := StdlibFunctions[n.Name]
+	if !ok {
+		return TypeAny, v1alpha2.NewCOAError(nil, fmt.Sprintf("unknown function $%s()", n.Name), v1alpha2.BadConfig)
+	}
+	if len(n.Args) < len(f.Args)-f.Optional || len(n.Args) > len(f.Args) {
+		if f.Optional > 0 {
+			return TypeAny, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects %d to %d arguments, found %d", n.Name, len(f.Args)-f.Optional, len(f.Args), len(n.Args)), v1alpha2.BadConfig)
+		}
+		return TypeAny, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects %d arguments, found %d", n.Name, len(f.Args), len(n.Args)), v1alpha2.BadConfig)
+	}
+	for i, t := range types {
+		if !typeAccepts(f.Args[i], t) {
+			return TypeAny, v1alpha2.NewCOAError(nil, fmt.Sprintf("$%s() expects argument %d to be a %s, found type %s", n.Name, i+1, f.Args[i], t), v1alpha2.BadConfig)
+		}
+	}
+	return f.Returns, nil
+}
+
+// typeAccepts tells whether a value of the given type may be passed where the expected type is needed. A string may be
+// a bool, like 'true'.
+func typeAccepts(expected ExpressionType, t ExpressionType) bool {
+	if expected == TypeAny || t == TypeAny || expected == t {
+		return true
+	}
+	switch expected {
+	case TypeSized:
+		return t == TypeString || t == TypeList || t == TypeMap
+	case TypeBool:
+		return t == TypeString
+	}
+	return false
+}
+
 type Parser struct {
 	Segments     []string
 	OriginalText string
+	// offsets are the offsets of the segments in the original text
+	offsets []int
 }
 
 type ExpressionParser struct {
 	s     *scanner.Scanner
 	token Token
 	text  string
+	// source is the text the expression is part of, and offset is the offset of the expression in it
+	source string
+	offset int
+	// start and end are the offsets of the current token in the source, and prevEnd is the end of the previous token
+	start   int
+	end     int
+	prevEnd int
 }
 
 func NewParser(text string) *Parser {
@@ -883,30 +1425,44 @@ func NewParser(text string) *Parser {
 	loc := re.FindAllStringIndex(text, -1)
 
 	segments := make([]string, 0, len(loc)*2+1)
+	offsets := make([]int, 0, len(loc)*2+1)
 	start := 0
 	for _, l := range loc {
 		if start != l[0] {
 			segments = append(segments, text[start:l[0]])
+			offsets = append(offsets, start)
 		}
 		segments = append(segments, text[l[0]:l[1]])
+		offsets = append(offsets, l[0])
 		start = l[1]
 	}
 	if start < len(text) {
 		segments = append(segments, text[start:])
+		offsets = append(offsets, start)
 	}
 
 	p := &Parser{
-		Segments: segments,
+		Segments:     segments,
+		OriginalText: text,
+		offsets:      offsets,
 	}
 	return p
 }
 
+// expressionParser returns the parser of the expression in a ${{...}} segment
+func (p *Parser) expressionParser(i int) *ExpressionParser {
+	s := p.Segments[i]
+	if len(p.offsets) != len(p.Segments) {
+		return newExpressionParser(s[3 : len(s)-2])
+	}
+	return newSourceExpressionParser(p.OriginalText, p.offsets[i]+3, s[3:len(s)-2])
+}
+
 func (p *Parser) Eval(context utils.EvaluationContext) (interface{}, error) {
 	results := make([]interface{}, 0)
-	for _, s := range p.Segments {
+	for i, s := range p.Segments {
 		if strings.HasPrefix(s, "${{") && strings.HasSuffix(s, "}}") {
-			text := s[3 : len(s)-2]
-			parser := newExpressionParser(text)
+			parser := p.expressionParser(i)
 			n, err := parser.Eval(context)
 			if err != nil {
 				log.ErrorfCtx(context.Context, " (Parser): Parser evaluate failed: %v", err)
@@ -932,13 +1488,63 @@ func (p *Parser) Eval(context utils.EvaluationContext) (interface{}, error) {
 	return ret, nil
 }
 
+// Parse parses the text without evaluating it, and returns the plain text segments as TextNodes and the expressions in
+// the ${{...}} segments as expression trees
+func (p *Parser) Parse() ([]Node, error) {
+	nodes := make([]Node, 0, len(p.Segments))
+	for i, s := range p.Segments {
+		if strings.HasPrefix(s, "${{") && strings.HasSuffix(s, "}}") {
+			parser := p.expressionParser(i)
+			n, err := parser.Parse()
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n...)
+		} else {
+			span := Span{Start: 0, End: len(s)}
+			if len(p.offsets) == len(p.Segments) {
+				span = Span{Start: p.offsets[i], End: p.offsets[i] + len(s)}
+			}
+			nodes = append(nodes, &TextNode{Value: s, Span: span})
+		}
+	}
+	return nodes, nil
+}
+
+// Validate parses the text and checks its expressions without evaluating them. The functions must be known and be
+// given the number of arguments they take, and the arguments whose types are known before evaluation, like numbers,
+// names and the results of most functions, must have the types the functions expect.
+func (p *Parser) Validate() error {
+	nodes, err := p.Parse()
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if _, err := validateNode(node); err != nil {
+			return positionError(err, p.OriginalText)
+		}
+	}
+	return nil
+}
+
 func newExpressionParser(text string) *ExpressionParser {
+	return newSourceExpressionParser(text, 0, text)
+}
+
+// newSourceExpressionParser returns the parser of an expression at the given offset of a source text, so that the
+// positions of its nodes and errors are offsets in the source
+func newSourceExpressionParser(source string, offset int, text string) *ExpressionParser {
 	var s scanner.Scanner // TODO: this is mostly used to scan go code, we should use a custom scanner
-	s.Init(strings.NewReader(strings.TrimSpace(text)))
+	trimmed := strings.TrimSpace(text)
+	s.Init(strings.NewReader(trimmed))
 	s.Mode = scanner.ScanIdents | scanner.ScanChars | scanner.ScanStrings | scanner.ScanInts
+	// scanner errors, like an unknown escape in a quoted string, don't stop the scan, so they aren't printed
+	s.Error = func(s *scanner.Scanner, msg string) {}
 	p := &ExpressionParser{
-		s:    &s,
-		text: text,
+		s:      &s,
+		text:   text,
+		source: source,
+		offset: offset + len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace)),
 	}
 	p.next()
 	return p
@@ -954,7 +1560,7 @@ func (p *ExpressionParser) Eval(context utils.EvaluationContext) (interface{}, e
 		if _, ok := n.(*NullNode); !ok {
 			v, r := n.Eval(context)
 			if r != nil {
-				return "", r
+				return "", positionError(r, p.source)
 			}
 			if vt, ok := v.([]string); ok {
 				if ret == nil {
@@ -1002,13 +1608,35 @@ func (p *ExpressionParser) Eval(context utils.EvaluationContext) (interface{}, e
 	}
 }
 
+// Parse parses the expression the same way as Eval, without evaluating it
+func (p *ExpressionParser) Parse() ([]Node, error) {
+	nodes := make([]Node, 0, 1)
+	for {
+		n, err := p.expr(false)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := n.(*NullNode); ok {
+			return nodes, nil
+		}
+		nodes = append(nodes, n)
+		p.next()
+	}
+}
+
 func (p *ExpressionParser) next() {
+	p.prevEnd = p.end
 	p.token = p.scan()
 }
 
 func (p *ExpressionParser) scan() Token {
 	tok := p.s.Scan()
 	p.text = p.s.TokenText()
+	p.start = p.offset + p.s.Position.Offset
+	p.end = p.offset + p.s.Pos().Offset
+	if tok == scanner.EOF {
+		p.start = p.end
+	}
 	switch tok {
 	case scanner.EOF:
 		return EOF
@@ -1069,21 +1697,26 @@ func (p *ExpressionParser) match(t Token) error {
 	if p.token == t {
 		p.next()
 	} else {
-		return v1alpha2.NewCOAError(nil, fmt.Sprintf("expected %T, got %s", t, p.text), v1alpha2.BadConfig)
+		found := fmt.Sprintf("'%s'", p.text)
+		if p.token == EOF {
+			found = "the end of the expression"
+		}
+		return v1alpha2.NewCOAError(nil, fmt.Sprintf("expected %s at column %d, found %s", tokenNames[t], p.start+1, found), v1alpha2.BadConfig)
 	}
 	return nil
 }
 
 func (p *ExpressionParser) primary() (Node, error) {
+	start := p.start
 	switch p.token {
 	case INT:
 		v, _ := strconv.ParseInt(p.text, 10, 64)
 		p.next()
-		return &IntNode{v}, nil
+		return &IntNode{v, Span{start, p.prevEnd}}, nil
 	case NUMBER:
 		v, _ := strconv.ParseFloat(p.text, 64)
 		p.next()
-		return &NumberNode{v}, nil
+		return &NumberNode{v, Span{start, p.prevEnd}}, nil
 	case DOLLAR:
 		return p.function()
 	case OPAREN:
@@ -1107,7 +1740,7 @@ func (p *ExpressionParser) primary() (Node, error) {
 		if err := p.match(CBRACKET); err != nil {
 			return nil, err
 		}
-		return &UnaryNode{OBRACKET, bexpr}, nil
+		return &UnaryNode{OBRACKET, bexpr, Span{start, p.prevEnd}}, nil
 	case OCURLY:
 		p.next()
 		node, err := p.expr(false)
@@ -1118,30 +1751,31 @@ func (p *ExpressionParser) primary() (Node, error) {
 		if err := p.match(CCURLY); err != nil {
 			return nil, err
 		}
-		return &UnaryNode{OCURLY, cexpr}, nil
+		return &UnaryNode{OCURLY, cexpr, Span{start, p.prevEnd}}, nil
 	case PLUS:
 		p.next()
 		node, err := p.primary()
 		if err != nil {
 			return nil, err
 		}
-		return &UnaryNode{PLUS, node}, nil
+		return &UnaryNode{PLUS, node, Span{start, p.prevEnd}}, nil
 	case MINUS:
 		p.next()
 		node, err := p.primary()
 		if err != nil {
 			return nil, err
 		}
-		return &UnaryNode{MINUS, node}, nil
+		return &UnaryNode{MINUS, node, Span{start, p.prevEnd}}, nil
 	case IDENT:
 		v := p.text
 		p.next()
-		return &IdentifierNode{v}, nil
+		return &IdentifierNode{v, Span{start, p.prevEnd}}, nil
 	}
 	return nil, nil
 }
 
 func (p *ExpressionParser) factor() (Node, error) {
+	start := p.start
 	node, err := p.primary()
 	if err != nil {
 		return nil, err
@@ -1154,63 +1788,63 @@ func (p *ExpressionParser) factor() (Node, error) {
 			if err != nil {
 				return nil, err
 			}
-			node = &BinaryNode{MULT, node, n}
+			node = &BinaryNode{MULT, node, n, Span{start, p.prevEnd}}
 		case DIV:
 			p.next()
 			n, err := p.primary()
 			if err != nil {
 				return nil, err
 			}
-			node = &BinaryNode{DIV, node, n}
+			node = &BinaryNode{DIV, node, n, Span{start, p.prevEnd}}
 		case SLASH:
 			p.next()
 			n, err := p.primary()
 			if err != nil {
 				return nil, err
 			}
-			node = &BinaryNode{SLASH, node, n}
+			node = &BinaryNode{SLASH, node, n, Span{start, p.prevEnd}}
 		case PERIOD:
 			p.next()
 			n, err := p.primary()
 			if err != nil {
 				return nil, err
 			}
-			node = &BinaryNode{PERIOD, node, n}
+			node = &BinaryNode{PERIOD, node, n, Span{start, p.prevEnd}}
 		case COLON:
 			p.next()
 			n, err := p.primary()
 			if err != nil {
 				return nil, err
 			}
-			node = &BinaryNode{COLON, node, n}
+			node = &BinaryNode{COLON, node, n, Span{start, p.prevEnd}}
 		case QUESTION:
 			p.next()
 			n, err := p.primary()
 			if err != nil {
 				return nil, err
 			}
-			node = &BinaryNode{QUESTION, node, n}
+			node = &BinaryNode{QUESTION, node, n, Span{start, p.prevEnd}}
 		case EQUAL:
 			p.next()
 			n, err := p.primary()
 			if err != nil {
 				return nil, err
 			}
-			node = &BinaryNode{EQUAL, node, n}
+			node = &BinaryNode{EQUAL, node, n, Span{start, p.prevEnd}}
 		case TILDE:
 			p.next()
 			n, err := p.primary()
 			if err != nil {
 				return nil, err
 			}
-			node = &BinaryNode{TILDE, node, n}
+			node = &BinaryNode{TILDE, node, n, Span{start, p.prevEnd}}
 		case AMPHERSAND:
 			p.next()
 			n, err := p.primary()
 			if err != nil {
 				return nil, err
 			}
-			node = &BinaryNode{AMPHERSAND, node, n}
+			node = &BinaryNode{AMPHERSAND, node, n, Span{start, p.prevEnd}}
 		default:
 			return node, nil
 		}
@@ -1218,6 +1852,7 @@ func (p *ExpressionParser) factor() (Node, error) {
 }
 
 func (p *ExpressionParser) expr(inFunc bool) (Node, error) {
+	start := p.start
 	node, err := p.factor()
 	if node == nil || err != nil {
 		return &NullNode{}, err
@@ -1230,14 +1865,14 @@ func (p *ExpressionParser) expr(inFunc bool) (Node, error) {
 			if err != nil {
 				return &NullNode{}, err
 			}
-			node = &BinaryNode{PLUS, node, f}
+			node = &BinaryNode{PLUS, node, f, Span{start, p.prevEnd}}
 		case MINUS:
 			p.next()
 			f, err := p.factor()
 			if err != nil {
 				return &NullNode{}, err
 			}
-			node = &BinaryNode{MINUS, node, f}
+			node = &BinaryNode{MINUS, node, f, Span{start, p.prevEnd}}
 		case COMMA:
 			if !inFunc {
 				p.next()
@@ -1245,7 +1880,7 @@ func (p *ExpressionParser) expr(inFunc bool) (Node, error) {
 				if err != nil {
 					return &NullNode{}, err
 				}
-				node = &BinaryNode{COMMA, node, f}
+				node = &BinaryNode{COMMA, node, f, Span{start, p.prevEnd}}
 			} else {
 				return node, nil
 			}
@@ -1267,6 +1902,7 @@ func (p *ExpressionParser) expr(inFunc bool) (Node, error) {
 }
 
 func (p *ExpressionParser) function() (Node, error) {
+	start := p.start
 	err := p.match(DOLLAR)
 	if err != nil {
 		return nil, err
@@ -1287,7 +1923,10 @@ func (p *ExpressionParser) function() (Node, error) {
 			return nil, err
 		}
 		if _, ok := node.(*NullNode); ok {
-			return nil, v1alpha2.NewCOAError(nil, "invalid argument", v1alpha2.BadConfig)
+			if p.token == EOF {
+				return nil, p.match(CPAREN)
+			}
+			return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid argument of $%s() at column %d, found '%s'", name, p.start+1, p.text), v1alpha2.BadConfig)
 		}
 		args = append(args, node)
 		if p.token == COMMA {
@@ -1298,7 +1937,7 @@ func (p *ExpressionParser) function() (Node, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &FunctionNode{name, args}, nil
+	return &FunctionNode{name, args, Span{start, p.prevEnd}}, nil
 }
 
 func EvaluateDeployment(context utils.EvaluationContext) (model.DeploymentSpec, error) {
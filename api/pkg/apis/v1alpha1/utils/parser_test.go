@@ -9,6 +9,7 @@ package utils
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
@@ -2988,3 +2989,199 @@ func TestRecursiveUnsupported(t *testing.T) {
 	})
 	assert.NotNil(t, err)
 }
+
+func TestParseWithoutEvaluation(t *testing.T) {
+	parser := NewParser("stage-${{$if($lt($output(counter, val), 20), counter, '')}}")
+	nodes, err := parser.Parse()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(nodes))
+	assert.Equal(t, &TextNode{Value: "stage-", Span: Span{0, 6}}, nodes[0])
+	f, ok := nodes[1].(*FunctionNode)
+	assert.True(t, ok)
+	assert.Equal(t, "if", f.Name)
+	assert.Equal(t, 3, len(f.Args))
+	assert.Equal(t, Span{9, 57}, f.Span)
+	assert.Equal(t, &IdentifierNode{Value: "counter", Span: Span{45, 52}}, f.Args[1])
+}
+
+func TestParseInvalidExpression(t *testing.T) {
+	parser := NewParser("${{$if($lt(1, 2), a}}")
+	_, err := parser.Parse()
+	assert.NotNil(t, err)
+}
+func TestStringFunctions(t *testing.T) {
+	context := utils.EvaluationContext{
+		Context: ctx,
+		Inputs: map[string]interface{}{
+			"sites": "eu-1,eu-2,us-1",
+			"name":  "Edge Gateway",
+		},
+	}
+	cases := map[string]interface{}{
+		"${{$split($input(sites), ',')}}":                                       []interface{}{"eu-1", "eu-2", "us-1"},
+		"${{$join($split($input(sites), ','), ';')}}":                           "eu-1;eu-2;us-1",
+		"${{$replace($input(name), ' ', '-')}}":                                 "Edge-Gateway",
+		"${{$lower($input(name))}}":                                             "edge gateway",
+		"${{$upper($input(name))}}":                                             "EDGE GATEWAY",
+		"${{$substring($input(name), 5)}}":                                      "Gateway",
+		"${{$substring($input(name), 0, 4)}}":                                   "Edge",
+		"${{$regexMatch($input(name), '^Edge')}}":                               true,
+		"${{$regexMatch($input(name), '^Gateway')}}":                            false,
+		"${{$lower($replace($input(name), ' ', '-'))}}-${{$len($input(name))}}": "edge-gateway-12",
+	}
+	for expression, expected := range cases {
+		val, err := NewParser(expression).Eval(context)
+		assert.Nil(t, err, expression)
+		assert.Equal(t, expected, val, expression)
+	}
+}
+func TestCollectionFunctions(t *testing.T) {
+	context := utils.EvaluationContext{
+		Context: ctx,
+		Inputs: map[string]interface{}{
+			"sites": []interface{}{
+				map[string]interface{}{"name": "hq", "region": "eu"},
+				map[string]interface{}{"name": "plant", "region": "us"},
+				map[string]interface{}{"name": "lab", "region": "eu"},
+			},
+			"labels": map[string]interface{}{"tier": "edge", "env": "prod"},
+		},
+	}
+	cases := map[string]interface{}{
+		"${{$len($input(sites))}}":                     int64(3),
+		"${{$len($input(labels))}}":                    int64(2),
+		"${{$keys($input(labels))}}":                   []interface{}{"env", "tier"},
+		"${{$map($input(sites), $upper($val(name)))}}": []interface{}{"HQ", "PLANT", "LAB"},
+		"${{$join($map($filter($input(sites), $equal($val(region), eu)), $val(name)), ',')}}": "hq,lab",
+		"${{$first($map($input(sites), $val(name)))}}":                                        "hq",
+		"${{$last($map($input(sites), $val(name)))}}":                                         "lab",
+		"${{$len($filter($input(sites), $equal($val(region), apac)))}}":                       int64(0),
+	}
+	for expression, expected := range cases {
+		val, err := NewParser(expression).Eval(context)
+		assert.Nil(t, err, expression)
+		assert.Equal(t, expected, val, expression)
+	}
+}
+func TestEncodingFunctions(t *testing.T) {
+	cases := map[string]interface{}{
+		"${{$sha256(symphony)}}":                         "0c873ecbd3c57a0116ca9190d67c9d72bc0154efc4f81cca5d11c62181846184",
+		"${{$base64Encode('user:pass')}}":                "dXNlcjpwYXNz",
+		"${{$base64Decode($base64Encode('user:pass'))}}": "user:pass",
+	}
+	for expression, expected := range cases {
+		val, err := NewParser(expression).Eval(utils.EvaluationContext{Context: ctx})
+		assert.Nil(t, err, expression)
+		assert.Equal(t, expected, val, expression)
+	}
+}
+func TestTimeFunctions(t *testing.T) {
+	context := utils.EvaluationContext{
+		Context: ctx,
+		Inputs: map[string]interface{}{
+			"start": "2024-05-01T22:30:00Z",
+		},
+	}
+	val, err := NewParser("${{$addDuration($input(start), '2h')}}").Eval(context)
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-05-02T00:30:00Z", val)
+	val, err = NewParser("${{$formatTime($addDuration($input(start), '-30m'), '2006-01-02 15:04')}}").Eval(context)
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-05-01 22:00", val)
+
+	val, err = NewParser("${{$now()}}").Eval(context)
+	assert.Nil(t, err)
+	now, err := time.Parse(time.RFC3339, val.(string))
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now(), now, time.Minute)
+}
+func TestStdlibFunctionErrors(t *testing.T) {
+	context := utils.EvaluationContext{
+		Context: ctx,
+		Inputs: map[string]interface{}{
+			"count": 3,
+			"empty": []interface{}{},
+		},
+	}
+	cases := map[string]string{
+		"${{$upper($input(count))}}":     "$upper() expects argument 1 to be a string, found '3'",
+		"${{$split(a)}}":                 "$split() expects 2 arguments, found 1",
+		"${{$substring(abc)}}":           "$substring() expects 2 to 3 arguments, found 1",
+		"${{$substring(abc, 1, 5)}}":     "$substring() range [1:5] is out of bounds for 'abc'",
+		"${{$substring(abc, x)}}":        "$substring() expects argument 2 to be a int, found 'x'",
+		"${{$first($input(empty))}}":     "$first() expects a list with at least 1 item",
+		"${{$keys(abc)}}":                "$keys() expects argument 1 to be a map, found 'abc'",
+		"${{$map(abc, $val())}}":         "$map() expects argument 1 to be a list, found 'abc'",
+		"${{$addDuration(today, '1h')}}": "$addDuration() argument 'today' is not an RFC 3339 time",
+		"${{$base64Decode('%%')}}":       "$base64Decode() argument '%%' is not base64",
+		"${{$len($input(count))}}":       "$len() expects argument 1 to be a string, list or map, found '3'",
+	}
+	for expression, expected := range cases {
+		_, err := NewParser(expression).Eval(context)
+		if expected == "" {
+			assert.Nil(t, err, expression)
+			continue
+		}
+		assert.NotNil(t, err, expression)
+		if err != nil {
+			assert.Contains(t, err.Error(), expected, expression)
+		}
+	}
+}
+
+func TestEvalErrorPosition(t *testing.T) {
+	parser := NewParser("name-${{$upper($split(a.b, '.'))}}")
+	_, err := parser.Eval(utils.EvaluationContext{})
+	assert.NotNil(t, err)
+	coaErr, ok := err.(v1alpha2.COAError)
+	assert.True(t, ok)
+	assert.Equal(t, v1alpha2.BadConfig, coaErr.State)
+	assert.Equal(t, "$upper() expects argument 1 to be a string, found '[a b]' at column 9 in '$upper($split(a.b, '.'))'", coaErr.Message)
+
+	parser = NewParser("${{$and(true, $secret(check))}}")
+	_, err = parser.Eval(utils.EvaluationContext{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "$secret() expects 2 arguments, found 1 at column 15 in '$secret(check)'")
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	parser := NewParser("${{$if($lt(1, 2), a}}")
+	_, err := parser.Parse()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "expected ')' at column 20, found the end of the expression")
+}
+
+func TestValidate(t *testing.T) {
+	for _, valid := range []string{
+		"plain text",
+		"stage-${{$if($lt($output(counter, val), 20), counter, '')}}",
+		"${{$join($map($split($input(sites), ','), $upper($val())), '-')}}",
+		"${{$substring($property(name), 0, 3)}}",
+		"${{$in($val(), a, b, c)}}",
+		"${{$config(app, key, x, y)}}",
+		"${{$filter($json($input(list)), $equal($val(), a))}}",
+		"${{$len($keys($val()))}}",
+	} {
+		assert.Nil(t, NewParser(valid).Validate(), valid)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	for text, message := range map[string]string{
+		"${{$nope(a)}}":                       "unknown function $nope() at column 4 in '$nope(a)'",
+		"${{$if($equal(a, b), c)}}":           "$if() expects 3 arguments, found 2 at column 4 in '$if($equal(a, b), c)'",
+		"${{$in(a)}}":                         "$in() expects at least 2 arguments, found 1 at column 4 in '$in(a)'",
+		"${{$val(a, b)}}":                     "$val() expects 0 to 1 arguments, found 2 at column 4 in '$val(a, b)'",
+		"x ${{$substring(abc)}}":              "$substring() expects 2 to 3 arguments, found 1 at column 6 in '$substring(abc)'",
+		"${{$upper(5)}}":                      "$upper() expects argument 1 to be a string, found type int at column 4 in '$upper(5)'",
+		"${{$join($upper(a), ',')}}":          "$join() expects argument 1 to be a list, found type string at column 4 in '$join($upper(a), ',')'",
+		"${{$map($equal(a, b), $val())}}":     "$map() expects argument 1 to be a list, found type bool at column 4 in '$map($equal(a, b), $val())'",
+		"${{$if(true, $lower($keys(a)), b)}}": "$keys() expects argument 1 to be a map, found type string at column 21 in '$keys(a)'",
+	} {
+		err := NewParser(text).Validate()
+		assert.NotNil(t, err, text)
+		if err != nil {
+			assert.Equal(t, message, err.(v1alpha2.COAError).Message, text)
+		}
+	}
+}
@@ -402,6 +402,20 @@ func ConvertReferenceToObjectName(name string) string {
 	return name
 }
 
+// SplitRevisionReference splits a reference like 'catalog:v1@3' into the reference of the object and the revision of
+// the object. The revision is 0 when the reference has none.
+func SplitRevisionReference(reference string) (string, int, error) {
+	index := strings.LastIndex(reference, constants.RevisionSeparator)
+	if index == -1 {
+		return reference, 0, nil
+	}
+	revision, err := strconv.Atoi(reference[index+len(constants.RevisionSeparator):])
+	if err != nil || revision < 1 {
+		return reference, 0, v1alpha2.NewCOAError(nil, fmt.Sprintf("invalid revision in reference '%s'", reference), v1alpha2.BadRequest)
+	}
+	return reference[:index], revision, nil
+}
+
 func ConvertObjectNameToReference(name string) string {
 	index := strings.LastIndex(name, constants.ResourceSeperator)
 	if index == -1 {
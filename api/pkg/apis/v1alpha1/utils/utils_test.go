@@ -740,3 +740,20 @@ func TestReadPropertyNameWithArraySlicing(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, val, m3)
 }
+
+func TestSplitRevisionReference(t *testing.T) {
+	name, revision, err := SplitRevisionReference("site-config:v1@3")
+	assert.Nil(t, err)
+	assert.Equal(t, "site-config:v1", name)
+	assert.Equal(t, 3, revision)
+
+	name, revision, err = SplitRevisionReference("site-config:v1")
+	assert.Nil(t, err)
+	assert.Equal(t, "site-config:v1", name)
+	assert.Equal(t, 0, revision)
+
+	_, _, err = SplitRevisionReference("site-config:v1@latest")
+	assert.NotNil(t, err)
+	_, _, err = SplitRevisionReference("site-config:v1@0")
+	assert.NotNil(t, err)
+}
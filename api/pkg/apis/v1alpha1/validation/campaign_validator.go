@@ -9,9 +9,13 @@ package validation
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/providers/stage"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+	coa_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/utils"
 )
 
 type CampaignValidator struct {
@@ -32,8 +36,9 @@ func NewCampaignValidator(campaignContainerLookupFunc ObjectLookupFunc, campaign
 // Validate Campaign creation or update
 // 1. First stage is valid
 // 2. Stages in the list are
-// 3. campaign name and rootResource is valid. And rootResource is immutable
-// 4. Update is not allow when there are running activations
+// 3. Stage graph is valid
+// 4. campaign name and rootResource is valid. And rootResource is immutable
+// 5. Update is not allow when there are running activations
 func (c *CampaignValidator) ValidateCreateOrUpdate(ctx context.Context, newRef interface{}, oldRef interface{}) []ErrorField {
 	new := c.ConvertInterfaceToCampaign(newRef)
 	old := c.ConvertInterfaceToCampaign(oldRef)
@@ -49,6 +54,8 @@ func (c *CampaignValidator) ValidateCreateOrUpdate(ctx context.Context, newRef i
 	if err := c.ValidateStages(new); err != nil {
 		errorFields = append(errorFields, *err)
 	}
+	// validate stage graph
+	errorFields = append(errorFields, c.ValidateStageGraph(new)...)
 	if oldRef == nil {
 		// validate create specific fields
 		if err := ValidateObjectName(new.ObjectMeta.Name, new.Spec.RootResource); err != nil {
@@ -132,6 +139,271 @@ func (c *CampaignValidator) ValidateStages(campaign model.CampaignState) *ErrorF
 	return nil
 }
 
+// Validate the stage graph of the campaign, which links every stage to the stages its stageSelector can select
+// 1. Provider of every stage is a registered stage provider
+// 2. stageSelector and input expressions can be parsed
+// 3. stageSelector expressions can only select stages in the stages list
+// 4. Every stage can be reached from firstStage
+// 5. $output() refers to stages that can have run before the stage
+// A stageSelector whose result depends on the activation, like $input() or $output(), can select any stage.
+func (c *CampaignValidator) ValidateStageGraph(campaign model.CampaignState) []ErrorField {
+	errorFields := []ErrorField{}
+	names := make([]string, 0, len(campaign.Spec.Stages))
+	stages := make(map[string]model.StageSpec, len(campaign.Spec.Stages))
+	for _, s := range campaign.Spec.Stages {
+		names = append(names, s.Name)
+		stages[s.Name] = s
+	}
+	sort.Strings(names)
+
+	graph := stageGraph{
+		next: make(map[string][]string, len(names)),
+		any:  make(map[string]bool, len(names)),
+	}
+	selectors := make(map[string][]utils.Node, len(names))
+	inputs := make(map[string]map[string]parsedInput, len(names))
+	for _, name := range names {
+		s := stages[name]
+		if !stage.IsProviderType(s.Provider) {
+			errorFields = append(errorFields, ErrorField{
+				FieldPath:       fmt.Sprintf("spec.stages.%s.provider", name),
+				Value:           s.Provider,
+				DetailedMessage: "provider must be a registered stage provider",
+			})
+		}
+
+		nodes, err := parseExpression(s.StageSelector)
+		if err != nil {
+			errorFields = append(errorFields, ErrorField{
+				FieldPath:       fmt.Sprintf("spec.stages.%s.stageSelector", name),
+				Value:           s.StageSelector,
+				DetailedMessage: fmt.Sprintf("stageSelector is not a valid expression: %s", err.Error()),
+			})
+		} else {
+			selectors[name] = nodes
+			if !strings.Contains(s.StageSelector, "$") {
+				// literal stageSelectors are checked by ValidateStages
+				if _, ok := stages[s.StageSelector]; ok {
+					graph.next[name] = []string{s.StageSelector}
+				}
+			} else if candidates, ok := selectableStages(nodes); ok {
+				for _, candidate := range candidates {
+					if candidate == "" {
+						continue
+					}
+					if _, ok := stages[candidate]; !ok {
+						errorFields = append(errorFields, ErrorField{
+							FieldPath:       fmt.Sprintf("spec.stages.%s.stageSelector", name),
+							Value:           s.StageSelector,
+							DetailedMessage: fmt.Sprintf("stageSelector can select stage '%s', which is not in the stages list", candidate),
+						})
+						continue
+					}
+					graph.next[name] = append(graph.next[name], candidate)
+				}
+			} else {
+				graph.any[name] = true
+			}
+		}
+
+		inputs[name] = make(map[string]parsedInput)
+		errorFields = append(errorFields, parseInputs(fmt.Sprintf("spec.stages.%s.inputs", name), s.Inputs, inputs[name])...)
+	}
+
+	if _, ok := stages[campaign.Spec.FirstStage]; !ok {
+		// an invalid firstStage is reported by ValidateFirstStage, without it the graph can't be walked
+		return errorFields
+	}
+	reachable := graph.reach(campaign.Spec.FirstStage, names)
+	reachable[campaign.Spec.FirstStage] = true
+	for _, name := range names {
+		if !reachable[name] {
+			errorFields = append(errorFields, ErrorField{
+				FieldPath:       fmt.Sprintf("spec.stages.%s", name),
+				Value:           name,
+				DetailedMessage: fmt.Sprintf("stage can't be reached from firstStage '%s'", campaign.Spec.FirstStage),
+			})
+		}
+	}
+
+	// successors of every stage, a stage can read the outputs of the stages it is a successor of
+	successors := make(map[string]map[string]bool, len(names))
+	for _, name := range names {
+		successors[name] = graph.reach(name, names)
+	}
+	checkOutputs := func(fieldPath string, value string, name string, nodes []utils.Node, self bool) {
+		for _, ref := range outputReferences(nodes) {
+			if _, ok := stages[ref]; !ok {
+				errorFields = append(errorFields, ErrorField{
+					FieldPath:       fieldPath,
+					Value:           value,
+					DetailedMessage: fmt.Sprintf("$output() refers to stage '%s', which is not in the stages list", ref),
+				})
+			} else if !successors[ref][name] && !(self && ref == name) {
+				errorFields = append(errorFields, ErrorField{
+					FieldPath:       fieldPath,
+					Value:           value,
+					DetailedMessage: fmt.Sprintf("$output() refers to stage '%s', which can't have run before stage '%s'", ref, name),
+				})
+			}
+		}
+	}
+	for _, name := range names {
+		// the stageSelector is evaluated after the stage has run, so it can read the outputs of the stage itself
+		checkOutputs(fmt.Sprintf("spec.stages.%s.stageSelector", name), stages[name].StageSelector, name, selectors[name], true)
+		paths := make([]string, 0, len(inputs[name]))
+		for path := range inputs[name] {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			checkOutputs(path, inputs[name][path].value, name, inputs[name][path].nodes, false)
+		}
+	}
+	return errorFields
+}
+
+// stageGraph links every stage to the stages its stageSelector can select. A stage whose stageSelector depends on
+// the activation is linked to any stage.
+type stageGraph struct {
+	next map[string][]string
+	any  map[string]bool
+}
+
+// reach returns the stages that can run after the stage
+func (g stageGraph) reach(from string, names []string) map[string]bool {
+	reached := make(map[string]bool)
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		next := g.next[current]
+		if g.any[current] {
+			next = names
+		}
+		for _, n := range next {
+			if !reached[n] {
+				reached[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return reached
+}
+
+// parseExpression parses a stageSelector or an input, and checks its functions with the validation pass of the parser
+func parseExpression(value string) ([]utils.Node, error) {
+	parser := utils.NewParser(value)
+	if err := parser.Validate(); err != nil {
+		return nil, err
+	}
+	return parser.Parse()
+}
+
+type parsedInput struct {
+	value string
+	nodes []utils.Node
+}
+
+// parseInputs parses the string values of the inputs, keyed by their field paths
+func parseInputs(fieldPath string, value interface{}, parsed map[string]parsedInput) []ErrorField {
+	errorFields := []ErrorField{}
+	switch v := value.(type) {
+	case string:
+		nodes, err := parseExpression(v)
+		if err != nil {
+			errorFields = append(errorFields, ErrorField{
+				FieldPath:       fieldPath,
+				Value:           v,
+				DetailedMessage: fmt.Sprintf("input is not a valid expression: %s", err.Error()),
+			})
+		} else {
+			parsed[fieldPath] = parsedInput{value: v, nodes: nodes}
+		}
+	case map[string]interface{}:
+		for k, item := range v {
+			errorFields = append(errorFields, parseInputs(fmt.Sprintf("%s.%s", fieldPath, k), item, parsed)...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			errorFields = append(errorFields, parseInputs(fmt.Sprintf("%s[%d]", fieldPath, i), item, parsed)...)
+		}
+	}
+	return errorFields
+}
+
+// selectableStages returns the stages a parsed stageSelector can select, where an empty name ends the activation. It
+// returns false if the selected stage depends on the activation.
+func selectableStages(nodes []utils.Node) ([]string, bool) {
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return selectableValues(nodes[0])
+}
+
+func selectableValues(node utils.Node) ([]string, bool) {
+	if f, ok := node.(*utils.FunctionNode); ok {
+		if f.Name != "if" || len(f.Args) != 3 {
+			return nil, false
+		}
+		left, ok := selectableValues(f.Args[1])
+		if !ok {
+			return nil, false
+		}
+		right, ok := selectableValues(f.Args[2])
+		if !ok {
+			return nil, false
+		}
+		return append(left, right...), true
+	}
+	return constantValue(node)
+}
+
+// constantValue evaluates an expression that doesn't call any function
+func constantValue(node utils.Node) ([]string, bool) {
+	if !isConstant(node) {
+		return nil, false
+	}
+	val, err := node.Eval(coa_utils.EvaluationContext{})
+	if err != nil {
+		return nil, false
+	}
+	return []string{utils.FormatAsString(val)}, true
+}
+
+func isConstant(node utils.Node) bool {
+	switch n := node.(type) {
+	case *utils.FunctionNode:
+		return false
+	case *utils.UnaryNode:
+		return isConstant(n.Expr)
+	case *utils.BinaryNode:
+		return isConstant(n.Left) && isConstant(n.Right)
+	}
+	return true
+}
+
+// outputReferences returns the stages that $output() calls in the expressions read with a constant stage name
+func outputReferences(nodes []utils.Node) []string {
+	refs := []string{}
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *utils.FunctionNode:
+			if n.Name == "output" && len(n.Args) == 2 {
+				if values, ok := constantValue(n.Args[0]); ok {
+					refs = append(refs, values...)
+				}
+			}
+			refs = append(refs, outputReferences(n.Args)...)
+		case *utils.UnaryNode:
+			refs = append(refs, outputReferences([]utils.Node{n.Expr})...)
+		case *utils.BinaryNode:
+			refs = append(refs, outputReferences([]utils.Node{n.Left, n.Right})...)
+		}
+	}
+	return refs
+}
+
 // Validate NO running activations
 // CampaignActivationsLookupFunc will look up activations with label {"campaign" : c.ObjectMeta.Name}
 func (c *CampaignValidator) ValidateRunningActivation(ctx context.Context, campaign model.CampaignState) *ErrorField {
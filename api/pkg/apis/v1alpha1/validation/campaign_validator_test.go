@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func campaignWithStages(firstStage string, stages ...model.StageSpec) model.CampaignState {
+	campaign := model.CampaignState{
+		Spec: &model.CampaignSpec{
+			FirstStage: firstStage,
+			Stages:     map[string]model.StageSpec{},
+		},
+	}
+	for _, s := range stages {
+		campaign.Spec.Stages[s.Name] = s
+	}
+	return campaign
+}
+
+func TestValidateStageGraph(t *testing.T) {
+	validator := NewCampaignValidator(nil, nil)
+	campaign := campaignWithStages("deploy",
+		model.StageSpec{
+			Name:          "deploy",
+			Provider:      "providers.stage.mock",
+			StageSelector: "${{$if($output(deploy, status), check, '')}}",
+		},
+		model.StageSpec{
+			Name:          "check",
+			Provider:      "providers.stage.counter",
+			StageSelector: "${{$if($lt($output(check, val), 5), check, notify)}}",
+			Inputs: map[string]interface{}{
+				"val":    "${{$output(deploy, status)}}",
+				"values": []interface{}{"${{$output(check, val)}}"},
+			},
+		},
+		model.StageSpec{
+			Name:          "notify",
+			Provider:      "providers.stage.http",
+			StageSelector: "${{$input(next)}}",
+		},
+		model.StageSpec{
+			Name:     "rollback",
+			Provider: "providers.stage.mock",
+			Inputs: map[string]interface{}{
+				"status": "${{$output(notify, status)}}",
+			},
+		},
+	)
+	assert.Empty(t, validator.ValidateStageGraph(campaign))
+}
+
+func TestValidateStageGraphErrors(t *testing.T) {
+	validator := NewCampaignValidator(nil, nil)
+	campaign := campaignWithStages("deploy",
+		model.StageSpec{
+			Name:          "deploy",
+			Provider:      "providers.stage.mock",
+			StageSelector: "${{$if($output(deploy, status), check, missing)}}",
+		},
+		model.StageSpec{
+			Name:     "check",
+			Provider: "providers.stage.unknown",
+			Inputs: map[string]interface{}{
+				"config": map[string]interface{}{
+					"val": "${{$output(cleanup, val)}}",
+				},
+				"missing": "${{$output(nowhere, val)}}",
+			},
+		},
+		model.StageSpec{
+			Name:          "cleanup",
+			Provider:      "providers.stage.mock",
+			StageSelector: "${{$if(}}",
+		},
+	)
+	errorFields := validator.ValidateStageGraph(campaign)
+	messages := map[string]string{}
+	for _, e := range errorFields {
+		messages[e.FieldPath] = e.DetailedMessage
+	}
+	assert.Equal(t, 6, len(errorFields))
+	assert.Equal(t, "provider must be a registered stage provider", messages["spec.stages.check.provider"])
+	assert.Contains(t, messages["spec.stages.cleanup.stageSelector"], "stageSelector is not a valid expression")
+	assert.Equal(t, "stageSelector can select stage 'missing', which is not in the stages list", messages["spec.stages.deploy.stageSelector"])
+	assert.Equal(t, "stage can't be reached from firstStage 'deploy'", messages["spec.stages.cleanup"])
+	assert.Equal(t, "$output() refers to stage 'cleanup', which can't have run before stage 'check'", messages["spec.stages.check.inputs.config.val"])
+	assert.Equal(t, "$output() refers to stage 'nowhere', which is not in the stages list", messages["spec.stages.check.inputs.missing"])
+}
+
+func TestValidateStageGraphSelfOutput(t *testing.T) {
+	validator := NewCampaignValidator(nil, nil)
+	campaign := campaignWithStages("deploy",
+		model.StageSpec{
+			Name:     "deploy",
+			Provider: "providers.stage.mock",
+			Inputs: map[string]interface{}{
+				"previous": "${{$output(deploy, status)}}",
+			},
+		},
+	)
+	errorFields := validator.ValidateStageGraph(campaign)
+	assert.Equal(t, 1, len(errorFields))
+	assert.Equal(t, "spec.stages.deploy.inputs.previous", errorFields[0].FieldPath)
+}
+
+func TestValidateStageGraphExpressions(t *testing.T) {
+	validator := NewCampaignValidator(nil, nil)
+	campaign := campaignWithStages("deploy",
+		model.StageSpec{
+			Name:          "deploy",
+			Provider:      "providers.stage.mock",
+			StageSelector: "${{$if($output(deploy, status), deploy)}}",
+			Inputs: map[string]interface{}{
+				"sites": "${{$join($upper($input(sites)), ',')}}",
+			},
+		},
+	)
+	errorFields := validator.ValidateStageGraph(campaign)
+	messages := map[string]string{}
+	for _, e := range errorFields {
+		messages[e.FieldPath] = e.DetailedMessage
+	}
+	assert.Equal(t, "stageSelector is not a valid expression: Bad Config: $if() expects 3 arguments, found 2 at column 4 in '$if($output(deploy, status), deploy)'", messages["spec.stages.deploy.stageSelector"])
+	assert.Equal(t, "input is not a valid expression: Bad Config: $join() expects argument 1 to be a list, found type string at column 4 in '$join($upper($input(sites)), ',')'", messages["spec.stages.deploy.inputs.sites"])
+}
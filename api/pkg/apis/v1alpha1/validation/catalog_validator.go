@@ -9,6 +9,9 @@ package validation
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
@@ -33,13 +36,17 @@ func NewCatalogValidator(catalogLookupFunc ObjectLookupFunc, catalogContainerLoo
 // 1. Schema is valid
 // 2. Parent catalog exists
 // 3. Catalog name and rootResource is valid. And rootResource is immutable
-// TODO: 4. Update won't form a cycle in the parent-child relationship
+// 4. Expressions in properties are valid
+// 5. Merge mode is known
+// TODO: 6. Update won't form a cycle in the parent-child relationship
 func (c *CatalogValidator) ValidateCreateOrUpdate(ctx context.Context, newRef interface{}, oldRef interface{}) []ErrorField {
 	new := c.ConvertInterfaceToCatalog(newRef)
 	old := c.ConvertInterfaceToCatalog(oldRef)
 
 	errorFields := []ErrorField{}
-	if err := c.ValidateSchema(ctx, new); err != nil {
+	errorFields = append(errorFields, c.ValidateSchema(ctx, new)...)
+	errorFields = append(errorFields, ValidateExpressions("spec.properties", new.Spec.Properties)...)
+	if err := c.ValidateMergeMode(new); err != nil {
 		errorFields = append(errorFields, *err)
 	}
 	if new.Spec.ParentName != "" && (oldRef == nil || new.Spec.ParentName != old.Spec.ParentName) {
@@ -71,6 +78,18 @@ func (c *CatalogValidator) ValidateCreateOrUpdate(ctx context.Context, newRef in
 	return errorFields
 }
 
+// Validate merge mode is one of replace, deep-merge and append-list
+func (c *CatalogValidator) ValidateMergeMode(new model.CatalogState) *ErrorField {
+	if mode, ok := new.Spec.Metadata[model.MergeModeMetadataKey]; ok && !model.IsMergeMode(mode) {
+		return &ErrorField{
+			FieldPath:       "spec.metadata.mergeMode",
+			Value:           mode,
+			DetailedMessage: fmt.Sprintf("merge mode must be one of %s, %s and %s", model.MergeModeReplace, model.MergeModeDeepMerge, model.MergeModeAppendList),
+		}
+	}
+	return nil
+}
+
 // Validate Catalog deletion
 // 1. Catalog has no child catalogs
 func (c *CatalogValidator) ValidateDelete(ctx context.Context, catalog interface{}) []ErrorField {
@@ -84,64 +103,117 @@ func (c *CatalogValidator) ValidateDelete(ctx context.Context, catalog interface
 }
 
 // Validate Schema is valid
-func (c *CatalogValidator) ValidateSchema(ctx context.Context, new model.CatalogState) *ErrorField {
+// The schema catalog holds either the rules of a utils.Schema or a JSON Schema document in its spec property. A JSON
+// Schema can refer to the schemas of other catalogs by name, and each violation is reported at its nested property.
+func (c *CatalogValidator) ValidateSchema(ctx context.Context, new model.CatalogState) []ErrorField {
 	if c.CatalogLookupFunc == nil {
 		return nil
 	}
 	if schemaName, ok := new.Spec.Metadata["schema"]; ok {
 		// 1). Lookup catalog object with schema name
 		schemaName = ConvertReferenceToObjectName(schemaName)
-		lookupRes, err := c.CatalogLookupFunc(ctx, schemaName, new.ObjectMeta.Namespace)
+		spec, found, err := c.lookupSchema(ctx, schemaName, new.ObjectMeta.Namespace)
+		if err != nil {
+			return []ErrorField{{
+				FieldPath:       "spec.metadata.schema",
+				Value:           schemaName,
+				DetailedMessage: err.Error(),
+			}}
+		}
+		if !found {
+			return nil
+		}
+		if utils.IsJSONSchema(spec) {
+			return c.validateJSONSchema(ctx, new, schemaName, spec)
+		}
 
+		// 2). Extract Schema object from the catalog object
+		var schemaObj utils.Schema
+		jData, _ := json.Marshal(spec)
+		err = json.Unmarshal(jData, &schemaObj)
 		if err != nil {
-			return &ErrorField{
+			return []ErrorField{{
 				FieldPath:       "spec.metadata.schema",
 				Value:           schemaName,
-				DetailedMessage: "could not find the required schema",
-			}
+				DetailedMessage: "invalid schema",
+			}}
 		}
-		marshalResult, _ := json.Marshal(lookupRes)
-		var catalog model.CatalogState
-		err = json.Unmarshal(marshalResult, &catalog)
+
+		// 3). Validate the schema on the catalog which is being created/updated
+		result, err := schemaObj.CheckProperties(ctx, new.Spec.Properties, nil)
 		if err != nil {
-			return &ErrorField{
+			return []ErrorField{{
 				FieldPath:       "spec.metadata.schema",
 				Value:           schemaName,
-				DetailedMessage: "schema is not a valid catalog object",
-			}
+				DetailedMessage: "unable to determine the validity of the schema",
+			}}
 		}
-		if spec, ok := catalog.Spec.Properties["spec"]; ok {
-			// 2). Extract Schema object from the catalog object
-			var schemaObj utils.Schema
-			jData, _ := json.Marshal(spec)
-			err := json.Unmarshal(jData, &schemaObj)
-			if err != nil {
-				return &ErrorField{
-					FieldPath:       "spec.metadata.schema",
-					Value:           schemaName,
-					DetailedMessage: "invalid schema",
-				}
-			}
+		if !result.Valid {
+			return []ErrorField{{
+				FieldPath:       "spec.Properties",
+				Value:           "(hidden)",
+				DetailedMessage: "invalid schema result: " + result.ToErrorMessages(),
+			}}
+		}
+	}
+	return nil
+}
 
-			// 3). Validate the schema on the catalog which is being created/updated
-			result, err := schemaObj.CheckProperties(ctx, new.Spec.Properties, nil)
-			if err != nil {
-				return &ErrorField{
-					FieldPath:       "spec.metadata.schema",
-					Value:           schemaName,
-					DetailedMessage: "unable to determine the validity of the schema",
-				}
-			}
-			if !result.Valid {
-				return &ErrorField{
-					FieldPath:       "spec.Properties",
-					Value:           "(hidden)",
-					DetailedMessage: "invalid schema result: " + result.ToErrorMessages(),
-				}
+// validateJSONSchema validates the properties of a catalog against a JSON Schema, with an error field for each
+// property that violates it
+func (c *CatalogValidator) validateJSONSchema(ctx context.Context, new model.CatalogState, schemaName string, spec interface{}) []ErrorField {
+	schema := utils.JSONSchema{
+		Document: spec,
+		Lookup: func(ctx context.Context, name string) (interface{}, error) {
+			document, found, err := c.lookupSchema(ctx, ConvertReferenceToObjectName(name), new.ObjectMeta.Namespace)
+			if err == nil && !found {
+				err = fmt.Errorf("catalog '%s' has no schema", name)
 			}
+			return document, err
+		},
+	}
+	result, err := schema.CheckProperties(ctx, new.Spec.Properties)
+	if err != nil {
+		return []ErrorField{{
+			FieldPath:       "spec.metadata.schema",
+			Value:           schemaName,
+			DetailedMessage: "invalid schema: " + err.Error(),
+		}}
+	}
+	paths := make([]string, 0, len(result.Errors))
+	for path := range result.Errors {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	errorFields := make([]ErrorField, 0, len(paths))
+	for _, path := range paths {
+		fieldPath := "spec.properties"
+		if path != "" {
+			fieldPath = fieldPath + "." + path
 		}
+		errorFields = append(errorFields, ErrorField{
+			FieldPath:       fieldPath,
+			Value:           "(hidden)",
+			DetailedMessage: result.Errors[path].Error,
+		})
 	}
-	return nil
+	return errorFields
+}
+
+// lookupSchema returns the spec property of a schema catalog, and whether the catalog has one
+func (c *CatalogValidator) lookupSchema(ctx context.Context, name string, namespace string) (interface{}, bool, error) {
+	lookupRes, err := c.CatalogLookupFunc(ctx, name, namespace)
+	if err != nil {
+		return nil, false, errors.New("could not find the required schema")
+	}
+	marshalResult, _ := json.Marshal(lookupRes)
+	var catalog model.CatalogState
+	err = json.Unmarshal(marshalResult, &catalog)
+	if err != nil || catalog.Spec == nil {
+		return nil, false, errors.New("schema is not a valid catalog object")
+	}
+	spec, ok := catalog.Spec.Properties["spec"]
+	return spec, ok, nil
 }
 
 // Validate Parent Catalog exists if provided
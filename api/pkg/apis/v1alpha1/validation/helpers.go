@@ -9,6 +9,7 @@ package validation
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/eclipse-symphony/symphony/api/constants"
@@ -261,3 +262,50 @@ func ValidateObjectName(name string, rootResource string) *ErrorField {
 
 	return nil
 }
+
+// Validate the retry policy of instances and targets
+func ValidateRetryPolicy(policy *model.RetryPolicySpec) *ErrorField {
+	if policy == nil {
+		return nil
+	}
+	if err := policy.Validate(); err != nil {
+		return &ErrorField{
+			FieldPath:       "spec.retryPolicy",
+			Value:           *policy,
+			DetailedMessage: err.Error(),
+		}
+	}
+	return nil
+}
+
+// Validate the expressions in the string values of a property tree, such as component properties
+func ValidateExpressions(fieldPath string, value interface{}) []ErrorField {
+	errorFields := []ErrorField{}
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "${{") {
+			return errorFields
+		}
+		if err := api_utils.NewParser(v).Validate(); err != nil {
+			errorFields = append(errorFields, ErrorField{
+				FieldPath:       fieldPath,
+				Value:           v,
+				DetailedMessage: fmt.Sprintf("value is not a valid expression: %s", err.Error()),
+			})
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			errorFields = append(errorFields, ValidateExpressions(fmt.Sprintf("%s.%s", fieldPath, k), v[k])...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			errorFields = append(errorFields, ValidateExpressions(fmt.Sprintf("%s[%d]", fieldPath, i), item)...)
+		}
+	}
+	return errorFields
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateExpressions(t *testing.T) {
+	errorFields := ValidateExpressions("spec.properties", map[string]interface{}{
+		"plain": "$not an expression",
+		"name":  "${{$lower($property(name))}}",
+		"tags": []interface{}{
+			"${{$property(tag)}}",
+			"${{$unknown(tag)}}",
+		},
+		"limits": map[string]interface{}{
+			"cpu": "${{$substring($property(cpu))}}",
+		},
+	})
+	assert.Equal(t, 2, len(errorFields))
+	assert.Equal(t, "spec.properties.limits.cpu", errorFields[0].FieldPath)
+	assert.Contains(t, errorFields[0].DetailedMessage, "$substring() expects 2 to 3 arguments, found 1")
+	assert.Equal(t, "spec.properties.tags[1]", errorFields[1].FieldPath)
+	assert.Contains(t, errorFields[1].DetailedMessage, "unknown function $unknown() at column 4")
+}
+
+func TestValidateSolutionAndCatalogExpressions(t *testing.T) {
+	solutionValidator := NewSolutionValidator(nil, nil, nil)
+	errorFields := solutionValidator.ValidateComponentExpressions(model.SolutionState{
+		Spec: &model.SolutionSpec{
+			Components: []model.ComponentSpec{
+				{Name: "a", Properties: map[string]interface{}{"image": "${{$config(app)}}"}},
+			},
+		},
+	})
+	assert.Equal(t, 1, len(errorFields))
+	assert.Equal(t, "spec.components[0].properties.image", errorFields[0].FieldPath)
+
+	catalogValidator := NewCatalogValidator(nil, nil, nil)
+	errorFields = catalogValidator.ValidateCreateOrUpdate(context.Background(), model.CatalogState{
+		ObjectMeta: model.ObjectMeta{Name: "config-v-v1"},
+		Spec: &model.CatalogSpec{
+			RootResource: "config",
+			Properties:   map[string]interface{}{"color": "${{$if(a, b)}}"},
+		},
+	}, nil)
+	assert.Equal(t, 1, len(errorFields))
+	assert.Equal(t, "spec.properties.color", errorFields[0].FieldPath)
+}
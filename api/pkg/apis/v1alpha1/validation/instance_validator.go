@@ -32,6 +32,8 @@ func NewInstanceValidator(uniqueNameInstanceLookupFunc ObjectLookupFunc, solutio
 // 2. Solution exists
 // 3. Target exists if provided by name rather than selector
 // 4. Target is valid, i.e. either name or selector is provided
+// 5. Retry policy is valid if provided
+// 6. Rollout waves are valid if provided
 func (i *InstanceValidator) ValidateCreateOrUpdate(ctx context.Context, newRef interface{}, oldRef interface{}) []ErrorField {
 	new := i.ConvertInterfaceToInstance(newRef)
 	old := i.ConvertInterfaceToInstance(oldRef)
@@ -62,6 +64,12 @@ func (i *InstanceValidator) ValidateCreateOrUpdate(ctx context.Context, newRef i
 	if err := i.ValidateTargetValid(new); err != nil {
 		errorFields = append(errorFields, *err)
 	}
+	if err := ValidateRetryPolicy(new.Spec.RetryPolicy); err != nil {
+		errorFields = append(errorFields, *err)
+	}
+	if err := i.ValidateRollout(new); err != nil {
+		errorFields = append(errorFields, *err)
+	}
 	return errorFields
 }
 
@@ -131,6 +139,21 @@ func (i *InstanceValidator) ValidateTargetValid(c model.InstanceState) *ErrorFie
 	return nil
 }
 
+// Validate rollout waves have a valid size and the pause between waves is a valid duration
+func (i *InstanceValidator) ValidateRollout(c model.InstanceState) *ErrorField {
+	if c.Spec.Rollout == nil {
+		return nil
+	}
+	if err := c.Spec.Rollout.Validate(); err != nil {
+		return &ErrorField{
+			FieldPath:       "spec.rollout",
+			Value:           *c.Spec.Rollout,
+			DetailedMessage: err.Error(),
+		}
+	}
+	return nil
+}
+
 func (i *InstanceValidator) ConvertInterfaceToInstance(ref interface{}) model.InstanceState {
 	if ref == nil {
 		return model.InstanceState{
@@ -8,6 +8,7 @@ package validation
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
@@ -31,6 +32,8 @@ func NewSolutionValidator(solutionInstanceLookupFunc LinkedObjectLookupFunc, sol
 // Validate Solution creation or update
 // 1. DisplayName is unique
 // 2. name and rootResource is valid. And rootResource is immutable for update
+// 3. Component readiness probes are valid if provided
+// 4. Expressions in component properties are valid
 func (s *SolutionValidator) ValidateCreateOrUpdate(ctx context.Context, newRef interface{}, oldRef interface{}) []ErrorField {
 	new := s.ConvertInterfaceToSolution(newRef)
 	old := s.ConvertInterfaceToSolution(oldRef)
@@ -60,6 +63,8 @@ func (s *SolutionValidator) ValidateCreateOrUpdate(ctx context.Context, newRef i
 			})
 		}
 	}
+	errorFields = append(errorFields, s.ValidateComponentReadiness(new)...)
+	errorFields = append(errorFields, s.ValidateComponentExpressions(new)...)
 
 	return errorFields
 }
@@ -108,6 +113,39 @@ func (s *SolutionValidator) ValidateNoInstanceForSolution(ctx context.Context, s
 	return nil
 }
 
+// Validate readiness probes of the components have exactly one probe and valid durations
+func (s *SolutionValidator) ValidateComponentReadiness(solution model.SolutionState) []ErrorField {
+	errorFields := []ErrorField{}
+	if solution.Spec == nil {
+		return errorFields
+	}
+	for i, c := range solution.Spec.Components {
+		if c.Readiness == nil {
+			continue
+		}
+		if err := c.Readiness.Validate(); err != nil {
+			errorFields = append(errorFields, ErrorField{
+				FieldPath:       fmt.Sprintf("spec.components[%d].readiness", i),
+				Value:           *c.Readiness,
+				DetailedMessage: err.Error(),
+			})
+		}
+	}
+	return errorFields
+}
+
+// Validate expressions in the properties of the components
+func (s *SolutionValidator) ValidateComponentExpressions(solution model.SolutionState) []ErrorField {
+	errorFields := []ErrorField{}
+	if solution.Spec == nil {
+		return errorFields
+	}
+	for i, c := range solution.Spec.Components {
+		errorFields = append(errorFields, ValidateExpressions(fmt.Sprintf("spec.components[%d].properties", i), c.Properties)...)
+	}
+	return errorFields
+}
+
 func (s *SolutionValidator) ConvertInterfaceToSolution(ref interface{}) model.SolutionState {
 	if ref == nil {
 		return model.SolutionState{
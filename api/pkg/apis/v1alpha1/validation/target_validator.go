@@ -28,6 +28,7 @@ func NewTargetValidator(targetInstanceLookupFunc LinkedObjectLookupFunc, uniqueN
 
 // Validate Target creation or update
 // 1. DisplayName is unique
+// 2. Retry policy is valid if provided
 func (t *TargetValidator) ValidateCreateOrUpdate(ctx context.Context, newRef interface{}, oldRef interface{}) []ErrorField {
 	new := t.ConvertInterfaceToTarget(newRef)
 	old := t.ConvertInterfaceToTarget(oldRef)
@@ -45,6 +46,9 @@ func (t *TargetValidator) ValidateCreateOrUpdate(ctx context.Context, newRef int
 			DetailedMessage: "The target is already deployed. Cannot change isDryRun from false to true.",
 		})
 	}
+	if err := ValidateRetryPolicy(new.Spec.RetryPolicy); err != nil {
+		errorFields = append(errorFields, *err)
+	}
 	return errorFields
 }
 
@@ -7,6 +7,7 @@
 package vendors
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
 	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
 	"github.com/valyala/fasthttp"
@@ -76,7 +78,214 @@ func (o *ActivationsVendor) GetEndpoints() []v1alpha2.Endpoint {
 			Handler:    o.onStatus,
 			Parameters: []string{"name?"},
 		},
+		{
+			Methods:    []string{fasthttp.MethodPost},
+			Route:      route + "/cancel",
+			Version:    o.Version,
+			Handler:    o.onCancel,
+			Parameters: []string{"name"},
+		},
+		{
+			Methods:    []string{fasthttp.MethodPost},
+			Route:      route + "/pause",
+			Version:    o.Version,
+			Handler:    o.onPause,
+			Parameters: []string{"name"},
+		},
+		{
+			Methods:    []string{fasthttp.MethodPost},
+			Route:      route + "/resume",
+			Version:    o.Version,
+			Handler:    o.onResume,
+			Parameters: []string{"name"},
+		},
+		{
+			Methods:    []string{fasthttp.MethodGet},
+			Route:      route + "/archive",
+			Version:    o.Version,
+			Handler:    o.onArchive,
+			Parameters: []string{"name?"},
+		},
+	}
+}
+
+func (c *ActivationsVendor) onCancel(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	return c.onControl(request, "onCancel", c.cancelActivation)
+}
+
+// cancelActivation cancels an activation and then the child activations started by its campaign stages
+func (c *ActivationsVendor) cancelActivation(ctx context.Context, name string, namespace string) (model.ActivationState, error) {
+	state, err := c.ActivationsManager.CancelActivation(ctx, name, namespace)
+	if err != nil {
+		return state, err
+	}
+	c.Context.Publish("activation-cancel", v1alpha2.Event{
+		Body: v1alpha2.ActivationData{
+			Campaign:   state.Spec.Campaign,
+			Activation: name,
+			Namespace:  namespace,
+		},
+		Context: ctx,
+	})
+	for _, child := range state.Status.ChildActivations {
+		if _, err := c.cancelActivation(ctx, child, namespace); err != nil {
+			// finished or deleted children are left as they are
+			vLog.InfofCtx(ctx, "V (Activations Vendor): child activation %s of %s is not cancelled - %s", child, name, err.Error())
+		}
+	}
+	return state, nil
+}
+
+func (c *ActivationsVendor) onPause(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	return c.onControl(request, "onPause", c.ActivationsManager.PauseActivation)
+}
+
+func (c *ActivationsVendor) onResume(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	return c.onControl(request, "onResume", func(ctx context.Context, name string, namespace string) (model.ActivationState, error) {
+		state, err := c.ActivationsManager.ResumeActivation(ctx, name, namespace)
+		if err == nil {
+			c.Context.Publish("activation-resume", v1alpha2.Event{
+				Body: v1alpha2.ActivationData{
+					Campaign:   state.Spec.Campaign,
+					Activation: name,
+					Namespace:  namespace,
+				},
+				Context: ctx,
+			})
+		}
+		return state, err
+	})
+}
+
+// onControl handles the operations that cancel, pause or resume an activation, and returns the updated activation
+func (c *ActivationsVendor) onControl(request v1alpha2.COARequest, method string, operation func(context.Context, string, string) (model.ActivationState, error)) v1alpha2.COAResponse {
+	pCtx, span := observability.StartSpan("Activations Vendor", request.Context, &map[string]string{
+		"method": method,
+	})
+	defer span.End()
+
+	vLog.InfofCtx(pCtx, "V (Activations Vendor): %s, method: %s", method, string(request.Method))
+
+	namespace, namespaceSupplied := request.Parameters["namespace"]
+	if !namespaceSupplied {
+		namespace = "default"
+	}
+
+	switch request.Method {
+	case fasthttp.MethodPost:
+		ctx, span := observability.StartSpan(method+"-POST", pCtx, nil)
+		id := request.Parameters["__name"]
+		state, err := operation(ctx, id, namespace)
+		if err != nil {
+			vLog.ErrorfCtx(ctx, "V (Activations Vendor): %s failed - %s", method, err.Error())
+			errorState := v1alpha2.InternalError
+			if coaErr, ok := err.(v1alpha2.COAError); ok {
+				errorState = coaErr.State
+			}
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: errorState,
+				Body:  []byte(err.Error()),
+			})
+		}
+		jData, _ := json.Marshal(state)
+		return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State:       v1alpha2.OK,
+			Body:        jData,
+			ContentType: "application/json",
+		})
 	}
+	vLog.InfofCtx(pCtx, "V (Activations Vendor): %s failed - 405 method not allowed", method)
+	resp := v1alpha2.COAResponse{
+		State:       v1alpha2.MethodNotAllowed,
+		Body:        []byte("{\"result\":\"405 - method not allowed\"}"),
+		ContentType: "application/json",
+	}
+	observ_utils.UpdateSpanStatusFromCOAResponse(span, resp)
+	return resp
+}
+
+// onArchive returns the archive of a deleted activation, or lists the archives that match the "name", "campaign",
+// "outcome", "since" and "until" query parameters. The time range is given as RFC 3339 timestamps.
+func (c *ActivationsVendor) onArchive(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	pCtx, span := observability.StartSpan("Activations Vendor", request.Context, &map[string]string{
+		"method": "onArchive",
+	})
+	defer span.End()
+
+	vLog.InfofCtx(pCtx, "V (Activations Vendor): onArchive, method: %s", string(request.Method))
+
+	namespace, namespaceSupplied := request.Parameters["namespace"]
+	if !namespaceSupplied {
+		namespace = "default"
+	}
+
+	switch request.Method {
+	case fasthttp.MethodGet:
+		ctx, span := observability.StartSpan("onArchive-GET", pCtx, nil)
+		id := request.Parameters["__name"]
+		var err error
+		var state interface{}
+		isArray := false
+		if id == "" {
+			var query model.ActivationArchiveQuery
+			query, err = parseArchiveQuery(request.Parameters)
+			if err == nil {
+				state, err = c.ActivationsManager.ListArchives(ctx, namespace, query)
+			}
+			isArray = true
+		} else {
+			state, err = c.ActivationsManager.GetArchive(ctx, id, namespace)
+		}
+		if err != nil {
+			vLog.InfofCtx(ctx, "V (Activations Vendor): onArchive failed - %s", err.Error())
+			errorState := v1alpha2.InternalError
+			if coaErr, ok := err.(v1alpha2.COAError); ok {
+				errorState = coaErr.State
+			}
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: errorState,
+				Body:  []byte(err.Error()),
+			})
+		}
+		jData, _ := utils.FormatObject(state, isArray, request.Parameters["path"], request.Parameters["doc-type"])
+		resp := observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State:       v1alpha2.OK,
+			Body:        jData,
+			ContentType: "application/json",
+		})
+		if request.Parameters["doc-type"] == "yaml" {
+			resp.ContentType = "text/plain"
+		}
+		return resp
+	}
+	vLog.InfoCtx(pCtx, "V (Activations Vendor): onArchive failed - 405 method not allowed")
+	resp := v1alpha2.COAResponse{
+		State:       v1alpha2.MethodNotAllowed,
+		Body:        []byte("{\"result\":\"405 - method not allowed\"}"),
+		ContentType: "application/json",
+	}
+	observ_utils.UpdateSpanStatusFromCOAResponse(span, resp)
+	return resp
+}
+
+func parseArchiveQuery(parameters map[string]string) (model.ActivationArchiveQuery, error) {
+	query := model.ActivationArchiveQuery{
+		Name:     parameters["name"],
+		Campaign: parameters["campaign"],
+		Outcome:  parameters["outcome"],
+	}
+	var err error
+	if v := parameters["since"]; v != "" {
+		if query.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			return query, v1alpha2.NewCOAError(err, "since must be an RFC 3339 timestamp", v1alpha2.BadRequest)
+		}
+	}
+	if v := parameters["until"]; v != "" {
+		if query.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			return query, v1alpha2.NewCOAError(err, "until must be an RFC 3339 timestamp", v1alpha2.BadRequest)
+		}
+	}
+	return query, nil
 }
 
 func (c *ActivationsVendor) onStatus(request v1alpha2.COARequest) v1alpha2.COAResponse {
@@ -145,12 +354,17 @@ func (c *ActivationsVendor) onActivations(request v1alpha2.COARequest) v1alpha2.
 		id := request.Parameters["__name"]
 		var err error
 		var state interface{}
+		var continuationToken string
 		isArray := false
 		if id == "" {
 			if !namespaceSupplied {
 				namespace = ""
 			}
-			state, err = c.ActivationsManager.ListState(ctx, namespace)
+			var page states.PageOptions
+			page, err = parsePageOptions(request.Parameters)
+			if err == nil {
+				state, continuationToken, err = c.ActivationsManager.ListStatePage(ctx, namespace, page)
+			}
 			isArray = true
 		} else {
 			state, err = c.ActivationsManager.GetState(ctx, id, namespace)
@@ -158,7 +372,7 @@ func (c *ActivationsVendor) onActivations(request v1alpha2.COARequest) v1alpha2.
 		if err != nil {
 			vLog.InfofCtx(ctx, "V (Activations Vendor): onActivations failed - %s", err.Error())
 			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
-				State: v1alpha2.InternalError,
+				State: listErrorState(err),
 				Body:  []byte(err.Error()),
 			})
 		}
@@ -167,6 +381,7 @@ func (c *ActivationsVendor) onActivations(request v1alpha2.COARequest) v1alpha2.
 			State:       v1alpha2.OK,
 			Body:        jData,
 			ContentType: "application/json",
+			Metadata:    listResponseMetadata(continuationToken),
 		})
 		if request.Parameters["doc-type"] == "yaml" {
 			resp.ContentType = "text/plain"
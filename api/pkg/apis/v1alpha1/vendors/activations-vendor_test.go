@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/eclipse-symphony/symphony/api/constants"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/managers/activations"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
@@ -36,7 +37,7 @@ func TestActivationsEndpoints(t *testing.T) {
 	vendor := createActivationsVendor()
 	vendor.Route = "activations"
 	endpoints := vendor.GetEndpoints()
-	assert.Equal(t, 2, len(endpoints))
+	assert.Equal(t, 6, len(endpoints))
 }
 func TestActivationsInfo(t *testing.T) {
 	vendor := createActivationsVendor()
@@ -172,6 +173,127 @@ func TestActivationsOnActivations(t *testing.T) {
 	})
 	assert.Equal(t, v1alpha2.OK, resp.State)
 }
+func TestActivationsOnControl(t *testing.T) {
+	vendor := createActivationsVendor()
+	vendor.Context = &contexts.VendorContext{}
+	pubSubProvider := memory.InMemoryPubSubProvider{}
+	pubSubProvider.Init(memory.InMemoryPubSubConfig{Name: "test"})
+	vendor.Context.Init(&pubSubProvider)
+	sigs := make(chan string)
+	for _, topic := range []string{"activation-cancel", "activation-resume"} {
+		vendor.Context.Subscribe(topic, v1alpha2.EventHandler{
+			Handler: func(topic string, event v1alpha2.Event) error {
+				var activation v1alpha2.ActivationData
+				jData, _ := json.Marshal(event.Body)
+				err := json.Unmarshal(jData, &activation)
+				assert.Nil(t, err)
+				assert.Equal(t, "activation1", activation.Activation)
+				assert.Equal(t, "default", activation.Namespace)
+				sigs <- topic
+				return nil
+			},
+		})
+	}
+	err := vendor.ActivationsManager.UpsertState(context.Background(), "activation1", model.ActivationState{
+		Spec: &model.ActivationSpec{
+			Campaign: "campaign1",
+		},
+	})
+	assert.Nil(t, err)
+	request := v1alpha2.COARequest{
+		Method: fasthttp.MethodPost,
+		Parameters: map[string]string{
+			"__name": "activation1",
+		},
+		Context: context.Background(),
+	}
+
+	resp := vendor.onResume(request)
+	assert.Equal(t, v1alpha2.Conflict, resp.State)
+
+	resp = vendor.onPause(request)
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	var activation model.ActivationState
+	err = json.Unmarshal(resp.Body, &activation)
+	assert.Nil(t, err)
+	assert.True(t, activation.Status.PauseRequested)
+
+	resp = vendor.onResume(request)
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	assert.Equal(t, "activation-resume", <-sigs)
+
+	resp = vendor.onCancel(request)
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	assert.Equal(t, "activation-cancel", <-sigs)
+	err = json.Unmarshal(resp.Body, &activation)
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.Cancelled, activation.Status.Status)
+
+	resp = vendor.onCancel(request)
+	assert.Equal(t, v1alpha2.Conflict, resp.State)
+
+	resp = vendor.onPause(v1alpha2.COARequest{
+		Method: fasthttp.MethodPost,
+		Parameters: map[string]string{
+			"__name": "activation2",
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.NotFound, resp.State)
+}
+func TestActivationsCancelCascades(t *testing.T) {
+	vendor := createActivationsVendor()
+	vendor.Context = &contexts.VendorContext{}
+	pubSubProvider := memory.InMemoryPubSubProvider{}
+	pubSubProvider.Init(memory.InMemoryPubSubConfig{Name: "test"})
+	vendor.Context.Init(&pubSubProvider)
+	sigs := make(chan string, 3)
+	vendor.Context.Subscribe("activation-cancel", v1alpha2.EventHandler{
+		Handler: func(topic string, event v1alpha2.Event) error {
+			var activation v1alpha2.ActivationData
+			jData, _ := json.Marshal(event.Body)
+			json.Unmarshal(jData, &activation)
+			sigs <- activation.Activation
+			return nil
+		},
+	})
+	err := vendor.ActivationsManager.UpsertState(context.Background(), "parent", model.ActivationState{Spec: &model.ActivationSpec{}})
+	assert.Nil(t, err)
+	for _, child := range []string{"child1", "child2"} {
+		err = vendor.ActivationsManager.UpsertState(context.Background(), child, model.ActivationState{
+			ObjectMeta: model.ObjectMeta{
+				Annotations: map[string]string{
+					constants.ParentActivation: "parent",
+				},
+			},
+			Spec: &model.ActivationSpec{},
+		})
+		assert.Nil(t, err)
+	}
+	err = vendor.ActivationsManager.ReportStatus(context.Background(), "child2", "default", model.ActivationStatus{
+		Status:        v1alpha2.Done,
+		StatusMessage: v1alpha2.Done.String(),
+	})
+	assert.Nil(t, err)
+
+	resp := vendor.onCancel(v1alpha2.COARequest{
+		Method: fasthttp.MethodPost,
+		Parameters: map[string]string{
+			"__name": "parent",
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	cancelled := []string{<-sigs, <-sigs}
+	assert.ElementsMatch(t, []string{"parent", "child1"}, cancelled)
+
+	state, err := vendor.ActivationsManager.GetState(context.Background(), "child1", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.Cancelled, state.Status.Status)
+	state, err = vendor.ActivationsManager.GetState(context.Background(), "child2", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.Done, state.Status.Status)
+}
 func TestActivationsWrongMethod(t *testing.T) {
 	vendor := createActivationsVendor()
 	resp := vendor.onActivations(v1alpha2.COARequest{
@@ -191,4 +313,81 @@ func TestActivationsWrongMethod(t *testing.T) {
 		Context: context.Background(),
 	})
 	assert.Equal(t, v1alpha2.MethodNotAllowed, resp.State)
+
+	resp = vendor.onCancel(v1alpha2.COARequest{
+		Method: fasthttp.MethodGet,
+		Parameters: map[string]string{
+			"__name": "activation1",
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.MethodNotAllowed, resp.State)
+}
+
+func TestActivationsOnArchive(t *testing.T) {
+	vendor := createActivationsVendor()
+	archiveProvider := &memorystate.MemoryStateProvider{}
+	archiveProvider.Init(memorystate.MemoryStateProviderConfig{})
+	vendor.ActivationsManager.ArchiveStateProvider = archiveProvider
+	err := vendor.ActivationsManager.ArchiveActivation(context.Background(), model.ActivationState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "activation1",
+			Namespace: "default",
+		},
+		Spec: &model.ActivationSpec{
+			Campaign: "campaign1",
+		},
+		Status: &model.ActivationStatus{
+			Status:        v1alpha2.Done,
+			StatusMessage: v1alpha2.Done.String(),
+			UpdateTime:    "2024-01-01T00:00:00Z",
+		},
+	})
+	assert.Nil(t, err)
+
+	resp := vendor.onArchive(v1alpha2.COARequest{
+		Method: fasthttp.MethodGet,
+		Parameters: map[string]string{
+			"__name": "activation1",
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	var archive model.ActivationArchive
+	err = json.Unmarshal(resp.Body, &archive)
+	assert.Nil(t, err)
+	assert.Equal(t, "campaign1", archive.Campaign)
+
+	resp = vendor.onArchive(v1alpha2.COARequest{
+		Method: fasthttp.MethodGet,
+		Parameters: map[string]string{
+			"campaign": "campaign1",
+			"since":    "2023-12-31T00:00:00Z",
+			"until":    "2024-01-02T00:00:00Z",
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	var archives []model.ActivationArchive
+	err = json.Unmarshal(resp.Body, &archives)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(archives))
+
+	resp = vendor.onArchive(v1alpha2.COARequest{
+		Method: fasthttp.MethodGet,
+		Parameters: map[string]string{
+			"since": "yesterday",
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.BadRequest, resp.State)
+
+	resp = vendor.onArchive(v1alpha2.COARequest{
+		Method: fasthttp.MethodGet,
+		Parameters: map[string]string{
+			"__name": "activation2",
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.NotFound, resp.State)
 }
@@ -10,6 +10,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/managers/catalogs"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
@@ -21,6 +22,7 @@ import (
 	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
 	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
 	"github.com/valyala/fasthttp"
@@ -123,7 +125,127 @@ func (e *CatalogsVendor) GetEndpoints() []v1alpha2.Endpoint {
 			Handler:    e.onStatus,
 			Parameters: []string{"name"},
 		},
+		{
+			Methods:    []string{fasthttp.MethodGet, fasthttp.MethodPost},
+			Route:      route + "/revisions",
+			Version:    e.Version,
+			Handler:    e.onRevisions,
+			Parameters: []string{"name", "revision?"},
+		},
+	}
+}
+
+// onRevisions lists the revisions of a catalog, returns one of its revisions, or the diff between the "from" and "to"
+// revisions given as query parameters. Posting to a revision restores it.
+func (e *CatalogsVendor) onRevisions(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	pCtx, span := observability.StartSpan("Catalogs Vendor", request.Context, &map[string]string{
+		"method": "onRevisions",
+	})
+	defer span.End()
+
+	lLog.InfofCtx(pCtx, "V (Catalogs Vendor): onRevisions, method: %s", string(request.Method))
+
+	id := request.Parameters["__name"]
+	namespace, namesapceSupplied := request.Parameters["namespace"]
+	if !namesapceSupplied {
+		namespace = "default"
+	}
+	revision, err := parseRevision(request.Parameters["__revision"])
+	if err != nil {
+		return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State: v1alpha2.BadRequest,
+			Body:  []byte(err.Error()),
+		})
+	}
+
+	switch request.Method {
+	case fasthttp.MethodGet:
+		ctx, span := observability.StartSpan("onRevisions-GET", pCtx, nil)
+		var state interface{}
+		isArray := false
+		if revision > 0 {
+			state, err = e.CatalogsManager.GetRevision(ctx, id, namespace, revision)
+		} else if request.Parameters["from"] != "" || request.Parameters["to"] != "" {
+			var from, to int
+			if from, err = parseRevision(request.Parameters["from"]); err == nil {
+				if to, err = parseRevision(request.Parameters["to"]); err == nil {
+					if from == 0 || to == 0 {
+						err = v1alpha2.NewCOAError(nil, "a diff needs both the from and to revisions", v1alpha2.BadRequest)
+					} else {
+						state, err = e.CatalogsManager.DiffRevisions(ctx, id, namespace, from, to)
+					}
+				}
+			}
+		} else {
+			state, err = e.CatalogsManager.ListRevisions(ctx, id, namespace)
+			isArray = true
+		}
+		if err != nil {
+			lLog.InfofCtx(ctx, "V (Catalogs Vendor): onRevisions failed - %s", err.Error())
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: revisionErrorState(err),
+				Body:  []byte(err.Error()),
+			})
+		}
+		jData, _ := utils.FormatObject(state, isArray, request.Parameters["path"], request.Parameters["doc-type"])
+		resp := observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State:       v1alpha2.OK,
+			Body:        jData,
+			ContentType: "application/json",
+		})
+		if request.Parameters["doc-type"] == "yaml" {
+			resp.ContentType = "text/plain"
+		}
+		return resp
+	case fasthttp.MethodPost:
+		ctx, span := observability.StartSpan("onRevisions-POST", pCtx, nil)
+		if revision == 0 {
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: v1alpha2.BadRequest,
+				Body:  []byte("missing revision to restore"),
+			})
+		}
+		restored, err := e.CatalogsManager.RestoreRevision(ctx, id, namespace, revision)
+		if err != nil {
+			lLog.InfofCtx(ctx, "V (Catalogs Vendor): onRevisions failed - %s", err.Error())
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: revisionErrorState(err),
+				Body:  []byte(err.Error()),
+			})
+		}
+		jData, _ := json.Marshal(restored)
+		return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State:       v1alpha2.OK,
+			Body:        jData,
+			ContentType: "application/json",
+		})
+	}
+	resp := v1alpha2.COAResponse{
+		State:       v1alpha2.MethodNotAllowed,
+		Body:        []byte("{\"result\":\"405 - method not allowed\"}"),
+		ContentType: "application/json",
 	}
+	observ_utils.UpdateSpanStatusFromCOAResponse(span, resp)
+	return resp
+}
+
+// parseRevision parses a revision number, an empty revision is 0
+func parseRevision(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision < 1 {
+		return 0, v1alpha2.NewCOAError(err, fmt.Sprintf("invalid revision '%s'", value), v1alpha2.BadRequest)
+	}
+	return revision, nil
+}
+
+func revisionErrorState(err error) v1alpha2.State {
+	if coaErr, ok := err.(v1alpha2.COAError); ok {
+		return coaErr.State
+	}
+	return v1alpha2.InternalError
 }
 func (e *CatalogsVendor) onStatus(request v1alpha2.COARequest) v1alpha2.COAResponse {
 	rCtx, span := observability.StartSpan("Catalogs Vendor", request.Context, &map[string]string{
@@ -306,12 +428,17 @@ func (e *CatalogsVendor) onCatalogs(request v1alpha2.COARequest) v1alpha2.COARes
 		ctx, span := observability.StartSpan("onCatalogs-GET", pCtx, nil)
 		var err error
 		var state interface{}
+		var continuationToken string
 		isArray := false
 		if id == "" {
 			if !namesapceSupplied {
 				namespace = ""
 			}
-			state, err = e.CatalogsManager.ListState(ctx, namespace, request.Parameters["filterType"], request.Parameters["filterValue"])
+			var page states.PageOptions
+			page, err = parsePageOptions(request.Parameters)
+			if err == nil {
+				state, continuationToken, err = e.CatalogsManager.ListStatePage(ctx, namespace, request.Parameters["filterType"], request.Parameters["filterValue"], page)
+			}
 			isArray = true
 		} else {
 			state, err = e.CatalogsManager.GetState(ctx, id, namespace)
@@ -319,7 +446,7 @@ func (e *CatalogsVendor) onCatalogs(request v1alpha2.COARequest) v1alpha2.COARes
 		if err != nil {
 			if !utils.IsNotFound(err) {
 				return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
-					State: v1alpha2.InternalError,
+					State: listErrorState(err),
 					Body:  []byte(err.Error()),
 				})
 			} else {
@@ -335,6 +462,7 @@ func (e *CatalogsVendor) onCatalogs(request v1alpha2.COARequest) v1alpha2.COARes
 			State:       v1alpha2.OK,
 			Body:        jData,
 			ContentType: "application/json",
+			Metadata:    listResponseMetadata(continuationToken),
 		})
 		if request.Parameters["doc-type"] == "yaml" {
 			resp.ContentType = "text/plain"
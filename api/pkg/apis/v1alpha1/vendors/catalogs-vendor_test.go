@@ -157,7 +157,7 @@ func TestCatalogGetEndpoints(t *testing.T) {
 	vendor := CatalogVendorInit()
 	endpoints := vendor.GetEndpoints()
 	assert.NotNil(t, endpoints)
-	assert.Equal(t, "catalogs/status", endpoints[len(endpoints)-1].Route)
+	assert.Equal(t, "catalogs/revisions", endpoints[len(endpoints)-1].Route)
 }
 
 func TestCatalogOnCheck(t *testing.T) {
@@ -499,6 +499,96 @@ func TestCatalogOnCatalogsGraphMethodNotAllowed(t *testing.T) {
 	assert.Equal(t, v1alpha2.MethodNotAllowed, response.State)
 }
 
+func TestCatalogOnRevisions(t *testing.T) {
+	vendor := CatalogVendorInit()
+	revisionProvider := &memorystate.MemoryStateProvider{}
+	revisionProvider.Init(memorystate.MemoryStateProviderConfig{})
+	vendor.CatalogsManager.RevisionStateProvider = revisionProvider
+
+	ctx := context.WithValue(context.Background(), v1alpha2.COAIdentityContextKey, v1alpha2.Identity{User: "alice"})
+	var catalog model.CatalogState
+	jData, _ := json.Marshal(catalogState)
+	json.Unmarshal(jData, &catalog)
+	err := vendor.CatalogsManager.UpsertState(ctx, catalog.ObjectMeta.Name, catalog)
+	assert.Nil(t, err)
+	catalog.Spec.Properties["property1"] = "value3"
+	err = vendor.CatalogsManager.UpsertState(ctx, catalog.ObjectMeta.Name, catalog)
+	assert.Nil(t, err)
+
+	request := v1alpha2.COARequest{
+		Method:  fasthttp.MethodGet,
+		Context: context.Background(),
+		Parameters: map[string]string{
+			"__name": catalog.ObjectMeta.Name,
+		},
+	}
+	response := vendor.onRevisions(request)
+	assert.Equal(t, v1alpha2.OK, response.State)
+	var revisions []model.CatalogRevision
+	err = json.Unmarshal(response.Body, &revisions)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(revisions))
+	assert.Equal(t, 2, revisions[0].Revision)
+	assert.Equal(t, "alice", revisions[0].Author)
+
+	request.Parameters["__revision"] = "1"
+	response = vendor.onRevisions(request)
+	assert.Equal(t, v1alpha2.OK, response.State)
+	var revision model.CatalogRevision
+	err = json.Unmarshal(response.Body, &revision)
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", revision.Spec.Properties["property1"])
+
+	request.Parameters["__revision"] = "3"
+	response = vendor.onRevisions(request)
+	assert.Equal(t, v1alpha2.NotFound, response.State)
+
+	request.Parameters["__revision"] = "first"
+	response = vendor.onRevisions(request)
+	assert.Equal(t, v1alpha2.BadRequest, response.State)
+
+	delete(request.Parameters, "__revision")
+	request.Parameters["from"] = "1"
+	request.Parameters["to"] = "2"
+	response = vendor.onRevisions(request)
+	assert.Equal(t, v1alpha2.OK, response.State)
+	var diff model.CatalogRevisionDiff
+	err = json.Unmarshal(response.Body, &diff)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(diff.Changes))
+	assert.Equal(t, "properties.property1", diff.Changes[0].Path)
+
+	delete(request.Parameters, "to")
+	response = vendor.onRevisions(request)
+	assert.Equal(t, v1alpha2.BadRequest, response.State)
+
+	request = v1alpha2.COARequest{
+		Method:  fasthttp.MethodPost,
+		Context: context.Background(),
+		Parameters: map[string]string{
+			"__name": catalog.ObjectMeta.Name,
+		},
+	}
+	response = vendor.onRevisions(request)
+	assert.Equal(t, v1alpha2.BadRequest, response.State)
+
+	request.Parameters["__revision"] = "1"
+	response = vendor.onRevisions(request)
+	assert.Equal(t, v1alpha2.OK, response.State)
+	err = json.Unmarshal(response.Body, &revision)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, revision.Revision)
+	assert.Equal(t, 1, revision.RestoredFrom)
+
+	restored, err := vendor.CatalogsManager.GetState(context.Background(), catalog.ObjectMeta.Name, "default")
+	assert.Nil(t, err)
+	assert.Equal(t, "value1", restored.Spec.Properties["property1"])
+
+	request.Method = fasthttp.MethodDelete
+	response = vendor.onRevisions(request)
+	assert.Equal(t, v1alpha2.MethodNotAllowed, response.State)
+}
+
 func TestCatalogSubscribe(t *testing.T) {
 	vendor := CatalogVendorInit()
 	vendor.CatalogsManager.CatalogValidator = validation.NewCatalogValidator(vendor.CatalogsManager.CatalogLookup, nil, vendor.CatalogsManager.ChildCatalogLookup)
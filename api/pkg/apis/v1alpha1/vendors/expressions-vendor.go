@@ -0,0 +1,184 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package vendors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	api_utils "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability"
+	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
+	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
+	"github.com/valyala/fasthttp"
+)
+
+var eLog = logger.NewLogger("coa.runtime")
+
+// ExpressionsVendor evaluates expressions for debugging. Config and secret functions aren't resolved, they evaluate to
+// the function call itself.
+type ExpressionsVendor struct {
+	vendors.Vendor
+	EvaluationContext *utils.EvaluationContext
+}
+
+// ExpressionEvaluation is an expression and the context it's evaluated in
+type ExpressionEvaluation struct {
+	Expression string                      `json:"expression"`
+	Context    ExpressionEvaluationContext `json:"context,omitempty"`
+}
+
+// ExpressionEvaluationContext is the part of an EvaluationContext that can be sent with an expression
+type ExpressionEvaluationContext struct {
+	Namespace      string                            `json:"namespace,omitempty"`
+	Component      string                            `json:"component,omitempty"`
+	DeploymentSpec *model.DeploymentSpec             `json:"deploymentSpec,omitempty"`
+	Properties     map[string]string                 `json:"properties,omitempty"`
+	Inputs         map[string]interface{}            `json:"inputs,omitempty"`
+	Outputs        map[string]map[string]interface{} `json:"outputs,omitempty"`
+	Triggers       map[string]interface{}            `json:"triggers,omitempty"`
+	Value          interface{}                       `json:"value,omitempty"`
+}
+
+// ExpressionResult is the value of an evaluated expression, or the error that stopped it
+type ExpressionResult struct {
+	Value interface{} `json:"value,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+func (e *ExpressionsVendor) GetInfo() vendors.VendorInfo {
+	return vendors.VendorInfo{
+		Version:  e.Vendor.Version,
+		Name:     "Expressions",
+		Producer: "Microsoft",
+	}
+}
+
+func (e *ExpressionsVendor) Init(cfg vendors.VendorConfig, factories []managers.IManagerFactroy, providers map[string]map[string]providers.IProvider, pubsubProvider pubsub.IPubSubProvider) error {
+	err := e.Vendor.Init(cfg, factories, providers, pubsubProvider)
+	if err != nil {
+		return err
+	}
+	// Any caller can choose the expression and its namespace, so config and secret functions are never resolved here
+	e.EvaluationContext = &utils.EvaluationContext{
+		ConfigProvider: &stubConfigProvider{},
+		SecretProvider: &stubSecretProvider{},
+	}
+	return nil
+}
+
+// stubConfigProvider evaluates $config() to the call itself, without reading any catalog
+type stubConfigProvider struct{}
+
+func (s *stubConfigProvider) Get(ctx context.Context, object string, field string, overlays []string, localContext interface{}) (interface{}, error) {
+	return fmt.Sprintf("$config(%s, %s)", object, field), nil
+}
+
+func (s *stubConfigProvider) GetObject(ctx context.Context, object string, overlays []string, localContext interface{}) (map[string]interface{}, error) {
+	return nil, v1alpha2.NewCOAError(nil, fmt.Sprintf("config object '%s' is not read by expression evaluation", object), v1alpha2.BadRequest)
+}
+
+// stubSecretProvider evaluates $secret() to the call itself, without reading any secret
+type stubSecretProvider struct{}
+
+func (s *stubSecretProvider) Get(ctx context.Context, name string, field string, localContext interface{}) (string, error) {
+	return fmt.Sprintf("$secret(%s, %s)", name, field), nil
+}
+
+func (e *ExpressionsVendor) GetEndpoints() []v1alpha2.Endpoint {
+	route := "expressions"
+	if e.Route != "" {
+		route = e.Route
+	}
+	return []v1alpha2.Endpoint{
+		{
+			Methods: []string{fasthttp.MethodPost},
+			Route:   route + "/evaluate",
+			Version: e.Version,
+			Handler: e.onEvaluate,
+		},
+	}
+}
+
+func (e *ExpressionsVendor) onEvaluate(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	ctx, span := observability.StartSpan("Expressions Vendor", request.Context, &map[string]string{
+		"method": "onEvaluate",
+	})
+	defer span.End()
+	eLog.InfofCtx(ctx, "V (Expressions): onEvaluate method: %s", request.Method)
+
+	switch request.Method {
+	case fasthttp.MethodPost:
+		ctx, span := observability.StartSpan("onEvaluate-POST", ctx, nil)
+		var evaluation ExpressionEvaluation
+		err := json.Unmarshal(request.Body, &evaluation)
+		if err != nil {
+			eLog.ErrorfCtx(ctx, "V (Expressions): onEvaluate failed to parse request body, error: %v", err)
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: v1alpha2.BadRequest,
+				Body:  []byte(err.Error()),
+			})
+		}
+
+		evaluationContext := e.EvaluationContext.Clone()
+		if evaluationContext == nil {
+			evaluationContext = &utils.EvaluationContext{}
+		}
+		evaluationContext.Context = ctx
+		evaluationContext.Namespace = evaluation.Context.Namespace
+		if evaluationContext.Namespace == "" {
+			evaluationContext.Namespace = request.Parameters["namespace"]
+		}
+		if evaluationContext.Namespace == "" {
+			evaluationContext.Namespace = "default"
+		}
+		evaluationContext.Component = evaluation.Context.Component
+		if evaluation.Context.DeploymentSpec != nil {
+			evaluationContext.DeploymentSpec = *evaluation.Context.DeploymentSpec
+		}
+		evaluationContext.Properties = evaluation.Context.Properties
+		evaluationContext.Inputs = evaluation.Context.Inputs
+		evaluationContext.Outputs = evaluation.Context.Outputs
+		evaluationContext.Triggers = evaluation.Context.Triggers
+		evaluationContext.Value = evaluation.Context.Value
+
+		parser := api_utils.NewParser(evaluation.Expression)
+		var result ExpressionResult
+		state := v1alpha2.OK
+		if err = parser.Validate(); err == nil {
+			result.Value, err = parser.Eval(*evaluationContext)
+		}
+		if err != nil {
+			eLog.InfofCtx(ctx, "V (Expressions): onEvaluate failed to evaluate expression, error: %v", err)
+			result = ExpressionResult{Error: err.Error()}
+			state = v1alpha2.BadRequest
+		}
+		jData, _ := json.Marshal(result)
+		return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State:       state,
+			Body:        jData,
+			ContentType: "application/json",
+		})
+	}
+
+	eLog.ErrorCtx(ctx, "V (Expressions): onEvaluate returned MethodNotAllowed")
+	resp := v1alpha2.COAResponse{
+		State:       v1alpha2.MethodNotAllowed,
+		Body:        []byte("{\"result\":\"405 - method not allowed\"}"),
+		ContentType: "application/json",
+	}
+	observ_utils.UpdateSpanStatusFromCOAResponse(span, resp)
+	return resp
+}
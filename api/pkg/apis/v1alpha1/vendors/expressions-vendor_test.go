@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package vendors
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	sym_mgr "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/managers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
+	memory "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/config/memoryconfig"
+	memorypubsub "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub/memory"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func createExpressionsVendor() ExpressionsVendor {
+	provider := memory.MemoryConfigProvider{}
+	provider.Init(memory.MemoryConfigProviderConfig{})
+	provider.Set(ctx, "app-config", "color", "blue")
+	pubSubProvider := memorypubsub.InMemoryPubSubProvider{}
+	pubSubProvider.Init(memorypubsub.InMemoryPubSubConfig{Name: "test"})
+	vendor := ExpressionsVendor{}
+	vendor.Init(vendors.VendorConfig{
+		Route: "expressions",
+		Managers: []managers.ManagerConfig{
+			{
+				Name:       "config-manager",
+				Type:       "managers.symphony.configs",
+				Properties: map[string]string{},
+			},
+		},
+	}, []managers.IManagerFactroy{
+		&sym_mgr.SymphonyManagerFactory{},
+	}, map[string]map[string]providers.IProvider{
+		"config-manager": {
+			"memory": &provider,
+		},
+	}, &pubSubProvider)
+	return vendor
+}
+
+func evaluateExpression(t *testing.T, vendor ExpressionsVendor, evaluation ExpressionEvaluation) (v1alpha2.State, ExpressionResult) {
+	data, _ := json.Marshal(evaluation)
+	res := vendor.onEvaluate(v1alpha2.COARequest{
+		Method:  fasthttp.MethodPost,
+		Body:    data,
+		Context: context.Background(),
+	})
+	var result ExpressionResult
+	assert.Nil(t, json.Unmarshal(res.Body, &result))
+	return res.State, result
+}
+
+func TestExpressionsEndpoints(t *testing.T) {
+	vendor := createExpressionsVendor()
+	endpoints := vendor.GetEndpoints()
+	assert.Equal(t, 1, len(endpoints))
+	assert.Equal(t, "expressions/evaluate", endpoints[0].Route)
+}
+
+func TestExpressionsEvaluate(t *testing.T) {
+	vendor := createExpressionsVendor()
+	state, result := evaluateExpression(t, vendor, ExpressionEvaluation{
+		Expression: "${{$join($map($split($input(sites), ','), $upper($val())), '-')}}",
+		Context: ExpressionEvaluationContext{
+			Inputs: map[string]interface{}{
+				"sites": "east,west",
+			},
+		},
+	})
+	assert.Equal(t, v1alpha2.OK, state)
+	assert.Equal(t, "EAST-WEST", result.Value)
+
+	state, result = evaluateExpression(t, vendor, ExpressionEvaluation{
+		Expression: "${{$if($gt($output(check, count), 2), $config(app-config, color), none)}}",
+		Context: ExpressionEvaluationContext{
+			Outputs: map[string]map[string]interface{}{
+				"check": {"count": 3},
+			},
+		},
+	})
+	assert.Equal(t, v1alpha2.OK, state)
+	assert.Equal(t, "$config(app-config, color)", result.Value)
+
+	state, result = evaluateExpression(t, vendor, ExpressionEvaluation{
+		Expression: "${{$secret(db, password)}}",
+		Context: ExpressionEvaluationContext{
+			Namespace: "kube-system",
+		},
+	})
+	assert.Equal(t, v1alpha2.OK, state)
+	assert.Equal(t, "$secret(db, password)", result.Value)
+}
+
+func TestExpressionsEvaluateErrors(t *testing.T) {
+	vendor := createExpressionsVendor()
+	state, result := evaluateExpression(t, vendor, ExpressionEvaluation{
+		Expression: "${{$if($nope(a), b, c)}}",
+	})
+	assert.Equal(t, v1alpha2.BadRequest, state)
+	assert.Equal(t, "Bad Config: unknown function $nope() at column 8 in '$nope(a)'", result.Error)
+
+	state, result = evaluateExpression(t, vendor, ExpressionEvaluation{
+		Expression: "${{$upper($input(sites))}}",
+		Context: ExpressionEvaluationContext{
+			Inputs: map[string]interface{}{
+				"sites": []interface{}{"east"},
+			},
+		},
+	})
+	assert.Equal(t, v1alpha2.BadRequest, state)
+	assert.Equal(t, "Bad Config: $upper() expects argument 1 to be a string, found '[east]' at column 4 in '$upper($input(sites))'", result.Error)
+}
+
+func TestExpressionsNotAllowed(t *testing.T) {
+	vendor := createExpressionsVendor()
+	res := vendor.onEvaluate(v1alpha2.COARequest{
+		Method:  fasthttp.MethodGet,
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.MethodNotAllowed, res.State)
+}
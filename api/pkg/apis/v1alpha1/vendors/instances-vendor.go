@@ -20,6 +20,7 @@ import (
 	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
 	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
 	"github.com/valyala/fasthttp"
@@ -91,13 +92,18 @@ func (c *InstancesVendor) onInstances(request v1alpha2.COARequest) v1alpha2.COAR
 		ctx, span := observability.StartSpan("onInstances-GET", pCtx, nil)
 		var err error
 		var state interface{}
+		var continuationToken string
 		isArray := false
 		if id == "" {
 			// Change partition back to empty to indicate ListSpec need to query all namespaces
 			if !exist {
 				namespace = ""
 			}
-			state, err = c.InstancesManager.ListState(ctx, namespace)
+			var page states.PageOptions
+			page, err = parsePageOptions(request.Parameters)
+			if err == nil {
+				state, continuationToken, err = c.InstancesManager.ListStatePage(ctx, namespace, page)
+			}
 			isArray = true
 		} else {
 			state, err = c.InstancesManager.GetState(ctx, id, namespace)
@@ -105,7 +111,7 @@ func (c *InstancesVendor) onInstances(request v1alpha2.COARequest) v1alpha2.COAR
 		if err != nil {
 			iLog.ErrorfCtx(ctx, "V (Instances): onInstances failed - %s", err.Error())
 			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
-				State: v1alpha2.InternalError,
+				State: listErrorState(err),
 				Body:  []byte(err.Error()),
 			})
 		}
@@ -114,6 +120,7 @@ func (c *InstancesVendor) onInstances(request v1alpha2.COARequest) v1alpha2.COAR
 			State:       v1alpha2.OK,
 			Body:        jData,
 			ContentType: "application/json",
+			Metadata:    listResponseMetadata(continuationToken),
 		})
 		if request.Parameters["doc-type"] == "yaml" {
 			resp.ContentType = "text/plain"
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/managers/jobs"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
@@ -116,6 +117,13 @@ func (o *JobVendor) GetEndpoints() []v1alpha2.Endpoint {
 			Version: o.Version,
 			Handler: o.onHello,
 		},
+		{
+			Methods:    []string{fasthttp.MethodGet, fasthttp.MethodPost, fasthttp.MethodDelete},
+			Route:      route + "/schedules",
+			Version:    o.Version,
+			Handler:    o.onSchedules,
+			Parameters: []string{"name?"},
+		},
 	}
 }
 
@@ -157,3 +165,109 @@ func (c *JobVendor) onHello(request v1alpha2.COARequest) v1alpha2.COAResponse {
 
 	return resp
 }
+
+func (c *JobVendor) onSchedules(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	pCtx, span := observability.StartSpan("Job Vendor", request.Context, &map[string]string{
+		"method": "onSchedules",
+	})
+	defer span.End()
+
+	jLog.InfofCtx(pCtx, "V (Job): onSchedules, method: %s", string(request.Method))
+
+	namespace, namespaceSupplied := request.Parameters["namespace"]
+	if !namespaceSupplied {
+		namespace = "default"
+	}
+
+	switch request.Method {
+	case fasthttp.MethodGet:
+		ctx, span := observability.StartSpan("onSchedules-GET", pCtx, nil)
+		id := request.Parameters["__name"]
+		var err error
+		var state interface{}
+		isArray := false
+		if id == "" {
+			if !namespaceSupplied {
+				namespace = ""
+			}
+			state, err = c.JobsManager.ListActivationSchedules(ctx, namespace)
+			isArray = true
+		} else {
+			state, err = c.JobsManager.GetActivationSchedule(ctx, id, namespace)
+		}
+		if err != nil {
+			jLog.ErrorfCtx(ctx, "V (Job): onSchedules failed - %s", err.Error())
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: scheduleErrorState(err),
+				Body:  []byte(err.Error()),
+			})
+		}
+		jData, _ := utils.FormatObject(state, isArray, request.Parameters["path"], request.Parameters["doc-type"])
+		resp := observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State:       v1alpha2.OK,
+			Body:        jData,
+			ContentType: "application/json",
+		})
+		if request.Parameters["doc-type"] == "yaml" {
+			resp.ContentType = "text/plain"
+		}
+		return resp
+	case fasthttp.MethodPost:
+		ctx, span := observability.StartSpan("onSchedules-POST", pCtx, nil)
+		id := request.Parameters["__name"]
+
+		var schedule model.ActivationScheduleState
+		err := json.Unmarshal(request.Body, &schedule)
+		if err != nil {
+			jLog.ErrorfCtx(ctx, "V (Job): onSchedules failed - %s", err.Error())
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: v1alpha2.BadRequest,
+				Body:  []byte(err.Error()),
+			})
+		}
+		if schedule.ObjectMeta.Namespace == "" {
+			schedule.ObjectMeta.Namespace = namespace
+		}
+		err = c.JobsManager.UpsertActivationSchedule(ctx, id, schedule)
+		if err != nil {
+			jLog.ErrorfCtx(ctx, "V (Job): onSchedules failed - %s", err.Error())
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: scheduleErrorState(err),
+				Body:  []byte(err.Error()),
+			})
+		}
+		return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State: v1alpha2.OK,
+		})
+	case fasthttp.MethodDelete:
+		ctx, span := observability.StartSpan("onSchedules-DELETE", pCtx, nil)
+		id := request.Parameters["__name"]
+		err := c.JobsManager.DeleteActivationSchedule(ctx, id, namespace)
+		if err != nil {
+			jLog.ErrorfCtx(ctx, "V (Job): onSchedules failed - %s", err.Error())
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: scheduleErrorState(err),
+				Body:  []byte(err.Error()),
+			})
+		}
+		return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State: v1alpha2.OK,
+		})
+	}
+	jLog.ErrorCtx(pCtx, "V (Job): onSchedules failed - 405 method not allowed")
+	resp := v1alpha2.COAResponse{
+		State:       v1alpha2.MethodNotAllowed,
+		Body:        []byte("{\"result\":\"405 - method not allowed\"}"),
+		ContentType: "application/json",
+	}
+	observ_utils.UpdateSpanStatusFromCOAResponse(span, resp)
+	return resp
+}
+
+// scheduleErrorState keeps the state of a not found or a bad request error, other errors are internal errors
+func scheduleErrorState(err error) v1alpha2.State {
+	if coaErr, ok := err.(v1alpha2.COAError); ok && (coaErr.State == v1alpha2.BadRequest || coaErr.State == v1alpha2.NotFound) {
+		return coaErr.State
+	}
+	return v1alpha2.InternalError
+}
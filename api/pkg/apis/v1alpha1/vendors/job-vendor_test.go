@@ -14,6 +14,7 @@ import (
 
 	sym_mgr "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/managers"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/managers/jobs"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/contexts"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
@@ -31,6 +32,7 @@ func createJobVendor() JobVendor {
 	manager := jobs.JobsManager{
 		VolatileStateProvider:   stateProvider,
 		PersistentStateProvider: stateProvider,
+		ScheduleStateProvider:   stateProvider,
 	}
 	vendor := JobVendor{
 		JobsManager: &manager,
@@ -41,7 +43,7 @@ func TestJobsEndpoints(t *testing.T) {
 	vendor := createJobVendor()
 	vendor.Route = "instances"
 	endpoints := vendor.GetEndpoints()
-	assert.Equal(t, 1, len(endpoints))
+	assert.Equal(t, 2, len(endpoints))
 }
 func TestJobsInfo(t *testing.T) {
 	vendor := createJobVendor()
@@ -130,3 +132,66 @@ func TestJobWrongMethod(t *testing.T) {
 	})
 	assert.Equal(t, v1alpha2.MethodNotAllowed, resp.State)
 }
+func TestJobsonSchedules(t *testing.T) {
+	vendor := createJobVendor()
+	schedule := model.ActivationScheduleState{
+		Spec: &model.ActivationScheduleSpec{
+			Schedule:          "0 * * * *",
+			Campaign:          "drift-detection:v1",
+			ConcurrencyPolicy: model.ConcurrencyForbid,
+		},
+	}
+	data, _ := json.Marshal(schedule)
+	resp := vendor.onSchedules(v1alpha2.COARequest{
+		Method:     fasthttp.MethodPost,
+		Body:       data,
+		Parameters: map[string]string{"__name": "drift"},
+		Context:    context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+
+	resp = vendor.onSchedules(v1alpha2.COARequest{
+		Method:     fasthttp.MethodGet,
+		Parameters: map[string]string{"__name": "drift"},
+		Context:    context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	var saved model.ActivationScheduleState
+	err := json.Unmarshal(resp.Body, &saved)
+	assert.Nil(t, err)
+	assert.Equal(t, "default", saved.ObjectMeta.Namespace)
+	assert.Equal(t, model.ConcurrencyForbid, saved.Spec.ConcurrencyPolicy)
+
+	resp = vendor.onSchedules(v1alpha2.COARequest{
+		Method:  fasthttp.MethodGet,
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	var schedules []model.ActivationScheduleState
+	err = json.Unmarshal(resp.Body, &schedules)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(schedules))
+
+	schedule.Spec.ConcurrencyPolicy = "Queue"
+	data, _ = json.Marshal(schedule)
+	resp = vendor.onSchedules(v1alpha2.COARequest{
+		Method:     fasthttp.MethodPost,
+		Body:       data,
+		Parameters: map[string]string{"__name": "drift"},
+		Context:    context.Background(),
+	})
+	assert.Equal(t, v1alpha2.BadRequest, resp.State)
+
+	resp = vendor.onSchedules(v1alpha2.COARequest{
+		Method:     fasthttp.MethodDelete,
+		Parameters: map[string]string{"__name": "drift"},
+		Context:    context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	resp = vendor.onSchedules(v1alpha2.COARequest{
+		Method:     fasthttp.MethodGet,
+		Parameters: map[string]string{"__name": "drift"},
+		Context:    context.Background(),
+	})
+	assert.Equal(t, v1alpha2.NotFound, resp.State)
+}
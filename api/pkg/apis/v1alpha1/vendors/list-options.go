@@ -0,0 +1,52 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package vendors
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
+)
+
+// continuationTokenKey is the response metadata key of the token of the next page of a list
+const continuationTokenKey = "continuationToken"
+
+// parsePageOptions reads the pageSize, continuationToken and sortBy query parameters of a list request
+func parsePageOptions(parameters map[string]string) (states.PageOptions, error) {
+	ret := states.PageOptions{
+		ContinuationToken: parameters["continuationToken"],
+		SortBy:            parameters["sortBy"],
+	}
+	if v, ok := parameters["pageSize"]; ok && v != "" {
+		size, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || size < 0 {
+			return ret, v1alpha2.NewCOAError(err, fmt.Sprintf("invalid pageSize '%s'", v), v1alpha2.BadRequest)
+		}
+		ret.PageSize = size
+	}
+	return ret, nil
+}
+
+// listResponseMetadata returns the response metadata of a list, which carries the token of the next page if any
+func listResponseMetadata(continuationToken string) map[string]string {
+	if continuationToken == "" {
+		return nil
+	}
+	return map[string]string{
+		continuationTokenKey: continuationToken,
+	}
+}
+
+// listErrorState returns the response state of a failed list, which is a bad request for invalid page options
+func listErrorState(err error) v1alpha2.State {
+	if coaErr, ok := err.(v1alpha2.COAError); ok && coaErr.State == v1alpha2.BadRequest {
+		return v1alpha2.BadRequest
+	}
+	return v1alpha2.InternalError
+}
@@ -7,9 +7,11 @@
 package vendors
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	api_utils "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
@@ -27,6 +29,18 @@ import (
 
 var csLog = logger.NewLogger("coa.runtime")
 
+// configExplainer is a config provider that can resolve a config value with its provenance
+type configExplainer interface {
+	Explain(ctx context.Context, object string, field string, overlays []string, localContext interface{}) (model.ConfigValue, error)
+}
+
+// ConfigExplanation is a resolved config value with the catalogs that supplied it. EvaluationStatus is set when a
+// whole object is explained.
+type ConfigExplanation struct {
+	model.ConfigValue
+	EvaluationStatus string `json:"evaluationStatus,omitempty"`
+}
+
 type SettingsVendor struct {
 	vendors.Vendor
 	EvaluationContext *utils.EvaluationContext
@@ -101,6 +115,9 @@ func (c *SettingsVendor) onConfig(request v1alpha2.COARequest) v1alpha2.COARespo
 		if overrides != "" {
 			parts = strings.Split(overrides, ",")
 		}
+		if request.Parameters["explain"] == "true" {
+			return observ_utils.CloseSpanWithCOAResponse(span, c.explainConfig(ctx, id, field, parts, EvaluationContext))
+		}
 		if field != "" {
 			val, err := c.EvaluationContext.ConfigProvider.Get(ctx, id, field, parts, EvaluationContext)
 			if err != nil {
@@ -151,3 +168,35 @@ func (c *SettingsVendor) onConfig(request v1alpha2.COARequest) v1alpha2.COARespo
 	observ_utils.UpdateSpanStatusFromCOAResponse(span, resp)
 	return resp
 }
+
+func (c *SettingsVendor) explainConfig(ctx context.Context, id string, field string, overlays []string, evaluationContext utils.EvaluationContext) v1alpha2.COAResponse {
+	explainer, ok := c.EvaluationContext.ConfigProvider.(configExplainer)
+	if !ok {
+		log.ErrorCtx(ctx, "V (Settings): onConfig config provider doesn't support explain")
+		return v1alpha2.COAResponse{
+			State: v1alpha2.BadRequest,
+			Body:  []byte("config provider doesn't support explain"),
+		}
+	}
+	val, err := explainer.Explain(ctx, id, field, overlays, evaluationContext)
+	ret := ConfigExplanation{ConfigValue: val}
+	if err != nil {
+		if field != "" || val.Value == nil {
+			log.ErrorfCtx(ctx, "V (Settings): onConfig failed to explain config %s, error: %v", id, err)
+			return v1alpha2.COAResponse{
+				State: v1alpha2.InternalError,
+				Body:  []byte(err.Error()),
+			}
+		}
+		log.WarnfCtx(ctx, "V (Settings): onConfig parsing object %s, warnings: %v", id, err)
+		ret.EvaluationStatus = "Failed"
+	} else if field == "" {
+		ret.EvaluationStatus = "Succeeded"
+	}
+	jData, _ := json.Marshal(ret)
+	return v1alpha2.COAResponse{
+		State:       v1alpha2.OK,
+		Body:        jData,
+		ContentType: "application/json",
+	}
+}
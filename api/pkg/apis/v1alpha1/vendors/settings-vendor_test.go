@@ -8,10 +8,12 @@ package vendors
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	sym_mgr "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/managers"
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/managers/configs"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
@@ -150,3 +152,51 @@ func TestConfigGetField(t *testing.T) {
 	res = vendor.onConfig(*request)
 	assert.Equal(t, v1alpha2.InternalError, res.State)
 }
+
+func TestConfigExplain(t *testing.T) {
+	vendor := createSettingsVendor()
+	manager := vendor.EvaluationContext.ConfigProvider.(*configs.ConfigsManager)
+	provider := manager.ConfigProviders["memory"]
+	provider.Set(ctx, "test", "field", "obj::field")
+	provider.Set(ctx, "test-overlay", "field", "overlay::field")
+
+	request := &v1alpha2.COARequest{
+		Method:  fasthttp.MethodGet,
+		Context: context.Background(),
+		Parameters: map[string]string{
+			"__name":    "test",
+			"field":     "field",
+			"overrides": "test-overlay",
+			"explain":   "true",
+		},
+	}
+	res := vendor.onConfig(*request)
+	assert.Equal(t, v1alpha2.OK, res.State)
+	var explanation ConfigExplanation
+	err := json.Unmarshal(res.Body, &explanation)
+	assert.Nil(t, err)
+	assert.Equal(t, "overlay::field", explanation.Value)
+	assert.Equal(t, []model.ConfigLayer{
+		{Catalog: "test-overlay", MergeMode: "replace", Overlay: true},
+		{Catalog: "test", MergeMode: "replace"},
+	}, explanation.Chain)
+	assert.Equal(t, "", explanation.EvaluationStatus)
+
+	delete(request.Parameters, "field")
+	res = vendor.onConfig(*request)
+	assert.Equal(t, v1alpha2.OK, res.State)
+	err = json.Unmarshal(res.Body, &explanation)
+	assert.Nil(t, err)
+	assert.Equal(t, "test-overlay", explanation.Sources["field"])
+	assert.Equal(t, "Succeeded", explanation.EvaluationStatus)
+
+	request.Parameters["__name"] = "unknown"
+	request.Parameters["overrides"] = ""
+	res = vendor.onConfig(*request)
+	assert.Equal(t, v1alpha2.InternalError, res.State)
+
+	// a config provider without Explain
+	vendor.EvaluationContext.ConfigProvider = struct{ config.IExtConfigProvider }{manager}
+	res = vendor.onConfig(*request)
+	assert.Equal(t, v1alpha2.BadRequest, res.State)
+}
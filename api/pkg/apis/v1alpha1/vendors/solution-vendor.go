@@ -73,6 +73,13 @@ func (o *SolutionVendor) GetEndpoints() []v1alpha2.Endpoint {
 			Parameters: []string{"delete?"},
 			Handler:    o.onReconcile,
 		},
+		{
+			Methods:    []string{fasthttp.MethodPost},
+			Route:      route + "/preview",
+			Version:    o.Version,
+			Parameters: []string{"delete?"},
+			Handler:    o.onPreview,
+		},
 		{
 			Methods: []string{fasthttp.MethodGet, fasthttp.MethodPost, fasthttp.MethodDelete},
 			Route:   route + "/queue",
@@ -275,6 +282,61 @@ func (c *SolutionVendor) onReconcile(request v1alpha2.COARequest) v1alpha2.COARe
 	})
 }
 
+func (c *SolutionVendor) onPreview(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	rContext, span := observability.StartSpan("Solution Vendor", request.Context, &map[string]string{
+		"method": "onPreview",
+	})
+	defer span.End()
+
+	sLog.InfofCtx(rContext, "V (Solution): onPreview, method: %s", request.Method)
+	namespace, exist := request.Parameters["namespace"]
+	if !exist {
+		namespace = constants.DefaultScope
+	}
+	switch request.Method {
+	case fasthttp.MethodPost:
+		ctx, span := observability.StartSpan("onPreview-POST", rContext, nil)
+		defer span.End()
+		var deployment model.DeploymentSpec
+		err := json.Unmarshal(request.Body, &deployment)
+		if err != nil {
+			sLog.ErrorfCtx(ctx, "V (Solution): onPreview failed POST - unmarshal request %s", err.Error())
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State:       v1alpha2.DeserializeError,
+				Body:        []byte(fmt.Sprintf(`{"result":"%s"}`, err.Error())),
+				ContentType: "application/json",
+			})
+		}
+		delete := request.Parameters["delete"]
+		targetName := ""
+		if request.Metadata != nil {
+			if v, ok := request.Metadata["active-target"]; ok {
+				targetName = v
+			}
+		}
+		preview, err := c.SolutionManager.Preview(ctx, deployment, delete == "true", namespace, targetName)
+		if err != nil {
+			sLog.ErrorfCtx(ctx, "V (Solution): onPreview failed POST - preview %s", err.Error())
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: v1alpha2.InternalError,
+				Body:  []byte(err.Error()),
+			})
+		}
+		data, _ := json.Marshal(preview)
+		return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State:       v1alpha2.OK,
+			Body:        data,
+			ContentType: "application/json",
+		})
+	}
+	sLog.ErrorCtx(rContext, "V (Solution): onPreview failed - 405 method not allowed")
+	return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+		State:       v1alpha2.MethodNotAllowed,
+		Body:        []byte("{\"result\":\"405 - method not allowed\"}"),
+		ContentType: "application/json",
+	})
+}
+
 func (c *SolutionVendor) onApplyDeployment(request v1alpha2.COARequest) v1alpha2.COAResponse {
 	rContext, span := observability.StartSpan("Solution Vendor", request.Context, &map[string]string{
 		"method": "onApplyDeployment",
@@ -23,6 +23,7 @@ import (
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub/memory"
 	mocksecret "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/secret/mock"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/memorystate"
+	coa_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
 	coalogcontexts "github.com/eclipse-symphony/symphony/coa/pkg/logger/contexts"
 	"github.com/google/uuid"
@@ -172,7 +173,7 @@ func TestSolutionEndpoints(t *testing.T) {
 	vendor := createSolutionVendor()
 	vendor.Route = "solution"
 	endpoints := vendor.GetEndpoints()
-	assert.Equal(t, 3, len(endpoints))
+	assert.Equal(t, 4, len(endpoints))
 }
 
 func TestSolutionInfo(t *testing.T) {
@@ -465,3 +466,56 @@ func TestSolutionQueueTargetUpdate(t *testing.T) {
 	time.Sleep(time.Second)
 	assert.Equal(t, 1, succeededCount)
 }
+
+func TestSolutionPreview(t *testing.T) {
+	vendor := createSolutionVendor()
+	secretProvider := mocksecret.MockSecretProvider{}
+	secretProvider.Init(mocksecret.MockSecretProviderConfig{})
+	vendor.SolutionManager.VendorContext = &contexts.VendorContext{
+		EvaluationContext: &coa_utils.EvaluationContext{
+			SecretProvider: &secretProvider,
+		},
+	}
+	deployment := createDeployment2Mocks1Target(uuid.New().String())
+	deployment.Solution.Spec.Components[0].Properties = map[string]interface{}{
+		"image":    "redis:6",
+		"password": "${{$secret(db, password)}}",
+	}
+	data, _ := json.Marshal(deployment)
+	resp := vendor.onPreview(v1alpha2.COARequest{
+		Method:  fasthttp.MethodPost,
+		Body:    data,
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	assert.NotContains(t, string(resp.Body), "db>>password")
+	var preview model.PreviewSpec
+	err := json.Unmarshal(resp.Body, &preview)
+	assert.Nil(t, err)
+	assert.False(t, preview.IsRemoval)
+	assert.Equal(t, 1, len(preview.Plan.Steps))
+	assert.Equal(t, "T1", preview.Plan.Steps[0].Target)
+	assert.Equal(t, 2, len(preview.Plan.Steps[0].Components))
+	assert.Equal(t, "redis:6", preview.Plan.Steps[0].Components[0].Component.Properties["image"])
+	assert.Equal(t, model.RedactedValue, preview.Plan.Steps[0].Components[0].Component.Properties["password"])
+	assert.Equal(t, 1, len(preview.Steps))
+	assert.Equal(t, "T1", preview.Steps[0].Target)
+	assert.Equal(t, 2, len(preview.Steps[0].Components))
+	assert.Equal(t, "a", preview.Steps[0].Components[0].Name)
+	assert.Equal(t, model.ComponentUpdate, preview.Steps[0].Components[0].Action)
+	assert.Contains(t, preview.Steps[0].Components[0].Changes, model.PropertyChange{Path: "properties.image", Type: model.PropertyAdded, Desired: "redis:6"})
+	assert.Contains(t, preview.Steps[0].Components[0].Changes, model.PropertyChange{Path: "properties.password", Type: model.PropertyAdded, Desired: model.RedactedValue})
+	assert.Equal(t, "b", preview.Steps[0].Components[1].Name)
+	assert.Equal(t, model.ComponentUpdate, preview.Steps[0].Components[1].Action)
+
+	resp = vendor.onApplyDeployment(v1alpha2.COARequest{
+		Method:  fasthttp.MethodGet,
+		Body:    data,
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	var components []model.ComponentSpec
+	err = json.Unmarshal(resp.Body, &components)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(components))
+}
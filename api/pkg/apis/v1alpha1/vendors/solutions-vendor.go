@@ -19,6 +19,7 @@ import (
 	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
 	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
 	"github.com/valyala/fasthttp"
@@ -88,13 +89,18 @@ func (c *SolutionsVendor) onSolutions(request v1alpha2.COARequest) v1alpha2.COAR
 		ctx, span := observability.StartSpan("onSolutions-GET", pCtx, nil)
 		var err error
 		var state interface{}
+		var continuationToken string
 		isArray := false
 		if id == "" {
 			// Change namespace back to empty to indicate ListSpec need to query all namespaces
 			if !exist {
 				namespace = ""
 			}
-			state, err = c.SolutionsManager.ListState(ctx, namespace)
+			var page states.PageOptions
+			page, err = parsePageOptions(request.Parameters)
+			if err == nil {
+				state, continuationToken, err = c.SolutionsManager.ListStatePage(ctx, namespace, page)
+			}
 			isArray = true
 		} else {
 			state, err = c.SolutionsManager.GetState(ctx, id, namespace)
@@ -102,7 +108,7 @@ func (c *SolutionsVendor) onSolutions(request v1alpha2.COARequest) v1alpha2.COAR
 		if err != nil {
 			uLog.ErrorfCtx(ctx, "V (Solutions): onSolutions failed - %s", err.Error())
 			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
-				State: v1alpha2.InternalError,
+				State: listErrorState(err),
 				Body:  []byte(err.Error()),
 			})
 		}
@@ -111,6 +117,7 @@ func (c *SolutionsVendor) onSolutions(request v1alpha2.COARequest) v1alpha2.COAR
 			State:       v1alpha2.OK,
 			Body:        jData,
 			ContentType: "application/json",
+			Metadata:    listResponseMetadata(continuationToken),
 		})
 		if request.Parameters["doc-type"] == "yaml" {
 			resp.ContentType = "text/plain"
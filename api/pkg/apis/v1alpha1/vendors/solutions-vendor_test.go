@@ -142,3 +142,82 @@ func TestSolutionsOnSolutions(t *testing.T) {
 	})
 	assert.Equal(t, v1alpha2.OK, resp.State)
 }
+
+func TestSolutionsListPaging(t *testing.T) {
+	vendor := createSolutionsVendor()
+	vendor.Context = &contexts.VendorContext{}
+	vendor.Context.SiteInfo = v1alpha2.SiteInfo{
+		SiteId: "fake",
+	}
+	pubSubProvider := memory.InMemoryPubSubProvider{}
+	pubSubProvider.Init(memory.InMemoryPubSubConfig{Name: "test"})
+	vendor.Context.Init(&pubSubProvider)
+	for _, name := range []string{"solutions1-v-v3", "solutions1-v-v1", "solutions1-v-v2"} {
+		solution := model.SolutionState{
+			Spec: &model.SolutionSpec{
+				RootResource: "solutions1",
+			},
+			ObjectMeta: model.ObjectMeta{
+				Name:      name,
+				Namespace: "scope1",
+			},
+		}
+		data, _ := json.Marshal(solution)
+		resp := vendor.onSolutions(v1alpha2.COARequest{
+			Method: fasthttp.MethodPost,
+			Body:   data,
+			Parameters: map[string]string{
+				"__name":    name,
+				"namespace": "scope1",
+			},
+			Context: context.Background(),
+		})
+		assert.Equal(t, v1alpha2.OK, resp.State)
+	}
+
+	resp := vendor.onSolutions(v1alpha2.COARequest{
+		Method: fasthttp.MethodGet,
+		Parameters: map[string]string{
+			"namespace": "scope1",
+			"pageSize":  "2",
+			"sortBy":    "-metadata.name",
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	var solutionsList []model.SolutionState
+	err := json.Unmarshal(resp.Body, &solutionsList)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(solutionsList))
+	assert.Equal(t, "solutions1-v-v3", solutionsList[0].ObjectMeta.Name)
+	assert.Equal(t, "solutions1-v-v2", solutionsList[1].ObjectMeta.Name)
+	token := resp.Metadata[continuationTokenKey]
+	assert.NotEmpty(t, token)
+
+	resp = vendor.onSolutions(v1alpha2.COARequest{
+		Method: fasthttp.MethodGet,
+		Parameters: map[string]string{
+			"namespace":         "scope1",
+			"pageSize":          "2",
+			"sortBy":            "-metadata.name",
+			"continuationToken": token,
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	err = json.Unmarshal(resp.Body, &solutionsList)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(solutionsList))
+	assert.Equal(t, "solutions1-v-v1", solutionsList[0].ObjectMeta.Name)
+	assert.Empty(t, resp.Metadata[continuationTokenKey])
+
+	resp = vendor.onSolutions(v1alpha2.COARequest{
+		Method: fasthttp.MethodGet,
+		Parameters: map[string]string{
+			"namespace": "scope1",
+			"pageSize":  "two",
+		},
+		Context: context.Background(),
+	})
+	assert.Equal(t, v1alpha2.BadRequest, resp.State)
+}
@@ -22,10 +22,13 @@ import (
 	api_utils "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability"
+	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
 	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
+	"github.com/valyala/fasthttp"
 )
 
 var sLog = logger.NewLogger("coa.runtime")
@@ -46,7 +49,131 @@ func (s *StageVendor) GetInfo() vendors.VendorInfo {
 }
 
 func (o *StageVendor) GetEndpoints() []v1alpha2.Endpoint {
-	return []v1alpha2.Endpoint{}
+	route := "stage"
+	if o.Route != "" {
+		route = o.Route
+	}
+	return []v1alpha2.Endpoint{
+		{
+			Methods:    []string{fasthttp.MethodPost},
+			Route:      route + "/approve",
+			Version:    o.Version,
+			Handler:    o.onApprove,
+			Parameters: []string{"name"},
+		},
+		{
+			Methods:    []string{fasthttp.MethodPost},
+			Route:      route + "/reject",
+			Version:    o.Version,
+			Handler:    o.onReject,
+			Parameters: []string{"name"},
+		},
+	}
+}
+
+func (s *StageVendor) onApprove(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	return s.onDecision(request, "onApprove", true)
+}
+
+func (s *StageVendor) onReject(request v1alpha2.COARequest) v1alpha2.COAResponse {
+	return s.onDecision(request, "onReject", false)
+}
+
+// onDecision approves or rejects the approval stage an activation is paused in, on behalf of the caller that is
+// authenticated by the JWT middleware
+func (s *StageVendor) onDecision(request v1alpha2.COARequest, method string, approved bool) v1alpha2.COAResponse {
+	pCtx, span := observability.StartSpan("Stage Vendor", request.Context, &map[string]string{
+		"method": method,
+	})
+	defer span.End()
+
+	sLog.InfofCtx(pCtx, "V (Stage): %s, method: %s", method, string(request.Method))
+
+	namespace, namespaceSupplied := request.Parameters["namespace"]
+	if !namespaceSupplied {
+		namespace = "default"
+	}
+
+	switch request.Method {
+	case fasthttp.MethodPost:
+		ctx, span := observability.StartSpan(method+"-POST", pCtx, nil)
+		id := request.Parameters["__name"]
+		identity, ok := v1alpha2.GetIdentity(request.Context)
+		if !ok || identity.User == "" {
+			sLog.ErrorfCtx(ctx, "V (Stage): %s failed - the caller is not authenticated", method)
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: v1alpha2.Unauthorized,
+				Body:  []byte("approvals require an authenticated user"),
+			})
+		}
+		var decision model.ApprovalDecision
+		if len(request.Body) > 0 {
+			if err := json.Unmarshal(request.Body, &decision); err != nil {
+				sLog.ErrorfCtx(ctx, "V (Stage): %s failed - %s", method, err.Error())
+				return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+					State: v1alpha2.BadRequest,
+					Body:  []byte(err.Error()),
+				})
+			}
+		}
+		status, err := s.decideApproval(ctx, id, namespace, identity, approved, decision)
+		if err != nil {
+			sLog.ErrorfCtx(ctx, "V (Stage): %s failed - %s", method, err.Error())
+			errorState := v1alpha2.InternalError
+			if coaErr, ok := err.(v1alpha2.COAError); ok {
+				errorState = coaErr.State
+			}
+			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+				State: errorState,
+				Body:  []byte(err.Error()),
+			})
+		}
+		jData, _ := json.Marshal(status)
+		return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
+			State:       v1alpha2.OK,
+			Body:        jData,
+			ContentType: "application/json",
+		})
+	}
+	sLog.InfofCtx(pCtx, "V (Stage): %s failed - 405 method not allowed", method)
+	resp := v1alpha2.COAResponse{
+		State:       v1alpha2.MethodNotAllowed,
+		Body:        []byte("{\"result\":\"405 - method not allowed\"}"),
+		ContentType: "application/json",
+	}
+	observ_utils.UpdateSpanStatusFromCOAResponse(span, resp)
+	return resp
+}
+
+// decideApproval records the decision on the approval stage of an activation and triggers the next stage when the
+// stage is approved
+func (s *StageVendor) decideApproval(ctx context.Context, name string, namespace string, identity v1alpha2.Identity, approved bool, decision model.ApprovalDecision) (model.StageStatus, error) {
+	activation, err := s.ActivationsManager.GetState(ctx, name, namespace)
+	if err != nil {
+		return model.StageStatus{}, err
+	}
+	if activation.Spec == nil {
+		return model.StageStatus{}, v1alpha2.NewCOAError(nil, fmt.Sprintf("activation %s has no spec", name), v1alpha2.BadRequest)
+	}
+	campaign, err := s.CampaignsManager.GetState(ctx, api_utils.ConvertReferenceToObjectName(activation.Spec.Campaign), namespace)
+	if err != nil {
+		return model.StageStatus{}, err
+	}
+	status, next, err := s.StageManager.DecideApproval(ctx, *campaign.Spec, activation, identity, approved, decision)
+	if err != nil {
+		return model.StageStatus{}, err
+	}
+	err = s.ActivationsManager.ReportStageStatus(ctx, name, namespace, status)
+	if err != nil {
+		return model.StageStatus{}, err
+	}
+	if next != nil {
+		s.Vendor.Context.Publish("trigger", v1alpha2.Event{
+			Body:    *next,
+			Context: ctx,
+		})
+	}
+	return status, nil
 }
 
 func (s *StageVendor) Init(config vendors.VendorConfig, factories []managers.IManagerFactroy, providers map[string]map[string]providers.IProvider, pubsubProvider pubsub.IPubSubProvider) error {
@@ -154,11 +281,18 @@ func (s *StageVendor) Init(config vendors.VendorConfig, factories []managers.IMa
 				triggerData.Activation, triggerData.Stage, triggerData.Namespace)
 
 			status.Outputs["__namespace"] = triggerData.Namespace
-			_, err = s.ActivationsManager.GetState(ctx, triggerData.Activation, triggerData.Namespace)
+			activationState, err := s.ActivationsManager.GetState(ctx, triggerData.Activation, triggerData.Namespace)
 			if err != nil {
 				sLog.ErrorfCtx(ctx, "V (Stage): unable to find activation: %+v", err)
 				return nil
 			}
+			if activationState.Status.Status == v1alpha2.Cancelled {
+				sLog.InfofCtx(ctx, "V (Stage): activation %s is cancelled, skipping stage %s", triggerData.Activation, triggerData.Stage)
+				return nil
+			}
+			if activationState.Status.PauseRequested && !triggerData.NeedsReport {
+				return s.parkTrigger(ctx, triggerData)
+			}
 			campaignName := api_utils.ConvertReferenceToObjectName(triggerData.Campaign)
 			campaign, err := s.CampaignsManager.GetState(ctx, campaignName, triggerData.Namespace)
 			if err != nil {
@@ -212,6 +346,47 @@ func (s *StageVendor) Init(config vendors.VendorConfig, factories []managers.IMa
 			return nil
 		},
 	})
+	s.Vendor.Context.Subscribe("activation-cancel", v1alpha2.EventHandler{
+		Handler: func(topic string, event v1alpha2.Event) error {
+			ctx := context.TODO()
+			if event.Context != nil {
+				ctx = event.Context
+			}
+			var actData v1alpha2.ActivationData
+			jData, _ := json.Marshal(event.Body)
+			if err := json.Unmarshal(jData, &actData); err != nil {
+				return v1alpha2.NewCOAError(nil, "event body is not an activation data", v1alpha2.BadRequest)
+			}
+			return s.StageManager.CancelActivation(ctx, actData.Namespace, actData.Activation)
+		},
+	})
+	s.Vendor.Context.Subscribe("activation-resume", v1alpha2.EventHandler{
+		Handler: func(topic string, event v1alpha2.Event) error {
+			ctx := context.TODO()
+			if event.Context != nil {
+				ctx = event.Context
+			}
+			var actData v1alpha2.ActivationData
+			jData, _ := json.Marshal(event.Body)
+			if err := json.Unmarshal(jData, &actData); err != nil {
+				return v1alpha2.NewCOAError(nil, "event body is not an activation data", v1alpha2.BadRequest)
+			}
+			// the activation may still be running the stage it was paused in, in which case nothing is parked
+			triggerData, err := s.StageManager.TakeParkedTrigger(ctx, actData.Namespace, actData.Activation)
+			if err != nil {
+				sLog.ErrorfCtx(ctx, "V (Stage): failed to get the parked stage of activation %s: %v", actData.Activation, err)
+				return err
+			}
+			if triggerData != nil {
+				sLog.InfofCtx(ctx, "V (Stage): resuming activation %s with stage %s", triggerData.Activation, triggerData.Stage)
+				s.Vendor.Context.Publish("trigger", v1alpha2.Event{
+					Body:    *triggerData,
+					Context: ctx,
+				})
+			}
+			return nil
+		},
+	})
 	s.Vendor.Context.Subscribe("job-report", v1alpha2.EventHandler{
 		Handler: func(topic string, event v1alpha2.Event) error {
 			ctx := context.TODO()
@@ -294,6 +469,17 @@ func (s *StageVendor) Init(config vendors.VendorConfig, factories []managers.IMa
 			if v, ok := dataPackage.Inputs["__schedule"]; ok {
 				schedule = utils.FormatAsString(v)
 			}
+			var timeZone = ""
+			if v, ok := dataPackage.Inputs["__timeZone"]; ok {
+				timeZone = utils.FormatAsString(v)
+			}
+			var maintenanceWindows []v1alpha2.MaintenanceWindow
+			if v, ok := dataPackage.Inputs["__maintenanceWindows"]; ok {
+				jData, _ = json.Marshal(v)
+				if err = json.Unmarshal(jData, &maintenanceWindows); err != nil {
+					return err
+				}
+			}
 
 			triggerData := v1alpha2.ActivationData{
 				Activation:           utils.FormatAsString(dataPackage.Inputs["__activation"]),
@@ -303,6 +489,8 @@ func (s *StageVendor) Init(config vendors.VendorConfig, factories []managers.IMa
 				Inputs:               dataPackage.Inputs,
 				Outputs:              dataPackage.Outputs,
 				Schedule:             schedule,
+				TimeZone:             timeZone,
+				MaintenanceWindows:   maintenanceWindows,
 				NeedsReport:          true,
 				Namespace:            utils.FormatAsString(dataPackage.Inputs["__namespace"]),
 			}
@@ -346,6 +534,34 @@ func (s *StageVendor) Init(config vendors.VendorConfig, factories []managers.IMa
 	return nil
 }
 
+// parkTrigger stops an activation that an operator paused before the triggered stage
+func (s *StageVendor) parkTrigger(ctx context.Context, triggerData v1alpha2.ActivationData) error {
+	err := s.StageManager.ParkTrigger(ctx, triggerData)
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "V (Stage): failed to park stage %s of activation %s: %v", triggerData.Stage, triggerData.Activation, err)
+		return err
+	}
+	paused, err := s.ActivationsManager.ReportPaused(ctx, triggerData.Activation, triggerData.Namespace)
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "V (Stage): failed to report paused status of activation %s: %v", triggerData.Activation, err)
+		return err
+	}
+	if !paused {
+		// the activation was resumed before the stage was parked
+		parked, err := s.StageManager.TakeParkedTrigger(ctx, triggerData.Namespace, triggerData.Activation)
+		if err != nil {
+			return err
+		}
+		if parked != nil {
+			s.Vendor.Context.Publish("trigger", v1alpha2.Event{
+				Body:    *parked,
+				Context: ctx,
+			})
+		}
+	}
+	return nil
+}
+
 func (s *StageVendor) reportActivationStatusWithBadRequest(activation string, namespace string, err error) error {
 	status := model.StageStatus{
 		Stage:         "",
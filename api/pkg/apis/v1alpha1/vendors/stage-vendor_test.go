@@ -7,22 +7,30 @@
 package vendors
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	sym_mgr "github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/managers"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/managers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub/memory"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states/memorystate"
+	coa_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
 	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
 )
 
 func TestStageEndpoints(t *testing.T) {
 	vendor := createStageVendor()
 	vendor.Route = "stage"
 	endpoints := vendor.GetEndpoints()
-	assert.Equal(t, 0, len(endpoints))
+	assert.Equal(t, 2, len(endpoints))
+	assert.Equal(t, "stage/approve", endpoints[0].Route)
+	assert.Equal(t, "stage/reject", endpoints[1].Route)
 }
 
 func TestStageInfo(t *testing.T) {
@@ -32,6 +40,103 @@ func TestStageInfo(t *testing.T) {
 	assert.NotNil(t, info)
 	assert.Equal(t, "1.0", info.Version)
 }
+func TestStageOnDecision(t *testing.T) {
+	vendor := createStageVendor()
+	vendor.StageManager.VendorContext.EvaluationContext = &coa_utils.EvaluationContext{}
+	ctx := context.Background()
+	campaign := model.CampaignSpec{
+		SelfDriving:  true,
+		FirstStage:   "approve",
+		RootResource: "campaign",
+		Stages: map[string]model.StageSpec{
+			"approve": {
+				Name:          "approve",
+				Provider:      "providers.stage.approval",
+				StageSelector: "deploy",
+				Inputs: map[string]interface{}{
+					"approvers": "alice",
+				},
+			},
+			"deploy": {
+				Name:     "deploy",
+				Provider: "providers.stage.mock",
+			},
+		},
+	}
+	err := vendor.CampaignsManager.UpsertState(ctx, "campaign-v-v1", model.CampaignState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "campaign-v-v1",
+			Namespace: "default",
+		},
+		Spec: &campaign,
+	})
+	assert.Nil(t, err)
+	err = vendor.ActivationsManager.UpsertState(ctx, "activation1", model.ActivationState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      "activation1",
+			Namespace: "default",
+		},
+		Spec: &model.ActivationSpec{
+			Campaign: "campaign:v1",
+		},
+	})
+	assert.Nil(t, err)
+
+	request := v1alpha2.COARequest{
+		Method: fasthttp.MethodPost,
+		Parameters: map[string]string{
+			"__name": "activation1",
+		},
+		Body:    []byte(`{"comment":"not during the freeze"}`),
+		Context: context.WithValue(ctx, v1alpha2.COAIdentityContextKey, v1alpha2.Identity{User: "alice"}),
+	}
+	resp := vendor.onReject(request)
+	assert.Equal(t, v1alpha2.Conflict, resp.State)
+
+	status, _ := vendor.StageManager.HandleTriggerEvent(ctx, campaign, v1alpha2.ActivationData{
+		Campaign:             "campaign:v1",
+		Activation:           "activation1",
+		ActivationGeneration: "1",
+		Stage:                "approve",
+		Provider:             "providers.stage.approval",
+		Namespace:            "default",
+	})
+	assert.Equal(t, v1alpha2.Paused, status.Status)
+	err = vendor.ActivationsManager.ReportStageStatus(ctx, "activation1", "default", status)
+	assert.Nil(t, err)
+
+	resp = vendor.onReject(v1alpha2.COARequest{
+		Method: fasthttp.MethodPost,
+		Parameters: map[string]string{
+			"__name": "activation1",
+		},
+		Context: ctx,
+	})
+	assert.Equal(t, v1alpha2.Unauthorized, resp.State)
+
+	resp = vendor.onReject(request)
+	assert.Equal(t, v1alpha2.OK, resp.State)
+	err = json.Unmarshal(resp.Body, &status)
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.Rejected, status.Status)
+	assert.Equal(t, "alice", status.Outputs[model.ApproverOutput])
+	assert.Equal(t, "not during the freeze", status.Outputs[model.ApprovalCommentOutput])
+
+	activation, err := vendor.ActivationsManager.GetState(ctx, "activation1", "default")
+	assert.Nil(t, err)
+	assert.Equal(t, v1alpha2.Rejected, activation.Status.Status)
+	assert.False(t, activation.Status.IsActive())
+
+	resp = vendor.onApprove(request)
+	assert.Equal(t, v1alpha2.Conflict, resp.State)
+
+	resp = vendor.onApprove(v1alpha2.COARequest{
+		Method:  fasthttp.MethodGet,
+		Context: ctx,
+	})
+	assert.Equal(t, v1alpha2.MethodNotAllowed, resp.State)
+}
+
 func createStageVendor() StageVendor {
 	stateProvider := memorystate.MemoryStateProvider{}
 	stateProvider.Init(memorystate.MemoryStateProviderConfig{})
@@ -48,6 +153,7 @@ func createStageVendor() StageVendor {
 				Type: "managers.symphony.stage",
 				Properties: map[string]string{
 					"providers.persistentstate": "mem-state",
+					"providers.volatilestate":   "mem-state",
 				},
 				Providers: map[string]managers.ProviderConfig{
 					"mem-state": {
@@ -21,6 +21,7 @@ import (
 	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/pubsub"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/vendors"
 	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
 	"github.com/golang-jwt/jwt/v4"
@@ -128,13 +129,18 @@ func (c *TargetsVendor) onRegistry(request v1alpha2.COARequest) v1alpha2.COAResp
 		ctx, span := observability.StartSpan("onRegistry-GET", pCtx, nil)
 		var err error
 		var state interface{}
+		var continuationToken string
 		isArray := false
 		if id == "" {
 			// Change namespace back to empty to indicate ListSpec need to query all namespaces
 			if !exist {
 				namespace = ""
 			}
-			state, err = c.TargetsManager.ListState(ctx, namespace)
+			var page states.PageOptions
+			page, err = parsePageOptions(request.Parameters)
+			if err == nil {
+				state, continuationToken, err = c.TargetsManager.ListStatePage(ctx, namespace, page)
+			}
 			isArray = true
 		} else {
 			state, err = c.TargetsManager.GetState(ctx, id, namespace)
@@ -142,7 +148,7 @@ func (c *TargetsVendor) onRegistry(request v1alpha2.COARequest) v1alpha2.COAResp
 		if err != nil {
 			tLog.ErrorfCtx(ctx, "V (Targets) : onRegistry failed - %s", err.Error())
 			return observ_utils.CloseSpanWithCOAResponse(span, v1alpha2.COAResponse{
-				State: v1alpha2.InternalError,
+				State: listErrorState(err),
 				Body:  []byte(err.Error()),
 			})
 		}
@@ -151,6 +157,7 @@ func (c *TargetsVendor) onRegistry(request v1alpha2.COARequest) v1alpha2.COAResp
 			State:       v1alpha2.OK,
 			Body:        jData,
 			ContentType: "application/json",
+			Metadata:    listResponseMetadata(continuationToken),
 		})
 		if request.Parameters["doc-type"] == "yaml" {
 			resp.ContentType = "text/plain"
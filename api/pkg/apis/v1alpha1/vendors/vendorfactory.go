@@ -57,6 +57,8 @@ func (c SymphonyVendorFactory) CreateVendor(config vendors.VendorConfig) (vendor
 		return &SkillsVendor{}, nil
 	case "vendors.settings":
 		return &SettingsVendor{}, nil
+	case "vendors.expressions":
+		return &ExpressionsVendor{}, nil
 	case "vendors.trails":
 		return &TrailsVendor{}, nil
 	case "vendors.backgroundjob":
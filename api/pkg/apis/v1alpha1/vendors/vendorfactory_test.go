@@ -106,6 +106,11 @@ func TestCreateVendor(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotNil(t, vendor.(*SettingsVendor))
 
+	config.Type = "vendors.expressions"
+	vendor, err = factory.CreateVendor(config)
+	assert.Nil(t, err)
+	assert.NotNil(t, vendor.(*ExpressionsVendor))
+
 	config.Type = "vendors.trails"
 	vendor, err = factory.CreateVendor(config)
 	assert.Nil(t, err)
@@ -121,6 +121,9 @@ func composeCOARequestContext(reqCtx *fasthttp.RequestCtx, actCtx *contexts.Acti
 	retCtx := context.TODO()
 	if reqCtx != nil {
 		retCtx = context.WithValue(retCtx, v1alpha2.COAFastHTTPContextKey, reqCtx)
+		if identity, ok := reqCtx.UserValue(v1alpha2.COAIdentityContextKey).(v1alpha2.Identity); ok {
+			retCtx = context.WithValue(retCtx, v1alpha2.COAIdentityContextKey, identity)
+		}
 	}
 	if actCtx != nil {
 		retCtx = context.WithValue(retCtx, contexts.ActivityLogContextKey, actCtx)
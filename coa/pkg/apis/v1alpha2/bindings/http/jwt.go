@@ -101,12 +101,13 @@ func (j JWT) JWT(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 			}
 			if issuer == SymphonyIssuer {
 				log.Debugf("JWT: Validating token with username plus pwd.")
-				_, roles, err := j.validateToken(tokenStr)
+				claims, roles, err := j.validateToken(tokenStr)
 				if err != nil {
 					log.Error("JWT: Validate token with user creds failed. %s\n", err.Error())
 					ctx.Response.SetStatusCode(fasthttp.StatusForbidden)
 					return
 				} else {
+					ctx.SetUserValue(v1alpha2.COAIdentityContextKey, identityFromClaims(claims, roles))
 					if j.EnableRBAC {
 						path := string(ctx.Path())
 						method := string(ctx.Method())
@@ -130,12 +131,13 @@ func (j JWT) JWT(next fasthttp.RequestHandler) fasthttp.RequestHandler {
 			} else {
 				if j.AuthServer == AuthServerKuberenetes {
 					log.Debugf("JWT: Validating token with k8s.")
-					err := j.validateServiceAccountToken(ctx, tokenStr)
+					identity, err := j.validateServiceAccountToken(ctx, tokenStr)
 					if err != nil {
 						log.Errorf("JWT: Validate token with k8s failed. %s\n", err.Error())
 						ctx.Response.SetStatusCode(fasthttp.StatusForbidden)
 						return
 					}
+					ctx.SetUserValue(v1alpha2.COAIdentityContextKey, identity)
 					next(ctx)
 				} else {
 					log.Errorf("JWT: Not supported auth server, %s.\n", j.AuthServer)
@@ -209,21 +211,31 @@ func (j *JWT) validateToken(tokenStr string) (map[string]interface{}, []string,
 			}
 		}
 	}
-	var roles []string
-	if j.EnableRBAC {
-		roles = make([]string, 0)
-		for _, m := range j.Roles {
-			if v, ok := ret[m.Claim]; ok {
-				if m.Value == "*" || v == m.Value {
-					roles = append(roles, m.Role)
-				}
+	// roles are mapped even without RBAC, as handlers may authorize callers by their roles
+	roles := make([]string, 0)
+	for _, m := range j.Roles {
+		if v, ok := ret[m.Claim]; ok {
+			if m.Value == "*" || v == m.Value {
+				roles = append(roles, m.Role)
 			}
 		}
-
 	}
 	return ret, roles, nil
 }
 
+// identityFromClaims returns the caller of a request from the "user" claim of its token, or from the subject if the
+// token has no user claim
+func identityFromClaims(claims map[string]interface{}, roles []string) v1alpha2.Identity {
+	user, _ := claims["user"].(string)
+	if user == "" {
+		user, _ = claims["sub"].(string)
+	}
+	return v1alpha2.Identity{
+		User:  user,
+		Roles: roles,
+	}
+}
+
 func decodeJWTTokenForIssuer(tokenString string) (string, error) {
 	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
 	if err != nil {
@@ -244,11 +256,13 @@ func decodeJWTTokenForIssuer(tokenString string) (string, error) {
 	}
 }
 
-func (j *JWT) validateServiceAccountToken(ctx *fasthttp.RequestCtx, tokenStr string) error {
+// validateServiceAccountToken reviews a service account token with the Kubernetes API server, and returns the service
+// account as the caller of the request with its groups as roles
+func (j *JWT) validateServiceAccountToken(ctx *fasthttp.RequestCtx, tokenStr string) (v1alpha2.Identity, error) {
 	clientset, err := getKubernetesClient()
 	if err != nil {
 		log.Errorf("JWT: Could not initialize Kubernetes client.\n")
-		return v1alpha2.NewCOAError(err, "Could not initialize Kubernetes client", v1alpha2.InternalError)
+		return v1alpha2.Identity{}, v1alpha2.NewCOAError(err, "Could not initialize Kubernetes client", v1alpha2.InternalError)
 	}
 	tokenReview := &v1.TokenReview{
 		Spec: v1.TokenReviewSpec{
@@ -262,26 +276,29 @@ func (j *JWT) validateServiceAccountToken(ctx *fasthttp.RequestCtx, tokenStr str
 	result, err := clientset.AuthenticationV1().TokenReviews().Create(ctx, tokenReview, metav1.CreateOptions{})
 	if err != nil {
 		log.Errorf("JWT: Token review using kubernetes api server failed. %s\n", err.Error())
-		return v1alpha2.NewCOAError(err, "Token review using kubernetes api server failed.", v1alpha2.InternalError)
+		return v1alpha2.Identity{}, v1alpha2.NewCOAError(err, "Token review using kubernetes api server failed.", v1alpha2.InternalError)
 	}
 	if !result.Status.Authenticated {
 		log.Errorf("JWT: Validate token with k8s failed. K8s returned not authenticated.\n")
-		return v1alpha2.NewCOAError(nil, "Authentication failed.", v1alpha2.Unauthorized)
+		return v1alpha2.Identity{}, v1alpha2.NewCOAError(nil, "Authentication failed.", v1alpha2.Unauthorized)
 	} else {
 		apiUsername, err := getApiServiceAccountUsername()
 		if err != nil {
-			return err
+			return v1alpha2.Identity{}, err
 		}
 		controllerUsername, err := getControllerServiceAccountUsername()
 		if err != nil {
-			return err
+			return v1alpha2.Identity{}, err
 		}
 		if result.Status.User.Username != apiUsername && result.Status.User.Username != controllerUsername {
 			log.Errorf("JWT: Validate token with k8s failed. K8s returned invalid username, %s\n", result.Status.User.Username)
-			return v1alpha2.NewCOAError(nil, "Authentication failed.", v1alpha2.Unauthorized)
+			return v1alpha2.Identity{}, v1alpha2.NewCOAError(nil, "Authentication failed.", v1alpha2.Unauthorized)
 		}
 	}
-	return nil
+	return v1alpha2.Identity{
+		User:  result.Status.User.Username,
+		Roles: result.Status.User.Groups,
+	}, nil
 
 }
 func getKubernetesClient() (*kubernetes.Clientset, error) {
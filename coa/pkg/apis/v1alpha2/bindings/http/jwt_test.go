@@ -14,8 +14,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	jwt "github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
 )
 
 func generateJWTToken(signingKey interface{}, method jwt.SigningMethod, userName string, expiresAt time.Time, issuedAt time.Time, notAfter time.Time, issuer string, subject string, audiences []string) (string, error) {
@@ -142,3 +144,39 @@ func TestValidateWithTokenWithCert(t *testing.T) {
 	_, _, err = j.validateToken(token)
 	assert.Nil(t, err)
 }
+
+func TestJWTSetsIdentity(t *testing.T) {
+	j := JWT{
+		AuthHeader: "Authorization",
+		VerifyKey:  "test",
+		EnableRBAC: false,
+		Roles: []ClaimRoleMap{
+			{
+				Role:  "approver",
+				Claim: "user",
+				Value: "alice",
+			},
+			{
+				Role:  "operator",
+				Claim: "user",
+				Value: "bob",
+			},
+		},
+	}
+
+	token, err := generateJWTToken([]byte("test"), jwt.SigningMethodHS256, "alice", time.Now().Add(time.Hour), time.Now(), time.Now(), SymphonyIssuer, "symphony", []string{"*"})
+	assert.Nil(t, err)
+
+	var identity v1alpha2.Identity
+	called := false
+	handler := j.JWT(func(ctx *fasthttp.RequestCtx) {
+		called = true
+		identity, _ = ctx.UserValue(v1alpha2.COAIdentityContextKey).(v1alpha2.Identity)
+	})
+	reqCtx := &fasthttp.RequestCtx{}
+	reqCtx.Request.Header.Set("Authorization", "Bearer "+token)
+	handler(reqCtx)
+	assert.True(t, called)
+	assert.Equal(t, "alice", identity.User)
+	assert.Equal(t, []string{"approver"}, identity.Roles)
+}
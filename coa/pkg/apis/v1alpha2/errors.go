@@ -83,6 +83,13 @@ func IsBadConfig(err error) bool {
 	}
 	return coaE.State == BadConfig
 }
+func IsConflict(err error) bool {
+	coaE, ok := err.(COAError)
+	if !ok {
+		return false
+	}
+	return coaE.State == Conflict
+}
 
 func IsRetriableErr(err error) bool {
 	coaE, ok := err.(COAError)
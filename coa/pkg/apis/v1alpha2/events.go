@@ -229,7 +229,12 @@ type ActivationData struct {
 	Config               interface{}                       `json:"config,omitempty"`
 	TriggeringStage      string                            `json:"triggeringStage,omitempty"`
 	Schedule             string                            `json:"schedule,omitempty"`
+	TimeZone             string                            `json:"timeZone,omitempty"`
+	MaintenanceWindows   []MaintenanceWindow               `json:"maintenanceWindows,omitempty"`
 	NeedsReport          bool                              `json:"needsReport,omitempty"`
+	// DryRun runs the stage with a simulated provider, which returns the DryRunOutputs of the stage if any
+	DryRun        bool                              `json:"dryRun,omitempty"`
+	DryRunOutputs map[string]map[string]interface{} `json:"dryRunOutputs,omitempty"`
 }
 
 // UnmarshalJSON customizes the JSON unmarshalling for ActivationData
@@ -243,11 +248,9 @@ func (s *ActivationData) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
-	// validate if Schedule meet RFC 3339
-	if s.Schedule != "" {
-		if _, err := time.Parse(time.RFC3339, s.Schedule); err != nil {
-			return fmt.Errorf("invalid timestamp format: %v", err)
-		}
+	// validate if Schedule is an RFC 3339 timestamp or a cron expression
+	if err := ValidateSchedule(s.Schedule, s.TimeZone, s.MaintenanceWindows); err != nil {
+		return fmt.Errorf("invalid schedule: %v", err)
 	}
 	return nil
 }
@@ -255,10 +258,8 @@ func (s *ActivationData) UnmarshalJSON(data []byte) error {
 // MarshalJSON customizes the JSON marshalling for ActivationData
 func (s ActivationData) MarshalJSON() ([]byte, error) {
 	type Alias ActivationData
-	if s.Schedule != "" {
-		if _, err := time.Parse(time.RFC3339, s.Schedule); err != nil {
-			return nil, fmt.Errorf("invalid timestamp format: %v", err)
-		}
+	if err := ValidateSchedule(s.Schedule, s.TimeZone, s.MaintenanceWindows); err != nil {
+		return nil, fmt.Errorf("invalid schedule: %v", err)
 	}
 	return json.Marshal(&struct {
 		*Alias
@@ -282,14 +283,52 @@ type HeartBeatData struct {
 	JobAction JobAction       `json:"jobaction"`
 }
 
-func (s ActivationData) ShouldFireNow() (bool, error) {
-	dt, err := time.Parse(time.RFC3339, s.Schedule)
+// IsScheduled checks if the stage waits for a schedule or a maintenance window before it starts
+func (s ActivationData) IsScheduled() bool {
+	return s.Schedule != "" || len(s.MaintenanceWindows) > 0
+}
+
+// ResolveSchedule replaces a cron schedule with the timestamp of its next occurrence after the given time, so that the
+// stage fires once for the occurrence it's waiting for
+func (s *ActivationData) ResolveSchedule(after time.Time) error {
+	if !IsCronSchedule(s.Schedule) {
+		return nil
+	}
+	next, err := NextScheduleTime(s.Schedule, s.TimeZone, after)
 	if err != nil {
-		return false, err
+		return err
+	}
+	s.Schedule = next.Format(time.RFC3339)
+	return nil
+}
+
+// ClearSchedule removes the schedule once it has fired, so that the triggered stage runs right away
+func (s *ActivationData) ClearSchedule() {
+	s.Schedule = ""
+	s.TimeZone = ""
+	s.MaintenanceWindows = nil
+}
+
+func (s ActivationData) ShouldFireNow() (bool, error) {
+	return s.ShouldFireAt(time.Now().UTC())
+}
+
+// ShouldFireAt checks if the schedule is due at the given time and the time is inside a maintenance window. A stage
+// that is due outside of its windows waits for the next window to open.
+func (s ActivationData) ShouldFireAt(now time.Time) (bool, error) {
+	if IsCronSchedule(s.Schedule) {
+		return false, fmt.Errorf("cron schedule '%s' is not resolved to a timestamp", s.Schedule)
+	}
+	if s.Schedule != "" {
+		dt, err := time.Parse(time.RFC3339, s.Schedule)
+		if err != nil {
+			return false, err
+		}
+		if !dt.In(time.UTC).Before(now.In(time.UTC)) {
+			return false, nil
+		}
 	}
-	dtNow := time.Now().UTC()
-	dtUTC := dt.In(time.UTC)
-	return dtUTC.Before(dtNow), nil
+	return InMaintenanceWindow(s.MaintenanceWindows, s.TimeZone, now)
 }
 
 type InputOutputData struct {
@@ -0,0 +1,370 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package filestate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	contexts "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/contexts"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability"
+	observ_utils "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/observability/utils"
+	providers "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers"
+	states "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/utils"
+	"github.com/eclipse-symphony/symphony/coa/pkg/logger"
+)
+
+var sLog = logger.NewLogger("coa.runtime")
+
+const defaultCompactionThreshold = 1000
+
+// stores are shared by path, so that providers of different managers can use the same files
+var (
+	stores   = make(map[string]*fileStore)
+	storesMu sync.Mutex
+)
+
+type FileStateProviderConfig struct {
+	Name string `json:"name"`
+	// Path is the directory of the snapshot and journal files, which is created if it doesn't exist
+	Path string `json:"path"`
+	// CompactionThreshold is the number of journal records that triggers a compaction
+	CompactionThreshold int `json:"compactionThreshold,omitempty"`
+}
+
+func FileStateProviderConfigFromMap(properties map[string]string) (FileStateProviderConfig, error) {
+	ret := FileStateProviderConfig{}
+	if v, ok := properties["name"]; ok {
+		ret.Name = utils.ParseProperty(v)
+	}
+	if v, ok := properties["path"]; ok {
+		ret.Path = utils.ParseProperty(v)
+	}
+	if v, ok := properties["compactionThreshold"]; ok {
+		val := utils.ParseProperty(v)
+		if val != "" {
+			iVal, err := strconv.Atoi(val)
+			if err != nil {
+				return ret, v1alpha2.NewCOAError(err, "invalid int value in the 'compactionThreshold' setting of file state provider", v1alpha2.BadConfig)
+			}
+			ret.CompactionThreshold = iVal
+		}
+	}
+	return ret, nil
+}
+
+// FileStateProvider is a state provider that keeps the entries in memory and persists them to a local directory, so
+// that a standalone deployment survives restarts without external infrastructure. Every change is appended to a
+// journal and synced before it's acknowledged, and the journal is periodically compacted into a snapshot.
+type FileStateProvider struct {
+	Config  FileStateProviderConfig
+	Context *contexts.ManagerContext
+	store   *fileStore
+}
+
+func (s *FileStateProvider) ID() string {
+	return s.Config.Name
+}
+
+func (s *FileStateProvider) SetContext(ctx *contexts.ManagerContext) {
+	s.Context = ctx
+}
+
+func (i *FileStateProvider) InitWithMap(properties map[string]string) error {
+	config, err := FileStateProviderConfigFromMap(properties)
+	if err != nil {
+		return err
+	}
+	return i.Init(config)
+}
+
+func (s *FileStateProvider) Init(config providers.IProviderConfig) error {
+	stateConfig, err := toFileStateProviderConfig(config)
+	if err != nil {
+		sLog.Errorf("  P (File State): failed to parse provider config %+v", err)
+		return errors.New("expected FileStateProviderConfig")
+	}
+	if stateConfig.Path == "" {
+		return v1alpha2.NewCOAError(nil, "file state provider path is not set", v1alpha2.BadConfig)
+	}
+	if stateConfig.CompactionThreshold < 0 {
+		return v1alpha2.NewCOAError(nil, "file state provider compaction threshold can't be negative", v1alpha2.BadConfig)
+	}
+	if stateConfig.CompactionThreshold == 0 {
+		stateConfig.CompactionThreshold = defaultCompactionThreshold
+	}
+	s.Config = stateConfig
+
+	storesMu.Lock()
+	defer storesMu.Unlock()
+	if store, ok := stores[s.Config.Path]; ok {
+		s.store = store
+		return nil
+	}
+	store, err := openFileStore(s.Config.Path, s.Config.CompactionThreshold)
+	if err != nil {
+		sLog.Errorf("  P (File State): failed to open state files in %s: %+v", s.Config.Path, err)
+		return v1alpha2.NewCOAError(err, fmt.Sprintf("failed to open state files in %s", s.Config.Path), v1alpha2.InternalError)
+	}
+	stores[s.Config.Path] = store
+	s.store = store
+	return nil
+}
+
+func (s *FileStateProvider) Upsert(ctx context.Context, entry states.UpsertRequest) (string, error) {
+	ctx, span := observability.StartSpan("File State Provider", ctx, &map[string]string{
+		"method": "Upsert",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	key := newEntryKey(entry.Metadata, entry.Value.ID, "default")
+	sLog.DebugfCtx(ctx, "  P (File State): upsert state %s in namespace %s", key.ID, key.Namespace)
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	existing, exists := s.store.entries[key]
+	if entry.ETag != nil && (!exists && *entry.ETag != "" || exists && *entry.ETag != existing.ETag) {
+		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("etag of entry '%s' doesn't match", key.ID), v1alpha2.Conflict)
+		sLog.ErrorfCtx(ctx, "  P (File State): failed to upsert %s state: %+v", key.ID, err)
+		return "", err
+	}
+
+	var value states.StateEntry
+	value, err = deepCopy(entry.Value)
+	if err != nil {
+		err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' doesn't have a valid body", key.ID), v1alpha2.BadRequest)
+		sLog.ErrorfCtx(ctx, "  P (File State): failed to upsert %s state: %+v", key.ID, err)
+		return "", err
+	}
+	if entry.Options.UpdateStatusOnly {
+		if !exists {
+			err = v1alpha2.NewCOAError(nil, fmt.Sprintf("entry '%s' is not found", key.ID), v1alpha2.NotFound)
+			sLog.ErrorfCtx(ctx, "  P (File State): failed to upsert %s state: %+v", key.ID, err)
+			return "", err
+		}
+		value.Body, err = mergeStatus(existing.Body, value.Body)
+		if err != nil {
+			err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' doesn't have a valid status", key.ID), v1alpha2.InternalError)
+			sLog.ErrorfCtx(ctx, "  P (File State): failed to upsert %s state: %+v", key.ID, err)
+			return "", err
+		}
+	}
+	value.ETag = "1"
+	if exists {
+		if v, parseErr := strconv.ParseInt(existing.ETag, 10, 64); parseErr == nil {
+			value.ETag = strconv.FormatInt(v+1, 10)
+		}
+	}
+
+	err = s.store.apply(journalRecord{Op: opUpsert, Key: key, Entry: &value})
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (File State): failed to upsert %s state: %+v", key.ID, err)
+		return "", err
+	}
+	return key.ID, nil
+}
+
+func (s *FileStateProvider) List(ctx context.Context, request states.ListRequest) ([]states.StateEntry, string, error) {
+	ctx, span := observability.StartSpan("File State Provider", ctx, &map[string]string{
+		"method": "List",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	// If namespace is not specified, get entries of all namespaces
+	scope := newEntryKey(request.Metadata, "", "")
+	sLog.DebugfCtx(ctx, "  P (File State): list states in namespace %s", scope.Namespace)
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	entities := make([]states.StateEntry, 0)
+	for key, entry := range s.store.entries {
+		if !scope.contains(key) {
+			continue
+		}
+		if request.FilterType != "" && request.FilterValue != "" {
+			var match bool
+			match, err = states.MatchFilter(entry, request.FilterType, request.FilterValue)
+			if err != nil {
+				return nil, "", err
+			} else if !match {
+				continue
+			}
+		}
+		var copy states.StateEntry
+		copy, err = deepCopy(entry)
+		if err != nil {
+			err = v1alpha2.NewCOAError(err, fmt.Sprintf("failed to create a deep copy of entry '%s'", key.ID), v1alpha2.InternalError)
+			sLog.ErrorfCtx(ctx, "  P (File State): failed to list states: %+v", err)
+			return nil, "", err
+		}
+		entities = append(entities, copy)
+	}
+
+	var token string
+	entities, token, err = states.PageEntries(entities, request.PageOptions)
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (File State): failed to list states: %+v", err)
+		return nil, "", err
+	}
+	return entities, token, nil
+}
+
+func (s *FileStateProvider) Delete(ctx context.Context, request states.DeleteRequest) error {
+	ctx, span := observability.StartSpan("File State Provider", ctx, &map[string]string{
+		"method": "Delete",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	key := newEntryKey(request.Metadata, request.ID, "default")
+	sLog.DebugfCtx(ctx, "  P (File State): delete state %s in namespace %s", key.ID, key.Namespace)
+
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	existing, ok := s.store.entries[key]
+	if !ok {
+		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("entry '%s' is not found in namespace %s", key.ID, key.Namespace), v1alpha2.NotFound)
+		sLog.ErrorfCtx(ctx, "  P (File State): failed to delete %s: %+v", key.ID, err)
+		return err
+	}
+	if request.ETag != nil && *request.ETag != existing.ETag {
+		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("etag of entry '%s' doesn't match", key.ID), v1alpha2.Conflict)
+		sLog.ErrorfCtx(ctx, "  P (File State): failed to delete %s: %+v", key.ID, err)
+		return err
+	}
+	err = s.store.apply(journalRecord{Op: opDelete, Key: key})
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (File State): failed to delete %s: %+v", key.ID, err)
+		return err
+	}
+	return nil
+}
+
+func (s *FileStateProvider) Get(ctx context.Context, request states.GetRequest) (states.StateEntry, error) {
+	ctx, span := observability.StartSpan("File State Provider", ctx, &map[string]string{
+		"method": "Get",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+
+	key := newEntryKey(request.Metadata, request.ID, "default")
+	sLog.DebugfCtx(ctx, "  P (File State): get state %s in namespace %s", key.ID, key.Namespace)
+
+	s.store.mu.RLock()
+	defer s.store.mu.RUnlock()
+
+	entry, ok := s.store.entries[key]
+	if !ok {
+		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("entry '%s' is not found in namespace %s", key.ID, key.Namespace), v1alpha2.NotFound)
+		sLog.ErrorfCtx(ctx, "  P (File State): failed to get %s state: %+v", key.ID, err)
+		return states.StateEntry{}, err
+	}
+	var copy states.StateEntry
+	copy, err = deepCopy(entry)
+	if err != nil {
+		err = v1alpha2.NewCOAError(err, fmt.Sprintf("failed to create a deep copy of entry '%s'", key.ID), v1alpha2.InternalError)
+		sLog.ErrorfCtx(ctx, "  P (File State): failed to get %s state: %+v", key.ID, err)
+		return states.StateEntry{}, err
+	}
+	return copy, nil
+}
+
+// Compact writes all the entries to a new snapshot and empties the journal
+func (s *FileStateProvider) Compact() error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+	return s.store.compact()
+}
+
+func toFileStateProviderConfig(config providers.IProviderConfig) (FileStateProviderConfig, error) {
+	ret := FileStateProviderConfig{}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return ret, err
+	}
+	err = json.Unmarshal(data, &ret)
+	return ret, err
+}
+
+func (a *FileStateProvider) Clone(config providers.IProviderConfig) (providers.IProvider, error) {
+	ret := &FileStateProvider{}
+	if config == nil {
+		err := ret.Init(a.Config)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err := ret.Init(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if a.Context != nil {
+		ret.Context = a.Context
+	}
+	return ret, nil
+}
+
+// deepCopy returns a copy of an entry whose body has the JSON types it has after being read from the files
+func deepCopy(s states.StateEntry) (states.StateEntry, error) {
+	var ret states.StateEntry
+	jBody, err := json.Marshal(s)
+	if err != nil {
+		return states.StateEntry{}, err
+	}
+	err = json.Unmarshal(jBody, &ret)
+	if err != nil {
+		return states.StateEntry{}, err
+	}
+	return ret, nil
+}
+
+// mergeStatus returns the existing body with the status properties of the new body
+func mergeStatus(existing interface{}, update interface{}) (interface{}, error) {
+	body, ok := existing.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("existing body is not an object")
+	}
+	updateMap, ok := update.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("new body is not an object")
+	}
+	statusMap, ok := updateMap["status"].(map[string]interface{})
+	if !ok {
+		return nil, errors.New("new body doesn't have a status")
+	}
+	ret := make(map[string]interface{}, len(body))
+	for k, v := range body {
+		ret[k] = v
+	}
+	status := make(map[string]interface{})
+	if existingStatus, ok := body["status"].(map[string]interface{}); ok {
+		for k, v := range existingStatus {
+			status[k] = v
+		}
+	}
+	for k, v := range statusMap {
+		status[k] = v
+	}
+	ret["status"] = status
+	return ret, nil
+}
@@ -0,0 +1,325 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package filestate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
+	states "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMetadata = map[string]interface{}{
+	"namespace": "default",
+	"group":     "solution.symphony",
+	"version":   "v1",
+	"resource":  "instances",
+}
+
+func newTestProvider(t *testing.T, dir string) *FileStateProvider {
+	provider := &FileStateProvider{}
+	err := provider.Init(FileStateProviderConfig{Name: "test", Path: dir})
+	assert.Nil(t, err)
+	return provider
+}
+
+// reopen drops the shared store of a directory, so that the next provider reads the files again like after a restart
+func reopen(t *testing.T, dir string) *FileStateProvider {
+	storesMu.Lock()
+	if store, ok := stores[dir]; ok {
+		store.journal.Close()
+		delete(stores, dir)
+	}
+	storesMu.Unlock()
+	return newTestProvider(t, dir)
+}
+
+func TestInitWithoutPath(t *testing.T) {
+	provider := FileStateProvider{}
+	err := provider.Init(FileStateProviderConfig{Name: "test"})
+	assert.NotNil(t, err)
+	coaErr, ok := err.(v1alpha2.COAError)
+	assert.True(t, ok)
+	assert.Equal(t, v1alpha2.BadConfig, coaErr.State)
+}
+
+func TestFileStateProviderConfigFromMap(t *testing.T) {
+	config, err := FileStateProviderConfigFromMap(map[string]string{
+		"name":                "test",
+		"path":                "/tmp/state",
+		"compactionThreshold": "10",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "test", config.Name)
+	assert.Equal(t, "/tmp/state", config.Path)
+	assert.Equal(t, 10, config.CompactionThreshold)
+
+	_, err = FileStateProviderConfigFromMap(map[string]string{
+		"compactionThreshold": "ten",
+	})
+	assert.NotNil(t, err)
+}
+
+func TestUpsertGetListDelete(t *testing.T) {
+	provider := newTestProvider(t, t.TempDir())
+	id, err := provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   "instance1",
+			Body: map[string]interface{}{"spec": map[string]interface{}{"solution": "solution1"}},
+		},
+		Metadata: testMetadata,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "instance1", id)
+
+	entry, err := provider.Get(context.Background(), states.GetRequest{ID: "instance1", Metadata: testMetadata})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", entry.ETag)
+	assert.Equal(t, "solution1", entry.Body.(map[string]interface{})["spec"].(map[string]interface{})["solution"])
+
+	// entries of another resource with the same name are kept apart
+	_, err = provider.Get(context.Background(), states.GetRequest{
+		ID: "instance1",
+		Metadata: map[string]interface{}{
+			"group":    "solution.symphony",
+			"resource": "solutions",
+		},
+	})
+	assert.True(t, v1alpha2.IsNotFound(err))
+
+	entries, _, err := provider.List(context.Background(), states.ListRequest{Metadata: testMetadata})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "instance1", Metadata: testMetadata})
+	assert.Nil(t, err)
+	_, err = provider.Get(context.Background(), states.GetRequest{ID: "instance1", Metadata: testMetadata})
+	assert.True(t, v1alpha2.IsNotFound(err))
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "instance1", Metadata: testMetadata})
+	assert.True(t, v1alpha2.IsNotFound(err))
+}
+
+func TestUpsertETagConcurrency(t *testing.T) {
+	provider := newTestProvider(t, t.TempDir())
+	_, err := provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance1", Body: map[string]interface{}{}},
+		Metadata: testMetadata,
+	})
+	assert.Nil(t, err)
+
+	stale := "0"
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance1", Body: map[string]interface{}{}},
+		ETag:     &stale,
+		Metadata: testMetadata,
+	})
+	coaErr, ok := err.(v1alpha2.COAError)
+	assert.True(t, ok)
+	assert.Equal(t, v1alpha2.Conflict, coaErr.State)
+
+	current := "1"
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance1", Body: map[string]interface{}{}},
+		ETag:     &current,
+		Metadata: testMetadata,
+	})
+	assert.Nil(t, err)
+	entry, err := provider.Get(context.Background(), states.GetRequest{ID: "instance1", Metadata: testMetadata})
+	assert.Nil(t, err)
+	assert.Equal(t, "2", entry.ETag)
+
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "instance1", ETag: &current, Metadata: testMetadata})
+	coaErr, ok = err.(v1alpha2.COAError)
+	assert.True(t, ok)
+	assert.Equal(t, v1alpha2.Conflict, coaErr.State)
+}
+
+func TestUpsertStatusOnly(t *testing.T) {
+	provider := newTestProvider(t, t.TempDir())
+	_, err := provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID: "instance1",
+			Body: map[string]interface{}{
+				"spec":   map[string]interface{}{"solution": "solution1"},
+				"status": map[string]interface{}{"a": "1"},
+			},
+		},
+		Metadata: testMetadata,
+	})
+	assert.Nil(t, err)
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID: "instance1",
+			Body: map[string]interface{}{
+				"spec":   map[string]interface{}{"solution": "solution2"},
+				"status": map[string]interface{}{"b": "2"},
+			},
+		},
+		Metadata: testMetadata,
+		Options:  states.UpsertOption{UpdateStatusOnly: true},
+	})
+	assert.Nil(t, err)
+	entry, err := provider.Get(context.Background(), states.GetRequest{ID: "instance1", Metadata: testMetadata})
+	assert.Nil(t, err)
+	body := entry.Body.(map[string]interface{})
+	assert.Equal(t, "solution1", body["spec"].(map[string]interface{})["solution"])
+	assert.Equal(t, map[string]interface{}{"a": "1", "b": "2"}, body["status"])
+
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance2", Body: map[string]interface{}{"status": map[string]interface{}{}}},
+		Metadata: testMetadata,
+		Options:  states.UpsertOption{UpdateStatusOnly: true},
+	})
+	assert.True(t, v1alpha2.IsNotFound(err))
+}
+
+func TestListFilterAndNamespace(t *testing.T) {
+	provider := newTestProvider(t, t.TempDir())
+	for _, namespace := range []string{"default", "nondefault"} {
+		for _, name := range []string{"instance1", "instance2"} {
+			_, err := provider.Upsert(context.Background(), states.UpsertRequest{
+				Value: states.StateEntry{
+					ID: name,
+					Body: map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"name":   name,
+							"labels": map[string]interface{}{"app": name},
+						},
+					},
+				},
+				Metadata: map[string]interface{}{
+					"namespace": namespace,
+					"group":     "solution.symphony",
+					"resource":  "instances",
+				},
+			})
+			assert.Nil(t, err)
+		}
+	}
+	entries, _, err := provider.List(context.Background(), states.ListRequest{
+		Metadata: map[string]interface{}{
+			"group":    "solution.symphony",
+			"resource": "instances",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(entries))
+
+	entries, _, err = provider.List(context.Background(), states.ListRequest{
+		Metadata: map[string]interface{}{
+			"namespace": "nondefault",
+			"group":     "solution.symphony",
+			"resource":  "instances",
+		},
+		FilterType:  "label",
+		FilterValue: "app=instance2",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "instance2", entries[0].ID)
+}
+
+func TestStateSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	provider := newTestProvider(t, dir)
+	for _, name := range []string{"instance1", "instance2"} {
+		_, err := provider.Upsert(context.Background(), states.UpsertRequest{
+			Value:    states.StateEntry{ID: name, Body: map[string]interface{}{"name": name}},
+			Metadata: testMetadata,
+		})
+		assert.Nil(t, err)
+	}
+	err := provider.Delete(context.Background(), states.DeleteRequest{ID: "instance1", Metadata: testMetadata})
+	assert.Nil(t, err)
+
+	provider = reopen(t, dir)
+	entries, _, err := provider.List(context.Background(), states.ListRequest{Metadata: testMetadata})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "instance2", entries[0].ID)
+	assert.Equal(t, "1", entries[0].ETag)
+}
+
+func TestTornJournalRecordIsDiscarded(t *testing.T) {
+	dir := t.TempDir()
+	provider := newTestProvider(t, dir)
+	_, err := provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance1", Body: map[string]interface{}{}},
+		Metadata: testMetadata,
+	})
+	assert.Nil(t, err)
+
+	// simulate a crash in the middle of appending a record
+	journal, err := os.OpenFile(filepath.Join(dir, journalFileName), os.O_APPEND|os.O_WRONLY, 0600)
+	assert.Nil(t, err)
+	_, err = journal.WriteString(`0badc0de {"op":"upsert","key":{"namespace":"default","id":"instance2"`)
+	assert.Nil(t, err)
+	journal.Close()
+
+	provider = reopen(t, dir)
+	entries, _, err := provider.List(context.Background(), states.ListRequest{Metadata: testMetadata})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+
+	// records appended after the recovery are replayed
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance3", Body: map[string]interface{}{}},
+		Metadata: testMetadata,
+	})
+	assert.Nil(t, err)
+	provider = reopen(t, dir)
+	entries, _, err = provider.List(context.Background(), states.ListRequest{Metadata: testMetadata})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+}
+
+func TestCompaction(t *testing.T) {
+	dir := t.TempDir()
+	provider := &FileStateProvider{}
+	err := provider.Init(FileStateProviderConfig{Name: "test", Path: dir, CompactionThreshold: 3})
+	assert.Nil(t, err)
+	for i := 0; i < 4; i++ {
+		_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+			Value:    states.StateEntry{ID: "instance1", Body: map[string]interface{}{"value": float64(i)}},
+			Metadata: testMetadata,
+		})
+		assert.Nil(t, err)
+	}
+	// the third upsert triggers a compaction, so only the fourth is left in the journal
+	assert.Equal(t, 1, provider.store.journalRecords)
+	_, err = os.Stat(filepath.Join(dir, snapshotFileName))
+	assert.Nil(t, err)
+
+	err = provider.Compact()
+	assert.Nil(t, err)
+	info, err := os.Stat(filepath.Join(dir, journalFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), info.Size())
+
+	provider = reopen(t, dir)
+	entry, err := provider.Get(context.Background(), states.GetRequest{ID: "instance1", Metadata: testMetadata})
+	assert.Nil(t, err)
+	assert.Equal(t, "4", entry.ETag)
+	assert.Equal(t, float64(3), entry.Body.(map[string]interface{})["value"])
+}
+
+func TestProvidersShareStoreByPath(t *testing.T) {
+	dir := t.TempDir()
+	provider1 := newTestProvider(t, dir)
+	provider2 := newTestProvider(t, dir)
+	_, err := provider1.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "instance1", Body: map[string]interface{}{}},
+		Metadata: testMetadata,
+	})
+	assert.Nil(t, err)
+	_, err = provider2.Get(context.Background(), states.GetRequest{ID: "instance1", Metadata: testMetadata})
+	assert.Nil(t, err)
+}
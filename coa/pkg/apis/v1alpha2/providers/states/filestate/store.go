@@ -0,0 +1,300 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package filestate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	states "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
+)
+
+const (
+	snapshotFileName = "state.snapshot"
+	journalFileName  = "state.journal"
+	opUpsert         = "upsert"
+	opDelete         = "delete"
+)
+
+// entryKey identifies an entry. Entries of different resources are kept apart even if they share a name.
+type entryKey struct {
+	Group     string `json:"group,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Namespace string `json:"namespace"`
+	ID        string `json:"id"`
+}
+
+func newEntryKey(metadata map[string]interface{}, id string, defaultNamespace string) entryKey {
+	ret := entryKey{
+		ID:        id,
+		Namespace: defaultNamespace,
+	}
+	if v, ok := metadata["group"].(string); ok {
+		ret.Group = v
+	}
+	if v, ok := metadata["resource"].(string); ok {
+		ret.Resource = v
+	}
+	if v, ok := metadata["namespace"].(string); ok && v != "" {
+		ret.Namespace = v
+	}
+	return ret
+}
+
+// contains checks if a key is in the scope of a list. Empty scope fields match any value.
+func (k entryKey) contains(key entryKey) bool {
+	return (k.Group == "" || k.Group == key.Group) &&
+		(k.Resource == "" || k.Resource == key.Resource) &&
+		(k.Namespace == "" || k.Namespace == key.Namespace)
+}
+
+type journalRecord struct {
+	Op    string             `json:"op"`
+	Key   entryKey           `json:"key"`
+	Entry *states.StateEntry `json:"entry,omitempty"`
+}
+
+type snapshot struct {
+	Records []journalRecord `json:"records"`
+}
+
+// fileStore keeps the entries in memory. A change is first appended to the journal as a line that starts with the
+// CRC32 checksum of the record, and the journal is synced before the change is applied in memory. A torn record at the
+// end of the journal, left by a crash in the middle of a write, fails its checksum and is discarded when the journal
+// is replayed. Compaction writes the entries to a temporary snapshot, renames it over the previous snapshot and then
+// empties the journal. Replaying records that are already in the snapshot is harmless, since a record sets or removes
+// a whole entry.
+type fileStore struct {
+	mu             sync.RWMutex
+	dir            string
+	threshold      int
+	entries        map[entryKey]states.StateEntry
+	journal        *os.File
+	journalSize    int64
+	journalRecords int
+}
+
+func openFileStore(dir string, threshold int) (*fileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	store := &fileStore{
+		dir:       dir,
+		threshold: threshold,
+		entries:   make(map[entryKey]states.StateEntry),
+	}
+	if err := store.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := store.replayJournal(); err != nil {
+		return nil, err
+	}
+	if store.journalRecords >= store.threshold {
+		if err := store.compact(); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// apply persists a record and applies it to the entries. It must be called with the lock held.
+func (f *fileStore) apply(record journalRecord) error {
+	line, err := encodeRecord(record)
+	if err != nil {
+		return err
+	}
+	if _, err = f.journal.Write(line); err != nil {
+		// drop a partial write, so that the records appended after it are not discarded on replay
+		if truncErr := f.journal.Truncate(f.journalSize); truncErr == nil {
+			f.journal.Seek(f.journalSize, io.SeekStart)
+		}
+		return err
+	}
+	if err = f.journal.Sync(); err != nil {
+		return err
+	}
+	f.journalSize += int64(len(line))
+	f.journalRecords++
+	f.applyInMemory(record)
+	if f.journalRecords >= f.threshold {
+		if err = f.compact(); err != nil {
+			// the change is already persisted in the journal, so it isn't lost
+			sLog.Errorf("  P (File State): failed to compact state files in %s: %+v", f.dir, err)
+		}
+	}
+	return nil
+}
+
+func (f *fileStore) applyInMemory(record journalRecord) {
+	switch record.Op {
+	case opUpsert:
+		if record.Entry != nil {
+			f.entries[record.Key] = *record.Entry
+		}
+	case opDelete:
+		delete(f.entries, record.Key)
+	}
+}
+
+// compact must be called with the lock held
+func (f *fileStore) compact() error {
+	data := snapshot{
+		Records: make([]journalRecord, 0, len(f.entries)),
+	}
+	for key, entry := range f.entries {
+		e := entry
+		data.Records = append(data.Records, journalRecord{Op: opUpsert, Key: key, Entry: &e})
+	}
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	tmpPath := filepath.Join(f.dir, snapshotFileName+".tmp")
+	if err = writeFileSync(tmpPath, content); err != nil {
+		return err
+	}
+	if err = os.Rename(tmpPath, filepath.Join(f.dir, snapshotFileName)); err != nil {
+		return err
+	}
+	if err = syncDir(f.dir); err != nil {
+		return err
+	}
+	if err = f.journal.Truncate(0); err != nil {
+		return err
+	}
+	if _, err = f.journal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err = f.journal.Sync(); err != nil {
+		return err
+	}
+	f.journalSize = 0
+	f.journalRecords = 0
+	return nil
+}
+
+func (f *fileStore) loadSnapshot() error {
+	content, err := os.ReadFile(filepath.Join(f.dir, snapshotFileName))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var data snapshot
+	if err = json.Unmarshal(content, &data); err != nil {
+		return fmt.Errorf("snapshot is corrupted: %w", err)
+	}
+	for _, record := range data.Records {
+		f.applyInMemory(record)
+	}
+	return nil
+}
+
+// replayJournal applies the records of the journal and truncates it after the last valid record
+func (f *fileStore) replayJournal() error {
+	journal, err := os.OpenFile(filepath.Join(f.dir, journalFileName), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReader(journal)
+	var offset int64
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr == io.EOF {
+			// a record without its line end is torn
+			break
+		}
+		if readErr != nil {
+			journal.Close()
+			return readErr
+		}
+		record, decodeErr := decodeRecord(line)
+		if decodeErr != nil {
+			sLog.Errorf("  P (File State): discarding journal of %s after offset %d: %+v", f.dir, offset, decodeErr)
+			break
+		}
+		f.applyInMemory(record)
+		f.journalRecords++
+		offset += int64(len(line))
+	}
+	if err = journal.Truncate(offset); err != nil {
+		journal.Close()
+		return err
+	}
+	if _, err = journal.Seek(offset, io.SeekStart); err != nil {
+		journal.Close()
+		return err
+	}
+	f.journal = journal
+	f.journalSize = offset
+	return nil
+}
+
+func encodeRecord(record journalRecord) ([]byte, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	line := make([]byte, 0, len(data)+10)
+	line = append(line, fmt.Sprintf("%08x ", crc32.ChecksumIEEE(data))...)
+	line = append(line, data...)
+	line = append(line, '\n')
+	return line, nil
+}
+
+func decodeRecord(line []byte) (journalRecord, error) {
+	var record journalRecord
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	if len(line) < 10 || line[8] != ' ' {
+		return record, fmt.Errorf("record is malformed")
+	}
+	checksum, err := strconv.ParseUint(string(line[:8]), 16, 32)
+	if err != nil {
+		return record, fmt.Errorf("record checksum is malformed")
+	}
+	data := line[9:]
+	if crc32.ChecksumIEEE(data) != uint32(checksum) {
+		return record, fmt.Errorf("record checksum doesn't match")
+	}
+	err = json.Unmarshal(data, &record)
+	return record, err
+}
+
+func writeFileSync(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// syncDir makes a rename in the directory durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
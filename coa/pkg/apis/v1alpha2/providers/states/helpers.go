@@ -7,8 +7,10 @@
 package states
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
@@ -190,3 +192,187 @@ func traceDownField(entity map[string]interface{}, filter string) (map[string]in
 		return nil, filter, v1alpha2.NewCOAError(nil, fmt.Sprintf("filter '%s' is not a valid selector", filter), v1alpha2.BadConfig)
 	}
 }
+
+// MatchWatchScope checks if the metadata of a change is in the scope of a watch. Group, resource and namespace are
+// only compared when they are set in the watch request.
+func MatchWatchScope(request WatchRequest, metadata map[string]interface{}) bool {
+	for _, key := range []string{"group", "resource", "namespace"} {
+		want, _ := request.Metadata[key].(string)
+		if want == "" {
+			continue
+		}
+		got, _ := metadata[key].(string)
+		if key == "namespace" && got == "" {
+			got = "default"
+		}
+		if got != want {
+			return false
+		}
+	}
+	return true
+}
+
+// PageEntries sorts the entries by the sort key of the options and returns the selected page, together with the
+// continuation token of the next page, which is empty for the last page. Ties are broken by ID.
+func PageEntries(entries []StateEntry, options PageOptions) ([]StateEntry, string, error) {
+	keys, err := sortEntries(entries, options.SortBy)
+	if err != nil {
+		return nil, "", err
+	}
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	start, end, next, err := PageBounds(options, ids, keys)
+	if err != nil {
+		return nil, "", err
+	}
+	return entries[start:end], next, nil
+}
+
+// SortEntries sorts entries in place by a dotted path into their bodies, or by ID if the path is empty
+func SortEntries(entries []StateEntry, sortBy string) error {
+	_, err := sortEntries(entries, sortBy)
+	return err
+}
+
+// sortEntries sorts entries in place and returns their sort keys in the same order. The keys are nil when entries are
+// sorted by ID.
+func sortEntries(entries []StateEntry, sortBy string) ([]interface{}, error) {
+	descending := strings.HasPrefix(sortBy, "-")
+	path := strings.TrimPrefix(sortBy, "-")
+	if path == "" {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return (entries[i].ID < entries[j].ID) != descending
+		})
+		return nil, nil
+	}
+	keys := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		var dict map[string]interface{}
+		j, _ := json.Marshal(entry.Body)
+		if err := json.Unmarshal(j, &dict); err != nil {
+			return nil, v1alpha2.NewCOAError(nil, "failed to unmarshal state entry when sorting", v1alpha2.InternalError)
+		}
+		keys[i] = readPath(dict, strings.Split(path, "."))
+	}
+	index := make([]int, len(entries))
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(a, b int) bool {
+		c := compareSortKeys(keys[index[a]], keys[index[b]])
+		if c == 0 {
+			return entries[index[a]].ID < entries[index[b]].ID
+		}
+		return (c < 0) != descending
+	})
+	sorted := make([]StateEntry, len(entries))
+	sortedKeys := make([]interface{}, len(entries))
+	for i, k := range index {
+		sorted[i] = entries[k]
+		sortedKeys[i] = keys[k]
+	}
+	copy(entries, sorted)
+	return sortedKeys, nil
+}
+
+// pageToken is the position of the last entry of a page, which is where the next page starts
+type pageToken struct {
+	ID  string      `json:"id"`
+	Key interface{} `json:"key,omitempty"`
+}
+
+// PageBounds returns the bounds of the page selected by the options in a list sorted by the sort key of the options,
+// and the continuation token of the next page. ids are the IDs of the entries of the list and keys are their sort
+// keys, which may be nil when the list is sorted by ID. The token holds the position of the last entry of the page
+// rather than an offset, so the next page starts after that entry even if entries are added or removed before it.
+func PageBounds(options PageOptions, ids []string, keys []interface{}) (int, int, string, error) {
+	if options.PageSize < 0 {
+		return 0, 0, "", v1alpha2.NewCOAError(nil, fmt.Sprintf("page size %d is not valid", options.PageSize), v1alpha2.BadRequest)
+	}
+	total := len(ids)
+	keyAt := func(i int) interface{} {
+		if keys == nil {
+			return nil
+		}
+		return keys[i]
+	}
+	start := 0
+	if options.ContinuationToken != "" {
+		var token pageToken
+		data, err := base64.RawURLEncoding.DecodeString(options.ContinuationToken)
+		if err == nil {
+			err = json.Unmarshal(data, &token)
+		}
+		if err != nil || token.ID == "" {
+			return 0, 0, "", v1alpha2.NewCOAError(nil, fmt.Sprintf("continuation token '%s' is not valid", options.ContinuationToken), v1alpha2.BadRequest)
+		}
+		descending := strings.HasPrefix(options.SortBy, "-")
+		byID := strings.TrimPrefix(options.SortBy, "-") == ""
+		start = sort.Search(total, func(i int) bool {
+			if c := compareSortKeys(keyAt(i), token.Key); c != 0 {
+				return (c > 0) != descending
+			}
+			if byID {
+				return (ids[i] > token.ID) != descending
+			}
+			return ids[i] > token.ID
+		})
+	}
+	end := total
+	if options.PageSize > 0 && int64(total-start) > options.PageSize {
+		end = start + int(options.PageSize)
+	}
+	next := ""
+	if end < total {
+		data, _ := json.Marshal(pageToken{ID: ids[end-1], Key: keyAt(end - 1)})
+		next = base64.RawURLEncoding.EncodeToString(data)
+	}
+	return start, end, next, nil
+}
+
+func readPath(dict map[string]interface{}, path []string) interface{} {
+	v, ok := dict[path[0]]
+	if !ok || len(path) == 1 {
+		return v
+	}
+	if child, ok := v.(map[string]interface{}); ok {
+		return readPath(child, path[1:])
+	}
+	return nil
+}
+
+// compareSortKeys orders missing values first, then numbers and strings by value and anything else by its JSON
+func compareSortKeys(a interface{}, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	if fa, ok := a.(float64); ok {
+		if fb, ok := b.(float64); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	sa, ok := a.(string)
+	if !ok {
+		j, _ := json.Marshal(a)
+		sa = string(j)
+	}
+	sb, ok := b.(string)
+	if !ok {
+		j, _ := json.Marshal(b)
+		sb = string(j)
+	}
+	return strings.Compare(sa, sb)
+}
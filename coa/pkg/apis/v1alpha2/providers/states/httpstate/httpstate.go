@@ -144,6 +144,12 @@ func (s *HttpStateProvider) Upsert(ctx context.Context, entry states.UpsertReque
 		sLog.ErrorfCtx(ctx, " P (Http State): failed to upsert state: %+v", err)
 		return "", err
 	}
+	// the HTTP state store doesn't return etags, so a write that is conditional on an etag can't be checked
+	if entry.ETag != nil {
+		err = v1alpha2.NewCOAError(nil, "HTTP state provider doesn't support etags", v1alpha2.NotImplemented)
+		sLog.ErrorfCtx(ctx, " P (Http State): failed to upsert state: %+v", err)
+		return "", err
+	}
 	if s.Config.PostNameInPath {
 		rUrl, err = url.JoinPath(s.Config.Url, entry.Value.ID)
 	}
@@ -179,8 +185,122 @@ func (s *HttpStateProvider) Upsert(ctx context.Context, entry states.UpsertReque
 	return entry.Value.ID, nil
 }
 
+// httpListResponse is the paged form of a list response. A store can also respond with a plain array of entries, in
+// which case the entries are filtered, sorted and paged by the provider.
+type httpListResponse struct {
+	Items             []interface{} `json:"items"`
+	ContinuationToken string        `json:"continuationToken,omitempty"`
+}
+
+// List sends a GET request to the store url with the page options as the pageSize, continuationToken and sortBy
+// query parameters. Entries are unwrapped with the post body key and value names when they are configured, otherwise
+// the ID of an entry is read from its id or metadata.name property.
 func (s *HttpStateProvider) List(ctx context.Context, request states.ListRequest) ([]states.StateEntry, string, error) {
-	return nil, "", v1alpha2.NewCOAError(nil, "Http state store list is not implemented", v1alpha2.NotImplemented)
+	ctx, span := observability.StartSpan("Http State Provider", ctx, &map[string]string{
+		"method": "List",
+	})
+	var err error = nil
+	defer observ_utils.CloseSpanWithError(span, &err)
+	defer observ_utils.EmitUserDiagnosticsLogs(ctx, &err)
+	sLog.InfoCtx(ctx, "  P (Http State): list states")
+
+	var rUrl *url.URL
+	rUrl, err = url.Parse(s.Config.Url)
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (Http State): failed to parse url %s: %+v", s.Config.Url, err)
+		return nil, "", err
+	}
+	query := rUrl.Query()
+	if request.PageSize > 0 {
+		query.Set("pageSize", strconv.FormatInt(request.PageSize, 10))
+	}
+	if request.ContinuationToken != "" {
+		query.Set("continuationToken", request.ContinuationToken)
+	}
+	if request.SortBy != "" {
+		query.Set("sortBy", request.SortBy)
+	}
+	rUrl.RawQuery = query.Encode()
+
+	client := &http.Client{}
+	var req *http.Request
+	req, err = http.NewRequest("GET", rUrl.String(), nil)
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (Http State): failed to create a list request: %+v", err)
+		return nil, "", err
+	}
+	var resp *http.Response
+	resp, err = client.Do(req)
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (Http State): failed to get response from listing states: %+v", err)
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		sLog.ErrorfCtx(ctx, "  P (Http State): failed to get correct state code: status code %d", resp.StatusCode)
+		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("failed to list from HTTP state store: [%d]", resp.StatusCode), v1alpha2.InternalError)
+		return nil, "", err
+	}
+	var bodyBytes []byte
+	bodyBytes, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (Http State): failed to read response body: %+v", err)
+		return nil, "", err
+	}
+
+	paged := true
+	var list httpListResponse
+	if err = json.Unmarshal(bodyBytes, &list); err != nil {
+		paged = false
+		if err = json.Unmarshal(bodyBytes, &list.Items); err != nil {
+			sLog.ErrorfCtx(ctx, "  P (Http State): failed to unmarshall response body: %+v", err)
+			return nil, "", err
+		}
+	}
+
+	entities := make([]states.StateEntry, 0)
+	for _, item := range list.Items {
+		entry := s.toStateEntry(item)
+		if request.FilterType != "" && request.FilterValue != "" {
+			var match bool
+			match, err = states.MatchFilter(entry, request.FilterType, request.FilterValue)
+			if err != nil {
+				return nil, "", err
+			} else if !match {
+				continue
+			}
+		}
+		entities = append(entities, entry)
+	}
+	if paged {
+		return entities, list.ContinuationToken, nil
+	}
+	var token string
+	entities, token, err = states.PageEntries(entities, request.PageOptions)
+	return entities, token, err
+}
+
+func (s *HttpStateProvider) toStateEntry(item interface{}) states.StateEntry {
+	entry := states.StateEntry{
+		Body: item,
+	}
+	dict, ok := item.(map[string]interface{})
+	if !ok {
+		return entry
+	}
+	if s.Config.PostBodyKeyName != "" && s.Config.PostBodyValueName != "" {
+		if key, ok := dict[s.Config.PostBodyKeyName].(string); ok {
+			entry.ID = key
+			entry.Body = dict[s.Config.PostBodyValueName]
+			return entry
+		}
+	}
+	if id, ok := dict["id"].(string); ok {
+		entry.ID = id
+	} else if metadata, ok := dict["metadata"].(map[string]interface{}); ok {
+		entry.ID, _ = metadata["name"].(string)
+	}
+	return entry
 }
 
 func (s *HttpStateProvider) Delete(ctx context.Context, request states.DeleteRequest) error {
@@ -198,6 +318,11 @@ func (s *HttpStateProvider) Delete(ctx context.Context, request states.DeleteReq
 		sLog.ErrorfCtx(ctx, " P (Http State): failed to delete state: %+v", err)
 		return err
 	}
+	if request.ETag != nil {
+		err := v1alpha2.NewCOAError(nil, "HTTP state provider doesn't support etags", v1alpha2.NotImplemented)
+		sLog.ErrorfCtx(ctx, " P (Http State): failed to delete state: %+v", err)
+		return err
+	}
 	rUrl, err := url.JoinPath(s.Config.Url, request.ID)
 	if err != nil {
 		sLog.ErrorfCtx(ctx, "  P (Http State): failed to form %s request path: %+v", request.ID, err)
@@ -163,6 +163,28 @@ func TestDelete(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestUpsertAndDeleteWithETag(t *testing.T) {
+	provider := HttpStateProvider{}
+	err := provider.Init(HttpStateProviderConfig{
+		Url: "http://localhost:3500/v1.0/state/statestore",
+	})
+	assert.Nil(t, err)
+	etag := "1"
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   "123",
+			Body: TestPayload{Name: "Random name", Value: 12345},
+		},
+		ETag: &etag,
+	})
+	assert.Equal(t, v1alpha2.NotImplemented, err.(v1alpha2.COAError).State)
+	err = provider.Delete(context.Background(), states.DeleteRequest{
+		ID:   "123",
+		ETag: &etag,
+	})
+	assert.Equal(t, v1alpha2.NotImplemented, err.(v1alpha2.COAError).State)
+}
+
 func TestMemoryStateProviderConfigFromMapNil(t *testing.T) {
 	_, err := HttpStateProviderConfigFromMap(nil)
 	assert.NotNil(t, err)
@@ -357,3 +379,59 @@ func TestClone(t *testing.T) {
 	assert.NotNil(t, p)
 	assert.Nil(t, err)
 }
+
+func TestListWithPaging(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var response interface{}
+		if r.URL.Query().Get("pageSize") == "" {
+			// a store that doesn't page returns a plain array of entries
+			response = []map[string]interface{}{
+				{"key": "b", "value": map[string]interface{}{"name": "b"}},
+				{"key": "a", "value": map[string]interface{}{"name": "a"}},
+			}
+		} else {
+			assert.Equal(t, "1", r.URL.Query().Get("pageSize"))
+			assert.Equal(t, "name", r.URL.Query().Get("sortBy"))
+			response = map[string]interface{}{
+				"items": []map[string]interface{}{
+					{"key": "a", "value": map[string]interface{}{"name": "a"}},
+				},
+				"continuationToken": "next",
+			}
+		}
+		jsonResponse, _ := json.Marshal(response)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(jsonResponse)
+	}))
+	defer ts.Close()
+
+	provider := HttpStateProvider{}
+	err := provider.Init(HttpStateProviderConfig{
+		Url:               ts.URL,
+		PostBodyKeyName:   "key",
+		PostBodyValueName: "value",
+	})
+	assert.Nil(t, err)
+
+	entries, token, err := provider.List(context.Background(), states.ListRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, "", token)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "a", entries[0].ID)
+	assert.Equal(t, "b", entries[1].ID)
+
+	entries, token, err = provider.List(context.Background(), states.ListRequest{
+		PageOptions: states.PageOptions{
+			PageSize: 1,
+			SortBy:   "name",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "next", token)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "a", entries[0].ID)
+}
@@ -39,10 +39,21 @@ func MemoryStateProviderConfigFromMap(properties map[string]string) (MemoryState
 }
 
 type MemoryStateProvider struct {
-	Config  MemoryStateProviderConfig
-	Data    map[string]interface{}
-	Context *contexts.ManagerContext
-	mu      sync.RWMutex
+	Config      MemoryStateProviderConfig
+	Data        map[string]interface{}
+	Context     *contexts.ManagerContext
+	mu          sync.RWMutex
+	watchers    map[int]*memoryWatcher
+	nextWatcher int
+	revision    int64
+}
+
+// watchBufferSize is the number of events a watcher can fall behind before it's dropped
+const watchBufferSize = 100
+
+type memoryWatcher struct {
+	request states.WatchRequest
+	events  chan states.WatchEvent
 }
 
 func (s *MemoryStateProvider) ID() string {
@@ -97,14 +108,7 @@ func (s *MemoryStateProvider) Upsert(ctx context.Context, entry states.UpsertReq
 		s.Data[namespace] = map[string]interface{}{}
 	}
 
-	tag := "1"
-	if entry.Value.ETag != "" {
-		var v int64
-		if v, err = strconv.ParseInt(entry.Value.ETag, 10, 64); err == nil {
-			tag = strconv.FormatInt(v+1, 10)
-		}
-	}
-	entry.Value.ETag = tag
+	eventMetadata := watchMetadata(entry.Metadata, namespace)
 
 	list, ok := s.Data[namespace].(map[string]interface{})
 	if !ok {
@@ -112,6 +116,23 @@ func (s *MemoryStateProvider) Upsert(ctx context.Context, entry states.UpsertReq
 		sLog.ErrorfCtx(ctx, "  P (Memory State): failed to upsert %s states: %+v", entry.Value.ID, err)
 		return "", err
 	}
+	// the etag is incremented from the stored entry, so that an entry that is rewritten never gets an etag it had before
+	stored, exists := list[entry.Value.ID].(states.StateEntry)
+	tag := "1"
+	if exists {
+		if v, parseErr := strconv.ParseInt(stored.ETag, 10, 64); parseErr == nil {
+			tag = strconv.FormatInt(v+1, 10)
+		}
+	}
+	entry.Value.ETag = tag
+	// an empty etag only matches an entry that doesn't exist yet
+	if entry.ETag != nil {
+		if !exists && *entry.ETag != "" || exists && stored.ETag != *entry.ETag {
+			err = v1alpha2.NewCOAError(nil, fmt.Sprintf("entry '%s' has changed, its etag is not %s", entry.Value.ID, *entry.ETag), v1alpha2.Conflict)
+			sLog.ErrorfCtx(ctx, "  P (Memory State): failed to upsert %s state: %+v", entry.Value.ID, err)
+			return "", err
+		}
+	}
 	if entry.Options.UpdateStatusOnly {
 		existing, ok := list[entry.Value.ID]
 		if !ok {
@@ -152,7 +173,12 @@ func (s *MemoryStateProvider) Upsert(ctx context.Context, entry states.UpsertReq
 		entry.Value.Body = mapRef
 	}
 
+	eventType := states.WatchEventAdded
+	if _, ok := list[entry.Value.ID]; ok {
+		eventType = states.WatchEventUpdated
+	}
 	list[entry.Value.ID] = entry.Value
+	s.notify(ctx, eventType, entry.Value, eventMetadata)
 
 	return entry.Value.ID, nil
 }
@@ -212,7 +238,13 @@ func (s *MemoryStateProvider) List(ctx context.Context, request states.ListReque
 		}
 	}
 
-	return entities, "", nil
+	var token string
+	entities, token, err = states.PageEntries(entities, request.PageOptions)
+	if err != nil {
+		sLog.ErrorfCtx(ctx, "  P (Memory State): failed to list states: %+v", err)
+		return nil, "", err
+	}
+	return entities, token, nil
 }
 
 func (s *MemoryStateProvider) Delete(ctx context.Context, request states.DeleteRequest) error {
@@ -244,12 +276,26 @@ func (s *MemoryStateProvider) Delete(ctx context.Context, request states.DeleteR
 		sLog.ErrorfCtx(ctx, "  P (Memory State): failed to delete %s: %+v", request.ID, err)
 		return err
 	}
-	if _, ok := list[request.ID]; !ok {
+	existing, ok := list[request.ID]
+	if !ok {
 		err = v1alpha2.NewCOAError(nil, fmt.Sprintf("entry '%s' is not found", request.ID), v1alpha2.NotFound)
 		sLog.ErrorfCtx(ctx, "  P (Memory State): failed to delete %s: %+v", request.ID, err)
 		return err
 	}
+	if request.ETag != nil {
+		// the entry is only deleted if it hasn't changed since it was read with the ETag
+		if entry, ok := existing.(states.StateEntry); ok && entry.ETag != *request.ETag {
+			err = v1alpha2.NewCOAError(nil, fmt.Sprintf("entry '%s' has changed, its etag is not %s", request.ID, *request.ETag), v1alpha2.Conflict)
+			sLog.ErrorfCtx(ctx, "  P (Memory State): failed to delete %s: %+v", request.ID, err)
+			return err
+		}
+	}
 	delete(list, request.ID)
+	deleted, ok := existing.(states.StateEntry)
+	if !ok {
+		deleted = states.StateEntry{ID: request.ID}
+	}
+	s.notify(ctx, states.WatchEventDeleted, deleted, watchMetadata(request.Metadata, namespace))
 
 	return nil
 }
@@ -306,6 +352,79 @@ func (s *MemoryStateProvider) Get(ctx context.Context, request states.GetRequest
 	return states.StateEntry{}, err
 }
 
+// Watch streams the changes made through this provider. Past changes are not kept, so the resource version of the
+// request is ignored. A watcher that falls more than watchBufferSize events behind is dropped and its channel is closed.
+func (s *MemoryStateProvider) Watch(ctx context.Context, request states.WatchRequest) (<-chan states.WatchEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.watchers == nil {
+		s.watchers = make(map[int]*memoryWatcher)
+	}
+	id := s.nextWatcher
+	s.nextWatcher++
+	watcher := &memoryWatcher{
+		request: request,
+		events:  make(chan states.WatchEvent, watchBufferSize),
+	}
+	s.watchers[id] = watcher
+	sLog.DebugfCtx(ctx, "  P (Memory State): start watch %d with metadata %v", id, request.Metadata)
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.removeWatcher(id)
+	}()
+	return watcher.events, nil
+}
+
+// notify sends a change to the watchers in its scope. It must be called with the lock held.
+func (s *MemoryStateProvider) notify(ctx context.Context, eventType states.WatchEventType, entry states.StateEntry, metadata map[string]interface{}) {
+	s.revision++
+	for id, watcher := range s.watchers {
+		if !states.MatchWatchScope(watcher.request, metadata) {
+			continue
+		}
+		copy, err := s.ReturnDeepCopy(entry)
+		if err != nil {
+			sLog.ErrorfCtx(ctx, "  P (Memory State): failed to create a deep copy of entry '%s' for watch %d: %+v", entry.ID, id, err)
+			continue
+		}
+		event := states.WatchEvent{
+			Type:            eventType,
+			Entry:           copy,
+			ResourceVersion: strconv.FormatInt(s.revision, 10),
+			Metadata:        metadata,
+		}
+		select {
+		case watcher.events <- event:
+		default:
+			sLog.ErrorfCtx(ctx, "  P (Memory State): watch %d fell behind and is dropped", id)
+			s.removeWatcher(id)
+		}
+	}
+}
+
+func (s *MemoryStateProvider) removeWatcher(id int) {
+	if watcher, ok := s.watchers[id]; ok {
+		close(watcher.events)
+		delete(s.watchers, id)
+	}
+}
+
+func watchMetadata(metadata map[string]interface{}, namespace string) map[string]interface{} {
+	ret := map[string]interface{}{
+		"namespace": namespace,
+	}
+	for _, key := range []string{"group", "version", "resource", "kind"} {
+		if v, ok := metadata[key]; ok {
+			ret[key] = v
+		}
+	}
+	return ret
+}
+
 func toMemoryStateProviderConfig(config providers.IProviderConfig) (MemoryStateProviderConfig, error) {
 	ret := MemoryStateProviderConfig{}
 	data, err := json.Marshal(config)
@@ -236,6 +236,75 @@ func TestDelete(t *testing.T) {
 	assert.Equal(t, 0, len(entries))
 }
 
+func TestDeleteWithETag(t *testing.T) {
+	provider := MemoryStateProvider{}
+	err := provider.Init(MemoryStateProvider{})
+	assert.Nil(t, err)
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   "123",
+			Body: TestPayload{Name: "Random name"},
+		},
+	})
+	assert.Nil(t, err)
+	entry, err := provider.Get(context.Background(), states.GetRequest{ID: "123"})
+	assert.Nil(t, err)
+
+	stale := entry.ETag + "0"
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "123", ETag: &stale})
+	assert.True(t, v1alpha2.IsConflict(err))
+
+	// an entry that is rewritten without an etag gets the next etag, so the etag that was read doesn't match anymore
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   "123",
+			Body: TestPayload{Name: "Random name"},
+		},
+	})
+	assert.Nil(t, err)
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "123", ETag: &entry.ETag})
+	assert.True(t, v1alpha2.IsConflict(err))
+
+	entry, err = provider.Get(context.Background(), states.GetRequest{ID: "123"})
+	assert.Nil(t, err)
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "123", ETag: &entry.ETag})
+	assert.Nil(t, err)
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "123", ETag: &entry.ETag})
+	assert.True(t, v1alpha2.IsNotFound(err))
+}
+
+func TestUpsertWithETag(t *testing.T) {
+	provider := MemoryStateProvider{}
+	err := provider.Init(MemoryStateProvider{})
+	assert.Nil(t, err)
+	absent := ""
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{ID: "123", Body: TestPayload{Name: "first"}},
+		ETag:  &absent,
+	})
+	assert.Nil(t, err)
+	// an empty etag doesn't match an existing entry
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{ID: "123", Body: TestPayload{Name: "second"}},
+		ETag:  &absent,
+	})
+	assert.True(t, v1alpha2.IsConflict(err))
+
+	entry, err := provider.Get(context.Background(), states.GetRequest{ID: "123"})
+	assert.Nil(t, err)
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{ID: "123", Body: TestPayload{Name: "second"}, ETag: entry.ETag},
+		ETag:  &entry.ETag,
+	})
+	assert.Nil(t, err)
+	// the entry has a new etag after the update
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{ID: "123", Body: TestPayload{Name: "third"}, ETag: entry.ETag},
+		ETag:  &entry.ETag,
+	})
+	assert.True(t, v1alpha2.IsConflict(err))
+}
+
 func TestDeleteWithNamespace(t *testing.T) {
 	provider := MemoryStateProvider{}
 	err := provider.Init(MemoryStateProvider{})
@@ -823,3 +892,234 @@ func TestMultipleLabelsFilter(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, 1, len(entity))
 }
+
+func TestWatch(t *testing.T) {
+	provider := MemoryStateProvider{}
+	err := provider.Init(MemoryStateProviderConfig{})
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := provider.Watch(ctx, states.WatchRequest{
+		Metadata: map[string]interface{}{
+			"namespace": "nondefault",
+			"group":     "solution.symphony",
+			"resource":  "instances",
+		},
+	})
+	assert.Nil(t, err)
+
+	metadata := map[string]interface{}{
+		"namespace": "nondefault",
+		"group":     "solution.symphony",
+		"resource":  "instances",
+	}
+	for i := 0; i < 2; i++ {
+		_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+			Value: states.StateEntry{
+				ID:   "123",
+				Body: TestPayload{Name: "Random name", Value: i},
+			},
+			Metadata: metadata,
+		})
+		assert.Nil(t, err)
+	}
+	// changes out of the scope of the watch are not reported
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   "123",
+			Body: TestPayload{Name: "Random name", Value: 0},
+		},
+		Metadata: map[string]interface{}{
+			"group":    "solution.symphony",
+			"resource": "instances",
+		},
+	})
+	assert.Nil(t, err)
+	err = provider.Delete(context.Background(), states.DeleteRequest{
+		ID:       "123",
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+
+	event := <-events
+	assert.Equal(t, states.WatchEventAdded, event.Type)
+	assert.Equal(t, "123", event.Entry.ID)
+	assert.Equal(t, "1", event.ResourceVersion)
+	assert.Equal(t, "nondefault", event.Metadata["namespace"])
+	event = <-events
+	assert.Equal(t, states.WatchEventUpdated, event.Type)
+	assert.Equal(t, "2", event.ResourceVersion)
+	event = <-events
+	assert.Equal(t, states.WatchEventDeleted, event.Type)
+	assert.Equal(t, "123", event.Entry.ID)
+	assert.Equal(t, "4", event.ResourceVersion)
+
+	cancel()
+	_, ok := <-events
+	assert.False(t, ok)
+}
+
+func TestWatchDropsSlowWatcher(t *testing.T) {
+	provider := MemoryStateProvider{}
+	err := provider.Init(MemoryStateProviderConfig{})
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := provider.Watch(ctx, states.WatchRequest{})
+	assert.Nil(t, err)
+	for i := 0; i <= watchBufferSize; i++ {
+		_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+			Value: states.StateEntry{
+				ID:   "123",
+				Body: TestPayload{Name: "Random name", Value: i},
+			},
+		})
+		assert.Nil(t, err)
+	}
+	count := 0
+	for range events {
+		count++
+	}
+	assert.Equal(t, watchBufferSize, count)
+}
+
+func TestListPaging(t *testing.T) {
+	provider := MemoryStateProvider{}
+	err := provider.Init(MemoryStateProviderConfig{})
+	assert.Nil(t, err)
+	for _, id := range []string{"c", "a", "e", "b", "d"} {
+		_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+			Value: states.StateEntry{
+				ID:   id,
+				Body: TestPayload{Name: id},
+			},
+		})
+		assert.Nil(t, err)
+	}
+	ids := make([]string, 0)
+	token := ""
+	for {
+		entries, next, err := provider.List(context.Background(), states.ListRequest{
+			PageOptions: states.PageOptions{
+				PageSize:          2,
+				ContinuationToken: token,
+			},
+		})
+		assert.Nil(t, err)
+		assert.LessOrEqual(t, len(entries), 2)
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, ids)
+}
+
+func TestListPagingWithChanges(t *testing.T) {
+	provider := MemoryStateProvider{}
+	err := provider.Init(MemoryStateProviderConfig{})
+	assert.Nil(t, err)
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+			Value: states.StateEntry{
+				ID:   id,
+				Body: TestPayload{Name: id},
+			},
+		})
+		assert.Nil(t, err)
+	}
+	entries, token, err := provider.List(context.Background(), states.ListRequest{
+		PageOptions: states.PageOptions{
+			PageSize: 2,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "b", entries[1].ID)
+
+	// removing an entry of the first page and adding one before it don't shift the next page
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "a"})
+	assert.Nil(t, err)
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   "0",
+			Body: TestPayload{Name: "0"},
+		},
+	})
+	assert.Nil(t, err)
+	entries, _, err = provider.List(context.Background(), states.ListRequest{
+		PageOptions: states.PageOptions{
+			PageSize:          2,
+			ContinuationToken: token,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "c", entries[0].ID)
+	assert.Equal(t, "d", entries[1].ID)
+}
+
+func TestListSortBy(t *testing.T) {
+	provider := MemoryStateProvider{}
+	err := provider.Init(MemoryStateProviderConfig{})
+	assert.Nil(t, err)
+	for i, id := range []string{"a", "b", "c"} {
+		_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+			Value: states.StateEntry{
+				ID:   id,
+				Body: TestPayload{Name: id, Value: 10 - i},
+			},
+		})
+		assert.Nil(t, err)
+	}
+	entries, token, err := provider.List(context.Background(), states.ListRequest{
+		PageOptions: states.PageOptions{
+			PageSize: 2,
+			SortBy:   "Value",
+		},
+	})
+	assert.Nil(t, err)
+	assert.NotEqual(t, "", token)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "c", entries[0].ID)
+	assert.Equal(t, "b", entries[1].ID)
+
+	entries, token, err = provider.List(context.Background(), states.ListRequest{
+		PageOptions: states.PageOptions{
+			PageSize:          2,
+			SortBy:            "Value",
+			ContinuationToken: token,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "", token)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "a", entries[0].ID)
+
+	entries, token, err = provider.List(context.Background(), states.ListRequest{
+		PageOptions: states.PageOptions{
+			SortBy: "-Value",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "", token)
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "a", entries[0].ID)
+}
+
+func TestListBadContinuationToken(t *testing.T) {
+	provider := MemoryStateProvider{}
+	err := provider.Init(MemoryStateProviderConfig{})
+	assert.Nil(t, err)
+	_, _, err = provider.List(context.Background(), states.ListRequest{
+		PageOptions: states.PageOptions{
+			ContinuationToken: "abc",
+		},
+	})
+	assert.NotNil(t, err)
+	coaErr, ok := err.(v1alpha2.COAError)
+	assert.True(t, ok)
+	assert.Equal(t, v1alpha2.BadRequest, coaErr.State)
+}
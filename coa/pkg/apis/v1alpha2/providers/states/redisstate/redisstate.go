@@ -11,6 +11,7 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +31,10 @@ var rLog = logger.NewLogger("coa.runtime")
 const (
 	entryCountPerList = 100
 	separator         = "*"
+	// events of an object type are appended to a capped stream, which isn't matched by the key patterns of List
+	eventStreamPrefix = "stateevents:"
+	eventStreamMaxLen = 1000
+	watchBlockTimeout = time.Second
 )
 
 type RedisStateProviderConfig struct {
@@ -147,6 +152,10 @@ func (r *RedisStateProvider) Upsert(ctx context.Context, entry states.UpsertRequ
 	if err != nil {
 		return entry.Value.ID, err
 	}
+	if entry.ETag != nil {
+		err = r.upsertWithETag(ctx, key, entry, body)
+		return entry.Value.ID, err
+	}
 	if entry.Options.UpdateStatusOnly {
 		var existing string
 		existing, err = r.Client.HGet(r.Ctx, key, "values").Result()
@@ -170,17 +179,81 @@ func (r *RedisStateProvider) Upsert(ctx context.Context, entry states.UpsertRequ
 		oldEntryDict["status"] = oldStatusDict
 		body, _ = json.Marshal(oldEntryDict)
 		_, err = r.Client.HSet(r.Ctx, key, "values", string(body)).Result()
+		if err == nil {
+			r.publishEvent(ctx, states.WatchEventUpdated, entry.Value.ID, entry.Metadata, string(body), r.getETag(key))
+		}
 		return entry.Value.ID, err
 	}
 
+	eventType := states.WatchEventAdded
+	if n, existsErr := r.Client.Exists(r.Ctx, key).Result(); existsErr == nil && n > 0 {
+		eventType = states.WatchEventUpdated
+	}
+	// an entry without an etag of its own gets the next etag of the stored entry
+	etag := entry.Value.ETag
+	if etag == "" {
+		etag = nextETag(r.getETag(key))
+	}
 	properties := map[string]interface{}{
 		"values": string(body),
-		"etag":   entry.Value.ETag,
+		"etag":   etag,
 	}
 	_, err = r.Client.HSet(r.Ctx, key, properties).Result()
+	if err == nil {
+		r.publishEvent(ctx, eventType, entry.Value.ID, entry.Metadata, string(body), etag)
+	}
 	return entry.Value.ID, err
 }
 
+// upsertWithETag writes an entry only if the etag of the stored entry is the etag of the request, an empty etag only
+// matches an entry that doesn't exist yet. The key is watched, so a write that lands between the check and the update
+// fails the update with a conflict. The entry gets the next etag of the stored entry.
+func (r *RedisStateProvider) upsertWithETag(ctx context.Context, key string, entry states.UpsertRequest, body []byte) error {
+	if entry.Options.UpdateStatusOnly {
+		return v1alpha2.NewCOAError(nil, "redis state provider doesn't support status updates with an etag", v1alpha2.NotImplemented)
+	}
+	var etag string
+	var eventType states.WatchEventType
+	err := r.Client.Watch(r.Ctx, func(tx *redis.Tx) error {
+		n, err := tx.Exists(r.Ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		stored := ""
+		if n > 0 {
+			if stored, err = tx.HGet(r.Ctx, key, "etag").Result(); err != nil && err != redis.Nil {
+				return err
+			}
+		}
+		if n == 0 && *entry.ETag != "" || n > 0 && stored != *entry.ETag {
+			return v1alpha2.NewCOAError(nil, fmt.Sprintf("entry '%s' has changed, its etag is not %s", entry.Value.ID, *entry.ETag), v1alpha2.Conflict)
+		}
+		eventType = states.WatchEventAdded
+		etag = "1"
+		if n > 0 {
+			eventType = states.WatchEventUpdated
+			etag = nextETag(stored)
+		}
+		_, err = tx.TxPipelined(r.Ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(r.Ctx, key, map[string]interface{}{
+				"values": string(body),
+				"etag":   etag,
+			})
+			return nil
+		})
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' has changed, its etag is not %s", entry.Value.ID, *entry.ETag), v1alpha2.Conflict)
+	}
+	if err != nil {
+		rLog.ErrorfCtx(ctx, "  P (Redis State): failed to upsert %s state: %+v", entry.Value.ID, err)
+		return err
+	}
+	r.publishEvent(ctx, eventType, entry.Value.ID, entry.Metadata, string(body), etag)
+	return nil
+}
+
 func (r *RedisStateProvider) List(ctx context.Context, request states.ListRequest) ([]states.StateEntry, string, error) {
 	ctx, span := observability.StartSpan("Redis State Provider", ctx, &map[string]string{
 		"method": "List",
@@ -209,47 +282,88 @@ func (r *RedisStateProvider) List(ctx context.Context, request states.ListReques
 	var keys []string
 
 	for {
-		var err error
-		keys, cursor, err = r.Client.Scan(r.Ctx, cursor, filter, entryCountPerList).Result()
+		var page []string
+		page, cursor, err = r.Client.Scan(r.Ctx, cursor, filter, entryCountPerList).Result()
 		if err != nil {
 			rLog.Errorf("  P (Redis State): failed to get all the keys matching pattern %s: %+v", keyPrefix, err)
 		}
-
-		for _, key := range keys {
-			result, err := r.Client.HGetAll(r.Ctx, key).Result()
-			if err != nil || len(result) == 0 {
-				rLog.Errorf("  P (Redis State): failed to get entry for key %s: %+v", key, err)
+		for _, key := range page {
+			if len(strings.Split(key, separator)) != 3 {
+				rLog.Errorf("  P (Redis State): key is not valid %s", key)
 				continue
 			}
-			parts := strings.Split(key, separator)
-			if len(parts) != 3 {
-				rLog.Errorf("  P (Redis State): key is not valid %s: %+v", key, err)
-				continue
+			keys = append(keys, key)
+		}
+		if cursor == 0 {
+			break
+		}
+	}
+
+	// without a filter or a sort key, the page can be selected from the keys, so only the entries of the page are read
+	pageByKeys := request.SortBy == "" && (request.FilterType == "" || request.FilterValue == "")
+	if pageByKeys {
+		sort.Slice(keys, func(i, j int) bool {
+			idI, idJ := keyID(keys[i]), keyID(keys[j])
+			if idI == idJ {
+				return keys[i] < keys[j]
 			}
-			entry, err := CastRedisPropertiesToStateEntry(parts[2], result)
+			return idI < idJ
+		})
+		ids := make([]string, len(keys))
+		for i, key := range keys {
+			ids[i] = keyID(key)
+		}
+		var start, end int
+		var token string
+		start, end, token, err = states.PageBounds(request.PageOptions, ids, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		entities, err = r.readEntries(keys[start:end], request)
+		return entities, token, err
+	}
+
+	entities, err = r.readEntries(keys, request)
+	if err != nil {
+		return entities, "", err
+	}
+	var token string
+	entities, token, err = states.PageEntries(entities, request.PageOptions)
+	return entities, token, err
+}
+
+// readEntries reads the entries of the keys that match the filter of the request
+func (r *RedisStateProvider) readEntries(keys []string, request states.ListRequest) ([]states.StateEntry, error) {
+	var entities []states.StateEntry
+	for _, key := range keys {
+		result, err := r.Client.HGetAll(r.Ctx, key).Result()
+		if err != nil || len(result) == 0 {
+			rLog.Errorf("  P (Redis State): failed to get entry for key %s: %+v", key, err)
+			continue
+		}
+		entry, err := CastRedisPropertiesToStateEntry(keyID(key), result)
+		if err != nil {
+			rLog.Errorf("  P (Redis State): failed to cast entry for key %s: %+v", key, err)
+			continue
+		}
+		if request.FilterType != "" && request.FilterValue != "" {
+			var match bool
+			match, err = states.MatchFilter(entry, request.FilterType, request.FilterValue)
 			if err != nil {
-				rLog.Errorf("  P (Redis State): failed to cast entry for key %s: %+v", key, err)
+				return entities, err
+			} else if !match {
 				continue
 			}
-			if request.FilterType != "" && request.FilterValue != "" {
-				var match bool
-				match, err = states.MatchFilter(entry, request.FilterType, request.FilterValue)
-				if err != nil {
-					return entities, "", err
-				} else if !match {
-					continue
-				}
-			}
-
-			entities = append(entities, entry)
 		}
 
-		if cursor == 0 {
-			break
-		}
+		entities = append(entities, entry)
 	}
+	return entities, nil
+}
 
-	return entities, "", nil
+func keyID(key string) string {
+	parts := strings.Split(key, separator)
+	return parts[len(parts)-1]
 }
 
 func (r *RedisStateProvider) Delete(ctx context.Context, request states.DeleteRequest) error {
@@ -269,7 +383,50 @@ func (r *RedisStateProvider) Delete(ctx context.Context, request states.DeleteRe
 	rLog.DebugfCtx(ctx, "  P (Redis State): delete state %s with keyPrefix %s", request.ID, keyPrefix)
 
 	HKey := fmt.Sprintf("%s%s%s", keyPrefix, separator, request.ID)
-	_, err = r.Client.Del(r.Ctx, HKey).Result()
+	if request.ETag != nil {
+		err = r.deleteWithETag(ctx, HKey, request)
+		return err
+	}
+	var deleted int64
+	deleted, err = r.Client.Del(r.Ctx, HKey).Result()
+	if err == nil && deleted > 0 {
+		r.publishEvent(ctx, states.WatchEventDeleted, request.ID, request.Metadata, "", "")
+	}
+	return nil
+}
+
+// deleteWithETag deletes an entry only if it hasn't changed since it was read with the etag of the request. The key is
+// watched, so a write that lands between the check and the delete fails the delete with a conflict.
+func (r *RedisStateProvider) deleteWithETag(ctx context.Context, key string, request states.DeleteRequest) error {
+	err := r.Client.Watch(r.Ctx, func(tx *redis.Tx) error {
+		n, err := tx.Exists(r.Ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return v1alpha2.NewCOAError(nil, fmt.Sprintf("entry '%s' is not found", request.ID), v1alpha2.NotFound)
+		}
+		stored, err := tx.HGet(r.Ctx, key, "etag").Result()
+		if err != nil && err != redis.Nil {
+			return err
+		}
+		if stored != *request.ETag {
+			return v1alpha2.NewCOAError(nil, fmt.Sprintf("entry '%s' has changed, its etag is not %s", request.ID, *request.ETag), v1alpha2.Conflict)
+		}
+		_, err = tx.TxPipelined(r.Ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(r.Ctx, key)
+			return nil
+		})
+		return err
+	}, key)
+	if err == redis.TxFailedErr {
+		err = v1alpha2.NewCOAError(err, fmt.Sprintf("entry '%s' has changed, its etag is not %s", request.ID, *request.ETag), v1alpha2.Conflict)
+	}
+	if err != nil {
+		rLog.ErrorfCtx(ctx, "  P (Redis State): failed to delete %s: %+v", request.ID, err)
+		return err
+	}
+	r.publishEvent(ctx, states.WatchEventDeleted, request.ID, request.Metadata, "", "")
 	return nil
 }
 
@@ -303,6 +460,192 @@ func (r *RedisStateProvider) Get(ctx context.Context, request states.GetRequest)
 	return CastRedisPropertiesToStateEntry(request.ID, data)
 }
 
+// Watch streams the changes of an object type from its event stream. The resource version of an event is its stream
+// entry ID, which can be used to resume the watch as long as the entry hasn't been trimmed from the stream.
+func (r *RedisStateProvider) Watch(ctx context.Context, request states.WatchRequest) (<-chan states.WatchEvent, error) {
+	objectType, err := getObjectTypePrefixForList(request.Metadata)
+	if err != nil {
+		rLog.ErrorfCtx(ctx, "  P (Redis State): watch states failed to get object type with error %s", err.Error())
+		return nil, err
+	}
+	streamKey := eventStreamPrefix + objectType
+
+	// resolve the start position now, so that changes made after Watch returns are not missed
+	lastID := request.ResourceVersion
+	if lastID != "" {
+		err = r.checkResumeVersion(streamKey, lastID)
+		if err != nil {
+			rLog.ErrorfCtx(ctx, "  P (Redis State): failed to resume watch of stream %s: %+v", streamKey, err)
+			return nil, err
+		}
+	} else {
+		lastID = "0-0"
+		latest, err := r.Client.XRevRangeN(r.Ctx, streamKey, "+", "-", 1).Result()
+		if err != nil {
+			rLog.ErrorfCtx(ctx, "  P (Redis State): failed to read event stream %s: %+v", streamKey, err)
+			return nil, v1alpha2.NewCOAError(err, fmt.Sprintf("failed to read event stream %s", streamKey), v1alpha2.InternalError)
+		}
+		if len(latest) > 0 {
+			lastID = latest[0].ID
+		}
+	}
+	rLog.DebugfCtx(ctx, "  P (Redis State): watch stream %s after %s", streamKey, lastID)
+
+	events := make(chan states.WatchEvent)
+	go func() {
+		defer close(events)
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			streams, err := r.Client.XRead(ctx, &redis.XReadArgs{
+				Streams: []string{streamKey, lastID},
+				Count:   entryCountPerList,
+				Block:   watchBlockTimeout,
+			}).Result()
+			if err == redis.Nil {
+				continue
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					rLog.ErrorfCtx(ctx, "  P (Redis State): failed to watch stream %s: %+v", streamKey, err)
+				}
+				return
+			}
+			for _, stream := range streams {
+				for _, message := range stream.Messages {
+					lastID = message.ID
+					event, err := castStreamMessageToWatchEvent(message)
+					if err != nil {
+						rLog.ErrorfCtx(ctx, "  P (Redis State): failed to cast event %s: %+v", message.ID, err)
+						continue
+					}
+					if !states.MatchWatchScope(request, event.Metadata) {
+						continue
+					}
+					select {
+					case events <- event:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+	return events, nil
+}
+
+// checkResumeVersion checks that no event after the resource version has been trimmed from the capped event stream. The
+// stream only keeps its latest events, so a watch can only resume from an event that is still in the stream.
+func (r *RedisStateProvider) checkResumeVersion(streamKey string, resourceVersion string) error {
+	version, ok := parseStreamID(resourceVersion)
+	if !ok {
+		return v1alpha2.NewCOAError(nil, fmt.Sprintf("resource version %s is not valid", resourceVersion), v1alpha2.BadRequest)
+	}
+	oldest, err := r.Client.XRangeN(r.Ctx, streamKey, "-", "+", 1).Result()
+	if err != nil {
+		return v1alpha2.NewCOAError(err, fmt.Sprintf("failed to read event stream %s", streamKey), v1alpha2.InternalError)
+	}
+	if len(oldest) == 0 {
+		return v1alpha2.NewCOAError(nil, fmt.Sprintf("resource version %s is too old, list the entries and watch again", resourceVersion), v1alpha2.Conflict)
+	}
+	first, _ := parseStreamID(oldest[0].ID)
+	if first[0] > version[0] || first[0] == version[0] && first[1] > version[1] {
+		return v1alpha2.NewCOAError(nil, fmt.Sprintf("resource version %s is too old, list the entries and watch again", resourceVersion), v1alpha2.Conflict)
+	}
+	return nil
+}
+
+// parseStreamID parses the milliseconds and the sequence number of a stream entry ID such as "1526919030474-55"
+func parseStreamID(id string) ([2]uint64, bool) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 {
+		return [2]uint64{}, false
+	}
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return [2]uint64{}, false
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return [2]uint64{}, false
+	}
+	return [2]uint64{ms, seq}, true
+}
+
+// publishEvent appends a change to the event stream of its object type. A failure is logged but doesn't fail the change.
+func (r *RedisStateProvider) publishEvent(ctx context.Context, eventType states.WatchEventType, id string, metadata map[string]interface{}, values string, etag string) {
+	objectType, err := getObjectTypePrefixForList(metadata)
+	if err != nil {
+		return
+	}
+	namespace := "default"
+	if n, ok := metadata["namespace"].(string); ok && n != "" {
+		namespace = n
+	}
+	group, _ := metadata["group"].(string)
+	resource, _ := metadata["resource"].(string)
+	err = r.Client.XAdd(r.Ctx, &redis.XAddArgs{
+		Stream: eventStreamPrefix + objectType,
+		MaxLen: eventStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"type":      string(eventType),
+			"id":        id,
+			"namespace": namespace,
+			"group":     group,
+			"resource":  resource,
+			"values":    values,
+			"etag":      etag,
+		},
+	}).Err()
+	if err != nil {
+		rLog.ErrorfCtx(ctx, "  P (Redis State): failed to publish %s event of state %s: %+v", eventType, id, err)
+	}
+}
+
+func (r *RedisStateProvider) getETag(key string) string {
+	etag, _ := r.Client.HGet(r.Ctx, key, "etag").Result()
+	return etag
+}
+
+// nextETag returns the etag that follows the etag of a stored entry, an entry that isn't stored yet gets "1"
+func nextETag(stored string) string {
+	if v, err := strconv.ParseInt(stored, 10, 64); err == nil {
+		return strconv.FormatInt(v+1, 10)
+	}
+	return "1"
+}
+
+func castStreamMessageToWatchEvent(message redis.XMessage) (states.WatchEvent, error) {
+	fields := make(map[string]string)
+	for k, v := range message.Values {
+		if s, ok := v.(string); ok {
+			fields[k] = s
+		}
+	}
+	event := states.WatchEvent{
+		Type:            states.WatchEventType(fields["type"]),
+		ResourceVersion: message.ID,
+		Metadata: map[string]interface{}{
+			"namespace": fields["namespace"],
+			"group":     fields["group"],
+			"resource":  fields["resource"],
+		},
+		Entry: states.StateEntry{
+			ID: fields["id"],
+		},
+	}
+	if fields["values"] != "" {
+		entry, err := CastRedisPropertiesToStateEntry(fields["id"], fields)
+		if err != nil {
+			return event, err
+		}
+		event.Entry = entry
+	}
+	return event, nil
+}
+
 func toRedisStateProviderConfig(config providers.IProviderConfig) (RedisStateProviderConfig, error) {
 	ret := RedisStateProviderConfig{}
 	data, err := json.Marshal(config)
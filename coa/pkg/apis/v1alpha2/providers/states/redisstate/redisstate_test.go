@@ -12,6 +12,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	states "github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2/providers/states"
 	"github.com/stretchr/testify/assert"
@@ -72,6 +73,19 @@ func initializeProvider(t *testing.T) RedisStateProvider {
 	return provider
 }
 
+// initializeMiniredisProvider returns a provider backed by an in-process redis server, for the tests that don't need a
+// real redis
+func initializeMiniredisProvider(t *testing.T) RedisStateProvider {
+	server := miniredis.RunT(t)
+	provider := RedisStateProvider{}
+	err := provider.Init(RedisStateProviderConfig{
+		Name: "test",
+		Host: server.Addr(),
+	})
+	assert.Nil(t, err)
+	return provider
+}
+
 func TestUpsertGetListAndDelete(t *testing.T) {
 	provider := initializeProvider(t)
 	id, err := provider.Upsert(context.Background(), states.UpsertRequest{
@@ -879,3 +893,243 @@ func TestMultipleLabelsFilter(t *testing.T) {
 	})
 	assert.Nil(t, err)
 }
+
+func TestWatch(t *testing.T) {
+	provider := initializeMiniredisProvider(t)
+	metadata := map[string]interface{}{
+		"namespace": "nondefault",
+		"group":     "solution.symphony",
+		"resource":  "instances",
+	}
+	// changes made before the watch starts are not reported
+	_, err := provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   "old",
+			Body: TestPayload{Name: "Random name", Value: 0},
+		},
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := provider.Watch(ctx, states.WatchRequest{
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+			Value: states.StateEntry{
+				ID:   "123",
+				Body: TestPayload{Name: "Random name", Value: i},
+			},
+			Metadata: metadata,
+		})
+		assert.Nil(t, err)
+	}
+	// changes out of the scope of the watch are not reported
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value: states.StateEntry{
+			ID:   "123",
+			Body: TestPayload{Name: "Random name", Value: 0},
+		},
+		Metadata: map[string]interface{}{
+			"group":    "solution.symphony",
+			"resource": "instances",
+		},
+	})
+	assert.Nil(t, err)
+	err = provider.Delete(context.Background(), states.DeleteRequest{
+		ID:       "123",
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+
+	event := <-events
+	assert.Equal(t, states.WatchEventAdded, event.Type)
+	assert.Equal(t, "123", event.Entry.ID)
+	assert.Equal(t, "nondefault", event.Metadata["namespace"])
+	var payload TestPayload
+	data, _ := json.Marshal(event.Entry.Body)
+	err = json.Unmarshal(data, &payload)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, payload.Value)
+	added := event.ResourceVersion
+	event = <-events
+	assert.Equal(t, states.WatchEventUpdated, event.Type)
+	data, _ = json.Marshal(event.Entry.Body)
+	err = json.Unmarshal(data, &payload)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, payload.Value)
+	event = <-events
+	assert.Equal(t, states.WatchEventDeleted, event.Type)
+	assert.Equal(t, "123", event.Entry.ID)
+
+	cancel()
+	for range events {
+	}
+
+	// a watch resumes after the resource version of an event
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events, err = provider.Watch(ctx, states.WatchRequest{
+		Metadata:        metadata,
+		ResourceVersion: added,
+	})
+	assert.Nil(t, err)
+	event = <-events
+	assert.Equal(t, states.WatchEventUpdated, event.Type)
+	event = <-events
+	assert.Equal(t, states.WatchEventDeleted, event.Type)
+}
+
+func TestWatchWithoutObjectType(t *testing.T) {
+	provider := initializeMiniredisProvider(t)
+	_, err := provider.Watch(context.Background(), states.WatchRequest{})
+	assert.NotNil(t, err)
+	coaErr, ok := err.(v1alpha2.COAError)
+	assert.True(t, ok)
+	assert.Equal(t, v1alpha2.BadConfig, coaErr.State)
+}
+
+func TestUpsertWithETag(t *testing.T) {
+	provider := initializeMiniredisProvider(t)
+	metadata := map[string]interface{}{
+		"resource": "testresource",
+		"group":    "testgroup",
+	}
+	absent := ""
+	_, err := provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "123", Body: TestPayload{Name: "a", Value: 1}},
+		ETag:     &absent,
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+	entry, err := provider.Get(context.Background(), states.GetRequest{ID: "123", Metadata: metadata})
+	assert.Nil(t, err)
+	assert.Equal(t, "1", entry.ETag)
+
+	// an empty etag doesn't match an existing entry
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "123", Body: TestPayload{Name: "b", Value: 2}},
+		ETag:     &absent,
+		Metadata: metadata,
+	})
+	assert.True(t, v1alpha2.IsConflict(err))
+
+	stale := "1"
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "123", Body: TestPayload{Name: "b", Value: 2}},
+		ETag:     &stale,
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "123", Body: TestPayload{Name: "c", Value: 3}},
+		ETag:     &stale,
+		Metadata: metadata,
+	})
+	assert.True(t, v1alpha2.IsConflict(err))
+	entry, err = provider.Get(context.Background(), states.GetRequest{ID: "123", Metadata: metadata})
+	assert.Nil(t, err)
+	assert.Equal(t, "2", entry.ETag)
+	assert.Equal(t, "b", entry.Body.(map[string]interface{})["name"])
+
+	// a write without an etag of its own gets the next etag, so an etag that was read before never matches again
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "123", Body: TestPayload{Name: "d", Value: 4}},
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+	entry, err = provider.Get(context.Background(), states.GetRequest{ID: "123", Metadata: metadata})
+	assert.Nil(t, err)
+	assert.Equal(t, "3", entry.ETag)
+}
+
+func TestDeleteWithETag(t *testing.T) {
+	provider := initializeMiniredisProvider(t)
+	metadata := map[string]interface{}{
+		"resource": "testresource",
+		"group":    "testgroup",
+	}
+	_, err := provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "123", Body: TestPayload{Name: "a", Value: 1}},
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+	entry, err := provider.Get(context.Background(), states.GetRequest{ID: "123", Metadata: metadata})
+	assert.Nil(t, err)
+	etag := entry.ETag
+
+	// the entry is rewritten after it was read, so the delete with the etag that was read fails
+	_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+		Value:    states.StateEntry{ID: "123", Body: TestPayload{Name: "a", Value: 1}},
+		Metadata: metadata,
+	})
+	assert.Nil(t, err)
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "123", ETag: &etag, Metadata: metadata})
+	assert.True(t, v1alpha2.IsConflict(err))
+
+	entry, err = provider.Get(context.Background(), states.GetRequest{ID: "123", Metadata: metadata})
+	assert.Nil(t, err)
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "123", ETag: &entry.ETag, Metadata: metadata})
+	assert.Nil(t, err)
+	_, err = provider.Get(context.Background(), states.GetRequest{ID: "123", Metadata: metadata})
+	assert.NotNil(t, err)
+
+	err = provider.Delete(context.Background(), states.DeleteRequest{ID: "123", ETag: &entry.ETag, Metadata: metadata})
+	assert.True(t, v1alpha2.IsNotFound(err))
+}
+
+func TestWatchFromTrimmedVersion(t *testing.T) {
+	provider := initializeMiniredisProvider(t)
+	metadata := map[string]interface{}{
+		"resource": "testresource",
+		"group":    "testgroup",
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := provider.Watch(ctx, states.WatchRequest{Metadata: metadata})
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		_, err = provider.Upsert(context.Background(), states.UpsertRequest{
+			Value:    states.StateEntry{ID: "123", Body: TestPayload{Value: i}},
+			Metadata: metadata,
+		})
+		assert.Nil(t, err)
+	}
+	first := (<-events).ResourceVersion
+	second := (<-events).ResourceVersion
+	cancel()
+	for range events {
+	}
+
+	// the capped stream drops its oldest events
+	objectType, err := getObjectTypePrefixForList(metadata)
+	assert.Nil(t, err)
+	err = provider.Client.XTrimMaxLen(context.Background(), eventStreamPrefix+objectType, 2).Err()
+	assert.Nil(t, err)
+
+	// the first event was dropped, so the watch can't resume from it
+	_, err = provider.Watch(context.Background(), states.WatchRequest{
+		Metadata:        metadata,
+		ResourceVersion: first,
+	})
+	assert.True(t, v1alpha2.IsConflict(err))
+
+	// the second event is still in the stream
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events, err = provider.Watch(ctx, states.WatchRequest{
+		Metadata:        metadata,
+		ResourceVersion: second,
+	})
+	assert.Nil(t, err)
+	event := <-events
+	assert.Equal(t, states.WatchEventUpdated, event.Type)
+
+	_, err = provider.Watch(context.Background(), states.WatchRequest{
+		Metadata:        metadata,
+		ResourceVersion: "latest",
+	})
+	assert.Equal(t, v1alpha2.BadRequest, err.(v1alpha2.COAError).State)
+}
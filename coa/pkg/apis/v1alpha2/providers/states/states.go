@@ -65,6 +65,49 @@ type ListRequest struct {
 	FilterType  string                 `json:"filterType"`
 	FilterValue string                 `json:"filterValue"`
 	Metadata    map[string]interface{} `json:"metadata"`
+	PageOptions
+}
+
+// PageOptions selects a page of a sorted list. A zero page size returns all the remaining entries. The continuation
+// token is opaque, it's returned by List when there are more entries and must be passed with the same sort key. The
+// next page starts after the last entry of the previous page, so changes to the list between two pages don't make
+// entries skip or repeat, other than entries whose sort key changed.
+type PageOptions struct {
+	PageSize          int64  `json:"pageSize,omitempty"`
+	ContinuationToken string `json:"continuationToken,omitempty"`
+	// SortBy is a dotted path into the entry body, such as "spec.displayName", prefixed with "-" for descending order.
+	// Entries are sorted by ID when it's empty.
+	SortBy string `json:"sortBy,omitempty"`
+}
+
+// IWatchableStateProvider is implemented by state providers that can stream state changes, so that callers can react
+// to changes instead of polling with List
+type IWatchableStateProvider interface {
+	// Watch streams the changes of the entries in the scope of the request. The returned channel is closed when the
+	// context is cancelled or when the watch can't continue, in which case the caller should List and watch again. A
+	// resource version that is too old to resume from fails with a conflict, and the caller should List again as well.
+	Watch(context.Context, WatchRequest) (<-chan WatchEvent, error)
+}
+type WatchEventType string
+
+const (
+	WatchEventAdded   WatchEventType = "added"
+	WatchEventUpdated WatchEventType = "updated"
+	WatchEventDeleted WatchEventType = "deleted"
+)
+
+type WatchRequest struct {
+	// Metadata scopes the watch with group, resource and namespace. An empty namespace watches all namespaces.
+	Metadata map[string]interface{} `json:"metadata"`
+	// ResourceVersion resumes the watch after the given version, if the provider supports it
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+type WatchEvent struct {
+	Type WatchEventType `json:"type"`
+	// Entry is the entry after the change. For a deletion it's the last known entry, which may only have an ID.
+	Entry           StateEntry             `json:"entry"`
+	ResourceVersion string                 `json:"resourceVersion"`
+	Metadata        map[string]interface{} `json:"metadata"`
 }
 
 func GetObjectState(ctx context.Context, stateProvider IStateProvider, resourceType validation.ResourceType, name string, namespace string) (interface{}, error) {
@@ -110,6 +153,9 @@ func ListObjectStateWithLabels(ctx context.Context, stateProvider IStateProvider
 		},
 		FilterType:  "label",
 		FilterValue: formatLabelSelector(labels),
+		PageOptions: PageOptions{
+			PageSize: count,
+		},
 	})
 	if err != nil {
 		return nil, err
@@ -18,8 +18,24 @@ type ContextKey string
 
 const (
 	COAFastHTTPContextKey ContextKey = "coa-fasthttp-context"
+	COAIdentityContextKey ContextKey = "coa-identity"
 )
 
+// Identity is the authenticated caller of a request, with the roles that are mapped from the claims of its token
+type Identity struct {
+	User  string   `json:"user"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// GetIdentity returns the authenticated caller of a request, which is set by the JWT middleware
+func GetIdentity(ctx context.Context) (Identity, bool) {
+	if ctx == nil {
+		return Identity{}, false
+	}
+	identity, ok := ctx.Value(COAIdentityContextKey).(Identity)
+	return identity, ok
+}
+
 type COARequest struct {
 	Context     context.Context   `json:"-"`
 	Method      string            `json:"method"`
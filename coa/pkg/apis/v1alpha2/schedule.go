@@ -0,0 +1,306 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package v1alpha2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaintenanceWindow is a recurring period in which a scheduled stage is allowed to start
+type MaintenanceWindow struct {
+	// Start is a cron expression for the times the window opens, such as "0 22 * * *"
+	Start string `json:"start"`
+	// Duration is how long the window stays open, such as "4h"
+	Duration string `json:"duration"`
+}
+
+// CronSchedule is a parsed cron expression with the five standard fields: minute, hour, day of month, month and day
+// of week. Fields accept *, lists, ranges and steps, and months and days of week accept their three letter names.
+// The descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are supported too.
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+	location                      *time.Location
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is accepted for Sunday as well
+	dowField = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// cronSearchLimit bounds the search for the next occurrence, so that expressions that never match (such as "0 0 31 2 *")
+// don't loop forever
+const cronSearchLimit = 5
+
+// ParseCronSchedule parses a cron expression evaluated in the given IANA time zone. An empty zone means UTC.
+func ParseCronSchedule(expression string, timeZone string) (*CronSchedule, error) {
+	location, err := LoadScheduleLocation(timeZone)
+	if err != nil {
+		return nil, err
+	}
+	expression = strings.TrimSpace(expression)
+	if v, ok := cronDescriptors[strings.ToLower(expression)]; ok {
+		expression = v
+	}
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression '%s' must have 5 fields, found %d", expression, len(fields))
+	}
+	schedule := &CronSchedule{location: location}
+	if schedule.minute, err = parseCronField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+	if schedule.hour, err = parseCronField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+	if schedule.dom, err = parseCronField(fields[2], domField); err != nil {
+		return nil, err
+	}
+	if schedule.month, err = parseCronField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+	if schedule.dow, err = parseCronField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domAny = fields[2] == "*" || fields[2] == "?"
+	schedule.dowAny = fields[4] == "*" || fields[4] == "?"
+	return schedule, nil
+}
+
+// LoadScheduleLocation returns the location of an IANA time zone name. An empty name means UTC.
+func LoadScheduleLocation(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone '%s': %v", timeZone, err)
+	}
+	return location, nil
+}
+
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			v, err := strconv.Atoi(part[i+1:])
+			if err != nil || v <= 0 {
+				return 0, fmt.Errorf("invalid step in cron field '%s'", field)
+			}
+			step = v
+			part = part[:i]
+		}
+		low, high := spec.min, spec.max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if low, err = parseCronValue(bounds[0], spec); err != nil {
+				return 0, err
+			}
+			if high, err = parseCronValue(bounds[1], spec); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range in cron field '%s'", field)
+			}
+		default:
+			v, err := parseCronValue(part, spec)
+			if err != nil {
+				return 0, err
+			}
+			low = v
+			if step == 1 {
+				high = v
+			}
+		}
+		for i := low; i <= high; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, spec cronField) (int, error) {
+	if v, ok := spec.names[strings.ToLower(value)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil || v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("invalid cron value '%s', expected %d-%d", value, spec.min, spec.max)
+	}
+	return v, nil
+}
+
+// Next returns the first occurrence of the schedule strictly after the given time. It returns the zero time if the
+// schedule doesn't occur in the next few years.
+func (c *CronSchedule) Next(after time.Time) time.Time {
+	t := after.In(c.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + cronSearchLimit
+	for t.Year() <= limit {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.location)
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.location)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.location)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches follows the cron convention: when both day fields are restricted, a day matching either one is enough
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// IsCronSchedule checks if a schedule is a cron expression instead of a single RFC 3339 timestamp
+func IsCronSchedule(schedule string) bool {
+	if schedule == "" {
+		return false
+	}
+	_, err := time.Parse(time.RFC3339, schedule)
+	return err != nil
+}
+
+// ValidateSchedule checks a stage schedule, which is either an RFC 3339 timestamp or a cron expression, together with
+// its time zone and maintenance windows
+func ValidateSchedule(schedule string, timeZone string, windows []MaintenanceWindow) error {
+	if _, err := LoadScheduleLocation(timeZone); err != nil {
+		return err
+	}
+	if IsCronSchedule(schedule) {
+		if _, err := ParseCronSchedule(schedule, timeZone); err != nil {
+			return fmt.Errorf("schedule is neither an RFC 3339 timestamp nor a valid cron expression: %v", err)
+		}
+	}
+	for _, window := range windows {
+		if _, _, err := window.parse(timeZone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NextScheduleTime returns the time a schedule fires after the given time. A timestamp fires at its own time, even if
+// it's in the past.
+func NextScheduleTime(schedule string, timeZone string, after time.Time) (time.Time, error) {
+	if !IsCronSchedule(schedule) {
+		return time.Parse(time.RFC3339, schedule)
+	}
+	cron, err := ParseCronSchedule(schedule, timeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := cron.Next(after)
+	if next.IsZero() {
+		return next, fmt.Errorf("cron expression '%s' has no upcoming occurrence", schedule)
+	}
+	return next, nil
+}
+
+func (w MaintenanceWindow) parse(timeZone string) (*CronSchedule, time.Duration, error) {
+	cron, err := ParseCronSchedule(w.Start, timeZone)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid maintenance window start: %v", err)
+	}
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil || duration <= 0 {
+		return nil, 0, fmt.Errorf("invalid maintenance window duration '%s'", w.Duration)
+	}
+	return cron, duration, nil
+}
+
+// Contains checks if the window is open at the given time
+func (w MaintenanceWindow) Contains(timeZone string, t time.Time) (bool, error) {
+	cron, duration, err := w.parse(timeZone)
+	if err != nil {
+		return false, err
+	}
+	// the latest opening that is still open is the first one after t - duration
+	start := cron.Next(t.Add(-duration))
+	return !start.IsZero() && !start.After(t), nil
+}
+
+// InMaintenanceWindow checks if any of the windows is open at the given time. No windows means no restriction.
+func InMaintenanceWindow(windows []MaintenanceWindow, timeZone string, t time.Time) (bool, error) {
+	if len(windows) == 0 {
+		return true, nil
+	}
+	for _, window := range windows {
+		open, err := window.Contains(timeZone, t)
+		if err != nil {
+			return false, err
+		}
+		if open {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NextMaintenanceWindow returns the next time one of the windows opens after the given time
+func NextMaintenanceWindow(windows []MaintenanceWindow, timeZone string, after time.Time) (time.Time, error) {
+	var next time.Time
+	for _, window := range windows {
+		cron, _, err := window.parse(timeZone)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t := cron.Next(after)
+		if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next, nil
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package v1alpha2
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	ret, err := time.Parse(time.RFC3339, value)
+	assert.Nil(t, err)
+	return ret
+}
+
+func TestParseCronScheduleErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"* * * foo *",
+	} {
+		_, err := ParseCronSchedule(expression, "")
+		assert.NotNil(t, err, expression)
+	}
+	_, err := ParseCronSchedule("* * * * *", "Not/AZone")
+	assert.NotNil(t, err)
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	cases := []struct {
+		expression string
+		after      string
+		expected   string
+	}{
+		{"0 2 * * *", "2024-03-10T01:00:00Z", "2024-03-10T02:00:00Z"},
+		{"0 2 * * *", "2024-03-10T02:00:00Z", "2024-03-11T02:00:00Z"},
+		{"*/15 * * * *", "2024-03-10T01:07:30Z", "2024-03-10T01:15:00Z"},
+		{"30 22 * * MON-FRI", "2024-03-09T00:00:00Z", "2024-03-11T22:30:00Z"},
+		{"0 0 1 JAN *", "2024-03-09T00:00:00Z", "2025-01-01T00:00:00Z"},
+		{"0 0 * * 7", "2024-03-09T00:00:00Z", "2024-03-10T00:00:00Z"},
+		// both day fields are restricted, so either one matches
+		{"0 0 15 * MON", "2024-03-12T00:00:00Z", "2024-03-15T00:00:00Z"},
+		{"@hourly", "2024-03-10T01:07:00Z", "2024-03-10T02:00:00Z"},
+		{"@daily", "2024-03-10T01:07:00Z", "2024-03-11T00:00:00Z"},
+	}
+	for _, c := range cases {
+		cron, err := ParseCronSchedule(c.expression, "")
+		assert.Nil(t, err, c.expression)
+		assert.Equal(t, mustParseTime(t, c.expected), cron.Next(mustParseTime(t, c.after)), c.expression)
+	}
+}
+
+func TestCronScheduleNextNeverMatches(t *testing.T) {
+	cron, err := ParseCronSchedule("0 0 31 2 *", "")
+	assert.Nil(t, err)
+	assert.True(t, cron.Next(mustParseTime(t, "2024-03-10T01:00:00Z")).IsZero())
+}
+
+func TestCronScheduleNextTimeZone(t *testing.T) {
+	cron, err := ParseCronSchedule("0 2 * * *", "Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database is not available")
+	}
+	// 02:00 in Berlin is 01:00 UTC in winter
+	assert.True(t, mustParseTime(t, "2024-01-15T01:00:00Z").Equal(cron.Next(mustParseTime(t, "2024-01-14T12:00:00Z"))))
+	// 02:00 doesn't exist on the day the clocks go forward, so the next run is the day after
+	assert.True(t, mustParseTime(t, "2024-04-01T00:00:00Z").Equal(cron.Next(mustParseTime(t, "2024-03-30T12:00:00Z"))))
+}
+
+func TestMaintenanceWindowContains(t *testing.T) {
+	window := MaintenanceWindow{Start: "0 22 * * *", Duration: "4h"}
+	open, err := window.Contains("", mustParseTime(t, "2024-03-10T23:30:00Z"))
+	assert.Nil(t, err)
+	assert.True(t, open)
+	open, err = window.Contains("", mustParseTime(t, "2024-03-11T01:59:00Z"))
+	assert.Nil(t, err)
+	assert.True(t, open)
+	open, err = window.Contains("", mustParseTime(t, "2024-03-11T02:00:00Z"))
+	assert.Nil(t, err)
+	assert.False(t, open)
+	open, err = window.Contains("", mustParseTime(t, "2024-03-10T21:59:00Z"))
+	assert.Nil(t, err)
+	assert.False(t, open)
+
+	_, err = MaintenanceWindow{Start: "0 22 * * *", Duration: "-1h"}.Contains("", time.Now())
+	assert.NotNil(t, err)
+}
+
+func TestNextMaintenanceWindow(t *testing.T) {
+	windows := []MaintenanceWindow{
+		{Start: "0 22 * * SAT", Duration: "8h"},
+		{Start: "0 1 * * *", Duration: "1h"},
+	}
+	next, err := NextMaintenanceWindow(windows, "", mustParseTime(t, "2024-03-09T12:00:00Z"))
+	assert.Nil(t, err)
+	assert.Equal(t, mustParseTime(t, "2024-03-09T22:00:00Z"), next)
+}
+
+func TestShouldFireAtInsideWindow(t *testing.T) {
+	activationData := ActivationData{
+		Schedule:           "2024-03-10T20:00:00Z",
+		MaintenanceWindows: []MaintenanceWindow{{Start: "0 22 * * *", Duration: "2h"}},
+	}
+	// due, but the window isn't open yet
+	fire, err := activationData.ShouldFireAt(mustParseTime(t, "2024-03-10T21:00:00Z"))
+	assert.Nil(t, err)
+	assert.False(t, fire)
+	fire, err = activationData.ShouldFireAt(mustParseTime(t, "2024-03-10T22:30:00Z"))
+	assert.Nil(t, err)
+	assert.True(t, fire)
+	// the window was missed, so the stage waits for the next one
+	fire, err = activationData.ShouldFireAt(mustParseTime(t, "2024-03-11T03:00:00Z"))
+	assert.Nil(t, err)
+	assert.False(t, fire)
+	fire, err = activationData.ShouldFireAt(mustParseTime(t, "2024-03-11T22:00:00Z"))
+	assert.Nil(t, err)
+	assert.True(t, fire)
+}
+
+func TestResolveCronSchedule(t *testing.T) {
+	activationData := ActivationData{
+		Schedule: "0 2 * * *",
+	}
+	_, err := activationData.ShouldFireAt(mustParseTime(t, "2024-03-10T01:00:00Z"))
+	assert.NotNil(t, err)
+
+	err = activationData.ResolveSchedule(mustParseTime(t, "2024-03-10T01:00:00Z"))
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-03-10T02:00:00Z", activationData.Schedule)
+	fire, err := activationData.ShouldFireAt(mustParseTime(t, "2024-03-10T01:30:00Z"))
+	assert.Nil(t, err)
+	assert.False(t, fire)
+	fire, err = activationData.ShouldFireAt(mustParseTime(t, "2024-03-10T02:01:00Z"))
+	assert.Nil(t, err)
+	assert.True(t, fire)
+
+	activationData.ClearSchedule()
+	assert.False(t, activationData.IsScheduled())
+}
+
+func TestActivationDataScheduleValidation(t *testing.T) {
+	var activationData ActivationData
+	err := json.Unmarshal([]byte(`{"schedule":"0 2 * * *","timeZone":"UTC","maintenanceWindows":[{"start":"0 1 * * *","duration":"2h"}]}`), &activationData)
+	assert.Nil(t, err)
+	assert.True(t, activationData.IsScheduled())
+
+	err = json.Unmarshal([]byte(`{"schedule":"tomorrow"}`), &activationData)
+	assert.NotNil(t, err)
+	err = json.Unmarshal([]byte(`{"maintenanceWindows":[{"start":"0 1 * * *","duration":"soon"}]}`), &activationData)
+	assert.NotNil(t, err)
+}
@@ -56,6 +56,8 @@ const (
 	Updated        State = 8004
 	Deleted        State = 8005
 	// Workflow status
+	Rejected       State = 9992
+	Cancelled      State = 9993
 	Running        State = 9994
 	Paused         State = 9995
 	Done           State = 9996
@@ -209,6 +211,10 @@ func (s State) String() string {
 		return "Updated"
 	case Deleted:
 		return "Deleted"
+	case Rejected:
+		return "Rejected"
+	case Cancelled:
+		return "Cancelled"
 	case Running:
 		return "Running"
 	case Paused:
@@ -34,6 +34,8 @@ func TestStateString(t *testing.T) {
 		ValidateFailed:                "Validate Failed",
 		Updated:                       "Updated",
 		Deleted:                       "Deleted",
+		Rejected:                      "Rejected",
+		Cancelled:                     "Cancelled",
 		Running:                       "Running",
 		Paused:                        "Paused",
 		Done:                          "Done",
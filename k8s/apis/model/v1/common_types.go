@@ -10,9 +10,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/coa/pkg/apis/v1alpha2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -71,6 +71,8 @@ type ComponentSpec struct {
 	Dependencies []string             `json:"dependencies,omitempty"`
 	Skills       []string             `json:"skills,omitempty"`
 	Sidecars     []SidecarSpec        `json:"sidecars,omitempty"`
+	// Readiness is checked by the target provider after the component is applied
+	Readiness *model.ReadinessSpec `json:"readiness,omitempty"`
 }
 
 // Defines the desired state of Target
@@ -90,6 +92,10 @@ type TargetSpec struct {
 	// Now only periodic reconciliation is supported. If the interval is 0, it will only reconcile
 	// when the instance is created or updated.
 	ReconciliationPolicy *ReconciliationPolicySpec `json:"reconciliationPolicy,omitempty"`
+
+	// Optional RetryPolicy to specify how failed deployment steps on the target are retried. It takes
+	// precedence over the retry policy of the instance.
+	RetryPolicy *model.RetryPolicySpec `json:"retryPolicy,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -108,6 +114,18 @@ type InstanceSpec struct {
 	// Now only periodic reconciliation is supported. If the interval is 0, it will only reconcile
 	// when the instance is created or updated.
 	ReconciliationPolicy *ReconciliationPolicySpec `json:"reconciliationPolicy,omitempty"`
+
+	// Optional RollbackPolicy to specify whether targets should be restored to the last successfully
+	// deployed state when a deployment step fails.
+	RollbackPolicy *model.RollbackPolicySpec `json:"rollbackPolicy,omitempty"`
+
+	// Optional RetryPolicy to specify how failed deployment steps are retried. A retry policy on a target
+	// takes precedence over this one.
+	RetryPolicy *model.RetryPolicySpec `json:"retryPolicy,omitempty"`
+
+	// Optional Rollout to deploy to the targets selected by the instance in waves. A wave only starts after
+	// the targets of the previous wave pass the health gate.
+	Rollout *model.RolloutSpec `json:"rollout,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -136,7 +154,41 @@ type StageSpec struct {
 	// +kubebuilder:validation:Schemaless
 	Inputs          runtime.RawExtension `json:"inputs,omitempty"`
 	TriggeringStage string               `json:"triggeringStage,omitempty"`
-	Schedule        string               `json:"schedule,omitempty"`
+	// Schedule is either an RFC 3339 timestamp or a cron expression
+	Schedule string `json:"schedule,omitempty"`
+	// TimeZone is the IANA time zone the cron expressions are evaluated in, UTC by default
+	TimeZone           string              `json:"timeZone,omitempty"`
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// Timeout limits each run of the stage provider for a site, as a duration like "10m"
+	Timeout string `json:"timeout,omitempty"`
+	// MaxRetries is the number of times a failed or timed out stage provider is run again before the stage fails
+	// +kubebuilder:validation:Minimum=0
+	MaxRetries int `json:"maxRetries,omitempty"`
+	// RetryBackoff is the wait before the first retry, which is doubled on each retry up to MaxRetryBackoff
+	RetryBackoff    string `json:"retryBackoff,omitempty"`
+	MaxRetryBackoff string `json:"maxRetryBackoff,omitempty"`
+	// MaxParallel limits the number of sites of the contexts the stage runs for at once, no limit by default
+	// +kubebuilder:validation:Minimum=0
+	MaxParallel int `json:"maxParallel,omitempty"`
+	// SuccessThreshold is the number of sites, like "498", or the percentage of sites, like "99%", that have to succeed
+	// for the stage to succeed. All sites have to succeed by default.
+	SuccessThreshold string `json:"successThreshold,omitempty"`
+}
+
+// +kubebuilder:object:generate=true
+type MaintenanceWindow struct {
+	// Start is a cron expression for the times the window opens
+	Start string `json:"start"`
+	// Duration is how long the window stays open, such as "4h"
+	Duration string `json:"duration"`
+}
+
+func (s StageSpec) validateSchedule() error {
+	windows := make([]v1alpha2.MaintenanceWindow, 0, len(s.MaintenanceWindows))
+	for _, window := range s.MaintenanceWindows {
+		windows = append(windows, v1alpha2.MaintenanceWindow{Start: window.Start, Duration: window.Duration})
+	}
+	return v1alpha2.ValidateSchedule(s.Schedule, s.TimeZone, windows)
 }
 
 // UnmarshalJSON customizes the JSON unmarshalling for StageSpec
@@ -150,11 +202,9 @@ func (s *StageSpec) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
 	}
-	// validate if Schedule meet RFC 3339
-	if s.Schedule != "" {
-		if _, err := time.Parse(time.RFC3339, s.Schedule); err != nil {
-			return fmt.Errorf("invalid timestamp format: %v", err)
-		}
+	// validate if Schedule is an RFC 3339 timestamp or a cron expression
+	if err := s.validateSchedule(); err != nil {
+		return fmt.Errorf("invalid schedule: %v", err)
 	}
 	return nil
 }
@@ -162,10 +212,8 @@ func (s *StageSpec) UnmarshalJSON(data []byte) error {
 // MarshalJSON customizes the JSON marshalling for StageSpec
 func (s StageSpec) MarshalJSON() ([]byte, error) {
 	type Alias StageSpec
-	if s.Schedule != "" {
-		if _, err := time.Parse(time.RFC3339, s.Schedule); err != nil {
-			return nil, fmt.Errorf("invalid timestamp format: %v", err)
-		}
+	if err := s.validateSchedule(); err != nil {
+		return nil, fmt.Errorf("invalid schedule: %v", err)
 	}
 	return json.Marshal(&struct {
 		*Alias
@@ -181,6 +229,12 @@ type ActivationSpec struct {
 	// +kubebuilder:pruning:PreserveUnknownFields
 	// +kubebuilder:validation:Schemaless
 	Inputs runtime.RawExtension `json:"inputs,omitempty"`
+	// DryRun runs every stage with a simulated provider instead of its stage provider
+	DryRun bool `json:"dryRun,omitempty"`
+	// DryRunOutputs are the canned outputs of the simulated providers, keyed by stage name
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	DryRunOutputs runtime.RawExtension `json:"dryRunOutputs,omitempty"`
 }
 
 // +kubebuilder:object:generate=true
@@ -24,3 +24,23 @@ func TestStageSpecSchedule(t *testing.T) {
 		t.Fatalf("Failed to unmarshal JSON: %v", err)
 	}
 }
+
+func TestInstanceSpecDeploymentPolicies(t *testing.T) {
+	jsonString := `{"solution": "app", "rollbackPolicy": {"enabled": true}, "retryPolicy": {"maxAttempts": 3, "initialBackoff": "5s"}, "rollout": {"waves": [{"name": "canary", "targets": 1}], "pauseBetweenWaves": "10m"}}`
+
+	var spec InstanceSpec
+	if err := json.Unmarshal([]byte(jsonString), &spec); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v", err)
+	}
+	copied := spec.DeepCopy()
+	spec.Rollout.Waves[0].Targets = 2
+	if copied.RollbackPolicy == nil || !copied.RollbackPolicy.Enabled {
+		t.Fatalf("rollbackPolicy is not copied: %v", copied.RollbackPolicy)
+	}
+	if copied.RetryPolicy == nil || copied.RetryPolicy.MaxAttempts != 3 || copied.RetryPolicy.InitialBackoff != "5s" {
+		t.Fatalf("retryPolicy is not copied: %v", copied.RetryPolicy)
+	}
+	if copied.Rollout == nil || copied.Rollout.PauseBetweenWaves != "10m" || len(copied.Rollout.Waves) != 1 || copied.Rollout.Waves[0].Targets != 1 {
+		t.Fatalf("rollout is not deep copied: %v", copied.Rollout)
+	}
+}
@@ -18,6 +18,7 @@ import (
 func (in *ActivationSpec) DeepCopyInto(out *ActivationSpec) {
 	*out = *in
 	in.Inputs.DeepCopyInto(&out.Inputs)
+	in.DryRunOutputs.DeepCopyInto(&out.DryRunOutputs)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActivationSpec.
@@ -141,6 +142,11 @@ func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(model.ReadinessSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentSpec.
@@ -251,6 +257,21 @@ func (in *InstanceSpec) DeepCopyInto(out *InstanceSpec) {
 		*out = new(ReconciliationPolicySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RollbackPolicy != nil {
+		in, out := &in.RollbackPolicy, &out.RollbackPolicy
+		*out = new(model.RollbackPolicySpec)
+		**out = **in
+	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(model.RetryPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(model.RolloutSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSpec.
@@ -263,6 +284,21 @@ func (in *InstanceSpec) DeepCopy() *InstanceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReconciliationPolicySpec) DeepCopyInto(out *ReconciliationPolicySpec) {
 	*out = *in
@@ -348,6 +384,11 @@ func (in *StageSpec) DeepCopyInto(out *StageSpec) {
 	*out = *in
 	in.Config.DeepCopyInto(&out.Config)
 	in.Inputs.DeepCopyInto(&out.Inputs)
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StageSpec.
@@ -396,6 +437,11 @@ func (in *TargetSpec) DeepCopyInto(out *TargetSpec) {
 		*out = new(ReconciliationPolicySpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RetryPolicy != nil {
+		in, out := &in.RetryPolicy, &out.RetryPolicy
+		*out = new(model.RetryPolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetSpec.
@@ -20,6 +20,9 @@ type ActivationStatus struct {
 	ActivationGeneration string         `json:"activationGeneration,omitempty"`
 	UpdateTime           string         `json:"updateTime,omitempty"`
 	StageHistory         []StageStatus  `json:"stageHistory,omitempty"`
+	PauseRequested       bool           `json:"pauseRequested,omitempty"`
+	ParentActivation     string         `json:"parentActivation,omitempty"`
+	ChildActivations     []string       `json:"childActivations,omitempty"`
 }
 
 type StageStatus struct {
@@ -35,6 +38,17 @@ type StageStatus struct {
 	StatusMessage string               `json:"statusMessage,omitempty"`
 	ErrorMessage  string               `json:"errorMessage,omitempty"`
 	IsActive      bool                 `json:"isActive,omitempty"`
+	Attempts      []StageAttempt       `json:"attempts,omitempty"`
+}
+
+// StageAttempt records a run of the stage provider for a site
+type StageAttempt struct {
+	Site         string         `json:"site,omitempty"`
+	Attempt      int            `json:"attempt,omitempty"`
+	Status       v1alpha2.State `json:"status,omitempty"`
+	ErrorMessage string         `json:"errorMessage,omitempty"`
+	StartTime    string         `json:"startTime,omitempty"`
+	EndTime      string         `json:"endTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
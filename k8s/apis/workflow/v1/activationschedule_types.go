@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type ActivationScheduleSpec struct {
+	// Schedule is a cron expression for the times an activation is created
+	Schedule string `json:"schedule"`
+	// TimeZone is the IANA time zone the schedule is evaluated in, UTC by default
+	TimeZone string `json:"timeZone,omitempty"`
+	Campaign string `json:"campaign"`
+	Stage    string `json:"stage,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	Inputs runtime.RawExtension `json:"inputs,omitempty"`
+	// ConcurrencyPolicy decides what happens when the schedule fires while an activation it created is still running
+	// +kubebuilder:validation:Enum=Allow;Forbid;Replace
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+	Suspend           bool   `json:"suspend,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulHistoryLimit *int32 `json:"successfulHistoryLimit,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	FailedHistoryLimit *int32 `json:"failedHistoryLimit,omitempty"`
+}
+
+type ActivationScheduleStatus struct {
+	LastScheduleTime string   `json:"lastScheduleTime,omitempty"`
+	NextScheduleTime string   `json:"nextScheduleTime,omitempty"`
+	Active           []string `json:"active,omitempty"`
+	Succeeded        []string `json:"succeeded,omitempty"`
+	Failed           []string `json:"failed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+// +kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+// +kubebuilder:printcolumn:name="Last Schedule",type=string,JSONPath=`.status.lastScheduleTime`
+// ActivationSchedule is the Schema for the activationschedules API
+type ActivationSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ActivationScheduleSpec   `json:"spec,omitempty"`
+	Status ActivationScheduleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// ActivationScheduleList contains a list of ActivationSchedule
+type ActivationScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ActivationSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ActivationSchedule{}, &ActivationScheduleList{})
+}
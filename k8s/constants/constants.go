@@ -33,16 +33,17 @@ const (
 	ActivityOperation_Read       = "Read"
 	ActivityOperation_Delete     = "Delete"
 
-	SolutionContainerOperationNamePrefix = "solutioncontainers.solution." + FullGroupName
-	SolutionOperationNamePrefix          = "solutions.solution." + FullGroupName
-	TargetOperationNamePrefix            = "targets.fabric." + FullGroupName
-	InstanceOperationNamePrefix          = "instances.solution." + FullGroupName
-	ActivationOperationNamePrefix        = "activations.workflow." + FullGroupName
-	CatalogOperationNamePrefix           = "catalogs.federation." + FullGroupName
-	CatalogEvalOperationNamePrefix       = "catalogevalexpression.federation." + FullGroupName
-	CampaignOperationNamePrefix          = "campaigns.workflow." + FullGroupName
-	CampaignContainerOperationNamePrefix = "campaigncontainers.workflow." + FullGroupName
-	DiagnosticsOperationNamePrefix       = "diagnostics.monitor." + FullGroupName
+	SolutionContainerOperationNamePrefix  = "solutioncontainers.solution." + FullGroupName
+	SolutionOperationNamePrefix           = "solutions.solution." + FullGroupName
+	TargetOperationNamePrefix             = "targets.fabric." + FullGroupName
+	InstanceOperationNamePrefix           = "instances.solution." + FullGroupName
+	ActivationOperationNamePrefix         = "activations.workflow." + FullGroupName
+	ActivationScheduleOperationNamePrefix = "activationschedules.workflow." + FullGroupName
+	CatalogOperationNamePrefix            = "catalogs.federation." + FullGroupName
+	CatalogEvalOperationNamePrefix        = "catalogevalexpression.federation." + FullGroupName
+	CampaignOperationNamePrefix           = "campaigns.workflow." + FullGroupName
+	CampaignContainerOperationNamePrefix  = "campaigncontainers.workflow." + FullGroupName
+	DiagnosticsOperationNamePrefix        = "diagnostics.monitor." + FullGroupName
 )
 
 // Environment variables keys
@@ -77,7 +77,7 @@ func (r *ActivationReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	if activation.ObjectMeta.DeletionTimestamp.IsZero() {
 		diagnostic.InfoWithCtx(log, ctx, fmt.Sprintf("Activation status: %v", activation.Status.Status))
 		if activation.Status.UpdateTime == "" && activation.ObjectMeta.Labels[api_constants.StatusMessage] == "" &&
-			activation.Status.Status != v1alpha2.Paused && activation.Status.Status != v1alpha2.Done && activation.Status.ActivationGeneration == "" {
+			activation.Status.Status != v1alpha2.Paused && activation.Status.Status != v1alpha2.Done && activation.Status.Status != v1alpha2.Cancelled && activation.Status.Status != v1alpha2.Rejected && activation.Status.ActivationGeneration == "" {
 			diagnostic.InfoWithCtx(log, ctx, "Publishing activation event", "Name", activation.Name, "Namespace", activation.Namespace)
 			err := r.ApiClient.PublishActivationEvent(ctx, v1alpha2.ActivationData{
 				Campaign:             activation.Spec.Campaign,
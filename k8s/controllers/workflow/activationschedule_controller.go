@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) Microsoft Corporation.
+ * Licensed under the MIT license.
+ * SPDX-License-Identifier: MIT
+ */
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	api_constants "github.com/eclipse-symphony/symphony/api/constants"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/model"
+	"github.com/eclipse-symphony/symphony/api/pkg/apis/v1alpha1/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workflowv1 "gopls-workspace/apis/workflow/v1"
+	"gopls-workspace/configutils"
+	"gopls-workspace/constants"
+	"gopls-workspace/utils/diagnostic"
+
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ActivationScheduleReconciler reconciles an ActivationSchedule object
+type ActivationScheduleReconciler struct {
+	client.Client
+	Scheme    *runtime.Scheme
+	ApiClient utils.ApiClient
+}
+
+//+kubebuilder:rbac:groups=workflow.symphony,resources=activationschedules,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=workflow.symphony,resources=activationschedules/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=workflow.symphony,resources=activationschedules/finalizers,verbs=update
+
+// Reconcile creates the activation of a schedule that is due, applies its concurrency policy, deletes the finished
+// activations beyond its history limits and requeues the schedule for its next occurrence
+func (r *ActivationScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+	diagnostic.InfoWithCtx(log, ctx, "Reconciling ActivationSchedule", "Name", req.Name, "Namespace", req.Namespace)
+
+	schedule := &workflowv1.ActivationSchedule{}
+	if err := r.Get(ctx, req.NamespacedName, schedule); err != nil {
+		diagnostic.ErrorWithCtx(log, ctx, err, "unable to fetch ActivationSchedule")
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !schedule.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	resourceK8SId := schedule.GetNamespace() + "/" + schedule.GetName()
+	operationName := fmt.Sprintf("%s/%s", constants.ActivationScheduleOperationNamePrefix, constants.ActivityOperation_Write)
+	ctx = configutils.PopulateActivityAndDiagnosticsContextFromAnnotations(schedule.GetNamespace(), resourceK8SId, schedule.Annotations, operationName, r, ctx, log)
+
+	state := toActivationScheduleState(schedule)
+	if err := state.Spec.Validate(); err != nil {
+		diagnostic.ErrorWithCtx(log, ctx, err, "invalid ActivationSchedule")
+		return ctrl.Result{}, nil
+	}
+
+	var activations workflowv1.ActivationList
+	if err := r.List(ctx, &activations, client.InNamespace(schedule.Namespace), client.MatchingLabels{api_constants.ActivationSchedule: schedule.Name}); err != nil {
+		diagnostic.ErrorWithCtx(log, ctx, err, "unable to list activations of ActivationSchedule")
+		return ctrl.Result{}, err
+	}
+	scheduled := make([]model.ScheduledActivation, 0, len(activations.Items))
+	for _, activation := range activations.Items {
+		scheduled = append(scheduled, model.NewScheduledActivation(activation.Name, &model.ActivationStatus{
+			Status: activation.Status.Status,
+		}))
+	}
+
+	now := time.Now().UTC()
+	plan, err := state.Plan(scheduled, now)
+	if err != nil {
+		diagnostic.ErrorWithCtx(log, ctx, err, "unable to plan ActivationSchedule")
+		return ctrl.Result{}, nil
+	}
+	for _, name := range plan.Cancel {
+		diagnostic.InfoWithCtx(log, ctx, "Replacing running activation", "Name", name, "Namespace", schedule.Namespace)
+		if err := r.ApiClient.CancelActivation(ctx, name, schedule.Namespace, "", ""); err != nil && !utils.IsNotFound(err) {
+			diagnostic.ErrorWithCtx(log, ctx, err, "unable to cancel activation", "Name", name)
+		}
+	}
+	if plan.Create != "" {
+		diagnostic.InfoWithCtx(log, ctx, "Creating scheduled activation", "Name", plan.Create, "Namespace", schedule.Namespace)
+		activation := &workflowv1.Activation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      plan.Create,
+				Namespace: schedule.Namespace,
+				Labels: map[string]string{
+					api_constants.ActivationSchedule: schedule.Name,
+				},
+			},
+		}
+		activation.Spec.Campaign = schedule.Spec.Campaign
+		activation.Spec.Stage = schedule.Spec.Stage
+		schedule.Spec.Inputs.DeepCopyInto(&activation.Spec.Inputs)
+		if err := r.Create(ctx, activation); err != nil && !apierrors.IsAlreadyExists(err) {
+			// the status isn't updated, so the activation is created on the next reconcile
+			diagnostic.ErrorWithCtx(log, ctx, err, "unable to create scheduled activation")
+			return ctrl.Result{}, err
+		}
+	}
+	for _, name := range plan.Delete {
+		diagnostic.InfoWithCtx(log, ctx, "Deleting activation beyond history limit", "Name", name, "Namespace", schedule.Namespace)
+		activation := &workflowv1.Activation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: schedule.Namespace,
+			},
+		}
+		if err := r.Delete(ctx, activation); err != nil && !apierrors.IsNotFound(err) {
+			diagnostic.ErrorWithCtx(log, ctx, err, "unable to delete activation", "Name", name)
+		}
+	}
+
+	schedule.Status = workflowv1.ActivationScheduleStatus{
+		LastScheduleTime: plan.Status.LastScheduleTime,
+		NextScheduleTime: plan.Status.NextScheduleTime,
+		Active:           plan.Status.Active,
+		Succeeded:        plan.Status.Succeeded,
+		Failed:           plan.Status.Failed,
+	}
+	if err := r.Status().Update(ctx, schedule); err != nil {
+		diagnostic.ErrorWithCtx(log, ctx, err, "unable to update ActivationSchedule status")
+		return ctrl.Result{}, err
+	}
+
+	// activations don't change the schedule, so their progress is picked up by polling once a minute
+	requeueAfter := time.Minute
+	if next, err := time.Parse(time.RFC3339, plan.Status.NextScheduleTime); err == nil && next.Sub(now) < requeueAfter {
+		requeueAfter = next.Sub(now)
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+func toActivationScheduleState(schedule *workflowv1.ActivationSchedule) model.ActivationScheduleState {
+	state := model.ActivationScheduleState{
+		ObjectMeta: model.ObjectMeta{
+			Name:      schedule.Name,
+			Namespace: schedule.Namespace,
+		},
+		Spec: &model.ActivationScheduleSpec{
+			Schedule:          schedule.Spec.Schedule,
+			TimeZone:          schedule.Spec.TimeZone,
+			Campaign:          schedule.Spec.Campaign,
+			Stage:             schedule.Spec.Stage,
+			Inputs:            convertRawExtensionToMap(&schedule.Spec.Inputs),
+			ConcurrencyPolicy: schedule.Spec.ConcurrencyPolicy,
+			Suspend:           schedule.Spec.Suspend,
+		},
+		Status: &model.ActivationScheduleStatus{
+			LastScheduleTime: schedule.Status.LastScheduleTime,
+			NextScheduleTime: schedule.Status.NextScheduleTime,
+			Active:           schedule.Status.Active,
+			Succeeded:        schedule.Status.Succeeded,
+			Failed:           schedule.Status.Failed,
+		},
+	}
+	if schedule.Spec.SuccessfulHistoryLimit != nil {
+		limit := int(*schedule.Spec.SuccessfulHistoryLimit)
+		state.Spec.SuccessfulHistoryLimit = &limit
+	}
+	if schedule.Spec.FailedHistoryLimit != nil {
+		limit := int(*schedule.Spec.FailedHistoryLimit)
+		state.Spec.FailedHistoryLimit = &limit
+	}
+	return state
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ActivationScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&workflowv1.ActivationSchedule{}).
+		Complete(r)
+}
@@ -303,6 +303,14 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Activation")
 		os.Exit(1)
 	}
+	if err = (&workflowcontrollers.ActivationScheduleReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		ApiClient: apiClient,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ActivationSchedule")
+		os.Exit(1)
+	}
 	if err = (&solutioncontrollers.InstanceQueueingReconciler{
 		InstanceReconciler: solutioncontrollers.InstanceReconciler{
 			Client:                 mgr.GetClient(),